@@ -34,7 +34,9 @@ import (
 	iamv1alpha2 "kubesphere.io/api/iam/v1alpha2"
 
 	"kubesphere.io/kubesphere/cmd/controller-manager/app/options"
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider"
 	"kubesphere.io/kubesphere/pkg/controller/alerting"
+	"kubesphere.io/kubesphere/pkg/controller/apiservice"
 	"kubesphere.io/kubesphere/pkg/controller/application"
 	"kubesphere.io/kubesphere/pkg/controller/certificatesigningrequest"
 	"kubesphere.io/kubesphere/pkg/controller/cluster"
@@ -44,6 +46,7 @@ import (
 	"kubesphere.io/kubesphere/pkg/controller/globalrolebinding"
 	"kubesphere.io/kubesphere/pkg/controller/group"
 	"kubesphere.io/kubesphere/pkg/controller/groupbinding"
+	"kubesphere.io/kubesphere/pkg/controller/groupsync"
 	"kubesphere.io/kubesphere/pkg/controller/helm"
 	"kubesphere.io/kubesphere/pkg/controller/job"
 	"kubesphere.io/kubesphere/pkg/controller/loginrecord"
@@ -109,11 +112,13 @@ var allControllers = []string{
 	"globalrolebinding",
 	"groupbinding",
 	"group",
+	"groupsync",
 	"notification",
 	"pvcworkloadrestarter",
 	"rulegroup",
 	"clusterrulegroup",
 	"globalrulegroup",
+	"apiservice",
 }
 
 // setup all available controllers one by one
@@ -253,6 +258,7 @@ func addAllControllers(mgr manager.Manager, client k8s.Client, informerFactory i
 			MultiClusterEnable: cmOptions.MultiClusterOptions.Enable,
 			WaitTime:           cmOptions.OpenPitrixOptions.ReleaseControllerOptions.WaitTime,
 			MaxConcurrent:      cmOptions.OpenPitrixOptions.ReleaseControllerOptions.MaxConcurrent,
+			DriftCheckInterval: cmOptions.OpenPitrixOptions.ReleaseControllerOptions.DriftCheckInterval,
 			StopChan:           stopCh,
 		}
 		addControllerWithSetup(mgr, "helmrelease", reconcileHelmRelease)
@@ -284,6 +290,12 @@ func addAllControllers(mgr manager.Manager, client k8s.Client, informerFactory i
 		addControllerWithSetup(mgr, "serviceaccount", saReconciler)
 	}
 
+	// "apiservice" controller
+	if cmOptions.IsControllerEnabled("apiservice") {
+		apiServiceReconciler := &apiservice.Reconciler{}
+		addControllerWithSetup(mgr, "apiservice", apiServiceReconciler)
+	}
+
 	// "resourcequota" controller
 	if cmOptions.IsControllerEnabled("resourcequota") {
 		resourceQuotaReconciler := &quota.Reconciler{
@@ -323,7 +335,7 @@ func addAllControllers(mgr manager.Manager, client k8s.Client, informerFactory i
 
 	// "job" controller
 	if cmOptions.IsControllerEnabled("job") {
-		jobController := job.NewJobController(kubernetesInformer.Batch().V1().Jobs(), client.Kubernetes())
+		jobController := job.NewJobController(kubernetesInformer, client.Kubernetes())
 		addController(mgr, "job", jobController)
 	}
 
@@ -469,6 +481,18 @@ func addAllControllers(mgr manager.Manager, client k8s.Client, informerFactory i
 		addController(mgr, "group", groupController)
 	}
 
+	// "groupsync" controller
+	if cmOptions.IsControllerEnabled("groupsync") {
+		identityProviders := cmOptions.AuthenticationOptions.OAuthOptions.IdentityProviders
+		if err := identityprovider.SetupWithOptions(identityProviders); err != nil {
+			klog.Fatalf("Unable to setup identity providers: %v", err)
+		}
+		groupSyncController := groupsync.NewController(client.KubeSphere(),
+			kubesphereInformer.Iam().V1alpha2().Users(),
+			identityProviders)
+		addController(mgr, "groupsync", groupSyncController)
+	}
+
 	// "cluster" controller
 	if cmOptions.IsControllerEnabled("cluster") {
 		if cmOptions.MultiClusterOptions.Enable {
@@ -0,0 +1,46 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+const (
+	configApplied  = "ConfigApplied"
+	configRejected = "ConfigRejected"
+)
+
+// the events of configuration changes are recorded on the ConfigMap the configuration is mounted from
+var configReference = &corev1.ObjectReference{
+	APIVersion: "v1",
+	Kind:       "ConfigMap",
+	Namespace:  constants.KubeSphereNamespace,
+	Name:       constants.KubeSphereConfigName,
+}
+
+func newConfigEventRecorder(client kubernetes.Interface) (record.EventRecorder, func()) {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "ks-apiserver"}), eventBroadcaster.Shutdown
+}
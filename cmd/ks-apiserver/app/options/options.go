@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
 	"sync"
 
@@ -33,6 +34,7 @@ import (
 
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
 
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/kubernetes/scheme"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog/v2"
@@ -92,6 +94,10 @@ func (s *ServerRunOptions) Flags() (fss cliflag.NamedFlagSets) {
 	s.EventsOptions.AddFlags(fss.FlagSet("events"), s.EventsOptions)
 	s.AuditingOptions.AddFlags(fss.FlagSet("auditing"), s.AuditingOptions)
 	s.AlertingOptions.AddFlags(fss.FlagSet("alerting"), s.AlertingOptions)
+	s.VulnerabilityOptions.AddFlags(fss.FlagSet("vulnerability"), s.VulnerabilityOptions)
+	s.RateLimitOptions.AddFlags(fss.FlagSet("ratelimit"), s.RateLimitOptions)
+	s.ComponentsOptions.AddFlags(fss.FlagSet("components"), s.ComponentsOptions)
+	s.TerminalOptions.AddFlags(fss.FlagSet("terminal"), s.TerminalOptions)
 
 	fs = fss.FlagSet("klog")
 	local := flag.NewFlagSet("klog", flag.ExitOnError)
@@ -107,7 +113,8 @@ func (s *ServerRunOptions) Flags() (fss cliflag.NamedFlagSets) {
 // NewAPIServer creates an APIServer instance using given options
 func (s *ServerRunOptions) NewAPIServer(stopCh <-chan struct{}) (*apiserver.APIServer, error) {
 	apiServer := &apiserver.APIServer{
-		Config: s.Config,
+		Config:          s.Config,
+		ConfigRevisions: apiserverconfig.NewRevisionTracker(),
 	}
 
 	kubernetesClient, err := k8s.NewKubernetesClient(s.KubernetesOptions)
@@ -120,63 +127,19 @@ func (s *ServerRunOptions) NewAPIServer(stopCh <-chan struct{}) (*apiserver.APIS
 		kubernetesClient.Istio(), kubernetesClient.Snapshot(), kubernetesClient.ApiExtensions(), kubernetesClient.Prometheus())
 	apiServer.InformerFactory = informerFactory
 
-	if s.MonitoringOptions == nil || len(s.MonitoringOptions.Endpoint) == 0 {
-		return nil, fmt.Errorf("moinitoring service address in configuration MUST not be empty, please check configmap/kubesphere-config in kubesphere-system namespace")
-	} else {
-		if apiServer.MonitoringClient, err = prometheus.NewPrometheus(s.MonitoringOptions); err != nil {
-			return nil, fmt.Errorf("failed to connect to prometheus, please check prometheus status, error: %v", err)
-		}
-	}
-
 	apiServer.MetricsClient = metricsserver.NewMetricsClient(kubernetesClient.Kubernetes(), s.KubernetesOptions)
 
-	if s.LoggingOptions.Host != "" {
-		if apiServer.LoggingClient, err = esclient.NewClient(s.LoggingOptions); err != nil {
-			return nil, fmt.Errorf("failed to connect to elasticsearch, please check elasticsearch status, error: %v", err)
-		}
-	}
-
-	if s.DevopsOptions.Host != "" {
-		if apiServer.DevopsClient, err = jenkins.NewDevopsClient(s.DevopsOptions); err != nil {
-			return nil, fmt.Errorf("failed to connect to jenkins, please check jenkins status, error: %v", err)
-		}
-	}
-
-	if s.SonarQubeOptions.Host != "" {
-		sonarClient, err := sonarqube.NewSonarQubeClient(s.SonarQubeOptions)
-		if err != nil {
-			return nil, fmt.Errorf("failed to connecto to sonarqube, please check sonarqube status, error: %v", err)
-		}
-		apiServer.SonarClient = sonarqube.NewSonar(sonarClient.SonarQube())
-	}
-
 	if apiServer.CacheClient, err = cache.New(s.CacheOptions, stopCh); err != nil {
 		return nil, fmt.Errorf("failed to create cache, error: %v", err)
 	}
 
-	if s.EventsOptions.Host != "" {
-		if apiServer.EventsClient, err = eventsclient.NewClient(s.EventsOptions); err != nil {
-			return nil, fmt.Errorf("failed to connect to elasticsearch, please check elasticsearch status, error: %v", err)
-		}
-	}
-
-	if s.AuditingOptions.Host != "" {
-		if apiServer.AuditingClient, err = auditingclient.NewClient(s.AuditingOptions); err != nil {
-			return nil, fmt.Errorf("failed to connect to elasticsearch, please check elasticsearch status, error: %v", err)
-		}
-	}
-
-	if s.AlertingOptions != nil && (s.AlertingOptions.PrometheusEndpoint != "" || s.AlertingOptions.ThanosRulerEndpoint != "") {
-		if apiServer.AlertingClient, err = alerting.NewRuleClient(s.AlertingOptions); err != nil {
-			return nil, fmt.Errorf("failed to init alerting client: %v", err)
-		}
-	}
-
 	if s.Config.MultiClusterOptions.Enable {
 		apiServer.ClusterClient = clusterclient.NewClusterClient(informerFactory.KubeSphereSharedInformerFactory().Cluster().V1alpha1().Clusters())
 	}
 
-	apiServer.OpenpitrixClient = openpitrixv1.NewOpenpitrixClient(informerFactory, apiServer.KubernetesClient.KubeSphere(), s.OpenPitrixOptions, apiServer.ClusterClient)
+	if err = newClients(apiServer); err != nil {
+		return nil, err
+	}
 
 	server := &http.Server{
 		Addr: fmt.Sprintf(":%d", s.GenericServerRunOptions.InsecurePort),
@@ -194,6 +157,14 @@ func (s *ServerRunOptions) NewAPIServer(stopCh <-chan struct{}) (*apiserver.APIS
 		server.Addr = fmt.Sprintf(":%d", s.GenericServerRunOptions.SecurePort)
 	}
 
+	if s.GenericServerRunOptions.ProxyClientCertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(s.GenericServerRunOptions.ProxyClientCertFile, s.GenericServerRunOptions.ProxyClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load proxy client certificate: %v", err)
+		}
+		apiServer.ProxyClientCert = &certificate
+	}
+
 	sch := scheme.Scheme
 	s.schemeOnce.Do(func() {
 		if err := apis.AddToScheme(sch); err != nil {
@@ -216,12 +187,114 @@ func (s *ServerRunOptions) NewAPIServer(stopCh <-chan struct{}) (*apiserver.APIS
 		klog.Fatalf("unable to create controller runtime client: %v", err)
 	}
 
-	apiServer.Issuer, err = token.NewIssuer(s.AuthenticationOptions)
-	if err != nil {
-		klog.Fatalf("unable to create issuer: %v", err)
-	}
-
 	apiServer.Server = server
 
 	return apiServer, nil
 }
+
+// ReloadAPIServer creates an APIServer instance using the given configuration, which shares the server,
+// the Kubernetes clients, caches and the cache client with current, and rebuilds the clients of the other services.
+func (s *ServerRunOptions) ReloadAPIServer(current *apiserver.APIServer, conf *apiserverconfig.Config) (*apiserver.APIServer, error) {
+	reloaded := &ServerRunOptions{
+		GenericServerRunOptions: s.GenericServerRunOptions,
+		Config:                  conf,
+	}
+	if errs := reloaded.Validate(); len(errs) != 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	if !reflect.DeepEqual(current.Config.KubernetesOptions, conf.KubernetesOptions) ||
+		!reflect.DeepEqual(current.Config.CacheOptions, conf.CacheOptions) ||
+		!reflect.DeepEqual(current.Config.MultiClusterOptions, conf.MultiClusterOptions) {
+		return nil, fmt.Errorf("changes of kubernetes, cache and multicluster options take effect after ks-apiserver restarts")
+	}
+
+	apiServer := &apiserver.APIServer{
+		Server:           current.Server,
+		Config:           conf,
+		ConfigRevisions:  current.ConfigRevisions,
+		KubernetesClient: current.KubernetesClient,
+		InformerFactory:  current.InformerFactory,
+		MetricsClient:    current.MetricsClient,
+		CacheClient:      current.CacheClient,
+		ClusterClient:    current.ClusterClient,
+		RuntimeCache:     current.RuntimeCache,
+		RuntimeClient:    current.RuntimeClient,
+		ProxyClientCert:  current.ProxyClientCert,
+	}
+	// the openpitrix client caches helm repos with the shared informers, rebuild it only if necessary
+	if reflect.DeepEqual(current.Config.OpenPitrixOptions, conf.OpenPitrixOptions) {
+		apiServer.OpenpitrixClient = current.OpenpitrixClient
+	}
+	// the issuer generates a new signing key if none is configured, rebuilding it invalidates the issued tokens
+	if reflect.DeepEqual(current.Config.AuthenticationOptions, conf.AuthenticationOptions) {
+		apiServer.Issuer = current.Issuer
+	}
+	if err := newClients(apiServer); err != nil {
+		return nil, err
+	}
+	return apiServer, nil
+}
+
+// newClients creates the clients of the services configured by apiServer.Config.
+func newClients(apiServer *apiserver.APIServer) error {
+	var err error
+	conf := apiServer.Config
+
+	if conf.MonitoringOptions == nil || len(conf.MonitoringOptions.Endpoint) == 0 {
+		return fmt.Errorf("moinitoring service address in configuration MUST not be empty, please check configmap/kubesphere-config in kubesphere-system namespace")
+	} else {
+		if apiServer.MonitoringClient, err = prometheus.NewPrometheus(conf.MonitoringOptions); err != nil {
+			return fmt.Errorf("failed to connect to prometheus, please check prometheus status, error: %v", err)
+		}
+	}
+
+	if conf.LoggingOptions.Host != "" {
+		if apiServer.LoggingClient, err = esclient.NewClient(conf.LoggingOptions); err != nil {
+			return fmt.Errorf("failed to connect to elasticsearch, please check elasticsearch status, error: %v", err)
+		}
+	}
+
+	if conf.DevopsOptions.Host != "" {
+		if apiServer.DevopsClient, err = jenkins.NewDevopsClient(conf.DevopsOptions); err != nil {
+			return fmt.Errorf("failed to connect to jenkins, please check jenkins status, error: %v", err)
+		}
+	}
+
+	if conf.SonarQubeOptions.Host != "" {
+		sonarClient, err := sonarqube.NewSonarQubeClient(conf.SonarQubeOptions)
+		if err != nil {
+			return fmt.Errorf("failed to connecto to sonarqube, please check sonarqube status, error: %v", err)
+		}
+		apiServer.SonarClient = sonarqube.NewSonar(sonarClient.SonarQube())
+	}
+
+	if conf.EventsOptions.Host != "" {
+		if apiServer.EventsClient, err = eventsclient.NewClient(conf.EventsOptions); err != nil {
+			return fmt.Errorf("failed to connect to elasticsearch, please check elasticsearch status, error: %v", err)
+		}
+	}
+
+	if conf.AuditingOptions.Host != "" {
+		if apiServer.AuditingClient, err = auditingclient.NewClient(conf.AuditingOptions); err != nil {
+			return fmt.Errorf("failed to connect to elasticsearch, please check elasticsearch status, error: %v", err)
+		}
+	}
+
+	if conf.AlertingOptions != nil && (conf.AlertingOptions.PrometheusEndpoint != "" || conf.AlertingOptions.ThanosRulerEndpoint != "") {
+		if apiServer.AlertingClient, err = alerting.NewRuleClient(conf.AlertingOptions); err != nil {
+			return fmt.Errorf("failed to init alerting client: %v", err)
+		}
+	}
+
+	if apiServer.OpenpitrixClient == nil {
+		apiServer.OpenpitrixClient = openpitrixv1.NewOpenpitrixClient(apiServer.InformerFactory, apiServer.KubernetesClient.KubeSphere(), conf.OpenPitrixOptions, apiServer.ClusterClient)
+	}
+
+	if apiServer.Issuer == nil {
+		if apiServer.Issuer, err = token.NewIssuer(conf.AuthenticationOptions); err != nil {
+			return fmt.Errorf("unable to create issuer: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -36,6 +36,10 @@ func (s *ServerRunOptions) Validate() []error {
 	errors = append(errors, s.EventsOptions.Validate()...)
 	errors = append(errors, s.AuditingOptions.Validate()...)
 	errors = append(errors, s.AlertingOptions.Validate()...)
+	errors = append(errors, s.VulnerabilityOptions.Validate()...)
+	errors = append(errors, s.RateLimitOptions.Validate()...)
+	errors = append(errors, s.ComponentsOptions.Validate()...)
+	errors = append(errors, s.TerminalOptions.Validate()...)
 
 	return errors
 }
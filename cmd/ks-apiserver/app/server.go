@@ -23,6 +23,7 @@ import (
 
 	"github.com/google/gops/agent"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog/v2"
@@ -30,6 +31,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
 	"kubesphere.io/kubesphere/cmd/ks-apiserver/app/options"
+	"kubesphere.io/kubesphere/pkg/apiserver"
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider"
 	apiserverconfig "kubesphere.io/kubesphere/pkg/apiserver/config"
 	"kubesphere.io/kubesphere/pkg/utils/term"
 	"kubesphere.io/kubesphere/pkg/version"
@@ -96,58 +99,85 @@ cluster's shared state through which all other components interact.`,
 }
 
 func Run(s *options.ServerRunOptions, configCh <-chan apiserverconfig.Config, ctx context.Context) error {
-	ictx, cancelFunc := context.WithCancel(context.TODO())
-	errCh := make(chan error)
-	defer close(errCh)
+	// The ctx (signals.SetupSignalHandler()) is to control the entire program life cycle,
+	// the runCtx is canceled once the server stops, and the genCtx (generation context) controls the life cycle
+	// of the APIs installed with the configuration in use, which are replaced when the configuration changes.
+	runCtx, runCancel := context.WithCancel(ctx)
+	defer runCancel()
+
+	server, err := s.NewAPIServer(runCtx.Done())
+	if err != nil {
+		return err
+	}
+	// the states of the identity providers are shared by all the replicas, the cache is kept across the reloads
+	identityprovider.SetCache(server.CacheClient)
+	if err = identityprovider.SetupWithOptions(s.AuthenticationOptions.OAuthOptions.IdentityProviders); err != nil {
+		return err
+	}
+
+	genCtx, genCancel := context.WithCancel(runCtx)
+	if err = server.PrepareRun(genCtx.Done()); err != nil {
+		genCancel()
+		return err
+	}
+	status := server.ConfigRevisions.Applied(s.Config)
+	klog.Infof("Running with configuration revision %s", status.Revision)
+
+	recorder, stopRecording := newConfigEventRecorder(server.KubernetesClient.Kubernetes())
+	defer stopRecording()
+
+	errCh := make(chan error, 1)
 	go func() {
-		if err := run(s, ictx); err != nil {
-			errCh <- err
-		}
+		errCh <- server.Run(runCtx)
+		runCancel()
 	}()
 
-	// The ctx (signals.SetupSignalHandler()) is to control the entire program life cycle,
-	// The ictx(internal context)  is created here to control the life cycle of the ks-apiserver(http server, sharedInformer etc.)
-	// when config change, stop server and renew context, start new server
 	for {
 		select {
 		case <-ctx.Done():
-			cancelFunc()
+			genCancel()
 			return nil
-		case cfg := <-configCh:
-			cancelFunc()
-			s.Config = &cfg
-			ictx, cancelFunc = context.WithCancel(context.TODO())
-			go func() {
-				if errs := s.Validate(); len(errs) != 0 {
-					for _, err := range errs {
-						errCh <- err
-					}
-				}
-				if err := run(s, ictx); err != nil {
-					errCh <- err
-				}
-			}()
 		case err := <-errCh:
-			cancelFunc()
+			genCancel()
+			if err == http.ErrServerClosed {
+				return nil
+			}
 			return err
+		case cfg := <-configCh:
+			nextCtx, nextCancel := context.WithCancel(runCtx)
+			next, err := reload(s, server, &cfg, runCtx, nextCtx.Done())
+			if err != nil {
+				nextCancel()
+				status := server.ConfigRevisions.Rejected(&cfg, err)
+				klog.Errorf("Rejected configuration revision %s, keep running with revision %s: %v", status.Rejected.Revision, status.Revision, err)
+				recorder.Eventf(configReference, corev1.EventTypeWarning, configRejected,
+					"Configuration revision %s is rejected, keep running with revision %s: %v", status.Rejected.Revision, status.Revision, err)
+				continue
+			}
+			// requests in flight hold the handlers of the previous generation, only the background tasks are stopped
+			genCancel()
+			genCancel = nextCancel
+			server, s.Config = next, &cfg
+			status := server.ConfigRevisions.Applied(&cfg)
+			klog.Infof("Applied configuration revision %s, generation %d", status.Revision, status.Generation)
+			recorder.Eventf(configReference, corev1.EventTypeNormal, configApplied,
+				"Configuration revision %s is applied, generation %d", status.Revision, status.Generation)
 		}
 	}
 }
 
-func run(s *options.ServerRunOptions, ctx context.Context) error {
-	apiserver, err := s.NewAPIServer(ctx.Done())
+// reload builds the APIServer of conf and makes it serve requests in place of current.
+func reload(s *options.ServerRunOptions, current *apiserver.APIServer, conf *apiserverconfig.Config, ctx context.Context, stopCh <-chan struct{}) (*apiserver.APIServer, error) {
+	next, err := s.ReloadAPIServer(current, conf)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	err = apiserver.PrepareRun(ctx.Done())
-	if err != nil {
-		return err
+	if err = current.Reload(ctx, next, stopCh); err != nil {
+		return nil, err
 	}
-
-	err = apiserver.Run(ctx)
-	if err == http.ErrServerClosed {
-		return nil
+	// the identity providers are validated already
+	if err = identityprovider.SetupWithOptions(conf.AuthenticationOptions.OAuthOptions.IdentityProviders); err != nil {
+		return nil, err
 	}
-	return err
+	return next, nil
 }
@@ -27,6 +27,23 @@ type ComponentStatus struct {
 	StartedAt       time.Time   `json:"startedAt" description:"started time"`
 	TotalBackends   int         `json:"totalBackends" description:"the total replicas of each backend system component"`
 	HealthyBackends int         `json:"healthyBackends" description:"the number of healthy backend components"`
+
+	// the following are set for the features of kubesphere enabled in the config, e.g. devops, logging
+	Kind             string             `json:"kind,omitempty" description:"kind of the component, feature for the features of kubesphere enabled in the config"`
+	Version          string             `json:"version,omitempty" description:"the version running, e.g. v3.4.0"`
+	LatestVersion    string             `json:"latestVersion,omitempty" description:"the latest version of the feature"`
+	UpgradeAvailable bool               `json:"upgradeAvailable,omitempty" description:"whether the version running is older than the latest version"`
+	Dependencies     []DependencyStatus `json:"dependencies,omitempty" description:"the backends the feature depends on, e.g. elasticsearch of logging"`
+	LastError        string             `json:"lastError,omitempty" description:"the last error of the feature"`
+	LastErrorTime    *time.Time         `json:"lastErrorTime,omitempty" description:"the time of the last error"`
+	LastCheckTime    *time.Time         `json:"lastCheckTime,omitempty" description:"the time the dependencies were last checked"`
+}
+
+// DependencyStatus represents the reachability of a backend a feature depends on.
+type DependencyStatus struct {
+	Name      string `json:"name" description:"dependency name, e.g. elasticsearch"`
+	Reachable bool   `json:"reachable" description:"whether the dependency is reachable"`
+	Error     string `json:"error,omitempty" description:"the error of the last check"`
 }
 
 // NodeStatus assembles cluster nodes status, simply wrap unhealthy and total nodes.
@@ -18,6 +18,7 @@ package api
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -26,6 +27,12 @@ type ListResult struct {
 	TotalItems int           `json:"totalItems"`
 }
 
+// TableResult presents the objects of a ListResult as the rows of a meta.k8s.io/v1 Table.
+type TableResult struct {
+	metav1.Table `json:",inline"`
+	TotalItems   int `json:"totalItems"`
+}
+
 type ResourceQuota struct {
 	Namespace string                     `json:"namespace" description:"namespace"`
 	Data      corev1.ResourceQuotaStatus `json:"data" description:"resource quota status"`
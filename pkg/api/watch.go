@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/emicklei/go-restful/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// ServeWatch streams the events of the watcher in the format of the Kubernetes watch, one JSON object per line,
+// until the client disconnects or the watcher stops.
+func ServeWatch(watcher watch.Interface, request *restful.Request, response *restful.Response) {
+	defer watcher.Stop()
+
+	response.Header().Set(restful.HEADER_ContentType, restful.MIME_JSON)
+	response.WriteHeader(http.StatusOK)
+	response.Flush()
+
+	encoder := json.NewEncoder(response)
+	for {
+		select {
+		case <-request.Request.Context().Done():
+			return
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return
+			}
+			if err := encoder.Encode(&metav1.WatchEvent{
+				Type:   string(event.Type),
+				Object: runtime.RawExtension{Object: event.Object},
+			}); err != nil {
+				return
+			}
+			response.Flush()
+		}
+	}
+}
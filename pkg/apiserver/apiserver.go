@@ -19,9 +19,11 @@ package apiserver
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	rt "runtime"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -40,6 +42,7 @@ import (
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1alpha1 "kubesphere.io/api/cluster/v1alpha1"
+	extensionsv1alpha1 "kubesphere.io/api/extensions/v1alpha1"
 	iamv1alpha2 "kubesphere.io/api/iam/v1alpha2"
 	notificationv2beta1 "kubesphere.io/api/notification/v2beta1"
 	notificationv2beta2 "kubesphere.io/api/notification/v2beta2"
@@ -58,9 +61,11 @@ import (
 	"kubesphere.io/kubesphere/pkg/apiserver/authorization/authorizerfactory"
 	"kubesphere.io/kubesphere/pkg/apiserver/authorization/path"
 	"kubesphere.io/kubesphere/pkg/apiserver/authorization/rbac"
+	"kubesphere.io/kubesphere/pkg/apiserver/authorization/scope"
 	unionauthorizer "kubesphere.io/kubesphere/pkg/apiserver/authorization/union"
 	apiserverconfig "kubesphere.io/kubesphere/pkg/apiserver/config"
 	"kubesphere.io/kubesphere/pkg/apiserver/filters"
+	"kubesphere.io/kubesphere/pkg/apiserver/ratelimit"
 	"kubesphere.io/kubesphere/pkg/apiserver/request"
 	"kubesphere.io/kubesphere/pkg/informers"
 	alertingv1 "kubesphere.io/kubesphere/pkg/kapis/alerting/v1"
@@ -85,12 +90,14 @@ import (
 	operationsv1alpha2 "kubesphere.io/kubesphere/pkg/kapis/operations/v1alpha2"
 	resourcesv1alpha2 "kubesphere.io/kubesphere/pkg/kapis/resources/v1alpha2"
 	resourcev1alpha3 "kubesphere.io/kubesphere/pkg/kapis/resources/v1alpha3"
+	resourcesv1beta1 "kubesphere.io/kubesphere/pkg/kapis/resources/v1beta1"
 	servicemeshv1alpha2 "kubesphere.io/kubesphere/pkg/kapis/servicemesh/metrics/v1alpha2"
 	tenantv1alpha2 "kubesphere.io/kubesphere/pkg/kapis/tenant/v1alpha2"
 	tenantv1alpha3 "kubesphere.io/kubesphere/pkg/kapis/tenant/v1alpha3"
 	terminalv1alpha2 "kubesphere.io/kubesphere/pkg/kapis/terminal/v1alpha2"
 	"kubesphere.io/kubesphere/pkg/kapis/version"
 	"kubesphere.io/kubesphere/pkg/models/auth"
+	"kubesphere.io/kubesphere/pkg/models/components"
 	"kubesphere.io/kubesphere/pkg/models/iam/am"
 	"kubesphere.io/kubesphere/pkg/models/iam/group"
 	"kubesphere.io/kubesphere/pkg/models/iam/im"
@@ -103,10 +110,14 @@ import (
 	"kubesphere.io/kubesphere/pkg/simple/client/auditing"
 	"kubesphere.io/kubesphere/pkg/simple/client/cache"
 	"kubesphere.io/kubesphere/pkg/simple/client/devops"
+	"kubesphere.io/kubesphere/pkg/simple/client/es"
 	"kubesphere.io/kubesphere/pkg/simple/client/events"
 	"kubesphere.io/kubesphere/pkg/simple/client/k8s"
+	"kubesphere.io/kubesphere/pkg/simple/client/ldap"
 	"kubesphere.io/kubesphere/pkg/simple/client/logging"
 	"kubesphere.io/kubesphere/pkg/simple/client/monitoring"
+	"kubesphere.io/kubesphere/pkg/simple/client/monitoring/prometheus"
+	"kubesphere.io/kubesphere/pkg/simple/client/s3"
 	"kubesphere.io/kubesphere/pkg/simple/client/sonarqube"
 	"kubesphere.io/kubesphere/pkg/utils/clusterclient"
 	"kubesphere.io/kubesphere/pkg/utils/iputil"
@@ -123,9 +134,18 @@ type APIServer struct {
 
 	Config *apiserverconfig.Config
 
+	// tracks the revisions of the configuration, shared by the APIServers reloaded from each other
+	ConfigRevisions *apiserverconfig.RevisionTracker
+
 	// webservice container, where all webservice defines
 	container *restful.Container
 
+	// handler chain built upon the container
+	handler http.Handler
+
+	// closed once the caches are synced
+	cacheSynced chan struct{}
+
 	// kubeClient is a collection of all kubernetes(include CRDs) objects clientset
 	KubernetesClient k8s.Client
 
@@ -162,12 +182,16 @@ type APIServer struct {
 	// controller-runtime client
 	RuntimeClient runtimeclient.Client
 
+	// client certificate presented to the backends of the APIServices of the RequestHeader auth mode
+	ProxyClientCert *tls.Certificate
+
 	ClusterClient clusterclient.ClusterClients
 
 	OpenpitrixClient openpitrix.Interface
 }
 
 func (s *APIServer) PrepareRun(stopCh <-chan struct{}) error {
+	s.cacheSynced = make(chan struct{})
 	s.container = restful.NewContainer()
 	s.container.Filter(logRequestAndResponse)
 	s.container.Filter(monitorRequest)
@@ -178,15 +202,21 @@ func (s *APIServer) PrepareRun(stopCh <-chan struct{}) error {
 	s.installDynamicResourceAPI()
 	s.installKubeSphereAPIs(stopCh)
 	s.installMetricsAPI()
-	s.installHealthz()
+	s.installHealthz(stopCh)
 
 	for _, ws := range s.container.RegisteredWebServices() {
 		klog.V(2).Infof("%s", ws.RootPath())
 	}
 
-	s.Server.Handler = s.container
 	s.buildHandlerChain(stopCh)
 
+	// the APIServers reloaded from s share the server, see Reload
+	if _, ok := s.Server.Handler.(*handlerSwitch); !ok {
+		handler := &handlerSwitch{}
+		handler.store(s.handler)
+		s.Server.Handler = handler
+	}
+
 	return nil
 }
 
@@ -222,20 +252,31 @@ func (s *APIServer) installKubeSphereAPIs(stopCh <-chan struct{}) {
 		s.DevopsClient)
 	rbacAuthorizer := rbac.NewRBACAuthorizer(amOperator)
 
-	urlruntime.Must(configv1alpha2.AddToContainer(s.container, s.Config))
-	urlruntime.Must(resourcev1alpha3.AddToContainer(s.container, s.InformerFactory, s.RuntimeCache))
+	urlruntime.Must(configv1alpha2.AddToContainer(s.container, s.Config, s.ConfigRevisions))
+	// the bulk requests are excluded from the authorization filter, the scopes of personal access tokens are enforced
+	// on each resource operated, the same as the requests authorized by the filter, see buildHandlerChain
+	bulkAuthorizer := unionauthorizer.New(scope.NewAuthorizer(s.InformerFactory.KubernetesSharedInformerFactory().Core().V1().Namespaces().Lister()), rbacAuthorizer)
+	urlruntime.Must(resourcesv1beta1.AddToContainer(s.container, resourcev1beta1.New(s.RuntimeClient, s.RuntimeCache), bulkAuthorizer))
+	featureChecker := s.newFeatureChecker()
+	go featureChecker.Run(stopCh)
+	urlruntime.Must(resourcev1alpha3.AddToContainer(s.container, s.InformerFactory, s.KubernetesClient.Kubernetes(), s.RuntimeCache, s.Config.VulnerabilityOptions, featureChecker))
 	urlruntime.Must(monitoringv1alpha3.AddToContainer(s.container, s.KubernetesClient.Kubernetes(), s.MonitoringClient, s.MetricsClient, s.InformerFactory, s.OpenpitrixClient, s.RuntimeClient))
 	urlruntime.Must(meteringv1alpha1.AddToContainer(s.container, s.KubernetesClient.Kubernetes(), s.MonitoringClient, s.InformerFactory, s.RuntimeCache, s.Config.MeteringOptions, s.OpenpitrixClient, s.RuntimeClient))
 	urlruntime.Must(openpitrixv1.AddToContainer(s.container, s.InformerFactory, s.KubernetesClient.KubeSphere(), s.Config.OpenPitrixOptions, s.OpenpitrixClient))
 	urlruntime.Must(openpitrixv2alpha1.AddToContainer(s.container, s.InformerFactory, s.KubernetesClient.KubeSphere(), s.Config.OpenPitrixOptions))
-	urlruntime.Must(operationsv1alpha2.AddToContainer(s.container, s.KubernetesClient.Kubernetes()))
+	urlruntime.Must(operationsv1alpha2.AddToContainer(s.container, s.KubernetesClient.Kubernetes(), s.InformerFactory.KubernetesSharedInformerFactory()))
 	urlruntime.Must(resourcesv1alpha2.AddToContainer(s.container, s.KubernetesClient.Kubernetes(), s.InformerFactory,
 		s.KubernetesClient.Master()))
 	urlruntime.Must(tenantv1alpha2.AddToContainer(s.container, s.InformerFactory, s.KubernetesClient.Kubernetes(),
 		s.KubernetesClient.KubeSphere(), s.EventsClient, s.LoggingClient, s.AuditingClient, amOperator, imOperator, rbacAuthorizer, s.MonitoringClient, s.RuntimeCache, s.Config.MeteringOptions, s.OpenpitrixClient))
 	urlruntime.Must(tenantv1alpha3.AddToContainer(s.container, s.InformerFactory, s.KubernetesClient.Kubernetes(),
 		s.KubernetesClient.KubeSphere(), s.EventsClient, s.LoggingClient, s.AuditingClient, amOperator, imOperator, rbacAuthorizer, s.MonitoringClient, s.RuntimeCache, s.Config.MeteringOptions, s.OpenpitrixClient))
-	urlruntime.Must(terminalv1alpha2.AddToContainer(s.container, s.KubernetesClient.Kubernetes(), rbacAuthorizer, s.KubernetesClient.Config(), s.Config.TerminalOptions))
+	if s.Config.TerminalOptions != nil && s.Config.TerminalOptions.Kubectl != nil && s.Config.TerminalOptions.Kubectl.Image == "" {
+		// the kubectl sessions run the same image as the kubectl deployments of the users by default
+		s.Config.TerminalOptions.Kubectl.Image = s.Config.AuthenticationOptions.KubectlImage
+	}
+	urlruntime.Must(terminalv1alpha2.AddToContainer(s.container, s.KubernetesClient.Kubernetes(), rbacAuthorizer, s.KubernetesClient.Config(), s.Config.TerminalOptions,
+		auth.NewTokenOperator(s.CacheClient, s.Issuer, s.Config.AuthenticationOptions)))
 	urlruntime.Must(clusterkapisv1alpha1.AddToContainer(s.container,
 		s.KubernetesClient.KubeSphere(),
 		s.InformerFactory.KubernetesSharedInformerFactory(),
@@ -245,6 +286,7 @@ func (s *APIServer) installKubeSphereAPIs(stopCh <-chan struct{}) {
 		s.Config.MultiClusterOptions.AgentImage))
 	urlruntime.Must(iamapi.AddToContainer(s.container, imOperator, amOperator,
 		group.New(s.InformerFactory, s.KubernetesClient.KubeSphere(), s.KubernetesClient.Kubernetes()),
+		auth.NewPersonalAccessTokenOperator(s.CacheClient, s.Issuer, s.Config.AuthenticationOptions),
 		rbacAuthorizer))
 
 	userLister := s.InformerFactory.KubeSphereSharedInformerFactory().Iam().V1alpha2().Users().Lister()
@@ -274,18 +316,149 @@ func (s *APIServer) installKubeSphereAPIs(stopCh <-chan struct{}) {
 	urlruntime.Must(gatewayv1alpha1.AddToContainer(s.container, s.Config.GatewayOptions, s.RuntimeCache, s.RuntimeClient, s.InformerFactory, s.KubernetesClient.Kubernetes(), s.LoggingClient))
 }
 
-// installHealthz creates the healthz endpoint for this server
-func (s *APIServer) installHealthz() {
-	urlruntime.Must(healthz.InstallHandler(s.container, []healthz.HealthChecker{}...))
+// installHealthz creates the healthz, livez and readyz endpoints for this server.
+// livez only tells the server is running, readyz tells whether the server is ready to serve requests,
+// which means the caches are synced and the cache server is reachable, and healthz tells whether
+// all configured dependencies are healthy, an unhealthy dependency only breaks the APIs relying on it.
+// The dependencies are checked in the background until the stopCh is closed, the endpoints serve
+// the last results as they are public.
+func (s *APIServer) installHealthz(stopCh <-chan struct{}) {
+	informerSync := healthz.NamedCheck("informer-sync", func(_ *http.Request) error {
+		select {
+		case <-s.cacheSynced:
+			return nil
+		default:
+			return fmt.Errorf("caches are not synced yet")
+		}
+	})
+	readyChecks := []healthz.HealthChecker{healthz.PingHealthz, informerSync}
+	if s.CacheClient != nil {
+		readyChecks = append(readyChecks, healthz.CachedCheck(cache.NewHealthChecker(s.CacheClient), healthz.CachedCheckInterval, stopCh))
+	}
+
+	healthChecks := append([]healthz.HealthChecker{}, readyChecks...)
+	if s.Config.MonitoringOptions != nil && s.Config.MonitoringOptions.Endpoint != "" {
+		healthChecks = append(healthChecks, prometheus.NewHealthChecker(s.Config.MonitoringOptions))
+	}
+	if o := s.Config.LoggingOptions; o != nil && o.Host != "" {
+		healthChecks = append(healthChecks, es.NewHealthChecker("logging", o.Host, o.BasicAuth, o.Username, o.Password))
+	}
+	if o := s.Config.EventsOptions; o != nil && o.Host != "" {
+		healthChecks = append(healthChecks, es.NewHealthChecker("events", o.Host, o.BasicAuth, o.Username, o.Password))
+	}
+	if o := s.Config.AuditingOptions; o != nil && o.Host != "" {
+		healthChecks = append(healthChecks, es.NewHealthChecker("auditing", o.Host, o.BasicAuth, o.Username, o.Password))
+	}
+	if s.Config.LdapOptions != nil && s.Config.LdapOptions.Host != "" {
+		healthChecks = append(healthChecks, ldap.NewHealthChecker(s.Config.LdapOptions))
+	}
+	if s.Config.S3Options != nil && s.Config.S3Options.Endpoint != "" {
+		if checker, err := s3.NewHealthChecker(s.Config.S3Options); err != nil {
+			klog.Warningf("failed to create s3 health checker: %v", err)
+		} else {
+			healthChecks = append(healthChecks, checker)
+		}
+	}
+	if o := s.Config.AlertingOptions; o != nil && (o.PrometheusEndpoint != "" || o.ThanosRulerEndpoint != "") {
+		healthChecks = append(healthChecks, alerting.NewHealthChecker(o))
+	}
+
+	for i := len(readyChecks); i < len(healthChecks); i++ {
+		healthChecks[i] = healthz.CachedCheck(healthChecks[i], healthz.CachedCheckInterval, stopCh)
+	}
+
+	urlruntime.Must(healthz.InstallHandler(s.container, healthChecks...))
+	urlruntime.Must(healthz.InstallLivezHandler(s.container))
+	urlruntime.Must(healthz.InstallReadyzHandler(s.container, readyChecks...))
 }
 
-func (s *APIServer) Run(ctx context.Context) (err error) {
+// newFeatureChecker creates the checker of the features enabled in the config, with the backends they depend on.
+func (s *APIServer) newFeatureChecker() *components.FeatureChecker {
+	options := s.Config.ComponentsOptions
+	if options == nil {
+		options = components.NewComponentsOptions()
+	}
+	enabled := s.Config.ToMap()
+	var features []components.Feature
+	add := func(name string, featureOptions *components.FeatureOptions, dependencies ...healthz.HealthChecker) {
+		if enabled[name] {
+			features = append(features, components.NewFeature(name, featureOptions, dependencies...))
+		}
+	}
+	reachable := func(endpoints map[string]string) []healthz.HealthChecker {
+		var checks []healthz.HealthChecker
+		for name, endpoint := range endpoints {
+			if endpoint == "" {
+				continue
+			}
+			check, err := healthz.ReachableCheck(name, endpoint)
+			if err != nil {
+				// the invalid endpoint is reported as an unhealthy dependency of the feature
+				klog.Errorf("invalid endpoint %s of %s: %v", endpoint, name, err)
+				check = healthz.NamedCheck(name, func(_ *http.Request) error { return err })
+			}
+			checks = append(checks, check)
+		}
+		sort.Slice(checks, func(i, j int) bool { return checks[i].Name() < checks[j].Name() })
+		return checks
+	}
 
-	err = s.waitForResourceSync(ctx)
-	if err != nil {
-		return err
+	if o := s.Config.LoggingOptions; o != nil {
+		add(components.FeatureLogging, options.FeatureOptions(components.FeatureLogging),
+			es.NewHealthChecker("elasticsearch", o.Host, o.BasicAuth, o.Username, o.Password))
+	}
+	if o := s.Config.AuditingOptions; o != nil {
+		add(components.FeatureAuditing, options.FeatureOptions(components.FeatureAuditing),
+			es.NewHealthChecker("elasticsearch", o.Host, o.BasicAuth, o.Username, o.Password))
+	}
+	if o := s.Config.DevopsOptions; o != nil {
+		add(components.FeatureDevOps, options.FeatureOptions(components.FeatureDevOps),
+			reachable(map[string]string{"jenkins": o.Host, "devops-apiserver": o.Endpoint})...)
+	}
+	if o := s.Config.ServiceMeshOptions; o != nil {
+		add(components.FeatureServiceMesh, options.FeatureOptions(components.FeatureServiceMesh), reachable(map[string]string{
+			"istio-pilot": o.IstioPilotHost,
+			"jaeger":      o.JaegerQueryHost,
+			"kiali":       o.KialiQueryHost,
+			"prometheus":  o.ServicemeshPrometheusHost,
+		})...)
+	}
+	if o := s.Config.AlertingOptions; o != nil {
+		var dependencies []healthz.HealthChecker
+		if o.PrometheusEndpoint != "" || o.ThanosRulerEndpoint != "" {
+			dependencies = append(dependencies, alerting.NewHealthChecker(o))
+		}
+		add(components.FeatureAlerting, options.FeatureOptions(components.FeatureAlerting), dependencies...)
+	}
+	if o := s.Config.NotificationOptions; o != nil {
+		add(components.FeatureNotification, options.FeatureOptions(components.FeatureNotification),
+			reachable(map[string]string{"notification-manager": o.Endpoint})...)
+	}
+	var monitoring []healthz.HealthChecker
+	if s.Config.MonitoringOptions != nil && s.Config.MonitoringOptions.Endpoint != "" {
+		monitoring = append(monitoring, prometheus.NewHealthChecker(s.Config.MonitoringOptions))
+	}
+	add(components.FeatureMetering, options.FeatureOptions(components.FeatureMetering), monitoring...)
+	gateway := options.FeatureOptions(components.FeatureGateway)
+	if o := s.Config.GatewayOptions; o != nil && o.Tag != "" && (gateway == nil || gateway.LatestVersion == "") {
+		// the gateways are upgradable to the image tag configured
+		withTag := components.FeatureOptions{}
+		if gateway != nil {
+			withTag = *gateway
+		}
+		withTag.LatestVersion = o.Tag
+		gateway = &withTag
+	}
+	add(components.FeatureGateway, gateway)
+	if o := s.Config.EdgeRuntimeOptions; o != nil {
+		add(components.FeatureEdgeRuntime, options.FeatureOptions(components.FeatureEdgeRuntime),
+			reachable(map[string]string{"edgeruntime": o.Endpoint})...)
 	}
 
+	return components.NewFeatureChecker(s.InformerFactory.KubernetesSharedInformerFactory(), options.CheckInterval, features...)
+}
+
+func (s *APIServer) Run(ctx context.Context) (err error) {
 	shutdownCtx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -294,6 +467,18 @@ func (s *APIServer) Run(ctx context.Context) (err error) {
 		_ = s.Server.Shutdown(shutdownCtx)
 	}()
 
+	// the server is not ready until the caches are synced, see installHealthz,
+	// and the requests are rejected with 503 until then, see buildHandlerChain
+	syncErr := make(chan error, 1)
+	go func() {
+		if err := s.waitForResourceSync(ctx); err != nil {
+			syncErr <- err
+			_ = s.Server.Shutdown(shutdownCtx)
+			return
+		}
+		close(s.cacheSynced)
+	}()
+
 	klog.V(0).Infof("Start listening on %s", s.Server.Addr)
 	if s.Server.TLSConfig != nil {
 		err = s.Server.ListenAndServeTLS("", "")
@@ -301,7 +486,12 @@ func (s *APIServer) Run(ctx context.Context) (err error) {
 		err = s.Server.ListenAndServe()
 	}
 
-	return err
+	select {
+	case e := <-syncErr:
+		return e
+	default:
+		return err
+	}
 }
 
 func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) {
@@ -332,9 +522,15 @@ func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) {
 		)
 	}
 
-	handler := s.Server.Handler
+	var handler http.Handler = s.container
 	handler = filters.WithKubeAPIServer(handler, s.KubernetesClient.Config())
 
+	if informer, err := s.RuntimeCache.GetInformer(context.Background(), &extensionsv1alpha1.APIService{}); err != nil {
+		klog.Warningf("APIServices are disabled: %v", err)
+	} else {
+		handler = filters.WithAPIService(handler, informer, s.isServed, s.ProxyClientCert, stopCh)
+	}
+
 	if s.Config.AuditingOptions.Enable {
 		handler = filters.WithAuditing(handler,
 			audit.NewAuditing(s.InformerFactory, s.Config.AuditingOptions, stopCh))
@@ -350,17 +546,30 @@ func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) {
 	default:
 		fallthrough
 	case authorization.RBAC:
-		excludedPaths := []string{"/oauth/*", "/kapis/config.kubesphere.io/*", "/kapis/version", "/kapis/metrics", "/healthz"}
+		excludedPaths := []string{"/oauth/*", "/kapis/config.kubesphere.io/*", "/kapis/version", "/kapis/metrics", "/healthz", "/livez", "/readyz",
+			// each resource operated by a bulk request is authorized by the handler
+			resourcesv1beta1.BulkPath}
 		pathAuthorizer, _ := path.NewAuthorizer(excludedPaths)
 		amOperator := am.NewReadOnlyOperator(s.InformerFactory, s.DevopsClient)
 		authorizers = unionauthorizer.New(pathAuthorizer, rbac.NewRBACAuthorizer(amOperator))
 	}
 
+	// the scopes of personal access tokens are enforced before any other authorizers
+	namespaceLister := s.InformerFactory.KubernetesSharedInformerFactory().Core().V1().Namespaces().Lister()
+	authorizers = unionauthorizer.New(scope.NewAuthorizer(namespaceLister), authorizers)
+
 	handler = filters.WithAuthorization(handler, authorizers)
 	if s.Config.MultiClusterOptions.Enable {
 		handler = filters.WithMulticluster(handler, s.ClusterClient)
 	}
 
+	// the requests are limited once the users are authenticated, before any other filters,
+	// except the probes of the health endpoints
+	if s.Config.RateLimitOptions != nil && s.Config.RateLimitOptions.Enable {
+		handler = filters.WithRateLimit(handler, ratelimit.NewRateLimiter(s.Config.RateLimitOptions, s.CacheClient),
+			"/healthz", "/livez", "/readyz")
+	}
+
 	userLister := s.InformerFactory.KubeSphereSharedInformerFactory().Iam().V1alpha2().Users().Lister()
 	loginRecorder := auth.NewLoginRecorder(s.KubernetesClient.KubeSphere(), userLister)
 
@@ -376,7 +585,20 @@ func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) {
 			userLister)))
 	handler = filters.WithAuthentication(handler, authn)
 	handler = filters.WithRequestInfo(handler, requestInfoResolver)
-	s.Server.Handler = handler
+	// the handlers read the caches, only the health endpoints are served before they are synced
+	handler = filters.WithCacheSync(handler, s.cacheSynced, "/healthz", "/livez", "/readyz")
+	s.handler = handler
+}
+
+// isServed returns whether the group version is served by ks-apiserver itself.
+func (s *APIServer) isServed(groupVersion schema.GroupVersion) bool {
+	rootPath := fmt.Sprintf("/kapis/%s/%s", groupVersion.Group, groupVersion.Version)
+	for _, ws := range s.container.RegisteredWebServices() {
+		if ws.RootPath() == rootPath {
+			return true
+		}
+	}
+	return false
 }
 
 func isResourceExists(apiResources []v1.APIResource, resource schema.GroupVersionResource) bool {
@@ -427,7 +649,19 @@ func waitForCacheSync(discoveryClient discovery.DiscoveryInterface, sharedInform
 func (s *APIServer) waitForResourceSync(ctx context.Context) error {
 	klog.V(0).Info("Start cache objects")
 
-	stopCh := ctx.Done()
+	if err := s.syncInformers(ctx.Done()); err != nil {
+		return err
+	}
+
+	go s.RuntimeCache.Start(ctx)
+	s.RuntimeCache.WaitForCacheSync(ctx)
+
+	klog.V(0).Info("Finished caching objects")
+	return nil
+}
+
+// syncInformers starts the informers required by the configuration, the informers started already are shared.
+func (s *APIServer) syncInformers(stopCh <-chan struct{}) error {
 	// resources we have to create informer first
 	k8sGVRs := map[schema.GroupVersion][]string{
 		{Group: "", Version: "v1"}: {
@@ -613,12 +847,7 @@ func (s *APIServer) waitForResourceSync(ctx context.Context) error {
 		}
 	}
 
-	go s.RuntimeCache.Start(ctx)
-	s.RuntimeCache.WaitForCacheSync(ctx)
-
-	klog.V(0).Info("Finished caching objects")
 	return nil
-
 }
 
 func (s *APIServer) installDynamicResourceAPI() {
@@ -25,6 +25,7 @@ import (
 
 	iamv1alpha2 "kubesphere.io/api/iam/v1alpha2"
 
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
 	"kubesphere.io/kubesphere/pkg/models/auth"
 
 	iamv1alpha2listers "kubesphere.io/kubesphere/pkg/client/listers/iam/v1alpha2"
@@ -47,8 +48,8 @@ func NewTokenAuthenticator(tokenOperator auth.TokenManagementInterface, userList
 	}
 }
 
-func (t *tokenAuthenticator) AuthenticateToken(ctx context.Context, token string) (*authenticator.Response, bool, error) {
-	verified, err := t.tokenOperator.Verify(token)
+func (t *tokenAuthenticator) AuthenticateToken(ctx context.Context, tokenStr string) (*authenticator.Response, bool, error) {
+	verified, err := t.tokenOperator.Verify(tokenStr)
 	if err != nil {
 		klog.Warning(err)
 		return nil, false, err
@@ -65,14 +66,41 @@ func (t *tokenAuthenticator) AuthenticateToken(ctx context.Context, token string
 		return nil, false, err
 	}
 
-	// AuthLimitExceeded state should be ignored
+	// AuthLimitExceeded and Locked state should be ignored, they only restrict the password login
 	if userInfo.Status.State == iamv1alpha2.UserDisabled {
 		return nil, false, auth.AccountIsNotActiveError
 	}
+	authenticated := &user.DefaultInfo{
+		Name:   userInfo.GetName(),
+		Groups: append(userInfo.Spec.Groups, user.AllAuthenticated),
+	}
+	// the personal access token is recorded in the extra, so that it can be distinguished
+	// by the scope authorizer and the auditing
+	if verified.TokenType == token.PersonalAccessToken {
+		authenticated.Extra = map[string][]string{
+			token.ExtraTokenType:   {string(verified.TokenType)},
+			token.ExtraTokenID:     {verified.ID},
+			token.ExtraTokenName:   {verified.Name},
+			token.ExtraTokenScopes: verified.Scopes,
+		}
+	}
+	// the expired password must be changed before the other requests are allowed,
+	// it's not required any more once the password is changed after the token was issued
+	if len(verified.User.GetExtra()[iamv1alpha2.ExtraPasswordExpired]) > 0 &&
+		(verified.IssuedAt == nil || !auth.PasswordChangedSince(userInfo, verified.IssuedAt.Time)) {
+		if authenticated.Extra == nil {
+			authenticated.Extra = map[string][]string{}
+		}
+		authenticated.Extra[iamv1alpha2.ExtraPasswordExpired] = verified.User.GetExtra()[iamv1alpha2.ExtraPasswordExpired]
+	}
+	// the OAuth client is recorded in the extra, so that the requests can be limited by the clients
+	if len(verified.Audience) > 0 {
+		if authenticated.Extra == nil {
+			authenticated.Extra = map[string][]string{}
+		}
+		authenticated.Extra[token.ExtraClientID] = []string{verified.Audience[0]}
+	}
 	return &authenticator.Response{
-		User: &user.DefaultInfo{
-			Name:   userInfo.GetName(),
-			Groups: append(userInfo.Spec.Groups, user.AllAuthenticated),
-		},
+		User: authenticated,
 	}, true, nil
 }
@@ -19,18 +19,24 @@ package identityprovider
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"k8s.io/klog/v2"
 
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
+	"kubesphere.io/kubesphere/pkg/simple/client/cache"
 )
 
 var (
 	oauthProviderFactories   = make(map[string]OAuthProviderFactory)
 	genericProviderFactories = make(map[string]GenericProviderFactory)
 	identityProviderNotFound = errors.New("identity provider not found")
-	oauthProviders           = make(map[string]OAuthProvider)
-	genericProviders         = make(map[string]GenericProvider)
+	// providers are replaced as a whole when the configuration changes
+	providersMutex   sync.RWMutex
+	oauthProviders   = make(map[string]OAuthProvider)
+	genericProviders = make(map[string]GenericProvider)
+	// sharedCache stores the states of the providers shared by all the replicas
+	sharedCache cache.Interface
 )
 
 // Identity represents the account mapped to kubesphere
@@ -45,25 +51,79 @@ type Identity interface {
 	GetEmail() string
 }
 
-// SetupWithOptions will verify the configuration and initialize the identityProviders
-func SetupWithOptions(options []oauth.IdentityProviderOptions) error {
+// GroupIdentity is implemented by the identities which carry the group membership,
+// e.g. the LDAP memberOf attribute or the OIDC groups claim.
+type GroupIdentity interface {
+	Identity
+	// GetGroups returns the groups the End-User belongs to,
+	// nil means that the group membership is not provided by the identity provider.
+	GetGroups() []string
+}
+
+// Group represents a group of the identity provider.
+type Group struct {
+	// Name of the group.
+	Name string
+	// Members are the user IDs of the group members, see Identity.GetUserID.
+	Members []string
+}
+
+// GroupProvider is implemented by the identity providers which support listing groups,
+// the groups will be synchronized periodically.
+type GroupProvider interface {
+	// ListGroups returns all the groups and members from remote server
+	ListGroups() ([]Group, error)
+}
+
+// CacheProvider is implemented by the identity providers which keep states shared by all the replicas,
+// e.g. the SAML assertions consumed, the states are stored in the cache of kubesphere.
+type CacheProvider interface {
+	// SetCache sets the cache the states are stored in
+	SetCache(cache cache.Interface)
+}
+
+// SetCache sets the cache shared by all the replicas, it's set to the providers created by SetupWithOptions after.
+func SetCache(c cache.Interface) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	sharedCache = c
+}
+
+// ValidateOptions verifies the configuration of the identityProviders
+func ValidateOptions(options []oauth.IdentityProviderOptions) error {
+	names := make(map[string]bool, len(options))
 	for _, o := range options {
-		if oauthProviders[o.Name] != nil || genericProviders[o.Name] != nil {
-			err := fmt.Errorf("duplicate identity provider found: %s, name must be unique", o.Name)
-			klog.Error(err)
-			return err
+		if names[o.Name] {
+			return fmt.Errorf("duplicate identity provider found: %s, name must be unique", o.Name)
 		}
+		names[o.Name] = true
 		if genericProviderFactories[o.Type] == nil && oauthProviderFactories[o.Type] == nil {
-			err := fmt.Errorf("identity provider %s with type %s is not supported", o.Name, o.Type)
-			klog.Error(err)
-			return err
+			return fmt.Errorf("identity provider %s with type %s is not supported", o.Name, o.Type)
+		}
+		if o.GroupSync != nil && o.GroupSync.Workspace == "" {
+			return fmt.Errorf("workspace of group synchronization is required, identity provider: %s", o.Name)
 		}
+	}
+	return nil
+}
+
+// SetupWithOptions will verify the configuration and initialize the identityProviders,
+// the identityProviders initialized before are replaced atomically, so it's safe to call it again when the configuration changes.
+func SetupWithOptions(options []oauth.IdentityProviderOptions) error {
+	if err := ValidateOptions(options); err != nil {
+		klog.Error(err)
+		return err
+	}
+	newOAuthProviders := make(map[string]OAuthProvider)
+	newGenericProviders := make(map[string]GenericProvider)
+	for _, o := range options {
 		if factory, ok := oauthProviderFactories[o.Type]; ok {
 			if provider, err := factory.Create(o.Provider); err != nil {
 				// don’t return errors, decoupling external dependencies
 				klog.Error(fmt.Sprintf("failed to create identity provider %s: %s", o.Name, err))
 			} else {
-				oauthProviders[o.Name] = provider
+				setCache(provider)
+				newOAuthProviders[o.Name] = provider
 				klog.V(4).Infof("create identity provider %s successfully", o.Name)
 			}
 		}
@@ -71,16 +131,35 @@ func SetupWithOptions(options []oauth.IdentityProviderOptions) error {
 			if provider, err := factory.Create(o.Provider); err != nil {
 				klog.Error(fmt.Sprintf("failed to create identity provider %s: %s", o.Name, err))
 			} else {
-				genericProviders[o.Name] = provider
+				setCache(provider)
+				newGenericProviders[o.Name] = provider
 				klog.V(4).Infof("create identity provider %s successfully", o.Name)
 			}
 		}
 	}
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	oauthProviders = newOAuthProviders
+	genericProviders = newGenericProviders
 	return nil
 }
 
+func setCache(provider interface{}) {
+	cacheProvider, ok := provider.(CacheProvider)
+	if !ok {
+		return
+	}
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+	if sharedCache != nil {
+		cacheProvider.SetCache(sharedCache)
+	}
+}
+
 // GetGenericProvider returns GenericProvider with given name
 func GetGenericProvider(providerName string) (GenericProvider, error) {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
 	if provider, ok := genericProviders[providerName]; ok {
 		return provider, nil
 	}
@@ -89,6 +168,8 @@ func GetGenericProvider(providerName string) (GenericProvider, error) {
 
 // GetOAuthProvider returns OAuthProvider with given name
 func GetOAuthProvider(providerName string) (OAuthProvider, error) {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
 	if provider, ok := oauthProviders[providerName]; ok {
 		return provider, nil
 	}
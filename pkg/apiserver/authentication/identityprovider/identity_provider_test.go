@@ -113,6 +113,25 @@ func TestSetupWith(t *testing.T) {
 					Type:          "LDAPIdentityProvider",
 					Provider:      options.DynamicOptions{},
 				},
+				{
+					Name:          "ldap",
+					MappingMethod: "auto",
+					Type:          "OIDCIdentityProvider",
+					Provider:      options.DynamicOptions{},
+				},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "group sync without workspace",
+			args: args{options: []oauth.IdentityProviderOptions{
+				{
+					Name:          "oidc",
+					MappingMethod: "auto",
+					Type:          "OIDCIdentityProvider",
+					Provider:      options.DynamicOptions{},
+					GroupSync:     &oauth.GroupSyncOptions{},
+				},
 			}},
 			wantErr: true,
 		},
@@ -137,3 +156,29 @@ func TestSetupWith(t *testing.T) {
 		})
 	}
 }
+
+func TestSetupWithOptionsReplacesProviders(t *testing.T) {
+	RegisterOAuthProvider(emptyOAuthProviderFactory{typeName: "GitHubIdentityProvider"})
+	RegisterGenericProvider(emptyGenericProviderFactory{typeName: "LDAPIdentityProvider"})
+
+	if err := SetupWithOptions([]oauth.IdentityProviderOptions{{Name: "ldap", Type: "LDAPIdentityProvider"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetupWithOptions([]oauth.IdentityProviderOptions{{Name: "github", Type: "GitHubIdentityProvider"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetGenericProvider("ldap"); err == nil {
+		t.Error("expected the removed identity provider to be unavailable")
+	}
+	if _, err := GetOAuthProvider("github"); err != nil {
+		t.Errorf("expected the added identity provider to be available, got %v", err)
+	}
+
+	// an invalid configuration keeps the identity providers in use
+	if err := SetupWithOptions([]oauth.IdentityProviderOptions{{Name: "test", Type: "NotSupported"}}); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, err := GetOAuthProvider("github"); err != nil {
+		t.Errorf("expected the identity provider to be kept, got %v", err)
+	}
+}
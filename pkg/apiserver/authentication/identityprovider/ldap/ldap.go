@@ -22,6 +22,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-ldap/ldap"
@@ -34,8 +35,10 @@ import (
 )
 
 const (
-	ldapIdentityProvider = "LDAPIdentityProvider"
-	defaultReadTimeout   = 15000
+	ldapIdentityProvider     = "LDAPIdentityProvider"
+	defaultReadTimeout       = 15000
+	defaultGroupSearchFilter = "(|(objectClass=groupOfNames)(objectClass=groupOfUniqueNames)(objectClass=posixGroup)(objectClass=group))"
+	pagingSize               = 500
 )
 
 func init() {
@@ -99,6 +102,7 @@ func (l *ldapProviderFactory) Create(opts options.DynamicOptions) (identityprovi
 type ldapIdentity struct {
 	Username string
 	Email    string
+	Groups   []string
 }
 
 func (l *ldapIdentity) GetUserID() string {
@@ -113,6 +117,10 @@ func (l *ldapIdentity) GetEmail() string {
 	return l.Email
 }
 
+func (l *ldapIdentity) GetGroups() []string {
+	return l.Groups
+}
+
 func (l ldapProvider) Authenticate(username string, password string) (identityprovider.Identity, error) {
 	conn, err := l.newConn()
 	if err != nil {
@@ -140,7 +148,7 @@ func (l ldapProvider) Authenticate(username string, password string) (identitypr
 		TimeLimit:    0,
 		TypesOnly:    false,
 		Filter:       filter,
-		Attributes:   []string{l.LoginAttribute, l.MailAttribute},
+		Attributes:   l.userAttributes(),
 	})
 	if err != nil {
 		klog.Error(err)
@@ -167,10 +175,126 @@ func (l ldapProvider) Authenticate(username string, password string) (identitypr
 	}
 	email := entry.GetAttributeValue(l.MailAttribute)
 	uid := entry.GetAttributeValue(l.LoginAttribute)
-	return &ldapIdentity{
+	identity := &ldapIdentity{
 		Username: uid,
 		Email:    email,
-	}, nil
+	}
+	// the group membership is only available when the memberOf attribute is configured
+	if l.UserMemberAttribute != "" {
+		identity.Groups = make([]string, 0)
+		for _, group := range entry.GetAttributeValues(l.UserMemberAttribute) {
+			identity.Groups = append(identity.Groups, groupName(group))
+		}
+	}
+	return identity, nil
+}
+
+// ListGroups returns the groups under the GroupSearchBase, members of the groups are resolved to the login attribute.
+func (l ldapProvider) ListGroups() ([]identityprovider.Group, error) {
+	if l.GroupSearchBase == "" || l.GroupMemberAttribute == "" {
+		return nil, fmt.Errorf("ldap: groupSearchBase and groupMemberAttribute are required to list groups")
+	}
+	conn, err := l.newConn()
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	conn.SetTimeout(time.Duration(l.ReadTimeout) * time.Millisecond)
+	defer conn.Close()
+
+	if err = conn.Bind(l.ManagerDN, l.ManagerPassword); err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	filter := l.GroupSearchFilter
+	if filter == "" {
+		filter = defaultGroupSearchFilter
+	}
+	result, err := conn.SearchWithPaging(&ldap.SearchRequest{
+		BaseDN:       l.GroupSearchBase,
+		Scope:        ldap.ScopeWholeSubtree,
+		DerefAliases: ldap.NeverDerefAliases,
+		Filter:       filter,
+		Attributes:   []string{l.GroupMemberAttribute},
+	}, pagingSize)
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	// cache the resolved login attribute of the member DNs, users usually belong to multiple groups
+	resolved := make(map[string]string)
+	groups := make([]identityprovider.Group, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		group := identityprovider.Group{Name: groupName(entry.DN)}
+		for _, member := range entry.GetAttributeValues(l.GroupMemberAttribute) {
+			uid, ok := resolved[member]
+			if !ok {
+				if uid, err = l.resolveMember(conn, member); err != nil {
+					klog.Error(err)
+					return nil, err
+				}
+				resolved[member] = uid
+			}
+			if uid != "" {
+				group.Members = append(group.Members, uid)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// resolveMember returns the login attribute of the group member, the member can be either
+// a DN (e.g. member, uniqueMember) or the login attribute itself (e.g. memberUid).
+func (l ldapProvider) resolveMember(conn *ldap.Conn, member string) (string, error) {
+	dn, err := ldap.ParseDN(member)
+	if err != nil || len(dn.RDNs) == 0 {
+		return member, nil
+	}
+	for _, attribute := range dn.RDNs[0].Attributes {
+		if strings.EqualFold(attribute.Type, l.LoginAttribute) {
+			return attribute.Value, nil
+		}
+	}
+	result, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:       member,
+		Scope:        ldap.ScopeBaseObject,
+		DerefAliases: ldap.NeverDerefAliases,
+		SizeLimit:    1,
+		Filter:       "(objectClass=*)",
+		Attributes:   []string{l.LoginAttribute},
+	})
+	if err != nil {
+		// the member may have been deleted
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return "", nil
+		}
+		return "", err
+	}
+	if len(result.Entries) == 0 {
+		return "", nil
+	}
+	return result.Entries[0].GetAttributeValue(l.LoginAttribute), nil
+}
+
+func (l ldapProvider) userAttributes() []string {
+	attributes := []string{l.LoginAttribute, l.MailAttribute}
+	if l.UserMemberAttribute != "" {
+		attributes = append(attributes, l.UserMemberAttribute)
+	}
+	return attributes
+}
+
+// groupName returns the value of the first RDN, e.g. cn=developers,ou=groups,dc=example,dc=org => developers
+func groupName(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+		return dn
+	}
+	return parsed.RDNs[0].Attributes[0].Value
 }
 
 func (l *ldapProvider) newConn() (*ldap.Conn, error) {
@@ -89,3 +89,19 @@ func TestLdapProvider_Authenticate(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestGroupName(t *testing.T) {
+	tests := []struct {
+		dn       string
+		expected string
+	}{
+		{dn: "cn=developers,ou=groups,dc=example,dc=org", expected: "developers"},
+		{dn: "CN=Domain Admins,CN=Users,DC=example,DC=org", expected: "Domain Admins"},
+		{dn: "developers", expected: "developers"},
+	}
+	for _, tt := range tests {
+		if got := groupName(tt.dn); got != tt.expected {
+			t.Errorf("groupName(%s) = %s, want %s", tt.dn, got, tt.expected)
+		}
+	}
+}
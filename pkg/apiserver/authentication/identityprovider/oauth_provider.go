@@ -27,6 +27,13 @@ type OAuthProvider interface {
 	IdentityExchangeCallback(req *http.Request) (Identity, error)
 }
 
+// MetadataProvider is implemented by the providers which publish metadata for the remote server,
+// e.g. the SAML 2.0 service provider metadata.
+type MetadataProvider interface {
+	// Metadata returns the metadata document of the provider
+	Metadata() ([]byte, error)
+}
+
 type OAuthProviderFactory interface {
 	// Type unique type of the provider
 	Type() string
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// entityDescriptor is the metadata of the identity provider,
+// see also http://docs.oasis-open.org/security/saml/v2.0/saml-metadata-2.0-os.pdf
+type entityDescriptor struct {
+	XMLName           xml.Name           `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID          string             `xml:"entityID,attr"`
+	IDPSSODescriptors []idpSSODescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata IDPSSODescriptor"`
+
+	certificates    []*x509.Certificate
+	singleSignOnURL string
+}
+
+type entitiesDescriptor struct {
+	XMLName           xml.Name           `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntitiesDescriptor"`
+	EntityDescriptors []entityDescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+}
+
+type idpSSODescriptor struct {
+	KeyDescriptors       []keyDescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata KeyDescriptor"`
+	SingleSignOnServices []endpoint      `xml:"urn:oasis:names:tc:SAML:2.0:metadata SingleSignOnService"`
+}
+
+type keyDescriptor struct {
+	Use              string   `xml:"use,attr"`
+	X509Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+}
+
+type endpoint struct {
+	Binding  string `xml:"Binding,attr"`
+	Location string `xml:"Location,attr"`
+}
+
+type spEntityDescriptor struct {
+	XMLName         xml.Name        `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID        string          `xml:"entityID,attr"`
+	SPSSODescriptor spSSODescriptor `xml:"SPSSODescriptor"`
+}
+
+type spSSODescriptor struct {
+	AuthnRequestsSigned        bool              `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool              `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string            `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormats              []string          `xml:"NameIDFormat"`
+	AssertionConsumerServices  []indexedEndpoint `xml:"AssertionConsumerService"`
+}
+
+type indexedEndpoint struct {
+	Binding  string `xml:"Binding,attr"`
+	Location string `xml:"Location,attr"`
+	Index    int    `xml:"index,attr"`
+}
+
+// parseMetadata parses the metadata of the identity provider, the metadata
+// can be either an EntityDescriptor or an EntitiesDescriptor.
+func parseMetadata(data []byte) (*entityDescriptor, error) {
+	var descriptors []entityDescriptor
+	var entity entityDescriptor
+	if err := xml.Unmarshal(data, &entity); err == nil {
+		descriptors = append(descriptors, entity)
+	} else {
+		var entities entitiesDescriptor
+		if err = xml.Unmarshal(data, &entities); err != nil {
+			return nil, fmt.Errorf("saml: failed to parse metadata: %v", err)
+		}
+		descriptors = entities.EntityDescriptors
+	}
+
+	for i := range descriptors {
+		descriptor := &descriptors[i]
+		if len(descriptor.IDPSSODescriptors) == 0 {
+			continue
+		}
+		for _, idp := range descriptor.IDPSSODescriptors {
+			for _, key := range idp.KeyDescriptors {
+				if key.Use != "" && key.Use != "signing" {
+					continue
+				}
+				for _, data := range key.X509Certificates {
+					cert, err := parseCertificate(data)
+					if err != nil {
+						return nil, err
+					}
+					descriptor.certificates = append(descriptor.certificates, cert)
+				}
+			}
+			for _, sso := range idp.SingleSignOnServices {
+				// prefer the HTTP-Redirect binding, browsers can be redirected to it directly
+				if descriptor.singleSignOnURL == "" || sso.Binding == bindingHTTPRedirect {
+					descriptor.singleSignOnURL = sso.Location
+				}
+			}
+		}
+		if len(descriptor.certificates) == 0 {
+			return nil, errors.New("saml: signing certificate not found in metadata")
+		}
+		return descriptor, nil
+	}
+	return nil, errors.New("saml: IDPSSODescriptor not found in metadata")
+}
+
+func parseCertificate(data string) (*x509.Certificate, error) {
+	// certificates in the metadata are usually formatted with whitespaces
+	data = strings.Join(strings.Fields(data), "")
+	der, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("saml: failed to decode certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("saml: failed to parse certificate: %v", err)
+	}
+	return cert, nil
+}
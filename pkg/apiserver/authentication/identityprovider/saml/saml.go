@@ -0,0 +1,459 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package saml
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/mitchellh/mapstructure"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider"
+	"kubesphere.io/kubesphere/pkg/server/options"
+	"kubesphere.io/kubesphere/pkg/simple/client/cache"
+)
+
+const (
+	protocolNamespace         = "urn:oasis:names:tc:SAML:2.0:protocol"
+	assertionNamespace        = "urn:oasis:names:tc:SAML:2.0:assertion"
+	statusSuccess             = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	bindingHTTPPost           = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	bindingHTTPRedirect       = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	nameIDFormatUnspecified   = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	subjectConfirmationBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	// maximumClockSkew is the allowed time difference when checking the time-based conditions
+	maximumClockSkew = 90 * time.Second
+)
+
+func init() {
+	identityprovider.RegisterOAuthProvider(&samlProviderFactory{})
+}
+
+// samlProvider is a SAML 2.0 service provider, the identity provider posts the
+// signed SAML response to the assertion consumer service URL with the HTTP-POST binding.
+type samlProvider struct {
+	// EntityID is the unique identifier of KubeSphere as a service provider.
+	EntityID string `json:"entityID" yaml:"entityID"`
+
+	// AssertionConsumerServiceURL is the URL where the identity provider posts the SAML response,
+	// the recipient and destination of the response are verified against it.
+	AssertionConsumerServiceURL string `json:"assertionConsumerServiceURL" yaml:"assertionConsumerServiceURL"`
+
+	// IdentityProviderMetadataURL is the URL of the identity provider metadata,
+	// e.g. https://adfs.example.com/FederationMetadata/2007-06/FederationMetadata.xml
+	IdentityProviderMetadataURL string `json:"identityProviderMetadataURL" yaml:"identityProviderMetadataURL"`
+
+	// IdentityProviderMetadata is the raw XML metadata of the identity provider,
+	// it's used when the metadata URL is not reachable from KubeSphere.
+	IdentityProviderMetadata string `json:"-" yaml:"identityProviderMetadata"`
+
+	// Used to turn off TLS certificate checks when fetching the metadata
+	InsecureSkipVerify bool `json:"insecureSkipVerify" yaml:"insecureSkipVerify"`
+
+	// UsernameAttribute is the attribute which contains the username, defaults to the NameID of the subject.
+	UsernameAttribute string `json:"usernameAttribute" yaml:"usernameAttribute"`
+
+	// EmailAttribute is the attribute which contains the email.
+	EmailAttribute string `json:"emailAttribute" yaml:"emailAttribute"`
+
+	// GroupsAttribute is the attribute which contains the groups of the user.
+	GroupsAttribute string `json:"groupsAttribute" yaml:"groupsAttribute"`
+
+	// SingleSignOnURL is the SSO endpoint of the identity provider, it's discovered from the metadata.
+	SingleSignOnURL string `json:"singleSignOnURL" yaml:"singleSignOnURL"`
+
+	// issuer is the entity ID of the identity provider
+	issuer string
+	// certificates are used to verify the signatures of the identity provider
+	certificates []*x509.Certificate
+	// cache stores the consumed assertions until they expire, it's shared by all the replicas to prevent replay attacks
+	cache cache.Interface
+}
+
+type samlProviderFactory struct {
+}
+
+type samlIdentity struct {
+	NameID   string
+	Username string
+	Email    string
+	Groups   []string
+}
+
+func (s samlIdentity) GetUserID() string {
+	return s.NameID
+}
+
+func (s samlIdentity) GetUsername() string {
+	if s.Username != "" {
+		return s.Username
+	}
+	return s.NameID
+}
+
+func (s samlIdentity) GetEmail() string {
+	return s.Email
+}
+
+func (s samlIdentity) GetGroups() []string {
+	return s.Groups
+}
+
+func (f *samlProviderFactory) Type() string {
+	return "SAMLIdentityProvider"
+}
+
+func (f *samlProviderFactory) Create(opts options.DynamicOptions) (identityprovider.OAuthProvider, error) {
+	var samlProvider samlProvider
+	if err := mapstructure.Decode(opts, &samlProvider); err != nil {
+		return nil, err
+	}
+	if samlProvider.EntityID == "" {
+		return nil, errors.New("saml: entityID must not be empty")
+	}
+
+	metadata := []byte(samlProvider.IdentityProviderMetadata)
+	if samlProvider.IdentityProviderMetadataURL != "" {
+		data, err := fetchMetadata(samlProvider.IdentityProviderMetadataURL, samlProvider.InsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		metadata = data
+	}
+	if len(metadata) == 0 {
+		return nil, errors.New("saml: identity provider metadata must be specified")
+	}
+
+	descriptor, err := parseMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	samlProvider.issuer = descriptor.EntityID
+	samlProvider.certificates = descriptor.certificates
+	if samlProvider.SingleSignOnURL == "" {
+		samlProvider.SingleSignOnURL = descriptor.singleSignOnURL
+	}
+
+	return &samlProvider, nil
+}
+
+func (s *samlProvider) SetCache(cache cache.Interface) {
+	s.cache = cache
+}
+
+func (s *samlProvider) IdentityExchangeCallback(req *http.Request) (identityprovider.Identity, error) {
+	// HTTP-POST binding, see also http://docs.oasis-open.org/security/saml/v2.0/saml-bindings-2.0-os.pdf section 3.5
+	encoded := req.FormValue("SAMLResponse")
+	if encoded == "" {
+		return nil, errors.New("saml: SAMLResponse is required")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("saml: failed to decode SAMLResponse: %v", err)
+	}
+	assertion, err := s.verifyResponse(data, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.identityFrom(assertion)
+}
+
+// Metadata returns the metadata of KubeSphere as a service provider, it's imported by the identity provider.
+func (s *samlProvider) Metadata() ([]byte, error) {
+	metadata := spEntityDescriptor{
+		EntityID: s.EntityID,
+		SPSSODescriptor: spSSODescriptor{
+			AuthnRequestsSigned:        false,
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: protocolNamespace,
+			NameIDFormats:              []string{nameIDFormatUnspecified},
+			AssertionConsumerServices: []indexedEndpoint{{
+				Binding:  bindingHTTPPost,
+				Location: s.AssertionConsumerServiceURL,
+				Index:    0,
+			}},
+		},
+	}
+	data, err := xml.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// verifyResponse verifies the SAML response and returns the signed assertion.
+// Either the response or the assertion must be signed by the identity provider,
+// only the signed elements are trusted.
+func (s *samlProvider) verifyResponse(data []byte, now time.Time) (*etree.Element, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, fmt.Errorf("saml: failed to parse response: %v", err)
+	}
+	response := doc.Root()
+	if response == nil || response.Tag != "Response" || response.NamespaceURI() != protocolNamespace {
+		return nil, errors.New("saml: invalid response")
+	}
+
+	validationContext := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: s.certificates})
+	validationContext.Clock = dsig.NewFakeClockAt(now)
+
+	signed := false
+	if childElement(response, dsig.Namespace, dsig.SignatureTag) != nil {
+		validated, err := validationContext.Validate(response)
+		if err != nil {
+			return nil, fmt.Errorf("saml: failed to verify the signature of response: %v", err)
+		}
+		response = validated
+		signed = true
+	}
+
+	if destination := response.SelectAttrValue("Destination", ""); destination != "" &&
+		s.AssertionConsumerServiceURL != "" && destination != s.AssertionConsumerServiceURL {
+		return nil, fmt.Errorf("saml: unexpected destination %s", destination)
+	}
+	if err := s.verifyIssuer(response); err != nil {
+		return nil, err
+	}
+	status := childElement(childElement(response, protocolNamespace, "Status"), protocolNamespace, "StatusCode")
+	if status == nil || status.SelectAttrValue("Value", "") != statusSuccess {
+		return nil, errors.New("saml: the status of response is not success")
+	}
+
+	if childElement(response, assertionNamespace, "EncryptedAssertion") != nil {
+		return nil, errors.New("saml: encrypted assertion is not supported")
+	}
+	assertions := childElements(response, assertionNamespace, "Assertion")
+	if len(assertions) != 1 {
+		return nil, fmt.Errorf("saml: expected exactly one assertion, got %d", len(assertions))
+	}
+	assertion := assertions[0]
+
+	if childElement(assertion, dsig.Namespace, dsig.SignatureTag) != nil {
+		validated, err := validationContext.Validate(assertion)
+		if err != nil {
+			return nil, fmt.Errorf("saml: failed to verify the signature of assertion: %v", err)
+		}
+		assertion = validated
+		signed = true
+	}
+	if !signed {
+		return nil, errors.New("saml: neither the response nor the assertion is signed")
+	}
+
+	if err := s.verifyAssertion(assertion, now); err != nil {
+		return nil, err
+	}
+	return assertion, nil
+}
+
+func (s *samlProvider) verifyIssuer(el *etree.Element) error {
+	issuer := childElement(el, assertionNamespace, "Issuer")
+	// the issuer of response is optional
+	if issuer == nil && el.Tag == "Response" {
+		return nil
+	}
+	if issuer == nil || (s.issuer != "" && strings.TrimSpace(issuer.Text()) != s.issuer) {
+		return errors.New("saml: unexpected issuer")
+	}
+	return nil
+}
+
+func (s *samlProvider) verifyAssertion(assertion *etree.Element, now time.Time) error {
+	if err := s.verifyIssuer(assertion); err != nil {
+		return err
+	}
+
+	subject := childElement(assertion, assertionNamespace, "Subject")
+	if subject == nil || childElement(subject, assertionNamespace, "NameID") == nil {
+		return errors.New("saml: subject of assertion is required")
+	}
+	// the assertion can't be used after the bearer confirmation expires
+	var expiresAt time.Time
+	for _, confirmation := range childElements(subject, assertionNamespace, "SubjectConfirmation") {
+		if confirmation.SelectAttrValue("Method", "") != subjectConfirmationBearer {
+			continue
+		}
+		data := childElement(confirmation, assertionNamespace, "SubjectConfirmationData")
+		if data == nil {
+			continue
+		}
+		if recipient := data.SelectAttrValue("Recipient", ""); s.AssertionConsumerServiceURL != "" && recipient != s.AssertionConsumerServiceURL {
+			continue
+		}
+		// NotOnOrAfter is required for the bearer confirmation, see also saml-profiles-2.0-os section 4.1.4.2
+		notOnOrAfter, err := parseTime(data.SelectAttrValue("NotOnOrAfter", ""))
+		if err != nil || notOnOrAfter.IsZero() || !now.Add(-maximumClockSkew).Before(notOnOrAfter) {
+			continue
+		}
+		expiresAt = notOnOrAfter
+		break
+	}
+	if expiresAt.IsZero() {
+		return errors.New("saml: subject confirmation failed")
+	}
+
+	conditions := childElement(assertion, assertionNamespace, "Conditions")
+	if conditions != nil {
+		notBefore, err := parseTime(conditions.SelectAttrValue("NotBefore", ""))
+		if err != nil {
+			return err
+		}
+		if !notBefore.IsZero() && now.Add(maximumClockSkew).Before(notBefore) {
+			return errors.New("saml: assertion is not yet valid")
+		}
+		notOnOrAfter, err := parseTime(conditions.SelectAttrValue("NotOnOrAfter", ""))
+		if err != nil {
+			return err
+		}
+		if !notOnOrAfter.IsZero() && !now.Add(-maximumClockSkew).Before(notOnOrAfter) {
+			return errors.New("saml: assertion is expired")
+		}
+		for _, restriction := range childElements(conditions, assertionNamespace, "AudienceRestriction") {
+			matched := false
+			for _, audience := range childElements(restriction, assertionNamespace, "Audience") {
+				if strings.TrimSpace(audience.Text()) == s.EntityID {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return errors.New("saml: audience restriction is not satisfied")
+			}
+		}
+	}
+
+	id := assertion.SelectAttrValue("ID", "")
+	if id == "" {
+		return errors.New("saml: assertion ID is required")
+	}
+	return s.consumeAssertion(id, expiresAt.Add(maximumClockSkew).Sub(now))
+}
+
+// consumeAssertion records the assertion as consumed until it expires, the assertions consumed are rejected.
+func (s *samlProvider) consumeAssertion(id string, ttl time.Duration) error {
+	if s.cache == nil {
+		return errors.New("saml: cache is required to prevent replay attacks")
+	}
+	key := fmt.Sprintf("kubesphere:saml:%s:assertion:%s", s.issuer, id)
+	exists, err := s.cache.Exists(key)
+	if err != nil {
+		return fmt.Errorf("saml: failed to check assertion: %v", err)
+	}
+	if exists {
+		return errors.New("saml: assertion has already been used")
+	}
+	if err = s.cache.Set(key, "", ttl); err != nil {
+		return fmt.Errorf("saml: failed to record assertion: %v", err)
+	}
+	return nil
+}
+
+func (s *samlProvider) identityFrom(assertion *etree.Element) (identityprovider.Identity, error) {
+	nameID := childElement(childElement(assertion, assertionNamespace, "Subject"), assertionNamespace, "NameID")
+	identity := &samlIdentity{NameID: strings.TrimSpace(nameID.Text())}
+	if identity.NameID == "" {
+		return nil, errors.New("saml: NameID must not be empty")
+	}
+
+	attributes := make(map[string][]string)
+	for _, statement := range childElements(assertion, assertionNamespace, "AttributeStatement") {
+		for _, attribute := range childElements(statement, assertionNamespace, "Attribute") {
+			var values []string
+			for _, value := range childElements(attribute, assertionNamespace, "AttributeValue") {
+				values = append(values, strings.TrimSpace(value.Text()))
+			}
+			// attributes can be referred by name or friendly name
+			for _, key := range []string{attribute.SelectAttrValue("Name", ""), attribute.SelectAttrValue("FriendlyName", "")} {
+				if key != "" {
+					attributes[key] = append(attributes[key], values...)
+				}
+			}
+		}
+	}
+
+	if s.UsernameAttribute != "" && len(attributes[s.UsernameAttribute]) > 0 {
+		identity.Username = attributes[s.UsernameAttribute][0]
+	}
+	if s.EmailAttribute != "" && len(attributes[s.EmailAttribute]) > 0 {
+		identity.Email = attributes[s.EmailAttribute][0]
+	}
+	if s.GroupsAttribute != "" {
+		identity.Groups = append(make([]string, 0), attributes[s.GroupsAttribute]...)
+	}
+	return identity, nil
+}
+
+func childElement(el *etree.Element, space, tag string) *etree.Element {
+	if el == nil {
+		return nil
+	}
+	for _, child := range el.ChildElements() {
+		if child.Tag == tag && child.NamespaceURI() == space {
+			return child
+		}
+	}
+	return nil
+}
+
+func childElements(el *etree.Element, space, tag string) []*etree.Element {
+	var elements []*etree.Element
+	for _, child := range el.ChildElements() {
+		if child.Tag == tag && child.NamespaceURI() == space {
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("saml: invalid time %s: %v", value, err)
+	}
+	return t, nil
+}
+
+func fetchMetadata(metadataURL string, insecureSkipVerify bool) ([]byte, error) {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify},
+		},
+	}
+	resp, err := client.Get(metadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("saml: failed to fetch metadata: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("saml: failed to fetch metadata: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
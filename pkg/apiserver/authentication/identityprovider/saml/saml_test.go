@@ -0,0 +1,290 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package saml
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider"
+	"kubesphere.io/kubesphere/pkg/server/options"
+	"kubesphere.io/kubesphere/pkg/simple/client/cache"
+)
+
+const (
+	idpEntityID = "https://idp.example.com/saml"
+	spEntityID  = "https://ks-console.example.com"
+	acsURL      = "https://ks-console.example.com/oauth/callback/saml"
+)
+
+const metadataTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="%s">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+        <ds:X509Data>
+          <ds:X509Certificate>
+            %s
+          </ds:X509Certificate>
+        </ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.example.com/saml/sso/post"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/saml/sso"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`
+
+const assertionTemplate = `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="%s" IssueInstant="%s" Version="2.0">
+  <saml:Issuer>%s</saml:Issuer>
+  <saml:Subject>
+    <saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified">user1-id</saml:NameID>
+    <saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">
+      <saml:SubjectConfirmationData NotOnOrAfter="%s" Recipient="%s"/>
+    </saml:SubjectConfirmation>
+  </saml:Subject>
+  <saml:Conditions NotBefore="%s" NotOnOrAfter="%s">
+    <saml:AudienceRestriction>
+      <saml:Audience>%s</saml:Audience>
+    </saml:AudienceRestriction>
+  </saml:Conditions>
+  <saml:AttributeStatement>
+    <saml:Attribute Name="urn:oid:0.9.2342.19200300.100.1.1" FriendlyName="uid">
+      <saml:AttributeValue>user1</saml:AttributeValue>
+    </saml:Attribute>
+    <saml:Attribute Name="email">
+      <saml:AttributeValue>user1@kubesphere.io</saml:AttributeValue>
+    </saml:Attribute>
+    <saml:Attribute Name="groups">
+      <saml:AttributeValue>developers</saml:AttributeValue>
+      <saml:AttributeValue>admins</saml:AttributeValue>
+    </saml:Attribute>
+  </saml:AttributeStatement>
+</saml:Assertion>`
+
+const responseTemplate = `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="response-%s" Version="2.0" IssueInstant="%s" Destination="%s">
+  <saml:Issuer>%s</saml:Issuer>
+  <samlp:Status>
+    <samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/>
+  </samlp:Status>
+</samlp:Response>`
+
+type assertionOptions struct {
+	id       string
+	audience string
+	expired  bool
+	// the bearer confirmation never expires
+	noExpiry bool
+}
+
+type responseBuilder struct {
+	keyStore dsig.X509KeyStore
+}
+
+func (b *responseBuilder) assertion(opts assertionOptions) *etree.Element {
+	now := time.Now().UTC()
+	notOnOrAfter := now.Add(5 * time.Minute)
+	if opts.expired {
+		notOnOrAfter = now.Add(-10 * time.Minute)
+	}
+	audience := spEntityID
+	if opts.audience != "" {
+		audience = opts.audience
+	}
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(fmt.Sprintf(assertionTemplate, opts.id, now.Format(time.RFC3339), idpEntityID,
+		notOnOrAfter.Format(time.RFC3339), acsURL, now.Add(-time.Minute).Format(time.RFC3339),
+		notOnOrAfter.Format(time.RFC3339), audience)); err != nil {
+		panic(err)
+	}
+	if opts.noExpiry {
+		doc.FindElement("./Assertion/Subject/SubjectConfirmation/SubjectConfirmationData").RemoveAttr("NotOnOrAfter")
+	}
+	return doc.Root()
+}
+
+func (b *responseBuilder) sign(el *etree.Element) *etree.Element {
+	ctx := dsig.NewDefaultSigningContext(b.keyStore)
+	ctx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	signed, err := ctx.SignEnveloped(el)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+func (b *responseBuilder) response(id string, assertion *etree.Element, signResponse bool) string {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(fmt.Sprintf(responseTemplate, id, time.Now().UTC().Format(time.RFC3339), acsURL, idpEntityID)); err != nil {
+		panic(err)
+	}
+	response := doc.Root()
+	response.AddChild(assertion)
+	if signResponse {
+		doc.SetRoot(b.sign(response))
+	}
+	data, err := doc.WriteToBytes()
+	if err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func newMetadata(keyStore dsig.X509KeyStore) string {
+	_, cert, err := keyStore.GetKeyPair()
+	if err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf(metadataTemplate, idpEntityID, base64.StdEncoding.EncodeToString(cert))
+}
+
+func callback(samlResponse string) *http.Request {
+	form := url.Values{"SAMLResponse": {samlResponse}}
+	req, _ := http.NewRequest(http.MethodPost, acsURL, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestSAMLProvider(t *testing.T) {
+	keyStore := dsig.RandomKeyStoreForTest()
+	builder := &responseBuilder{keyStore: keyStore}
+	opts := options.DynamicOptions{
+		"entityID":                    spEntityID,
+		"assertionConsumerServiceURL": acsURL,
+		"identityProviderMetadata":    newMetadata(keyStore),
+		"usernameAttribute":           "uid",
+		"emailAttribute":              "email",
+		"groupsAttribute":             "groups",
+	}
+	provider, err := new(samlProviderFactory).Create(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sso := provider.(*samlProvider).SingleSignOnURL; sso != "https://idp.example.com/saml/sso" {
+		t.Errorf("unexpected single sign-on URL: %v", sso)
+	}
+	if _, ok := opts["singleSignOnURL"]; ok {
+		t.Errorf("unexpected single sign-on URL in the options")
+	}
+	cacheClient, err := cache.NewInMemoryCache(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.(identityprovider.CacheProvider).SetCache(cacheClient)
+
+	metadata, err := provider.(identityprovider.MetadataProvider).Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if descriptor := string(metadata); !strings.Contains(descriptor, spEntityID) || !strings.Contains(descriptor, acsURL) {
+		t.Errorf("unexpected service provider metadata: %s", descriptor)
+	}
+
+	otherKeyStore := &responseBuilder{keyStore: dsig.RandomKeyStoreForTest()}
+	tampered := builder.sign(builder.assertion(assertionOptions{id: "tampered"}))
+	tampered.FindElement("./Subject/NameID").SetText("admin")
+
+	tests := []struct {
+		name         string
+		samlResponse string
+		wantErr      bool
+	}{
+		{
+			name:         "signed assertion",
+			samlResponse: builder.response("1", builder.sign(builder.assertion(assertionOptions{id: "assertion-1"})), false),
+		},
+		{
+			name:         "signed response",
+			samlResponse: builder.response("2", builder.assertion(assertionOptions{id: "assertion-2"}), true),
+		},
+		{
+			name:         "replayed assertion",
+			samlResponse: builder.response("3", builder.sign(builder.assertion(assertionOptions{id: "assertion-1"})), false),
+			wantErr:      true,
+		},
+		{
+			name:         "unsigned",
+			samlResponse: builder.response("4", builder.assertion(assertionOptions{id: "assertion-4"}), false),
+			wantErr:      true,
+		},
+		{
+			name:         "tampered assertion",
+			samlResponse: builder.response("5", tampered, false),
+			wantErr:      true,
+		},
+		{
+			name:         "untrusted certificate",
+			samlResponse: otherKeyStore.response("6", otherKeyStore.sign(builder.assertion(assertionOptions{id: "assertion-6"})), false),
+			wantErr:      true,
+		},
+		{
+			name:         "audience mismatch",
+			samlResponse: builder.response("7", builder.sign(builder.assertion(assertionOptions{id: "assertion-7", audience: "other"})), false),
+			wantErr:      true,
+		},
+		{
+			name:         "expired",
+			samlResponse: builder.response("8", builder.sign(builder.assertion(assertionOptions{id: "assertion-8", expired: true})), false),
+			wantErr:      true,
+		},
+		{
+			name:         "bearer confirmation without expiry",
+			samlResponse: builder.response("9", builder.sign(builder.assertion(assertionOptions{id: "assertion-9", noExpiry: true})), false),
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := provider.IdentityExchangeCallback(callback(tt.samlResponse))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IdentityExchangeCallback() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if identity.GetUserID() != "user1-id" || identity.GetUsername() != "user1" || identity.GetEmail() != "user1@kubesphere.io" {
+				t.Errorf("unexpected identity: %+v", identity)
+			}
+			if groups := identity.(*samlIdentity).GetGroups(); len(groups) != 2 || groups[0] != "developers" {
+				t.Errorf("unexpected groups: %v", groups)
+			}
+		})
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	if _, err := parseMetadata([]byte(`<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="sp"/>`)); err == nil {
+		t.Errorf("expected error for metadata without IDPSSODescriptor")
+	}
+	keyStore := dsig.RandomKeyStoreForTest()
+	entities := fmt.Sprintf(`<md:EntitiesDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata">%s</md:EntitiesDescriptor>`,
+		strings.TrimPrefix(newMetadata(keyStore), `<?xml version="1.0" encoding="UTF-8"?>`))
+	descriptor, err := parseMetadata([]byte(entities))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if descriptor.EntityID != idpEntityID || len(descriptor.certificates) != 1 {
+		t.Errorf("unexpected descriptor: %+v", descriptor)
+	}
+}
@@ -68,6 +68,14 @@ type Options struct {
 	// Raw RSA private key. Base64 encoded PEM file
 	SignKeyData string `json:"-,omitempty" yaml:"signKeyData,omitempty"`
 
+	// Additional RSA private keys used to sign the id token, keys can be rotated by scheduling
+	// the activation of the new key without invalidating the issued tokens.
+	SignKeys []SignKey `json:"signKeys,omitempty" yaml:"signKeys,omitempty"`
+
+	// SignKeyRotationPeriod controls how often the automatically generated sign key is rotated,
+	// it only takes effect when no sign key is provided. 0 means never rotate.
+	SignKeyRotationPeriod time.Duration `json:"signKeyRotationPeriod,omitempty" yaml:"signKeyRotationPeriod,omitempty"`
+
 	// Register identity providers.
 	IdentityProviders []IdentityProviderOptions `json:"identityProviders,omitempty" yaml:"identityProviders,omitempty"`
 
@@ -91,6 +99,23 @@ type Options struct {
 	AccessTokenInactivityTimeout time.Duration `json:"accessTokenInactivityTimeout" yaml:"accessTokenInactivityTimeout"`
 }
 
+// SignKey is an RSA private key used to sign the id token. The key with the latest activation time
+// signs the new tokens, the retired keys are still used to verify the tokens until they expire.
+type SignKey struct {
+	// KeyID is the unique identifier of the key, defaults to the hash of the key data.
+	KeyID string `json:"keyID,omitempty" yaml:"keyID,omitempty"`
+
+	// RSA private key file
+	SignKey string `json:"signKey,omitempty" yaml:"signKey,omitempty"`
+
+	// Raw RSA private key. Base64 encoded PEM file
+	SignKeyData string `json:"-" yaml:"signKeyData,omitempty"`
+
+	// ActivateAt is the time from which the key is used to sign new tokens,
+	// the key is published in advance so that the relying parties can refresh their caches.
+	ActivateAt time.Time `json:"activateAt,omitempty" yaml:"activateAt,omitempty"`
+}
+
 type IdentityProviderOptions struct {
 	// The provider name.
 	Name string `json:"name" yaml:"name"`
@@ -114,6 +139,27 @@ type IdentityProviderOptions struct {
 
 	// The options of identify provider
 	Provider options.DynamicOptions `json:"provider" yaml:"provider"`
+
+	// GroupSync synchronizes the groups from the identity provider to the Group and GroupBinding,
+	// disabled if not set.
+	GroupSync *GroupSyncOptions `json:"groupSync,omitempty" yaml:"groupSync,omitempty"`
+}
+
+type GroupSyncOptions struct {
+	// The workspace which the synchronized groups belong to.
+	Workspace string `json:"workspace" yaml:"workspace"`
+
+	// Prefix of the synchronized group names, it helps to avoid conflicts with the existing groups.
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+
+	// Mapping maps the group names of identity provider to the group names in KubeSphere,
+	// only the mapped groups will be synchronized if it's not empty.
+	Mapping map[string]string `json:"mapping,omitempty" yaml:"mapping,omitempty"`
+
+	// SyncPeriod controls how often the groups will be synchronized from the identity provider,
+	// only takes effect for the identity providers which support listing groups, e.g. LDAP.
+	// 0 means the groups are only synchronized when users login.
+	SyncPeriod time.Duration `json:"syncPeriod,omitempty" yaml:"syncPeriod,omitempty"`
 }
 
 type Token struct {
@@ -30,6 +30,7 @@ import (
 	_ "kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider/github"
 	_ "kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider/ldap"
 	_ "kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider/oidc"
+	_ "kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider/saml"
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
 )
 
@@ -58,6 +59,11 @@ type Options struct {
 	JwtSecret string `json:"-" yaml:"jwtSecret"`
 	// OAuthOptions defines options needed for integrated oauth plugins
 	OAuthOptions *oauth.Options `json:"oauthOptions" yaml:"oauthOptions"`
+	// PersonalAccessTokenMaxAge restricts the maximum lifetime of personal access tokens, 0 means no limitation.
+	PersonalAccessTokenMaxAge time.Duration `json:"personalAccessTokenMaxAge,omitempty" yaml:"personalAccessTokenMaxAge,omitempty"`
+	// PasswordPolicy defines the password complexity, history, expiration and account lockout rules,
+	// disabled if not set.
+	PasswordPolicy *PasswordPolicy `json:"passwordPolicy,omitempty" yaml:"passwordPolicy,omitempty"`
 	// KubectlImage is the image address we use to create kubectl pod for users who have admin access to the cluster.
 	KubectlImage string `json:"kubectlImage" yaml:"kubectlImage"`
 }
@@ -84,7 +90,8 @@ func (options *Options) Validate() []error {
 	if options.AuthenticateRateLimiterMaxTries > options.LoginHistoryMaximumEntries {
 		errs = append(errs, errors.New("authenticateRateLimiterMaxTries MUST not be greater than loginHistoryMaximumEntries"))
 	}
-	if err := identityprovider.SetupWithOptions(options.OAuthOptions.IdentityProviders); err != nil {
+	errs = append(errs, options.PasswordPolicy.validateOptions(options.LoginHistoryMaximumEntries, options.AuthenticateRateLimiterMaxTries)...)
+	if err := identityprovider.ValidateOptions(options.OAuthOptions.IdentityProviders); err != nil {
 		errs = append(errs, err)
 	}
 	return errs
@@ -99,5 +106,6 @@ func (options *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
 	fs.IntVar(&options.LoginHistoryMaximumEntries, "login-history-maximum-entries", s.LoginHistoryMaximumEntries, "login-history-maximum-entries defines how many entries of login history should be kept.")
 	fs.DurationVar(&options.OAuthOptions.AccessTokenMaxAge, "access-token-max-age", s.OAuthOptions.AccessTokenMaxAge, "access-token-max-age control the lifetime of access tokens, 0 means no expiration.")
 	fs.StringVar(&s.KubectlImage, "kubectl-image", s.KubectlImage, "Setup the image used by kubectl terminal pod")
+	fs.DurationVar(&options.PersonalAccessTokenMaxAge, "personal-access-token-max-age", s.PersonalAccessTokenMaxAge, "personal-access-token-max-age restricts the maximum lifetime of personal access tokens, 0 means no limitation.")
 	fs.DurationVar(&options.MaximumClockSkew, "maximum-clock-skew", s.MaximumClockSkew, "The maximum time difference between the system clocks of the ks-apiserver that issued a JWT and the ks-apiserver that verified the JWT.")
 }
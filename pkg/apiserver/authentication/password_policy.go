@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authentication
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// PasswordPolicy defines the rules of the passwords managed by KubeSphere,
+// the users mapped from identity providers are not affected.
+type PasswordPolicy struct {
+	// MinLength is the minimum length of the password.
+	MinLength int `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	// RequireUppercase requires at least one uppercase letter.
+	RequireUppercase bool `json:"requireUppercase,omitempty" yaml:"requireUppercase,omitempty"`
+	// RequireLowercase requires at least one lowercase letter.
+	RequireLowercase bool `json:"requireLowercase,omitempty" yaml:"requireLowercase,omitempty"`
+	// RequireDigit requires at least one digit.
+	RequireDigit bool `json:"requireDigit,omitempty" yaml:"requireDigit,omitempty"`
+	// RequireSymbol requires at least one character which is neither a letter nor a digit.
+	RequireSymbol bool `json:"requireSymbol,omitempty" yaml:"requireSymbol,omitempty"`
+	// HistorySize is the number of the recent passwords which can not be reused, 0 means no limitation.
+	HistorySize int `json:"historySize,omitempty" yaml:"historySize,omitempty"`
+	// MaxAge is the maximum age of the password, users are required to change the expired password
+	// at next login, 0 means the password never expires.
+	MaxAge time.Duration `json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+	// LockoutThreshold is the number of consecutive failed login attempts after which the user will be locked,
+	// the locked user can only be unlocked by administrators, 0 means the user will never be locked.
+	// It must not be greater than AuthenticateRateLimiterMaxTries, as the attempts are counted again
+	// once the user blocked by the rate limiter is unblocked.
+	LockoutThreshold int `json:"lockoutThreshold,omitempty" yaml:"lockoutThreshold,omitempty"`
+}
+
+// Validate checks the password against the complexity rules, all the unsatisfied rules are reported.
+func (p *PasswordPolicy) Validate(password string) error {
+	if p == nil {
+		return nil
+	}
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	var violations []string
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+	if p.RequireUppercase && !hasUpper {
+		violations = append(violations, "an uppercase letter")
+	}
+	if p.RequireLowercase && !hasLower {
+		violations = append(violations, "a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, "a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, "a symbol")
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("password must contain %s", strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+// Expired returns whether the password changed at lastChange is expired.
+func (p *PasswordPolicy) Expired(lastChange time.Time, now time.Time) bool {
+	if p == nil || p.MaxAge <= 0 {
+		return false
+	}
+	return lastChange.Add(p.MaxAge).Before(now)
+}
+
+func (p *PasswordPolicy) validateOptions(loginHistoryMaximumEntries, rateLimiterMaxTries int) []error {
+	if p == nil {
+		return nil
+	}
+	var errs []error
+	if p.MinLength < 0 || p.HistorySize < 0 || p.MaxAge < 0 || p.LockoutThreshold < 0 {
+		errs = append(errs, errors.New("passwordPolicy MUST not contain negative values"))
+	}
+	if p.LockoutThreshold > loginHistoryMaximumEntries {
+		errs = append(errs, errors.New("passwordPolicy.lockoutThreshold MUST not be greater than loginHistoryMaximumEntries"))
+	}
+	if p.LockoutThreshold > rateLimiterMaxTries {
+		errs = append(errs, errors.New("passwordPolicy.lockoutThreshold MUST not be greater than authenticateRateLimiterMaxTries"))
+	}
+	return errs
+}
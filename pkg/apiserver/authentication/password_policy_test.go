@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authentication
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := &PasswordPolicy{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+	tests := []struct {
+		policy   *PasswordPolicy
+		password string
+		wantErr  bool
+	}{
+		{policy: nil, password: "a", wantErr: false},
+		{policy: policy, password: "P@88w0rd", wantErr: false},
+		{policy: policy, password: "P@8w0rd", wantErr: true},
+		{policy: policy, password: "p@88w0rd", wantErr: true},
+		{policy: policy, password: "P@88W0RD", wantErr: true},
+		{policy: policy, password: "P@ssword", wantErr: true},
+		{policy: policy, password: "P88w0rd1", wantErr: true},
+		{policy: &PasswordPolicy{MinLength: 4}, password: "密码密码", wantErr: false},
+	}
+	for _, tt := range tests {
+		if err := tt.policy.Validate(tt.password); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%s) error = %v, wantErr %v", tt.password, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPasswordPolicyExpired(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		policy     *PasswordPolicy
+		lastChange time.Time
+		expected   bool
+	}{
+		{policy: nil, lastChange: now.Add(-time.Hour), expected: false},
+		{policy: &PasswordPolicy{}, lastChange: now.Add(-time.Hour), expected: false},
+		{policy: &PasswordPolicy{MaxAge: 2 * time.Hour}, lastChange: now.Add(-time.Hour), expected: false},
+		{policy: &PasswordPolicy{MaxAge: time.Hour}, lastChange: now.Add(-2 * time.Hour), expected: true},
+	}
+	for i, tt := range tests {
+		if got := tt.policy.Expired(tt.lastChange, now); got != tt.expected {
+			t.Errorf("case %d: Expired() = %v, want %v", i, got, tt.expected)
+		}
+	}
+}
+
+func TestPasswordPolicyValidateOptions(t *testing.T) {
+	tests := []struct {
+		policy  *PasswordPolicy
+		wantErr bool
+	}{
+		{policy: nil, wantErr: false},
+		{policy: &PasswordPolicy{LockoutThreshold: 5}, wantErr: false},
+		{policy: &PasswordPolicy{LockoutThreshold: -1}, wantErr: true},
+		// the lockout never triggers if the rate limiter blocks the user first
+		{policy: &PasswordPolicy{LockoutThreshold: 6}, wantErr: true},
+		{policy: &PasswordPolicy{LockoutThreshold: 101}, wantErr: true},
+	}
+	for i, tt := range tests {
+		if errs := tt.policy.validateOptions(100, 5); (len(errs) > 0) != tt.wantErr {
+			t.Errorf("case %d: validateOptions() errors = %v, wantErr %v", i, errs, tt.wantErr)
+		}
+	}
+}
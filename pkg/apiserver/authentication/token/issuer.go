@@ -20,12 +20,10 @@ import (
 	cryptorand "crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
-	"encoding/base64"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"hash/fnv"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -37,13 +35,25 @@ import (
 )
 
 const (
-	AccessToken       Type   = "access_token"
-	RefreshToken      Type   = "refresh_token"
-	StaticToken       Type   = "static_token"
-	AuthorizationCode Type   = "code"
-	IDToken           Type   = "id_token"
-	headerKeyID       string = "kid"
-	headerAlgorithm   string = "alg"
+	AccessToken         Type   = "access_token"
+	RefreshToken        Type   = "refresh_token"
+	StaticToken         Type   = "static_token"
+	AuthorizationCode   Type   = "code"
+	IDToken             Type   = "id_token"
+	PersonalAccessToken Type   = "personal_access_token"
+	headerKeyID         string = "kid"
+	headerAlgorithm     string = "alg"
+)
+
+// The following keys are set in the extra of the authenticated user info,
+// when the request is authenticated by a personal access token.
+const (
+	ExtraTokenType   = "tokenType"
+	ExtraTokenID     = "tokenID"
+	ExtraTokenName   = "tokenName"
+	ExtraTokenScopes = "tokenScopes"
+	// ExtraClientID is set when the request is authenticated by a token issued to an OAuth client.
+	ExtraClientID = "clientID"
 )
 
 type Type string
@@ -63,6 +73,9 @@ type VerifiedResponse struct {
 type Keys struct {
 	SigningKey    *jose.JSONWebKey
 	SigningKeyPub *jose.JSONWebKey
+	// PublicKeys contains the public keys of the current, retiring and scheduled signing keys,
+	// which should be published to the relying parties.
+	PublicKeys []jose.JSONWebKey
 }
 
 // Issuer issues token to user, tokens are required to perform mutating requests to resources
@@ -113,7 +126,7 @@ type issuer struct {
 	// signing access_token and refresh_token
 	secret []byte
 	// signing id_token
-	signKey *Keys
+	keySet *keySet
 	// Token verification maximum time difference
 	maximumClockSkew time.Duration
 }
@@ -134,6 +147,10 @@ func (s *issuer) IssueTo(request *IssueRequest) (string, error) {
 	if len(request.Audience) > 0 {
 		claims.Audience = request.Audience
 	}
+
+	if request.ID != "" {
+		claims.ID = request.ID
+	}
 	if request.Name != "" {
 		claims.Name = request.Name
 	}
@@ -159,9 +176,10 @@ func (s *issuer) IssueTo(request *IssueRequest) (string, error) {
 	var token string
 	var err error
 	if request.TokenType == IDToken {
+		signKey := s.keySet.current()
 		t := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-		t.Header[headerKeyID] = s.signKey.SigningKey.KeyID
-		token, err = t.SignedString(s.signKey.SigningKey.Key)
+		t.Header[headerKeyID] = signKey.keyID
+		token, err = t.SignedString(signKey.key)
 	} else {
 		token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
 	}
@@ -211,7 +229,12 @@ func (s *issuer) Verify(token string) (*VerifiedResponse, error) {
 }
 
 func (s *issuer) Keys() *Keys {
-	return s.signKey
+	signKey := s.keySet.current()
+	return &Keys{
+		SigningKey:    signKey.jsonWebKey(),
+		SigningKeyPub: signKey.jsonWebKeyPub(),
+		PublicKeys:    s.keySet.publicKeys(),
+	}
 }
 
 func (s *issuer) keyFunc(token *jwt.Token) (i interface{}, err error) {
@@ -220,7 +243,13 @@ func (s *issuer) keyFunc(token *jwt.Token) (i interface{}, err error) {
 	case jwt.SigningMethodHS256.Alg():
 		return s.secret, nil
 	case jwt.SigningMethodRS256.Alg():
-		return s.signKey.SigningKey.Key, nil
+		// tokens signed by the retired keys are still valid until they expire
+		keyID, _ := token.Header[headerKeyID].(string)
+		signKey, err := s.keySet.lookup(keyID)
+		if err != nil {
+			return nil, err
+		}
+		return signKey.key.Public(), nil
 	default:
 		return nil, fmt.Errorf("unexpect signature algorithm %v", token.Header[headerAlgorithm])
 	}
@@ -253,69 +282,28 @@ func generatePrivateKeyData() ([]byte, error) {
 	return pemData, nil
 }
 
-func loadSignKey(options *authentication.Options) (*rsa.PrivateKey, string, error) {
-	var signKey *rsa.PrivateKey
-	var signKeyData []byte
-	var err error
-
-	if options.OAuthOptions.SignKey != "" {
-		signKeyData, err = os.ReadFile(options.OAuthOptions.SignKey)
-		if err != nil {
-			klog.Errorf("issuer: failed to read private key file %s: %v", options.OAuthOptions.SignKey, err)
-			return nil, "", err
-		}
-	} else if options.OAuthOptions.SignKeyData != "" {
-		signKeyData, err = base64.StdEncoding.DecodeString(options.OAuthOptions.SignKeyData)
-		if err != nil {
-			klog.Errorf("issuer: failed to decode sign key data: %s", err)
-			return nil, "", err
-		}
-	}
-
-	// automatically generate private key
-	if len(signKeyData) == 0 {
-		signKeyData, err = generatePrivateKeyData()
-		if err != nil {
-			klog.Errorf("issuer: failed to generate private key: %v", err)
-			return nil, "", err
-		}
-	}
-
-	if len(signKeyData) > 0 {
-		signKey, err = loadPrivateKey(signKeyData)
-		if err != nil {
-			klog.Errorf("issuer: failed to load private key from data: %v", err)
-		}
-	}
-
-	keyID := fmt.Sprint(fnv32a(signKeyData))
-	return signKey, keyID, nil
-}
-
 func NewIssuer(options *authentication.Options) (Issuer, error) {
-	// TODO(hongming) automatically rotates keys
-	signKey, keyID, err := loadSignKey(options)
+	keys, generated, err := loadSigningKeys(options.OAuthOptions)
 	if err != nil {
+		klog.Errorf("issuer: failed to load sign keys: %v", err)
 		return nil, err
 	}
+	var rotationPeriod time.Duration
+	if generated {
+		rotationPeriod = options.OAuthOptions.SignKeyRotationPeriod
+	} else if options.OAuthOptions.SignKeyRotationPeriod > 0 {
+		klog.Warning("issuer: signKeyRotationPeriod is ignored, the provided sign keys are rotated by activateAt")
+	}
+	// the retired keys are removed after the id tokens signed by them are expired
+	retention := options.OAuthOptions.AccessTokenMaxAge + options.OAuthOptions.AccessTokenInactivityTimeout
+	if retention > 0 {
+		retention += options.MaximumClockSkew
+	}
 	return &issuer{
 		name:             options.OAuthOptions.Issuer,
 		secret:           []byte(options.JwtSecret),
 		maximumClockSkew: options.MaximumClockSkew,
-		signKey: &Keys{
-			SigningKey: &jose.JSONWebKey{
-				Key:       signKey,
-				KeyID:     keyID,
-				Algorithm: jwt.SigningMethodRS256.Alg(),
-				Use:       "sig",
-			},
-			SigningKeyPub: &jose.JSONWebKey{
-				Key:       signKey.Public(),
-				KeyID:     keyID,
-				Algorithm: jwt.SigningMethodRS256.Alg(),
-				Use:       "sig",
-			},
-		},
+		keySet:           newKeySet(keys, retention, rotationPeriod),
 	}, nil
 }
 
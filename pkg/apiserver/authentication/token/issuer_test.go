@@ -75,32 +75,33 @@ func TestNewIssuer(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	signKey, keyID, err := loadSignKey(options)
+	signKey, err := newSigningKey("", []byte(privateKeyData), time.Time{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	want := &issuer{
-		name:             options.OAuthOptions.Issuer,
-		secret:           []byte(options.JwtSecret),
-		maximumClockSkew: options.MaximumClockSkew,
-		signKey: &Keys{
-			SigningKey: &jose.JSONWebKey{
-				Key:       signKey,
-				KeyID:     keyID,
-				Algorithm: jwt.SigningMethodRS256.Alg(),
-				Use:       "sig",
-			},
-			SigningKeyPub: &jose.JSONWebKey{
-				Key:       signKey.Public(),
-				KeyID:     keyID,
-				Algorithm: jwt.SigningMethodRS256.Alg(),
-				Use:       "sig",
-			},
+	want := &Keys{
+		SigningKey: &jose.JSONWebKey{
+			Key:       signKey.key,
+			KeyID:     signKey.keyID,
+			Algorithm: jwt.SigningMethodRS256.Alg(),
+			Use:       "sig",
+		},
+		SigningKeyPub: &jose.JSONWebKey{
+			Key:       signKey.key.Public(),
+			KeyID:     signKey.keyID,
+			Algorithm: jwt.SigningMethodRS256.Alg(),
+			Use:       "sig",
 		},
+		PublicKeys: []jose.JSONWebKey{{
+			Key:       signKey.key.Public(),
+			KeyID:     signKey.keyID,
+			Algorithm: jwt.SigningMethodRS256.Alg(),
+			Use:       "sig",
+		}},
 	}
-	if !reflect.DeepEqual(got, want) {
-		t.Errorf("NewIssuer() got = %v, want %v", got, want)
+	if !reflect.DeepEqual(got.Keys(), want) {
+		t.Errorf("NewIssuer() got = %v, want %v", got.Keys(), want)
 		return
 	}
 }
@@ -119,12 +120,13 @@ func TestNewIssuerGenerateSignKey(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	iss := got.(*issuer)
-	assert.NotNil(t, iss.signKey)
-	assert.NotNil(t, iss.signKey.SigningKey)
-	assert.NotNil(t, iss.signKey.SigningKeyPub)
-	assert.NotNil(t, iss.signKey.SigningKey.KeyID)
-	assert.NotNil(t, iss.signKey.SigningKeyPub.KeyID)
+	keys := got.Keys()
+	assert.NotNil(t, keys)
+	assert.NotNil(t, keys.SigningKey)
+	assert.NotNil(t, keys.SigningKeyPub)
+	assert.NotEmpty(t, keys.SigningKey.KeyID)
+	assert.NotEmpty(t, keys.SigningKeyPub.KeyID)
+	assert.Len(t, keys.PublicKeys, 1)
 }
 
 func Test_issuer_IssueTo(t *testing.T) {
@@ -0,0 +1,250 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gopkg.in/square/go-jose.v2"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
+)
+
+type signingKey struct {
+	keyID      string
+	key        *rsa.PrivateKey
+	activateAt time.Time
+}
+
+func (k *signingKey) jsonWebKey() *jose.JSONWebKey {
+	return &jose.JSONWebKey{
+		Key:       k.key,
+		KeyID:     k.keyID,
+		Algorithm: jwt.SigningMethodRS256.Alg(),
+		Use:       "sig",
+	}
+}
+
+func (k *signingKey) jsonWebKeyPub() *jose.JSONWebKey {
+	return &jose.JSONWebKey{
+		Key:       k.key.Public(),
+		KeyID:     k.keyID,
+		Algorithm: jwt.SigningMethodRS256.Alg(),
+		Use:       "sig",
+	}
+}
+
+// keySet holds the keys used to sign and verify the id token. The key with the latest activation time
+// signs the new tokens, a key is retired once the next key is activated and removed after all the
+// tokens signed by it are expired.
+type keySet struct {
+	sync.Mutex
+	// sorted by the activation time
+	keys []*signingKey
+	// retention is the maximum lifetime of the tokens, 0 means the retired keys are never removed
+	retention time.Duration
+	// rotationPeriod rotates the automatically generated keys periodically, 0 means never rotate
+	rotationPeriod time.Duration
+	now            func() time.Time
+}
+
+func newKeySet(keys []*signingKey, retention, rotationPeriod time.Duration) *keySet {
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].activateAt.Before(keys[j].activateAt)
+	})
+	return &keySet{
+		keys:           keys,
+		retention:      retention,
+		rotationPeriod: rotationPeriod,
+		now:            time.Now,
+	}
+}
+
+// current returns the key used to sign new tokens.
+func (s *keySet) current() *signingKey {
+	s.Lock()
+	defer s.Unlock()
+	s.reconcile()
+	return s.keys[s.active(s.now())]
+}
+
+// lookup returns the key identified by keyID, the current key is returned if keyID is empty.
+func (s *keySet) lookup(keyID string) (*signingKey, error) {
+	s.Lock()
+	defer s.Unlock()
+	s.reconcile()
+	if keyID == "" {
+		return s.keys[s.active(s.now())], nil
+	}
+	for _, key := range s.keys {
+		if key.keyID == keyID {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key id %s", keyID)
+}
+
+// publicKeys returns the public keys of all the active, retiring and scheduled keys.
+func (s *keySet) publicKeys() []jose.JSONWebKey {
+	s.Lock()
+	defer s.Unlock()
+	s.reconcile()
+	active := s.active(s.now())
+	// the current key comes first
+	keys := []jose.JSONWebKey{*s.keys[active].jsonWebKeyPub()}
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if i != active {
+			keys = append(keys, *s.keys[i].jsonWebKeyPub())
+		}
+	}
+	return keys
+}
+
+// active returns the index of the key in effect, the earliest key is used if none is activated yet.
+func (s *keySet) active(now time.Time) int {
+	index := 0
+	for i, key := range s.keys {
+		if key.activateAt.After(now) {
+			break
+		}
+		index = i
+	}
+	return index
+}
+
+// reconcile rotates the generated key and removes the expired keys, the lock must be held.
+func (s *keySet) reconcile() {
+	now := s.now()
+	last := s.keys[len(s.keys)-1]
+	// the next key is generated half a period in advance, so that it's published before activation
+	if s.rotationPeriod > 0 && !last.activateAt.Add(s.rotationPeriod/2).After(now) {
+		activateAt := last.activateAt.Add(s.rotationPeriod)
+		if activateAt.Before(now) {
+			activateAt = now
+		}
+		key, err := generateSigningKey(activateAt)
+		if err != nil {
+			// keep signing with the current key
+			klog.Errorf("issuer: failed to rotate sign key: %v", err)
+		} else {
+			klog.V(4).Infof("issuer: sign key rotated, key id: %s", key.keyID)
+			s.keys = append(s.keys, key)
+		}
+	}
+	if s.retention <= 0 {
+		return
+	}
+	active := s.active(now)
+	expired := 0
+	for i := 0; i < active; i++ {
+		// retired when the next key is activated
+		if s.keys[i+1].activateAt.Add(s.retention).Before(now) {
+			expired = i + 1
+		}
+	}
+	if expired > 0 {
+		s.keys = s.keys[expired:]
+	}
+}
+
+func generateSigningKey(activateAt time.Time) (*signingKey, error) {
+	data, err := generatePrivateKeyData()
+	if err != nil {
+		return nil, err
+	}
+	return newSigningKey("", data, activateAt)
+}
+
+func newSigningKey(keyID string, data []byte, activateAt time.Time) (*signingKey, error) {
+	key, err := loadPrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	if keyID == "" {
+		keyID = fmt.Sprint(fnv32a(data))
+	}
+	return &signingKey{keyID: keyID, key: key, activateAt: activateAt}, nil
+}
+
+func loadSignKeyData(file, data string) ([]byte, error) {
+	if file != "" {
+		signKeyData, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key file %s: %v", file, err)
+		}
+		return signKeyData, nil
+	}
+	if data != "" {
+		signKeyData, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode sign key data: %v", err)
+		}
+		return signKeyData, nil
+	}
+	return nil, nil
+}
+
+// loadSigningKeys loads the configured sign keys, a key is generated automatically if none is provided,
+// the second return value reports whether the key is generated.
+func loadSigningKeys(options *oauth.Options) ([]*signingKey, bool, error) {
+	var keys []*signingKey
+	keyIDs := make(map[string]bool)
+	add := func(keyID, file, data string, activateAt time.Time) error {
+		signKeyData, err := loadSignKeyData(file, data)
+		if err != nil || len(signKeyData) == 0 {
+			return err
+		}
+		key, err := newSigningKey(keyID, signKeyData, activateAt)
+		if err != nil {
+			return err
+		}
+		if keyIDs[key.keyID] {
+			return fmt.Errorf("duplicate sign key id %s", key.keyID)
+		}
+		keyIDs[key.keyID] = true
+		keys = append(keys, key)
+		return nil
+	}
+	if err := add("", options.SignKey, options.SignKeyData, time.Time{}); err != nil {
+		return nil, false, err
+	}
+	for _, signKey := range options.SignKeys {
+		if signKey.SignKey == "" && signKey.SignKeyData == "" {
+			return nil, false, fmt.Errorf("sign key %s: either signKey or signKeyData must be provided", signKey.KeyID)
+		}
+		if err := add(signKey.KeyID, signKey.SignKey, signKey.SignKeyData, signKey.ActivateAt); err != nil {
+			return nil, false, err
+		}
+	}
+	if len(keys) > 0 {
+		return keys, false, nil
+	}
+	// automatically generate private key
+	key, err := generateSigningKey(time.Now())
+	if err != nil {
+		return nil, false, err
+	}
+	return []*signingKey{key}, true, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication"
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
+)
+
+func publicKeyIDs(keys *Keys) []string {
+	keyIDs := make([]string, 0)
+	for _, key := range keys.PublicKeys {
+		keyIDs = append(keyIDs, key.KeyID)
+	}
+	return keyIDs
+}
+
+func TestKeyRotation(t *testing.T) {
+	newKeyData, err := generatePrivateKeyData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	options := &authentication.Options{
+		JwtSecret: "test-secret",
+		OAuthOptions: &oauth.Options{
+			Issuer:            "kubesphere",
+			SignKeyData:       base64.StdEncoding.EncodeToString([]byte(privateKeyData)),
+			AccessTokenMaxAge: time.Hour,
+			SignKeys: []oauth.SignKey{
+				{
+					KeyID:       "new",
+					SignKeyData: base64.StdEncoding.EncodeToString(newKeyData),
+					ActivateAt:  now.Add(time.Hour),
+				},
+			},
+		},
+	}
+	got, err := NewIssuer(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss := got.(*issuer)
+	clock := now
+	iss.keySet.now = func() time.Time { return clock }
+	issue := func() string {
+		token, err := iss.IssueTo(&IssueRequest{
+			User:   &user.DefaultInfo{Name: "admin"},
+			Claims: Claims{TokenType: IDToken},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	// the scheduled key is published before activation
+	oldKeyID := iss.Keys().SigningKey.KeyID
+	assert.Equal(t, []string{oldKeyID, "new"}, publicKeyIDs(iss.Keys()))
+	oldToken := issue()
+
+	// new tokens are signed with the new key, the old tokens are still valid
+	clock = now.Add(90 * time.Minute)
+	assert.Equal(t, "new", iss.Keys().SigningKey.KeyID)
+	assert.Equal(t, []string{"new", oldKeyID}, publicKeyIDs(iss.Keys()))
+	newToken := issue()
+	_, err = iss.Verify(oldToken)
+	assert.Nil(t, err)
+	_, err = iss.Verify(newToken)
+	assert.Nil(t, err)
+
+	// the retired key is removed after all the tokens signed by it are expired
+	clock = now.Add(3 * time.Hour)
+	assert.Equal(t, []string{"new"}, publicKeyIDs(iss.Keys()))
+	_, err = iss.Verify(oldToken)
+	assert.NotNil(t, err)
+	_, err = iss.Verify(newToken)
+	assert.Nil(t, err)
+}
+
+func TestGeneratedKeyRotation(t *testing.T) {
+	options := &authentication.Options{
+		JwtSecret: "test-secret",
+		OAuthOptions: &oauth.Options{
+			Issuer:                "kubesphere",
+			AccessTokenMaxAge:     time.Hour,
+			SignKeyRotationPeriod: 24 * time.Hour,
+		},
+	}
+	got, err := NewIssuer(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss := got.(*issuer)
+	now := time.Now()
+	clock := now
+	iss.keySet.now = func() time.Time { return clock }
+	first := iss.Keys().SigningKey.KeyID
+	assert.Len(t, iss.Keys().PublicKeys, 1)
+
+	// the next key is generated and published in advance
+	clock = now.Add(13 * time.Hour)
+	assert.Equal(t, first, iss.Keys().SigningKey.KeyID)
+	assert.Len(t, iss.Keys().PublicKeys, 2)
+
+	clock = now.Add(24*time.Hour + 30*time.Minute)
+	second := iss.Keys().SigningKey.KeyID
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, []string{second, first}, publicKeyIDs(iss.Keys()))
+
+	clock = now.Add(27 * time.Hour)
+	assert.Equal(t, []string{second}, publicKeyIDs(iss.Keys()))
+}
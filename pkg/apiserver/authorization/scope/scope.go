@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
+	"kubesphere.io/kubesphere/pkg/apiserver/authorization/authorizer"
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+const (
+	// ReadOnly restricts the token to read-only verbs (get, list and watch)
+	ReadOnly = "read-only"
+	// WorkspacePrefix restricts the token to the resources of the specified workspace, e.g. workspace:demo
+	WorkspacePrefix = "workspace:"
+	// APIGroupPrefix restricts the token to the resources of the specified api group, e.g. apigroup:apps,
+	// the core api group is represented by "core"
+	APIGroupPrefix = "apigroup:"
+	coreAPIGroup   = "core"
+)
+
+// Scopes is the parsed form of a list of token scopes, the restrictions are combined with AND.
+type Scopes struct {
+	ReadOnly   bool
+	Workspaces sets.Set[string]
+	APIGroups  sets.Set[string]
+}
+
+// Parse parses the given scopes, return error if any of them is invalid.
+func Parse(scopes []string) (*Scopes, error) {
+	parsed := &Scopes{Workspaces: sets.New[string](), APIGroups: sets.New[string]()}
+	for _, scope := range scopes {
+		switch {
+		case scope == ReadOnly:
+			parsed.ReadOnly = true
+		case strings.HasPrefix(scope, WorkspacePrefix) && len(scope) > len(WorkspacePrefix):
+			parsed.Workspaces.Insert(strings.TrimPrefix(scope, WorkspacePrefix))
+		case strings.HasPrefix(scope, APIGroupPrefix) && len(scope) > len(APIGroupPrefix):
+			parsed.APIGroups.Insert(strings.TrimPrefix(scope, APIGroupPrefix))
+		default:
+			return nil, fmt.Errorf("invalid scope %q", scope)
+		}
+	}
+	return parsed, nil
+}
+
+type scopeAuthorizer struct {
+	namespaceLister corev1listers.NamespaceLister
+}
+
+// NewAuthorizer returns an authorizer which denies the requests beyond the scopes of the token.
+// It has no opinion on requests that are not restricted, the decision is left to the next authorizer.
+func NewAuthorizer(namespaceLister corev1listers.NamespaceLister) authorizer.Authorizer {
+	return &scopeAuthorizer{namespaceLister: namespaceLister}
+}
+
+func (s *scopeAuthorizer) Authorize(a authorizer.Attributes) (authorizer.Decision, string, error) {
+	if a.GetUser() == nil {
+		return authorizer.DecisionNoOpinion, "", nil
+	}
+	extra := a.GetUser().GetExtra()
+	if len(extra[token.ExtraTokenType]) == 0 {
+		return authorizer.DecisionNoOpinion, "", nil
+	}
+
+	scopes, err := Parse(extra[token.ExtraTokenScopes])
+	if err != nil {
+		return authorizer.DecisionDeny, "", err
+	}
+
+	if scopes.ReadOnly && !a.IsReadOnly() {
+		return authorizer.DecisionDeny, "token scope is read-only", nil
+	}
+
+	if scopes.APIGroups.Len() > 0 {
+		if !a.IsResourceRequest() {
+			return authorizer.DecisionDeny, "token scope does not allow non-resource requests", nil
+		}
+		apiGroup := a.GetAPIGroup()
+		if apiGroup == "" {
+			apiGroup = coreAPIGroup
+		}
+		if !scopes.APIGroups.Has(apiGroup) {
+			return authorizer.DecisionDeny, fmt.Sprintf("token scope does not allow api group %q", apiGroup), nil
+		}
+	}
+
+	if scopes.Workspaces.Len() > 0 {
+		workspace, err := s.workspaceOf(a)
+		if err != nil {
+			return authorizer.DecisionDeny, "", err
+		}
+		if !scopes.Workspaces.Has(workspace) {
+			return authorizer.DecisionDeny, "token scope does not allow resources outside the specified workspaces", nil
+		}
+	}
+
+	return authorizer.DecisionNoOpinion, "", nil
+}
+
+// workspaceOf returns the workspace of the requested resource, for namespaced resources,
+// the workspace is resolved by the label of the namespace.
+func (s *scopeAuthorizer) workspaceOf(a authorizer.Attributes) (string, error) {
+	if a.GetWorkspace() != "" {
+		return a.GetWorkspace(), nil
+	}
+	if a.GetNamespace() == "" {
+		return "", nil
+	}
+	namespace, err := s.namespaceLister.Get(a.GetNamespace())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		klog.Error(err)
+		return "", err
+	}
+	return namespace.Labels[constants.WorkspaceLabelKey], nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
+	"kubesphere.io/kubesphere/pkg/apiserver/authorization/authorizer"
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"valid", []string{ReadOnly, "workspace:demo", "apigroup:apps"}, false},
+		{"unknown", []string{"admin"}, true},
+		{"empty workspace", []string{"workspace:"}, true},
+		{"empty api group", []string{"apigroup:"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.scopes); (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%v) error = %v, wantErr %v", tt.scopes, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	informerFactory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	namespaces := informerFactory.Core().V1().Namespaces().Informer().GetIndexer()
+	_ = namespaces.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "demo-ns",
+		Labels: map[string]string{constants.WorkspaceLabelKey: "demo"},
+	}})
+	_ = namespaces.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other-ns"}})
+	a := NewAuthorizer(informerFactory.Core().V1().Namespaces().Lister())
+
+	tokenUser := func(scopes ...string) user.Info {
+		return &user.DefaultInfo{
+			Name: "admin",
+			Extra: map[string][]string{
+				token.ExtraTokenType:   {string(token.PersonalAccessToken)},
+				token.ExtraTokenScopes: scopes,
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		attributes authorizer.AttributesRecord
+		want       authorizer.Decision
+	}{
+		{
+			name:       "session token is not restricted",
+			attributes: authorizer.AttributesRecord{User: &user.DefaultInfo{Name: "admin"}, Verb: "delete"},
+			want:       authorizer.DecisionNoOpinion,
+		},
+		{
+			name:       "token without scopes is not restricted",
+			attributes: authorizer.AttributesRecord{User: tokenUser(), Verb: "delete"},
+			want:       authorizer.DecisionNoOpinion,
+		},
+		{
+			name:       "read-only allows list",
+			attributes: authorizer.AttributesRecord{User: tokenUser(ReadOnly), Verb: "list"},
+			want:       authorizer.DecisionNoOpinion,
+		},
+		{
+			name:       "read-only denies delete",
+			attributes: authorizer.AttributesRecord{User: tokenUser(ReadOnly), Verb: "delete"},
+			want:       authorizer.DecisionDeny,
+		},
+		{
+			name:       "core api group",
+			attributes: authorizer.AttributesRecord{User: tokenUser("apigroup:core"), Verb: "get", ResourceRequest: true, Resource: "pods"},
+			want:       authorizer.DecisionNoOpinion,
+		},
+		{
+			name:       "api group not in scope",
+			attributes: authorizer.AttributesRecord{User: tokenUser("apigroup:apps"), Verb: "get", ResourceRequest: true, APIGroup: "iam.kubesphere.io"},
+			want:       authorizer.DecisionDeny,
+		},
+		{
+			name:       "api group denies non-resource request",
+			attributes: authorizer.AttributesRecord{User: tokenUser("apigroup:apps"), Verb: "get", Path: "/kapis/version"},
+			want:       authorizer.DecisionDeny,
+		},
+		{
+			name:       "workspace in scope",
+			attributes: authorizer.AttributesRecord{User: tokenUser("workspace:demo"), Verb: "get", Workspace: "demo"},
+			want:       authorizer.DecisionNoOpinion,
+		},
+		{
+			name:       "namespace of workspace in scope",
+			attributes: authorizer.AttributesRecord{User: tokenUser("workspace:demo"), Verb: "get", Namespace: "demo-ns"},
+			want:       authorizer.DecisionNoOpinion,
+		},
+		{
+			name:       "namespace of other workspace",
+			attributes: authorizer.AttributesRecord{User: tokenUser("workspace:demo"), Verb: "get", Namespace: "other-ns"},
+			want:       authorizer.DecisionDeny,
+		},
+		{
+			name:       "cluster scoped resource",
+			attributes: authorizer.AttributesRecord{User: tokenUser("workspace:demo"), Verb: "get", Resource: "nodes"},
+			want:       authorizer.DecisionDeny,
+		},
+		{
+			name:       "invalid scopes",
+			attributes: authorizer.AttributesRecord{User: tokenUser("unknown"), Verb: "get"},
+			want:       authorizer.DecisionDeny,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _, _ := a.Authorize(tt.attributes); got != tt.want {
+				t.Errorf("Authorize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
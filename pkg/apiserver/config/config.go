@@ -32,7 +32,10 @@ import (
 
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication"
 	"kubesphere.io/kubesphere/pkg/apiserver/authorization"
+	"kubesphere.io/kubesphere/pkg/apiserver/ratelimit"
 	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/models/components"
+	"kubesphere.io/kubesphere/pkg/models/registries/vulnerability"
 	"kubesphere.io/kubesphere/pkg/models/terminal"
 	"kubesphere.io/kubesphere/pkg/simple/client/alerting"
 	"kubesphere.io/kubesphere/pkg/simple/client/auditing"
@@ -176,6 +179,9 @@ type Config struct {
 	GatewayOptions        *gateway.Options        `json:"gateway,omitempty" yaml:"gateway,omitempty" mapstructure:"gateway"`
 	GPUOptions            *gpu.Options            `json:"gpu,omitempty" yaml:"gpu,omitempty" mapstructure:"gpu"`
 	TerminalOptions       *terminal.Options       `json:"terminal,omitempty" yaml:"terminal,omitempty" mapstructure:"terminal"`
+	VulnerabilityOptions  *vulnerability.Options  `json:"vulnerability,omitempty" yaml:"vulnerability,omitempty" mapstructure:"vulnerability"`
+	RateLimitOptions      *ratelimit.Options      `json:"ratelimit,omitempty" yaml:"ratelimit,omitempty" mapstructure:"ratelimit"`
+	ComponentsOptions     *components.Options     `json:"components,omitempty" yaml:"components,omitempty" mapstructure:"components"`
 }
 
 // newConfig creates a default non-empty Config
@@ -205,6 +211,9 @@ func New() *Config {
 		GatewayOptions:        gateway.NewGatewayOptions(),
 		GPUOptions:            gpu.NewGPUOptions(),
 		TerminalOptions:       terminal.NewTerminalOptions(),
+		VulnerabilityOptions:  vulnerability.NewVulnerabilityOptions(),
+		RateLimitOptions:      ratelimit.NewOptions(),
+		ComponentsOptions:     components.NewComponentsOptions(),
 	}
 }
 
@@ -222,14 +231,16 @@ func WatchConfigChange() <-chan Config {
 // convertToMap simply converts config to map[string]bool
 // to hide sensitive information
 func (conf *Config) ToMap() map[string]bool {
-	conf.stripEmptyOptions()
 	result := make(map[string]bool, 0)
 
 	if conf == nil {
 		return result
 	}
 
-	c := reflect.Indirect(reflect.ValueOf(conf))
+	// strip a copy, the config is in use by the server
+	stripped := *conf
+	stripped.stripEmptyOptions()
+	c := reflect.Indirect(reflect.ValueOf(&stripped))
 
 	for i := 0; i < c.NumField(); i++ {
 		name := strings.Split(c.Type().Field(i).Tag.Get("json"), ",")[0]
@@ -266,6 +277,11 @@ func (conf *Config) ToMap() map[string]bool {
 			continue
 		}
 
+		// the status of the features, not a feature
+		if name == "components" {
+			continue
+		}
+
 		if name == "openpitrix" {
 			// openpitrix is always true
 			result[name] = true
@@ -360,6 +376,18 @@ func (conf *Config) stripEmptyOptions() {
 	if conf.GPUOptions != nil && len(conf.GPUOptions.Kinds) == 0 {
 		conf.GPUOptions = nil
 	}
+
+	if conf.VulnerabilityOptions != nil && conf.VulnerabilityOptions.DatabasePath == "" {
+		conf.VulnerabilityOptions = nil
+	}
+
+	if conf.RateLimitOptions != nil && !conf.RateLimitOptions.Enable {
+		conf.RateLimitOptions = nil
+	}
+
+	if conf.TerminalOptions != nil && conf.TerminalOptions.Kubectl != nil && !conf.TerminalOptions.Kubectl.Enable {
+		conf.TerminalOptions.Kubectl = nil
+	}
 }
 
 // GetFromConfigMap returns KubeSphere ruuning config by the given ConfigMap.
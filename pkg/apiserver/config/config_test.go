@@ -30,6 +30,9 @@ import (
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication"
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
 	"kubesphere.io/kubesphere/pkg/apiserver/authorization"
+	"kubesphere.io/kubesphere/pkg/apiserver/ratelimit"
+	"kubesphere.io/kubesphere/pkg/models/components"
+	"kubesphere.io/kubesphere/pkg/models/registries/vulnerability"
 	"kubesphere.io/kubesphere/pkg/models/terminal"
 	"kubesphere.io/kubesphere/pkg/simple/client/alerting"
 	"kubesphere.io/kubesphere/pkg/simple/client/auditing"
@@ -113,8 +116,9 @@ func newTestConfig() (*Config, error) {
 				Bucket:          "app",
 			},
 			ReleaseControllerOptions: &openpitrix.ReleaseControllerOptions{
-				MaxConcurrent: 10,
-				WaitTime:      30 * time.Second,
+				MaxConcurrent:      10,
+				WaitTime:           30 * time.Second,
+				DriftCheckInterval: 10 * time.Minute,
 			},
 		},
 		NetworkOptions: &network.Options{
@@ -195,6 +199,37 @@ func newTestConfig() (*Config, error) {
 		TerminalOptions: &terminal.Options{
 			Image:   "alpine:3.15",
 			Timeout: 600,
+			Kubectl: &terminal.KubectlOptions{
+				Enable:             true,
+				Image:              "kubesphere/kubectl:v1.0.0",
+				Namespace:          "kubesphere-controls-system",
+				Server:             "http://ks-apiserver.kubesphere-system.svc",
+				MaxAge:             2 * time.Hour,
+				MaxSessionsPerUser: 3,
+				CPU:                "500m",
+				Memory:             "256Mi",
+			},
+		},
+		VulnerabilityOptions: &vulnerability.Options{
+			DatabasePath: "/etc/kubesphere/vulnerability/db.json",
+		},
+		RateLimitOptions: &ratelimit.Options{
+			Enable:        true,
+			SharedBuckets: true,
+			Rules: []ratelimit.Rule{
+				{Name: "system", Priority: 100, Groups: []string{"system:masters"}, Exempt: true},
+				{Name: "default", KeyBy: []string{ratelimit.KeyUser, ratelimit.KeyClient}, QPS: 50, Burst: 100},
+			},
+		},
+		ComponentsOptions: &components.Options{
+			CheckInterval: time.Minute,
+			Features: map[string]*components.FeatureOptions{
+				components.FeatureDevOps: {
+					Namespace:     "kubesphere-devops-system",
+					Selector:      "app.kubernetes.io/name=ks-devops",
+					LatestVersion: "v3.4.0",
+				},
+			},
 		},
 	}
 	return conf, nil
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Revision returns the digest of the configuration, configurations with the same options share the same revision.
+func (conf *Config) Revision() string {
+	data, err := yaml.Marshal(conf)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// RevisionStatus describes the configuration in use by ks-apiserver and the latest one rejected.
+type RevisionStatus struct {
+	// Revision of the configuration in use.
+	Revision string `json:"revision"`
+	// Generation is increased each time a configuration is applied, starting at 1.
+	Generation int64     `json:"generation"`
+	AppliedAt  time.Time `json:"appliedAt"`
+	// Rejected is the latest configuration rejected since the configuration in use was applied.
+	Rejected *RejectedRevision `json:"rejected,omitempty"`
+}
+
+type RejectedRevision struct {
+	Revision   string    `json:"revision"`
+	Reason     string    `json:"reason"`
+	RejectedAt time.Time `json:"rejectedAt"`
+}
+
+// RevisionTracker tracks the configurations applied and rejected while ks-apiserver is running.
+type RevisionTracker struct {
+	mutex  sync.RWMutex
+	status RevisionStatus
+}
+
+func NewRevisionTracker() *RevisionTracker {
+	return &RevisionTracker{}
+}
+
+// Applied records that conf is in use, and returns the new status.
+func (t *RevisionTracker) Applied(conf *Config) RevisionStatus {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.status = RevisionStatus{
+		Revision:   conf.Revision(),
+		Generation: t.status.Generation + 1,
+		AppliedAt:  time.Now(),
+	}
+	return t.status
+}
+
+// Rejected records that conf is rejected for err, and returns the new status.
+func (t *RevisionTracker) Rejected(conf *Config, err error) RevisionStatus {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.status.Rejected = &RejectedRevision{
+		Revision:   conf.Revision(),
+		Reason:     err.Error(),
+		RejectedAt: time.Now(),
+	}
+	return t.status
+}
+
+// Status returns a copy of the current status.
+func (t *RevisionTracker) Status() RevisionStatus {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	status := t.status
+	if status.Rejected != nil {
+		rejected := *status.Rejected
+		status.Rejected = &rejected
+	}
+	return status
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRevisionTracker(t *testing.T) {
+	conf := New()
+	if conf.Revision() != New().Revision() {
+		t.Fatal("expected the same options to share the same revision")
+	}
+
+	tracker := NewRevisionTracker()
+	applied := tracker.Applied(conf)
+	if applied.Generation != 1 || applied.Revision != conf.Revision() || applied.Rejected != nil {
+		t.Errorf("unexpected status %+v", applied)
+	}
+
+	invalid := New()
+	invalid.AuthenticationOptions.JwtSecret = ""
+	invalid.MonitoringOptions.Endpoint = "http://prometheus:9090"
+	if invalid.Revision() == conf.Revision() {
+		t.Fatal("expected different options to have different revisions")
+	}
+	rejected := tracker.Rejected(invalid, errors.New("JWT secret MUST not be empty"))
+	if rejected.Generation != 1 || rejected.Revision != conf.Revision() ||
+		rejected.Rejected == nil || rejected.Rejected.Revision != invalid.Revision() {
+		t.Errorf("unexpected status %+v", rejected)
+	}
+
+	conf.MonitoringOptions.Endpoint = "http://prometheus:9090"
+	applied = tracker.Applied(conf)
+	if applied.Generation != 2 || applied.Rejected != nil || tracker.Status().Revision != conf.Revision() {
+		t.Errorf("unexpected status %+v", applied)
+	}
+}
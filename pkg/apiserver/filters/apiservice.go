@@ -0,0 +1,193 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/proxy"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	extensionsv1alpha1 "kubesphere.io/api/extensions/v1alpha1"
+	runtimecache "sigs.k8s.io/controller-runtime/pkg/cache"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/request"
+	"kubesphere.io/kubesphere/pkg/models/extensions"
+)
+
+const (
+	remoteUserHeader  = "X-Remote-User"
+	remoteGroupHeader = "X-Remote-Group"
+)
+
+type apiService struct {
+	*extensions.Backend
+	name         string
+	groupVersion schema.GroupVersion
+	createdAt    int64
+}
+
+type apiServiceProxy struct {
+	next http.Handler
+	// group versions served by ks-apiserver itself, which can't be overridden
+	served func(schema.GroupVersion) bool
+	// presented to the backends of the RequestHeader auth mode, which are disabled without it
+	proxyClientCert *tls.Certificate
+
+	mutex         sync.RWMutex
+	apiServices   map[string]*apiService
+	groupVersions map[schema.GroupVersion]*apiService
+}
+
+// WithAPIService proxies requests of /kapis/{group}/{version} to the backend declared by the APIService
+// of the group version, APIServices are registered and unregistered as the informer notifies until stopCh is closed.
+func WithAPIService(next http.Handler, informer runtimecache.Informer, served func(schema.GroupVersion) bool,
+	proxyClientCert *tls.Certificate, stopCh <-chan struct{}) http.Handler {
+	p := &apiServiceProxy{
+		next:            next,
+		served:          served,
+		proxyClientCert: proxyClientCert,
+		apiServices:     make(map[string]*apiService),
+		groupVersions:   make(map[schema.GroupVersion]*apiService),
+	}
+	registration, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: p.register,
+		UpdateFunc: func(_, obj interface{}) {
+			p.register(obj)
+		},
+		DeleteFunc: p.unregister,
+	})
+	if err != nil {
+		klog.Errorf("Unable to watch APIServices: %v", err)
+		return next
+	}
+	go func() {
+		<-stopCh
+		if err := informer.RemoveEventHandler(registration); err != nil {
+			klog.Warningf("Unable to stop watching APIServices: %v", err)
+		}
+	}()
+	return p
+}
+
+func (p *apiServiceProxy) register(obj interface{}) {
+	item, ok := obj.(*extensionsv1alpha1.APIService)
+	if !ok {
+		return
+	}
+
+	gv := schema.GroupVersion{Group: item.Spec.Group, Version: item.Spec.Version}
+	backend, err := extensions.NewBackend(&item.Spec, p.proxyClientCert)
+	if err == nil && p.served(gv) {
+		klog.Warningf("APIService %s is ignored, %s is served by ks-apiserver", item.Name, gv)
+		backend = nil
+	} else if err == nil && backend.AuthMode == extensionsv1alpha1.AuthModeRequestHeader && p.proxyClientCert == nil {
+		klog.Warningf("APIService %s is ignored, auth mode %s requires the proxy client certificate of ks-apiserver", item.Name, backend.AuthMode)
+		backend = nil
+	} else if err != nil {
+		klog.Warningf("APIService %s is ignored: %v", item.Name, err)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if backend == nil {
+		delete(p.apiServices, item.Name)
+	} else {
+		p.apiServices[item.Name] = &apiService{
+			Backend:      backend,
+			name:         item.Name,
+			groupVersion: gv,
+			createdAt:    item.CreationTimestamp.UnixNano(),
+		}
+		klog.V(4).Infof("APIService %s registered for %s", item.Name, gv)
+	}
+	p.rebuild()
+}
+
+func (p *apiServiceProxy) unregister(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	item, ok := obj.(*extensionsv1alpha1.APIService)
+	if !ok {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.apiServices, item.Name)
+	p.rebuild()
+	klog.V(4).Infof("APIService %s unregistered", item.Name)
+}
+
+// rebuild indexes APIServices by group version, the oldest one wins if
+// several APIServices declare the same group version.
+func (p *apiServiceProxy) rebuild() {
+	groupVersions := make(map[schema.GroupVersion]*apiService, len(p.apiServices))
+	for _, item := range p.apiServices {
+		if existing, ok := groupVersions[item.groupVersion]; ok &&
+			(existing.createdAt < item.createdAt || (existing.createdAt == item.createdAt && existing.name < item.name)) {
+			continue
+		}
+		groupVersions[item.groupVersion] = item
+	}
+	p.groupVersions = groupVersions
+}
+
+func (p *apiServiceProxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	item := p.match(req.URL.Path)
+	if item == nil {
+		p.next.ServeHTTP(w, req)
+		return
+	}
+
+	switch item.AuthMode {
+	case extensionsv1alpha1.AuthModeNone:
+		req.Header.Del("Authorization")
+	case extensionsv1alpha1.AuthModeRequestHeader:
+		req.Header.Del("Authorization")
+		req.Header.Del(remoteUserHeader)
+		req.Header.Del(remoteGroupHeader)
+		if user, ok := request.UserFrom(req.Context()); ok {
+			req.Header.Set(remoteUserHeader, user.GetName())
+			for _, group := range user.GetGroups() {
+				req.Header.Add(remoteGroupHeader, group)
+			}
+		}
+	}
+
+	httpProxy := proxy.NewUpgradeAwareHandler(item.URL(item.groupVersion.Group, req.URL), item.Transport, false, false, &responder{})
+	httpProxy.ServeHTTP(w, req)
+}
+
+func (p *apiServiceProxy) match(path string) *apiService {
+	if !strings.HasPrefix(path, "/kapis/") {
+		return nil
+	}
+	parts := strings.SplitN(strings.TrimPrefix(path, "/kapis/"), "/", 3)
+	if len(parts) < 2 {
+		return nil
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.groupVersions[schema.GroupVersion{Group: parts[0], Version: parts[1]}]
+}
@@ -59,9 +59,16 @@ func (a *auditingFilter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// Watches are long-running read-only requests, the responses streamed can't be captured.
+	if info.Verb == request.VerbWatch {
+		a.next.ServeHTTP(w, req)
+		return
+	}
+
 	if event := a.LogRequestObject(req, info); event != nil {
 		resp := auditing.NewResponseCapture(w)
-		a.next.ServeHTTP(resp, req)
+		// the handlers may complete the event, e.g. the resources operated by a bulk request
+		a.next.ServeHTTP(resp, req.WithContext(request.WithAuditEvent(req.Context(), &event.Event)))
 		go a.LogResponseObject(event, resp)
 	} else {
 		a.next.ServeHTTP(w, req)
@@ -23,9 +23,12 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
 	"k8s.io/klog/v2"
 
+	iamv1alpha2 "kubesphere.io/api/iam/v1alpha2"
+
 	"kubesphere.io/kubesphere/pkg/apiserver/authorization/authorizer"
 	"kubesphere.io/kubesphere/pkg/apiserver/request"
 )
@@ -55,6 +58,14 @@ func (a *authzFilter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	attributes, err := getAuthorizerAttributes(ctx)
 	if err != nil {
 		responsewriters.InternalError(w, req, err)
+		return
+	}
+
+	// the user whose password is expired is only allowed to change the password
+	if passwordExpired(attributes.GetUser()) && !passwordChangeRequest(attributes) {
+		klog.V(4).Infof("Forbidden: %#v, Reason: password expired", req.RequestURI)
+		responsewriters.Forbidden(ctx, attributes, w, req, "password expired, please change the password first", a.serializer)
+		return
 	}
 
 	authorized, reason, err := a.Authorize(attributes)
@@ -72,6 +83,23 @@ func (a *authzFilter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	responsewriters.Forbidden(ctx, attributes, w, req, reason, a.serializer)
 }
 
+func passwordExpired(u user.Info) bool {
+	return u != nil && len(u.GetExtra()[iamv1alpha2.ExtraPasswordExpired]) > 0
+}
+
+// passwordChangeRequest returns whether the request changes the password of the user self, the logout is also
+// allowed so that the user can leave without changing the password.
+func passwordChangeRequest(attributes authorizer.Attributes) bool {
+	if !attributes.IsResourceRequest() {
+		return attributes.GetPath() == "/oauth/logout"
+	}
+	return attributes.GetAPIGroup() == iamv1alpha2.SchemeGroupVersion.Group &&
+		attributes.GetResource() == iamv1alpha2.ResourcesPluralUser &&
+		attributes.GetSubresource() == "password" &&
+		attributes.GetName() == attributes.GetUser().GetName() &&
+		attributes.GetVerb() == "update"
+}
+
 func getAuthorizerAttributes(ctx context.Context) (authorizer.Attributes, error) {
 	attribs := authorizer.AttributesRecord{}
 
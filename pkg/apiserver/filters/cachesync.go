@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"net/http"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+)
+
+type cacheSyncFilter struct {
+	next        http.Handler
+	synced      <-chan struct{}
+	exemptPaths []string
+	serializer  runtime.NegotiatedSerializer
+}
+
+// WithCacheSync returns 503 Service Unavailable until synced is closed, as the handlers read
+// the caches, except the requests of the exempt paths, e.g. /readyz.
+func WithCacheSync(next http.Handler, synced <-chan struct{}, exemptPaths ...string) http.Handler {
+	return &cacheSyncFilter{
+		next:        next,
+		synced:      synced,
+		exemptPaths: exemptPaths,
+		serializer:  serializer.NewCodecFactory(runtime.NewScheme()).WithoutConversion(),
+	}
+}
+
+func (c *cacheSyncFilter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	select {
+	case <-c.synced:
+		c.next.ServeHTTP(w, req)
+		return
+	default:
+	}
+	for _, path := range c.exemptPaths {
+		if req.URL.Path == path || strings.HasPrefix(req.URL.Path, path+"/") {
+			c.next.ServeHTTP(w, req)
+			return
+		}
+	}
+	w.Header().Set("Retry-After", "1")
+	err := apierrors.NewServiceUnavailable("the caches are not synced yet")
+	responsewriters.ErrorNegotiated(err, c.serializer, schema.GroupVersion{}, w, req)
+}
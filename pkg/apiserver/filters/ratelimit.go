@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/ratelimit"
+	"kubesphere.io/kubesphere/pkg/apiserver/request"
+	"kubesphere.io/kubesphere/pkg/utils/iputil"
+)
+
+type rateLimitFilter struct {
+	next        http.Handler
+	limiter     *ratelimit.RateLimiter
+	exemptPaths []string
+	serializer  runtime.NegotiatedSerializer
+}
+
+// WithRateLimit passes the requests within the rate limits on to handler,
+// and returns 429 Too Many Requests with Retry-After otherwise.
+// The requests of the exempt paths, e.g. /healthz, are never limited.
+func WithRateLimit(next http.Handler, limiter *ratelimit.RateLimiter, exemptPaths ...string) http.Handler {
+	if limiter == nil {
+		klog.V(4).Infof("Rate limiting is disabled")
+		return next
+	}
+	return &rateLimitFilter{
+		next:        next,
+		limiter:     limiter,
+		exemptPaths: exemptPaths,
+		serializer:  serializer.NewCodecFactory(runtime.NewScheme()).WithoutConversion(),
+	}
+}
+
+func (r *rateLimitFilter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	for _, path := range r.exemptPaths {
+		if req.URL.Path == path || strings.HasPrefix(req.URL.Path, path+"/") {
+			r.next.ServeHTTP(w, req)
+			return
+		}
+	}
+
+	ctx := req.Context()
+	u, _ := request.UserFrom(ctx)
+	info, _ := request.RequestInfoFrom(ctx)
+
+	decision := r.limiter.Limit(u, info, iputil.RemoteIp(req))
+	if decision.Allowed {
+		r.next.ServeHTTP(w, req)
+		return
+	}
+
+	retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	klog.V(4).Infof("Throttled: %s, rule: %s", req.RequestURI, decision.Rule)
+	// the Retry-After header is set by the details of the status
+	err := apierrors.NewTooManyRequests(fmt.Sprintf("rate limit of rule %s exceeded", decision.Rule), retryAfter)
+	responsewriters.ErrorNegotiated(err, r.serializer, schema.GroupVersion{}, w, req)
+}
@@ -33,6 +33,13 @@ const (
 	ParameterLimit         = "limit"
 	ParameterOrderBy       = "sortBy"
 	ParameterAscending     = "ascending"
+	// ParameterWatch and ParameterResourceVersion are used to watch the changes instead of listing,
+	// from the resource version given, which is the resource version of an object or a bookmark received before.
+	ParameterWatch           = "watch"
+	ParameterResourceVersion = "resourceVersion"
+	// ParameterAs is used to present the objects listed in another form, e.g. ?as=Table
+	ParameterAs = "as"
+	AsTable     = "Table"
 )
 
 // Query represents api search terms
@@ -49,6 +56,15 @@ type Query struct {
 	Filters map[Field]Value
 
 	LabelSelector string
+
+	// Watch the changes of the objects matching the query
+	Watch bool
+
+	// ResourceVersion the watch starts from, the objects changed after it are sent
+	ResourceVersion string
+
+	// As is the form the objects listed are presented in, e.g. AsTable, the objects themselves if it's empty
+	As string
 }
 
 type Pagination struct {
@@ -140,8 +156,13 @@ func ParseQueryParameter(request *restful.Request) *Query {
 
 	query.LabelSelector = request.QueryParameter(ParameterLabelSelector)
 
+	query.Watch, _ = strconv.ParseBool(request.QueryParameter(ParameterWatch))
+	query.ResourceVersion = request.QueryParameter(ParameterResourceVersion)
+	query.As = request.QueryParameter(ParameterAs)
+
 	for key, values := range request.Request.URL.Query() {
-		if !sliceutil.HasString([]string{ParameterPage, ParameterLimit, ParameterOrderBy, ParameterAscending, ParameterLabelSelector}, key) {
+		if !sliceutil.HasString([]string{ParameterPage, ParameterLimit, ParameterOrderBy, ParameterAscending, ParameterLabelSelector,
+			ParameterWatch, ParameterResourceVersion, ParameterAs}, key) {
 			// support multiple query condition
 			for _, value := range values {
 				query.Filters[Field(key)] = Value(value)
@@ -45,6 +45,27 @@ func TestParseQueryParameter(t *testing.T) {
 				},
 			},
 		},
+		{
+			"test watch case",
+			"name=foo&watch=true&resourceVersion=12345",
+			&Query{
+				Pagination:      NoPagination,
+				SortBy:          FieldCreationTimeStamp,
+				Filters:         map[Field]Value{FieldName: Value("foo")},
+				Watch:           true,
+				ResourceVersion: "12345",
+			},
+		},
+		{
+			"test table case",
+			"name=foo&as=Table&sortBy=restarts",
+			&Query{
+				Pagination: NoPagination,
+				SortBy:     Field("restarts"),
+				Filters:    map[Field]Value{FieldName: Value("foo")},
+				As:         AsTable,
+			},
+		},
 		{
 			"test bad case",
 			"xxxx=xxxx&dsfsw=xxxx&page=abc&limit=add&ascending=ssss",
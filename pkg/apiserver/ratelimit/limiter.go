@@ -0,0 +1,170 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/kubesphere/pkg/simple/client/cache"
+)
+
+// Limiter takes the tokens from the token buckets.
+type Limiter interface {
+	// Take takes a token from the bucket of the key, which is filled at qps up to burst tokens.
+	// It returns false and how long to wait for the next token if the bucket is empty.
+	Take(key string, qps float64, burst int) (bool, time.Duration, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket and takes a token, a bucket never taken from is full.
+func (b *bucket) take(now time.Time, qps float64, burst int) (bool, time.Duration) {
+	if b.last.IsZero() {
+		b.tokens = float64(burst)
+	} else if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed.Seconds()*qps)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / qps * float64(time.Second))
+}
+
+// full returns whether the bucket is full at now, so that it can be forgotten.
+func (b *bucket) full(now time.Time, qps float64, burst int) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*qps >= float64(burst)
+}
+
+type memoryLimiter struct {
+	mutex       sync.Mutex
+	buckets     map[string]*memoryBucket
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+type memoryBucket struct {
+	bucket
+	qps   float64
+	burst int
+}
+
+// NewMemoryLimiter creates a Limiter keeping the buckets in memory.
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{buckets: make(map[string]*memoryBucket), now: time.Now}
+}
+
+func (l *memoryLimiter) Take(key string, qps float64, burst int) (bool, time.Duration, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := l.now()
+	// the buckets full are the same as the buckets never taken from
+	if now.Sub(l.lastCleanup) > time.Minute {
+		for k, b := range l.buckets {
+			if b.full(now, b.qps, b.burst) {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastCleanup = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &memoryBucket{}
+		l.buckets[key] = b
+	}
+	b.qps, b.burst = qps, burst
+	allowed, retryAfter := b.take(now, qps, burst)
+	return allowed, retryAfter, nil
+}
+
+// takeScript takes a token from the bucket of KEYS[1] atomically, ARGV are the qps, the burst and now in microseconds.
+// It returns 1 and the tokens left if the token is taken, or 0 and the tokens in the bucket. The bucket is forgotten
+// once it's full, which is the same as a bucket never taken from.
+const takeScript = `
+local qps = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local tokens = burst
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "last")
+if bucket[1] and bucket[2] then
+	local last = tonumber(bucket[2])
+	tokens = tonumber(bucket[1])
+	if now > last then
+		tokens = math.min(burst, tokens + (now - last) / 1e6 * qps)
+	else
+		now = last
+	end
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "last", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / qps * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`
+
+type cacheLimiter struct {
+	cache cache.ScriptRunner
+	now   func() time.Time
+}
+
+// NewCacheLimiter creates a Limiter keeping the buckets in the cache, so that the buckets are shared by the
+// replicas of ks-apiserver. Each token is taken by a script run atomically by the cache, the buckets are kept
+// in memory if the cache can't run scripts, e.g. the in-memory cache, which is not shared anyway.
+func NewCacheLimiter(cacheClient cache.Interface) Limiter {
+	runner, ok := cacheClient.(cache.ScriptRunner)
+	if !ok {
+		klog.Warning("the cache can't run scripts, the rate limit buckets are kept in memory")
+		return NewMemoryLimiter()
+	}
+	return &cacheLimiter{cache: runner, now: time.Now}
+}
+
+func (l *cacheLimiter) Take(key string, qps float64, burst int) (bool, time.Duration, error) {
+	key = fmt.Sprintf("kubesphere:ratelimit:%s", key)
+	result, err := l.cache.RunScript(takeScript, []string{key},
+		strconv.FormatFloat(qps, 'f', -1, 64), burst, l.now().UnixMicro())
+	if err != nil {
+		return false, 0, err
+	}
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected result %v of taking token", result)
+	}
+	allowed, _ := values[0].(int64)
+	value, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("unexpected tokens %v of bucket: %v", values[1], err)
+	}
+	if allowed == 1 {
+		return true, 0, nil
+	}
+	return false, time.Duration((1 - tokens) / qps * float64(time.Second)), nil
+}
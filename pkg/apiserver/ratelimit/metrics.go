@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+
+	"kubesphere.io/kubesphere/pkg/utils/metrics"
+)
+
+const (
+	resultAllowed   = "allowed"
+	resultThrottled = "throttled"
+	resultExempt    = "exempt"
+	resultError     = "error"
+)
+
+var (
+	requestsTotal = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "ks_server_rate_limit_requests_total",
+			Help:           "Counter of ks_server requests matching the rate limit rules broken out for each rule and result, e.g. allowed, throttled, exempt and error.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"rule", "result"},
+	)
+
+	initMetrics sync.Once
+)
+
+func registerMetrics() {
+	metrics.MustRegister(requestsTotal)
+}
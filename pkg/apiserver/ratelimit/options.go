@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
+)
+
+// The requests matching a rule share the bucket of the same values of the keys of the rule.
+const (
+	KeyUser     = "user"
+	KeyGroup    = "group"
+	KeyClient   = "client"
+	KeyAPIGroup = "apiGroup"
+)
+
+// Options defines the rate limits of the requests to ks-apiserver, e.g.
+//
+//	ratelimit:
+//	  enable: true
+//	  sharedBuckets: true
+//	  rules:
+//	  - name: system
+//	    priority: 100
+//	    groups: ["system:masters"]
+//	    exempt: true
+//	  - name: monitoring
+//	    priority: 10
+//	    apiGroups: ["monitoring.kubesphere.io", "logging.kubesphere.io"]
+//	    keyBy: ["user"]
+//	    qps: 5
+//	    burst: 20
+//	  - name: default
+//	    keyBy: ["user", "client"]
+//	    qps: 50
+//	    burst: 100
+type Options struct {
+	Enable bool `json:"enable" yaml:"enable"`
+	// SharedBuckets keeps the buckets in the cache, e.g. Redis, so that the requests to all the replicas
+	// of ks-apiserver are limited together, each replica keeps its own buckets otherwise.
+	SharedBuckets bool `json:"sharedBuckets,omitempty" yaml:"sharedBuckets,omitempty"`
+	// Rules are matched in order of their priority, the first rule matched applies,
+	// the requests matching none of the rules are not limited.
+	Rules []Rule `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+type Rule struct {
+	Name string `json:"name" yaml:"name"`
+	// Priority of the rule, the rules of higher priority are matched first,
+	// the rules of the same priority are matched in order.
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
+	// The rule matches the requests of any of the users, groups, OAuth clients and API groups listed,
+	// any of them if the list is empty. "*" matches any.
+	Users     []string `json:"users,omitempty" yaml:"users,omitempty"`
+	Groups    []string `json:"groups,omitempty" yaml:"groups,omitempty"`
+	ClientIDs []string `json:"clientIDs,omitempty" yaml:"clientIDs,omitempty"`
+	APIGroups []string `json:"apiGroups,omitempty" yaml:"apiGroups,omitempty"`
+	// Exempt the requests matched from rate limiting.
+	Exempt bool `json:"exempt,omitempty" yaml:"exempt,omitempty"`
+	// KeyBy tells which requests matched share a bucket, the keys are user, group, client and apiGroup,
+	// defaults to user. The group is the group matched by the rule, and the client is the OAuth client
+	// or the personal access token the request is authenticated with. The anonymous requests keyed by
+	// user are keyed by the remote IP as well.
+	KeyBy []string `json:"keyBy,omitempty" yaml:"keyBy,omitempty"`
+	// QPS is the rate the tokens are added to the bucket, and Burst is the size of the bucket.
+	QPS   float64 `json:"qps,omitempty" yaml:"qps,omitempty"`
+	Burst int     `json:"burst,omitempty" yaml:"burst,omitempty"`
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+	names := make(map[string]bool, len(o.Rules))
+	for _, rule := range o.Rules {
+		if rule.Name == "" {
+			errs = append(errs, fmt.Errorf("the name of the rate limit rule is required"))
+			continue
+		}
+		if names[rule.Name] {
+			errs = append(errs, fmt.Errorf("duplicate rate limit rule %s", rule.Name))
+		}
+		names[rule.Name] = true
+		if rule.Exempt {
+			continue
+		}
+		if rule.QPS <= 0 || rule.Burst < 1 {
+			errs = append(errs, fmt.Errorf("the qps and burst of rate limit rule %s must be positive", rule.Name))
+		}
+		for _, key := range rule.KeyBy {
+			if !sliceutil.HasString([]string{KeyUser, KeyGroup, KeyClient, KeyAPIGroup}, key) {
+				errs = append(errs, fmt.Errorf("invalid key %s of rate limit rule %s", key, rule.Name))
+			}
+		}
+	}
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet, s *Options) {
+	fs.BoolVar(&o.Enable, "rate-limit", s.Enable, "Limit the rate of the requests by the rules configured.")
+	fs.BoolVar(&o.SharedBuckets, "rate-limit-shared-buckets", s.SharedBuckets, ""+
+		"Keep the rate limit buckets in the cache so that the replicas of ks-apiserver share the limits.")
+}
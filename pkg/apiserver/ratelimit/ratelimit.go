@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
+	"kubesphere.io/kubesphere/pkg/apiserver/request"
+	"kubesphere.io/kubesphere/pkg/simple/client/cache"
+)
+
+// Decision is the result of limiting a request.
+type Decision struct {
+	// Rule matched by the request, empty if none of the rules are matched.
+	Rule string
+	// Allowed is false if the request exceeds the limit of the rule.
+	Allowed bool
+	// RetryAfter is how long to wait before the request may be allowed.
+	RetryAfter time.Duration
+}
+
+// RateLimiter limits the rate of the requests by the rules.
+type RateLimiter struct {
+	rules   []Rule
+	limiter Limiter
+}
+
+// NewRateLimiter creates a RateLimiter of the rules, the buckets are kept in cacheClient if they are shared.
+func NewRateLimiter(options *Options, cacheClient cache.Interface) *RateLimiter {
+	initMetrics.Do(registerMetrics)
+
+	rules := make([]Rule, len(options.Rules))
+	copy(rules, options.Rules)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority > rules[j].Priority
+	})
+	var limiter Limiter
+	if options.SharedBuckets && cacheClient != nil {
+		limiter = NewCacheLimiter(cacheClient)
+	} else {
+		limiter = NewMemoryLimiter()
+	}
+	return &RateLimiter{rules: rules, limiter: limiter}
+}
+
+// Limit takes a token for the request of the user from the bucket of the rule matched, the anonymous
+// requests are keyed by the remote IP in addition, so that a client can't exhaust the bucket of all.
+// The request is allowed if it fails to take the token, e.g. the cache is not available.
+func (r *RateLimiter) Limit(u user.Info, info *request.RequestInfo, remoteIP string) Decision {
+	attributes := attributesOf(u, info, remoteIP)
+	for _, rule := range r.rules {
+		group, ok := rule.matches(attributes)
+		if !ok {
+			continue
+		}
+		if rule.Exempt {
+			requestsTotal.WithLabelValues(rule.Name, resultExempt).Inc()
+			return Decision{Rule: rule.Name, Allowed: true}
+		}
+		allowed, retryAfter, err := r.limiter.Take(rule.key(attributes, group), rule.QPS, rule.Burst)
+		if err != nil {
+			klog.Warningf("failed to limit the request by rule %s: %v", rule.Name, err)
+			requestsTotal.WithLabelValues(rule.Name, resultError).Inc()
+			return Decision{Rule: rule.Name, Allowed: true}
+		}
+		if !allowed {
+			requestsTotal.WithLabelValues(rule.Name, resultThrottled).Inc()
+			return Decision{Rule: rule.Name, RetryAfter: retryAfter}
+		}
+		requestsTotal.WithLabelValues(rule.Name, resultAllowed).Inc()
+		return Decision{Rule: rule.Name, Allowed: true}
+	}
+	return Decision{Allowed: true}
+}
+
+type attributes struct {
+	user     string
+	groups   []string
+	clientID string
+	apiGroup string
+	remoteIP string
+}
+
+func attributesOf(u user.Info, info *request.RequestInfo, remoteIP string) attributes {
+	a := attributes{remoteIP: remoteIP}
+	if u != nil {
+		a.user = u.GetName()
+		a.groups = u.GetGroups()
+		a.clientID = clientIDOf(u)
+	}
+	if info != nil && info.RequestInfo != nil {
+		a.apiGroup = info.APIGroup
+	}
+	return a
+}
+
+// clientIDOf returns the OAuth client the token is issued to, or the personal access token.
+func clientIDOf(u user.Info) string {
+	extra := u.GetExtra()
+	if clientIDs := extra[token.ExtraClientID]; len(clientIDs) > 0 {
+		return clientIDs[0]
+	}
+	if tokenIDs := extra[token.ExtraTokenID]; len(tokenIDs) > 0 {
+		return "token:" + tokenIDs[0]
+	}
+	return ""
+}
+
+// matches returns whether the rule matches the request, and the group matched if the rule matches by groups.
+func (rule *Rule) matches(a attributes) (string, bool) {
+	if !matchesAny(rule.Users, a.user) || !matchesAny(rule.ClientIDs, a.clientID) || !matchesAny(rule.APIGroups, a.apiGroup) {
+		return "", false
+	}
+	if len(rule.Groups) == 0 {
+		return "", true
+	}
+	for _, group := range a.groups {
+		if matchesAny(rule.Groups, group) {
+			return group, true
+		}
+	}
+	return "", false
+}
+
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == "*" || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// key returns the key of the bucket of the request, which is unique to the rule.
+func (rule *Rule) key(a attributes, group string) string {
+	keyBy := rule.KeyBy
+	if len(keyBy) == 0 {
+		keyBy = []string{KeyUser}
+	}
+	parts := []string{rule.Name}
+	for _, key := range keyBy {
+		switch key {
+		case KeyUser:
+			parts = append(parts, "user="+a.user)
+			// the anonymous requests don't share a bucket
+			if a.user == "" || a.user == user.Anonymous {
+				parts = append(parts, "ip="+a.remoteIP)
+			}
+		case KeyGroup:
+			parts = append(parts, "group="+group)
+		case KeyClient:
+			parts = append(parts, "client="+a.clientID)
+		case KeyAPIGroup:
+			parts = append(parts, "apiGroup="+a.apiGroup)
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimit
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
+	"kubesphere.io/kubesphere/pkg/apiserver/request"
+	"kubesphere.io/kubesphere/pkg/simple/client/cache"
+)
+
+// fakeScriptRunner takes the tokens of the buckets in memory like the take script, which runs only in redis.
+type fakeScriptRunner struct {
+	buckets map[string]*bucket
+}
+
+func (r *fakeScriptRunner) RunScript(script string, keys []string, args ...interface{}) (interface{}, error) {
+	qps, err := strconv.ParseFloat(args[0].(string), 64)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := r.buckets[keys[0]]
+	if !ok {
+		b = &bucket{}
+		r.buckets[keys[0]] = b
+	}
+	allowed, _ := b.take(time.UnixMicro(args[2].(int64)), qps, args[1].(int))
+	result := []interface{}{int64(0), strconv.FormatFloat(b.tokens, 'g', -1, 64)}
+	if allowed {
+		result[0] = int64(1)
+	}
+	return result, nil
+}
+
+func TestLimiters(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	cacheClient, _ := cache.NewInMemoryCache(nil, stopCh)
+	// the in-memory cache is not shared, and can't run scripts
+	if _, ok := NewCacheLimiter(cacheClient).(*memoryLimiter); !ok {
+		t.Errorf("expected the buckets in memory with the in-memory cache")
+	}
+
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+	memory := NewMemoryLimiter().(*memoryLimiter)
+	memory.now = clock
+	shared := NewCacheLimiter(struct {
+		cache.Interface
+		*fakeScriptRunner
+	}{cacheClient, &fakeScriptRunner{buckets: make(map[string]*bucket)}}).(*cacheLimiter)
+	shared.now = clock
+
+	for name, limiter := range map[string]Limiter{"memory": memory, "cache": shared} {
+		now = time.Unix(1700000000, 0)
+		for i := 0; i < 3; i++ {
+			if allowed, _, err := limiter.Take("foo", 2, 3); err != nil || !allowed {
+				t.Fatalf("%s: expected token %d to be taken, got %v, %v", name, i, allowed, err)
+			}
+		}
+		allowed, retryAfter, err := limiter.Take("foo", 2, 3)
+		if err != nil || allowed || retryAfter != 500*time.Millisecond {
+			t.Errorf("%s: expected the bucket to be empty, got %v, %v, %v", name, allowed, retryAfter, err)
+		}
+		if allowed, _, _ = limiter.Take("bar", 2, 3); !allowed {
+			t.Errorf("%s: expected the buckets of other keys to be full", name)
+		}
+		now = now.Add(500 * time.Millisecond)
+		if allowed, _, _ = limiter.Take("foo", 2, 3); !allowed {
+			t.Errorf("%s: expected the bucket to be refilled", name)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(&Options{
+		Enable: true,
+		Rules: []Rule{
+			{Name: "default", KeyBy: []string{KeyUser}, QPS: 0.001, Burst: 2},
+			{Name: "monitoring", Priority: 10, APIGroups: []string{"monitoring.kubesphere.io"}, KeyBy: []string{KeyClient}, QPS: 0.001, Burst: 1},
+			{Name: "system", Priority: 100, Groups: []string{"system:masters"}, Exempt: true},
+		},
+	}, nil)
+
+	info := func(apiGroup string) *request.RequestInfo {
+		return &request.RequestInfo{RequestInfo: &k8srequest.RequestInfo{APIGroup: apiGroup}}
+	}
+	admin := &user.DefaultInfo{Name: "admin", Groups: []string{"system:masters"}}
+	alice := &user.DefaultInfo{Name: "alice", Extra: map[string][]string{token.ExtraClientID: {"kubesphere"}}}
+	bob := &user.DefaultInfo{Name: "bob", Extra: map[string][]string{token.ExtraClientID: {"kubesphere"}}}
+	anonymous := &user.DefaultInfo{Name: user.Anonymous, Groups: []string{user.AllUnauthenticated}}
+
+	tests := []struct {
+		user     user.Info
+		apiGroup string
+		remoteIP string
+		expected Decision
+	}{
+		{admin, "", "10.0.0.1", Decision{Rule: "system", Allowed: true}},
+		{admin, "", "10.0.0.1", Decision{Rule: "system", Allowed: true}},
+		{admin, "", "10.0.0.1", Decision{Rule: "system", Allowed: true}},
+		{alice, "", "10.0.0.1", Decision{Rule: "default", Allowed: true}},
+		{alice, "", "10.0.0.2", Decision{Rule: "default", Allowed: true}},
+		{alice, "", "10.0.0.3", Decision{Rule: "default"}},
+		{bob, "", "10.0.0.1", Decision{Rule: "default", Allowed: true}},
+		// the users of the same client share the bucket of the client
+		{alice, "monitoring.kubesphere.io", "10.0.0.1", Decision{Rule: "monitoring", Allowed: true}},
+		{bob, "monitoring.kubesphere.io", "10.0.0.1", Decision{Rule: "monitoring"}},
+		// the anonymous requests share the bucket of the remote IP only
+		{anonymous, "", "10.0.0.1", Decision{Rule: "default", Allowed: true}},
+		{anonymous, "", "10.0.0.1", Decision{Rule: "default", Allowed: true}},
+		{anonymous, "", "10.0.0.1", Decision{Rule: "default"}},
+		{anonymous, "", "10.0.0.2", Decision{Rule: "default", Allowed: true}},
+	}
+	for i, tt := range tests {
+		decision := limiter.Limit(tt.user, info(tt.apiGroup), tt.remoteIP)
+		decision.RetryAfter = 0
+		if decision != tt.expected {
+			t.Errorf("request %d of %s: expected %+v, got %+v", i, tt.user.GetName(), tt.expected, decision)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	options := &Options{Rules: []Rule{
+		{Name: "default", QPS: 10, Burst: 20},
+		{Name: "default", Exempt: true},
+		{Name: "zero", QPS: 0, Burst: 20},
+		{Name: "key", QPS: 1, Burst: 1, KeyBy: []string{"namespace"}},
+		{QPS: 1, Burst: 1},
+	}}
+	if errs := options.Validate(); len(errs) != 4 {
+		t.Errorf("expected 4 errors, got %v", errs)
+	}
+}
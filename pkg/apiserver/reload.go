@@ -0,0 +1,71 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"k8s.io/klog/v2"
+)
+
+// handlerSwitch serves requests with the handler chain of the configuration in use.
+type handlerSwitch struct {
+	handler atomic.Value
+}
+
+func (h *handlerSwitch) store(handler http.Handler) {
+	h.handler.Store(handler)
+}
+
+func (h *handlerSwitch) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.handler.Load().(http.Handler).ServeHTTP(w, req)
+}
+
+// Reload makes the server serve requests with next, which is created from a new configuration
+// and shares the server, Kubernetes clients and caches with s. The APIs of next are installed
+// with stopCh, the informers newly required are started with ctx, and next takes over once they
+// are synced. Requests in flight keep being served by s, it's up to the caller to stop s afterwards.
+func (s *APIServer) Reload(ctx context.Context, next *APIServer, stopCh <-chan struct{}) error {
+	if next.Server != s.Server {
+		return fmt.Errorf("the server can't be changed on reload")
+	}
+	handler, ok := s.Server.Handler.(*handlerSwitch)
+	if !ok {
+		return fmt.Errorf("the server is not prepared to run")
+	}
+	if err := next.PrepareRun(stopCh); err != nil {
+		return err
+	}
+
+	// the runtime cache is started once the initial sync is done, see Run
+	select {
+	case <-s.cacheSynced:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := next.syncInformers(ctx.Done()); err != nil {
+		return err
+	}
+	close(next.cacheSynced)
+
+	handler.store(next.handler)
+	klog.V(0).Info("Reloaded the server with the new configuration")
+	return nil
+}
@@ -28,6 +28,7 @@ const (
 	KubeSphereNamespace           = "kubesphere-system"
 	KubeSphereControlNamespace    = "kubesphere-controls-system"
 	PorterNamespace               = "porter-system"
+	KubeEdgeNamespace             = "kubeedge"
 	IngressControllerNamespace    = KubeSphereControlNamespace
 	AdminUserName                 = "admin"
 	IngressControllerPrefix       = "kubesphere-router-"
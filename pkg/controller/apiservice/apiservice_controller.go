@@ -0,0 +1,160 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	extensionsv1alpha1 "kubesphere.io/api/extensions/v1alpha1"
+
+	"kubesphere.io/kubesphere/pkg/models/extensions"
+)
+
+const (
+	controllerName = "apiservice-controller"
+
+	// availabilityCheckInterval is how often the backends of APIServices are checked
+	availabilityCheckInterval = time.Minute
+	availabilityCheckTimeout  = 5 * time.Second
+
+	reasonPassed           = "Passed"
+	reasonInvalidSpec      = "InvalidSpec"
+	reasonServiceNotFound  = "ServiceNotFound"
+	reasonMissingEndpoints = "MissingEndpoints"
+	reasonFailedCheck      = "FailedAvailabilityCheck"
+)
+
+// Reconciler checks the availability of the backends of APIServices, and reports it in their status
+type Reconciler struct {
+	client.Client
+	logger logr.Logger
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	if r.logger.GetSink() == nil {
+		r.logger = ctrl.Log.WithName("controllers").WithName(controllerName)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		For(&extensionsv1alpha1.APIService{}).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=extensions.kubesphere.io,resources=apiservices,verbs=get;list;watch
+// +kubebuilder:rbac:groups=extensions.kubesphere.io,resources=apiservices/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=services;endpoints,verbs=get;list;watch
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.logger.WithValues("apiservice", req.NamespacedName)
+	apiService := &extensionsv1alpha1.APIService{}
+	if err := r.Get(ctx, req.NamespacedName, apiService); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !apiService.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	condition := r.checkAvailability(ctx, apiService)
+	condition.Type = extensionsv1alpha1.APIServiceAvailable
+	condition.ObservedGeneration = apiService.Generation
+
+	updated := apiService.DeepCopy()
+	meta.SetStatusCondition(&updated.Status.Conditions, condition)
+	if !equality.Semantic.DeepEqual(apiService.Status, updated.Status) {
+		logger.V(4).Info("availability changed", "status", condition.Status, "reason", condition.Reason)
+		if err := r.Status().Update(ctx, updated); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: availabilityCheckInterval}, nil
+}
+
+func (r *Reconciler) checkAvailability(ctx context.Context, apiService *extensionsv1alpha1.APIService) metav1.Condition {
+	// the front proxy client certificate is only presented by ks-apiserver
+	backend, err := extensions.NewBackend(&apiService.Spec, nil)
+	if err != nil {
+		return unavailable(reasonInvalidSpec, err.Error())
+	}
+
+	if ref := apiService.Spec.Service; ref != nil {
+		service := &corev1.Service{}
+		if err = r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, service); err != nil {
+			if apierrors.IsNotFound(err) {
+				return unavailable(reasonServiceNotFound, fmt.Sprintf("service %s/%s is not found", ref.Namespace, ref.Name))
+			}
+			return unavailable(reasonFailedCheck, err.Error())
+		}
+		if service.Spec.Type != corev1.ServiceTypeExternalName {
+			endpoints := &corev1.Endpoints{}
+			if err = r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, endpoints); client.IgnoreNotFound(err) != nil {
+				return unavailable(reasonFailedCheck, err.Error())
+			}
+			if !hasReadyAddress(endpoints) {
+				return unavailable(reasonMissingEndpoints, fmt.Sprintf("service %s/%s has no ready endpoints", ref.Namespace, ref.Name))
+			}
+		}
+	}
+
+	// any response from the backend but a server error means it's available,
+	// the request has no credentials so it's usually rejected.
+	ctx, cancel := context.WithTimeout(ctx, availabilityCheckTimeout)
+	defer cancel()
+	target := backend.URL(apiService.Spec.Group, &url.URL{Path: fmt.Sprintf("/kapis/%s/%s", apiService.Spec.Group, apiService.Spec.Version)})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return unavailable(reasonFailedCheck, err.Error())
+	}
+	resp, err := (&http.Client{Transport: backend.Transport}).Do(req)
+	if err != nil {
+		return unavailable(reasonFailedCheck, fmt.Sprintf("failing or missing response from %s: %v", target, err))
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return unavailable(reasonFailedCheck, fmt.Sprintf("bad status from %s: %d", target, resp.StatusCode))
+	}
+
+	return metav1.Condition{Status: metav1.ConditionTrue, Reason: reasonPassed, Message: "all checks passed"}
+}
+
+func unavailable(reason, message string) metav1.Condition {
+	return metav1.Condition{Status: metav1.ConditionFalse, Reason: reason, Message: message}
+}
+
+func hasReadyAddress(endpoints *corev1.Endpoints) bool {
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiservice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	extensionsv1alpha1 "kubesphere.io/api/extensions/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	backendURL := server.URL + "/kapis/devops.kubesphere.io"
+	invalidURL := "ftp://devops"
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = extensionsv1alpha1.AddToScheme(scheme)
+
+	newAPIService := func(name string, spec extensionsv1alpha1.APIServiceSpec) *extensionsv1alpha1.APIService {
+		spec.Group, spec.Version = "devops.kubesphere.io", "v1alpha3"
+		return &extensionsv1alpha1.APIService{ObjectMeta: metav1.ObjectMeta{Name: name, Generation: 1}, Spec: spec}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newAPIService("url", extensionsv1alpha1.APIServiceSpec{URL: &backendURL, InsecurePassthrough: true}),
+		newAPIService("plain-http", extensionsv1alpha1.APIServiceSpec{URL: &backendURL}),
+		newAPIService("invalid", extensionsv1alpha1.APIServiceSpec{URL: &invalidURL}),
+		newAPIService("missing-service", extensionsv1alpha1.APIServiceSpec{
+			Service: &extensionsv1alpha1.ServiceReference{Namespace: "kubesphere-devops-system", Name: "missing"},
+		}),
+		newAPIService("no-endpoints", extensionsv1alpha1.APIServiceSpec{
+			Service: &extensionsv1alpha1.ServiceReference{Namespace: "kubesphere-devops-system", Name: "devops-apiserver"},
+		}),
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "kubesphere-devops-system", Name: "devops-apiserver"}},
+	).Build()
+	reconciler := &Reconciler{Client: client, logger: log.Log}
+
+	for name, expected := range map[string]string{
+		"url":             reasonPassed,
+		"invalid":         reasonInvalidSpec,
+		"plain-http":      reasonInvalidSpec,
+		"missing-service": reasonServiceNotFound,
+		"no-endpoints":    reasonMissingEndpoints,
+	} {
+		result, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.RequeueAfter != availabilityCheckInterval {
+			t.Errorf("unexpected result %+v", result)
+		}
+
+		apiService := &extensionsv1alpha1.APIService{}
+		if err = client.Get(context.Background(), types.NamespacedName{Name: name}, apiService); err != nil {
+			t.Fatal(err)
+		}
+		condition := meta.FindStatusCondition(apiService.Status.Conditions, extensionsv1alpha1.APIServiceAvailable)
+		if condition == nil || condition.Reason != expected || (condition.Status == metav1.ConditionTrue) != (expected == reasonPassed) ||
+			condition.ObservedGeneration != 1 {
+			t.Errorf("unexpected condition of %s: %+v", name, condition)
+		}
+	}
+
+	if len(paths) != 1 || paths[0] != "/kapis/devops.kubesphere.io/v1alpha3" {
+		t.Errorf("unexpected availability checks %v", paths)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package groupsync
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	iamv1alpha2 "kubesphere.io/api/iam/v1alpha2"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider"
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
+	kubesphere "kubesphere.io/kubesphere/pkg/client/clientset/versioned"
+	iamv1alpha2informers "kubesphere.io/kubesphere/pkg/client/informers/externalversions/iam/v1alpha2"
+	iamv1alpha2listers "kubesphere.io/kubesphere/pkg/client/listers/iam/v1alpha2"
+	"kubesphere.io/kubesphere/pkg/models/iam/group"
+)
+
+const controllerName = "groupsync-controller"
+
+// Controller synchronizes the groups periodically from the identity providers which support
+// listing groups, e.g. LDAP. Groups of other identity providers are synchronized when users login.
+type Controller struct {
+	userLister        iamv1alpha2listers.UserLister
+	userSynced        cache.InformerSynced
+	synchronizer      group.Synchronizer
+	identityProviders []oauth.IdentityProviderOptions
+}
+
+// NewController creates GroupSync Controller instance
+func NewController(ksClient kubesphere.Interface, userInformer iamv1alpha2informers.UserInformer,
+	identityProviders []oauth.IdentityProviderOptions) *Controller {
+	return &Controller{
+		userLister:        userInformer.Lister(),
+		userSynced:        userInformer.Informer().HasSynced,
+		synchronizer:      group.NewSynchronizer(ksClient),
+		identityProviders: identityProviders,
+	}
+}
+
+func (c *Controller) Start(ctx context.Context) error {
+	klog.Infof("Starting %s", controllerName)
+	defer klog.Infof("Shutting down %s", controllerName)
+	if !cache.WaitForCacheSync(ctx.Done(), c.userSynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	for i := range c.identityProviders {
+		providerOptions := c.identityProviders[i]
+		if providerOptions.GroupSync == nil || providerOptions.GroupSync.SyncPeriod <= 0 {
+			continue
+		}
+		provider := groupProvider(providerOptions.Name)
+		if provider == nil {
+			klog.Warningf("identity provider %s does not support listing groups, groups are only synchronized when users login", providerOptions.Name)
+			continue
+		}
+		go wait.UntilWithContext(ctx, func(ctx context.Context) {
+			if err := c.sync(&providerOptions, provider); err != nil {
+				klog.Errorf("failed to synchronize groups from identity provider %s: %v", providerOptions.Name, err)
+			}
+		}, providerOptions.GroupSync.SyncPeriod)
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// sync lists the groups from identity provider and reconciles the groups with the mapped users,
+// the group members which are not mapped to any user are ignored.
+func (c *Controller) sync(providerOptions *oauth.IdentityProviderOptions, provider identityprovider.GroupProvider) error {
+	groups, err := provider.ListGroups()
+	if err != nil {
+		return err
+	}
+	users, err := c.userLister.List(labels.SelectorFromSet(labels.Set{iamv1alpha2.IdentifyProviderLabel: providerOptions.Name}))
+	if err != nil {
+		return err
+	}
+	usernames := make(map[string]string, len(users))
+	for _, user := range users {
+		usernames[user.Labels[iamv1alpha2.OriginUIDLabel]] = user.Name
+	}
+	members := make(map[string][]string, len(groups))
+	for _, g := range groups {
+		members[g.Name] = make([]string, 0, len(g.Members))
+		for _, uid := range g.Members {
+			if username, ok := usernames[uid]; ok {
+				members[g.Name] = append(members[g.Name], username)
+			}
+		}
+	}
+	klog.V(4).Infof("synchronizing %d groups from identity provider %s", len(members), providerOptions.Name)
+	return c.synchronizer.SyncGroups(providerOptions.Name, providerOptions.GroupSync, members)
+}
+
+func groupProvider(name string) identityprovider.GroupProvider {
+	if provider, err := identityprovider.GetGenericProvider(name); err == nil {
+		if groupProvider, ok := provider.(identityprovider.GroupProvider); ok {
+			return groupProvider
+		}
+	}
+	if provider, err := identityprovider.GetOAuthProvider(name); err == nil {
+		if groupProvider, ok := provider.(identityprovider.GroupProvider); ok {
+			return groupProvider
+		}
+	}
+	return nil
+}
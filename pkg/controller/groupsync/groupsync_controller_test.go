@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package groupsync
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	iamv1alpha2 "kubesphere.io/api/iam/v1alpha2"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider"
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
+	fakeks "kubesphere.io/kubesphere/pkg/client/clientset/versioned/fake"
+	ksinformers "kubesphere.io/kubesphere/pkg/client/informers/externalversions"
+)
+
+type fakeGroupProvider []identityprovider.Group
+
+func (f fakeGroupProvider) ListGroups() ([]identityprovider.Group, error) {
+	return f, nil
+}
+
+type fakeSynchronizer struct {
+	members map[string][]string
+}
+
+func (f *fakeSynchronizer) SyncUserGroups(string, *oauth.GroupSyncOptions, string, []string) error {
+	return nil
+}
+
+func (f *fakeSynchronizer) SyncGroups(_ string, _ *oauth.GroupSyncOptions, members map[string][]string) error {
+	f.members = members
+	return nil
+}
+
+func newUser(name, uid, idp string) *iamv1alpha2.User {
+	return &iamv1alpha2.User{ObjectMeta: metav1.ObjectMeta{
+		Name: name,
+		Labels: map[string]string{
+			iamv1alpha2.IdentifyProviderLabel: idp,
+			iamv1alpha2.OriginUIDLabel:        uid,
+		},
+	}}
+}
+
+func TestSync(t *testing.T) {
+	ksClient := fakeks.NewSimpleClientset()
+	informerFactory := ksinformers.NewSharedInformerFactory(ksClient, 0)
+	userInformer := informerFactory.Iam().V1alpha2().Users()
+	for _, user := range []*iamv1alpha2.User{
+		newUser("alice", "alice", "ldap"),
+		newUser("bob", "bob", "ldap"),
+		newUser("carol", "alice", "oidc"),
+	} {
+		if err := userInformer.Informer().GetIndexer().Add(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	synchronizer := &fakeSynchronizer{}
+	controller := NewController(ksClient, userInformer, nil)
+	controller.synchronizer = synchronizer
+
+	provider := fakeGroupProvider{
+		{Name: "developers", Members: []string{"alice", "bob", "dave"}},
+		{Name: "admins", Members: []string{"bob"}},
+		{Name: "empty"},
+	}
+	providerOptions := &oauth.IdentityProviderOptions{Name: "ldap", GroupSync: &oauth.GroupSyncOptions{Workspace: "ws1"}}
+	if err := controller.sync(providerOptions, provider); err != nil {
+		t.Fatal(err)
+	}
+	for _, members := range synchronizer.members {
+		sort.Strings(members)
+	}
+	expected := map[string][]string{
+		"developers": {"alice", "bob"},
+		"admins":     {"bob"},
+		"empty":      {},
+	}
+	if !reflect.DeepEqual(synchronizer.members, expected) {
+		t.Errorf("got %v, want %v", synchronizer.members, expected)
+	}
+}
@@ -31,13 +31,15 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
-	batchv1informers "k8s.io/client-go/informers/batch/v1"
+	k8sinformers "k8s.io/client-go/informers"
 	batchv1listers "k8s.io/client-go/listers/batch/v1"
 	"k8s.io/klog/v2"
 
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
+
+	"kubesphere.io/kubesphere/pkg/models/workloads"
 )
 
 const (
@@ -53,23 +55,35 @@ const (
 type JobController struct {
 	client clientset.Interface
 
-	jobLister batchv1listers.JobLister
-	jobSynced cache.InformerSynced
+	cronJobOperator workloads.CronJobOperator
+
+	jobLister     batchv1listers.JobLister
+	cronJobLister batchv1listers.CronJobLister
+	// the cronjobs and the pods are read by the cronJobOperator
+	cacheSynced []cache.InformerSynced
 
 	queue workqueue.RateLimitingInterface
 
 	workerLoopPeriod time.Duration
 }
 
-func NewJobController(jobInformer batchv1informers.JobInformer, client clientset.Interface) *JobController {
+func NewJobController(informers k8sinformers.SharedInformerFactory, client clientset.Interface) *JobController {
 	v := &JobController{
 		client:           client,
+		cronJobOperator:  workloads.NewCronJobOperator(informers, client),
 		queue:            workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "job"),
 		workerLoopPeriod: time.Second,
 	}
 
+	jobInformer := informers.Batch().V1().Jobs()
+	cronJobInformer := informers.Batch().V1().CronJobs()
 	v.jobLister = jobInformer.Lister()
-	v.jobSynced = jobInformer.Informer().HasSynced
+	v.cronJobLister = cronJobInformer.Lister()
+	v.cacheSynced = []cache.InformerSynced{
+		jobInformer.Informer().HasSynced,
+		cronJobInformer.Informer().HasSynced,
+		informers.Core().V1().Pods().Informer().HasSynced,
+	}
 
 	jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: v.enqueueJob,
@@ -93,7 +107,7 @@ func (v *JobController) Run(workers int, stopCh <-chan struct{}) error {
 	klog.Info("starting job controller")
 	defer klog.Info("shutting down job controller")
 
-	if !cache.WaitForCacheSync(stopCh, v.jobSynced) {
+	if !cache.WaitForCacheSync(stopCh, v.cacheSynced...) {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
@@ -164,6 +178,51 @@ func (v *JobController) syncJob(key string) error {
 		return err
 	}
 
+	err = v.pruneCronJobHistory(key, job)
+	if err != nil {
+		klog.Error(err, "prune cronjob history failed", "namespace", namespace, "name", name)
+		return err
+	}
+
+	return nil
+}
+
+// pruneCronJobHistory applies the prune rules of the CronJob owning the job once the job finished,
+// and checks the job again when it expires.
+func (v *JobController) pruneCronJobHistory(key string, job *batchv1.Job) error {
+	owner := metav1.GetControllerOf(job)
+	if owner == nil || owner.Kind != "CronJob" {
+		return nil
+	}
+	status, finishedAt := workloads.JobStatus(job)
+	if status == workloads.JobRunRunning {
+		return nil
+	}
+
+	cronJob, err := v.cronJobLister.CronJobs(job.Namespace).Get(owner.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	rules, err := workloads.GetPruneRules(cronJob)
+	if err != nil {
+		klog.Warningf("ignore prune rules of cronjob %s/%s: %v", cronJob.Namespace, cronJob.Name, err)
+		return nil
+	}
+	if rules == nil {
+		return nil
+	}
+
+	if _, err = v.cronJobOperator.Prune(job.Namespace, owner.Name); err != nil {
+		return err
+	}
+	if rules.TTL > 0 && finishedAt != nil {
+		if remaining := rules.TTL - time.Since(finishedAt.Time); remaining > 0 {
+			v.queue.AddAfter(key, remaining+time.Second)
+		}
+	}
 	return nil
 }
 
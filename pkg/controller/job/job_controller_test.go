@@ -138,7 +138,7 @@ func (f *fixture) newController() (*JobController, kubeinformers.SharedInformerF
 
 	k8sI := kubeinformers.NewSharedInformerFactory(f.kubeclient, noResyncPeriodFunc())
 
-	jobController := NewJobController(k8sI.Batch().V1().Jobs(), f.kubeclient)
+	jobController := NewJobController(k8sI, f.kubeclient)
 
 	for _, job := range f.jobLister {
 		_ = k8sI.Batch().V1().Jobs().Informer().GetIndexer().Add(job)
@@ -201,6 +201,8 @@ func (r *ReconcileHelmApplicationVersion) updateStatus(appVersion *v1alpha1.Helm
 				Operator: appVersion.GetCreator(),
 			},
 		},
+		// the chart may be verified when it's uploaded
+		Verification: appVersion.Status.Verification,
 	}
 
 	err := r.Status().Update(context.TODO(), appVersion)
@@ -19,7 +19,6 @@ package helmrelease
 import (
 	"context"
 	"path"
-	"strings"
 
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
@@ -42,15 +41,21 @@ func (r *ReconcileHelmRelease) GetChartData(rls *v1alpha1.HelmRelease) (chartNam
 		index, _ := helmrepoindex.ByteArrayToSavedIndex([]byte(repo.Status.Data))
 
 		if version := index.GetApplicationVersion(rls.Spec.ApplicationId, rls.Spec.ApplicationVersionId); version != nil {
-			url := version.Spec.URLs[0]
-			if !(strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "s3://")) {
-				url = repo.Spec.Url + "/" + url
+			if err := helmrepoindex.CheckVerification(version.Status.Verification, repo.VerificationRequired()); err != nil {
+				klog.Errorf("app version %s can not be deployed, error: %s", rls.Spec.ApplicationVersionId, err)
+				return chartName, chartData, err
 			}
+			url := helmrepoindex.ChartURL(&repo, version.Spec.URLs[0])
 			buf, err := helmrepoindex.LoadChart(context.TODO(), url, &repo.Spec.Credential)
 			if err != nil {
 				klog.Infof("load chart failed, error: %s", err)
 				return chartName, chartData, ErrLoadChartFailed
 			}
+			// the chart may be replaced in the repo after it was verified
+			if err := helmrepoindex.CheckChartDigest(version.Status.Verification, buf.Bytes()); err != nil {
+				klog.Errorf("app version %s can not be deployed, error: %s", rls.Spec.ApplicationVersionId, err)
+				return chartName, chartData, err
+			}
 			chartData = buf.Bytes()
 			chartName = version.Name
 		} else {
@@ -65,6 +70,10 @@ func (r *ReconcileHelmRelease) GetChartData(rls *v1alpha1.HelmRelease) (chartNam
 			klog.Errorf("get app version %s failed, error: %v", rls.Spec.ApplicationVersionId, err)
 			return chartName, chartData, ErrGetAppVersionFailed
 		}
+		if err := helmrepoindex.CheckVerification(appVersion.Status.Verification, false); err != nil {
+			klog.Errorf("app version %s can not be deployed, error: %s", rls.Spec.ApplicationVersionId, err)
+			return chartName, chartData, err
+		}
 
 		if r.StorageClient == nil {
 			return "", nil, ErrS3Config
@@ -74,6 +83,13 @@ func (r *ReconcileHelmRelease) GetChartData(rls *v1alpha1.HelmRelease) (chartNam
 			klog.Errorf("load chart from storage failed, error: %s", err)
 			return chartName, chartData, ErrLoadChartFromStorageFailed
 		}
+		// the packages uploaded before their digests were recorded are kept in the storage of kubesphere
+		if verification := appVersion.Status.Verification; verification != nil && verification.Digest != "" {
+			if err := helmrepoindex.CheckChartDigest(verification, chartData); err != nil {
+				klog.Errorf("app version %s can not be deployed, error: %s", rls.Spec.ApplicationVersionId, err)
+				return chartName, nil, err
+			}
+		}
 
 		chartName = appVersion.GetTrueName()
 	}
@@ -19,6 +19,9 @@ package helmrelease
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -26,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -76,6 +80,8 @@ type ReconcileHelmRelease struct {
 	MaxConcurrent int
 	// wait time when check release is ready or not
 	WaitTime time.Duration
+	// interval of checking whether the resources of active releases are drifted, 0 means never check
+	DriftCheckInterval time.Duration
 
 	StopChan <-chan struct{}
 }
@@ -185,6 +191,9 @@ func (r *ReconcileHelmRelease) reconcile(instance *v1alpha1.HelmRelease) (reconc
 	case v1alpha1.HelmStatusFailed:
 		// Release used to be failed, but instance.Status.Version not equal to instance.Spec.Version
 		if instance.Status.Version != instance.Spec.Version {
+			if instance.Spec.RollbackRevision > 0 {
+				return r.rollbackHelmRelease(instance)
+			}
 			return r.createOrUpgradeHelmRelease(instance, true)
 		} else {
 			return reconcile.Result{}, nil
@@ -192,12 +201,12 @@ func (r *ReconcileHelmRelease) reconcile(instance *v1alpha1.HelmRelease) (reconc
 	case v1alpha1.HelmStatusActive:
 		// Release used to be active, but instance.Status.Version not equal to instance.Spec.Version
 		if instance.Status.Version != instance.Spec.Version {
-			instance.Status.State = v1alpha1.HelmStatusUpgrading
+			instance.Status.State = nextState(instance)
 			// Update the state first.
 			err = r.Status().Update(context.TODO(), instance)
 			return reconcile.Result{}, err
 		} else {
-			return reconcile.Result{}, nil
+			return r.checkDrift(instance)
 		}
 	case v1alpha1.HelmStatusCreating:
 		// create new release
@@ -210,7 +219,7 @@ func (r *ReconcileHelmRelease) reconcile(instance *v1alpha1.HelmRelease) (reconc
 			// Start a new backoff.
 			r.checkReleaseStatusBackoff.DeleteEntry(rlsBackoffKey(instance))
 
-			instance.Status.State = v1alpha1.HelmStatusUpgrading
+			instance.Status.State = nextState(instance)
 			err = r.Status().Update(context.TODO(), instance)
 			return reconcile.Result{}, err
 		} else {
@@ -218,13 +227,98 @@ func (r *ReconcileHelmRelease) reconcile(instance *v1alpha1.HelmRelease) (reconc
 			return reconcile.Result{RequeueAfter: retry}, err
 		}
 	case v1alpha1.HelmStatusRollbacking:
-		// TODO: rollback helm release
-		return reconcile.Result{}, nil
+		return r.rollbackHelmRelease(instance)
 	}
 
 	return reconcile.Result{}, nil
 }
 
+// nextState returns the state of the release whose spec has been changed,
+// the release is rolled back if a revision is specified, otherwise it's upgraded.
+func nextState(rls *v1alpha1.HelmRelease) string {
+	if rls.Spec.RollbackRevision > 0 {
+		return v1alpha1.HelmStatusRollbacking
+	}
+	return v1alpha1.HelmStatusUpgrading
+}
+
+// checkDrift compares the resources of the active release with the live objects periodically, the drifted resources
+// are recorded in the Drifted condition, and the release is upgraded again to re-apply the manifest if spec.reconcileDrift is set.
+func (r *ReconcileHelmRelease) checkDrift(rls *v1alpha1.HelmRelease) (reconcile.Result, error) {
+	if r.DriftCheckInterval <= 0 {
+		return reconcile.Result{}, nil
+	}
+
+	clusterName := rls.GetRlsCluster()
+
+	var clusterConfig string
+	var err error
+	if r.MultiClusterEnable && clusterName != "" {
+		clusterConfig, err = r.clusterClients.GetClusterKubeconfig(clusterName)
+		if err != nil {
+			klog.Errorf("get cluster %s config failed", clusterConfig)
+			return reconcile.Result{}, err
+		}
+	}
+
+	hw := helmwrapper.NewHelmWrapper(clusterConfig, rls.GetRlsNamespace(), rls.Spec.Name, helmwrapper.SetMock(r.helmMock))
+	drifted, err := hw.Drift()
+	if err != nil {
+		klog.Errorf("check drift of release %s/%s failed, error: %s", rls.GetRlsNamespace(), rls.GetTrueName(), err)
+		return reconcile.Result{RequeueAfter: r.DriftCheckInterval}, nil
+	}
+
+	condition := metav1.Condition{
+		Type:               v1alpha1.HelmReleaseConditionDrifted,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoDrift",
+		ObservedGeneration: rls.Generation,
+	}
+	if len(drifted) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ResourcesDrifted"
+		condition.Message = driftMessage(drifted)
+	}
+
+	old := meta.FindStatusCondition(rls.Status.Conditions, condition.Type)
+	changed := old == nil || old.Status != condition.Status || old.Message != condition.Message ||
+		old.ObservedGeneration != condition.ObservedGeneration ||
+		!reflect.DeepEqual(rls.Status.DriftedResources, drifted)
+	meta.SetStatusCondition(&rls.Status.Conditions, condition)
+	rls.Status.DriftedResources = drifted
+
+	if len(drifted) > 0 && rls.Spec.ReconcileDrift {
+		klog.V(2).Infof("release %s/%s drifted, upgrade it again", rls.GetRlsNamespace(), rls.GetTrueName())
+		r.checkReleaseStatusBackoff.DeleteEntry(rlsBackoffKey(rls))
+		return reconcile.Result{}, r.updateStatus(rls, v1alpha1.HelmStatusUpgrading, condition.Message)
+	}
+
+	if changed {
+		rls.Status.LastUpdate = metav1.Now()
+		if err = r.Status().Update(context.TODO(), rls); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{RequeueAfter: r.DriftCheckInterval}, nil
+}
+
+// driftMessage lists the drifted resources, e.g. "Deployment default/nginx Modified: spec.replicas"
+func driftMessage(drifted []v1alpha1.DriftedResource) string {
+	items := make([]string, 0, len(drifted))
+	for _, d := range drifted {
+		name := d.Name
+		if d.Namespace != "" {
+			name = d.Namespace + "/" + d.Name
+		}
+		item := fmt.Sprintf("%s %s %s", d.Kind, name, d.Reason)
+		if d.Field != "" {
+			item += ": " + d.Field
+		}
+		items = append(items, item)
+	}
+	return strings.Join(items, "; ")
+}
+
 func rlsBackoffKey(rls *v1alpha1.HelmRelease) string {
 	return rls.Name
 }
@@ -375,6 +469,34 @@ func (r *ReconcileHelmRelease) createOrUpgradeHelmRelease(rls *v1alpha1.HelmRele
 	return reconcile.Result{}, err
 }
 
+// rollbackHelmRelease runs helm rollback to roll back the release to spec.rollbackRevision
+func (r *ReconcileHelmRelease) rollbackHelmRelease(rls *v1alpha1.HelmRelease) (reconcile.Result, error) {
+	clusterName := rls.GetRlsCluster()
+
+	var clusterConfig string
+	var err error
+	if r.MultiClusterEnable && clusterName != "" {
+		clusterConfig, err = r.clusterClients.GetClusterKubeconfig(clusterName)
+		if err != nil {
+			klog.Errorf("get cluster %s config failed", clusterConfig)
+			return reconcile.Result{}, err
+		}
+	}
+
+	hw := helmwrapper.NewHelmWrapper(clusterConfig, rls.GetRlsNamespace(), rls.Spec.Name, helmwrapper.SetMock(r.helmMock))
+
+	// the rolled back release is checked like an upgraded one
+	currentState := v1alpha1.HelmStatusUpgraded
+	var msg string
+	if err = hw.Rollback(rls.Spec.RollbackRevision); err != nil {
+		currentState = v1alpha1.HelmStatusFailed
+		msg = err.Error()
+	}
+	err = r.updateStatus(rls, currentState, msg)
+
+	return reconcile.Result{}, err
+}
+
 func (r *ReconcileHelmRelease) uninstallHelmRelease(rls *v1alpha1.HelmRelease) error {
 
 	if rls.Status.State != v1alpha1.HelmStatusDeleting {
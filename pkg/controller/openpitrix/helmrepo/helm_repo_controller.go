@@ -53,6 +53,12 @@ const (
 	StateSuccess = "successful"
 	StateFailed  = "failed"
 	MessageLen   = 512
+
+	// the charts are verified in batches, so that the reconciliation is not blocked by downloading all of them
+	chartVerificationBatch   = 20
+	chartVerificationTimeout = 2 * time.Minute
+	// seconds to wait before verifying the next batch
+	chartVerificationRetry = 10
 )
 
 const (
@@ -198,6 +204,16 @@ func (r *ReconcileHelmRepo) Reconcile(ctx context.Context, request reconcile.Req
 		retryAfter = after
 	}
 
+	pending, err := r.verifyCharts(copyInstance)
+	if err != nil {
+		return reconcile.Result{
+			RequeueAfter: MinRetryDuration * time.Second,
+		}, err
+	}
+	if pending && (retryAfter == 0 || retryAfter > chartVerificationRetry) {
+		retryAfter = chartVerificationRetry
+	}
+
 	return reconcile.Result{
 		RequeueAfter: time.Duration(retryAfter) * time.Second,
 	}, nil
@@ -288,7 +304,7 @@ func (r *ReconcileHelmRepo) syncRepo(instance *v1alpha1.HelmRepo) error {
 	// 2. merge new index with old index which is stored in crd
 	savedIndex := helmrepoindex.MergeRepoIndex(instance, index, existsSavedIndex)
 
-	// 3. save index in crd
+	// 3. save index in crd, the signatures of the new charts are verified later
 	data, err := savedIndex.Bytes()
 	if err != nil {
 		klog.Errorf("json marshal failed, error: %s", err)
@@ -298,3 +314,35 @@ func (r *ReconcileHelmRepo) syncRepo(instance *v1alpha1.HelmRepo) error {
 	instance.Status.Data = string(data)
 	return nil
 }
+
+// verifyCharts verifies a batch of the charts in the index of the repo with a deadline, and saves the status of
+// them. It returns whether there are charts left to verify.
+func (r *ReconcileHelmRepo) verifyCharts(instance *v1alpha1.HelmRepo) (bool, error) {
+	if len(instance.Status.Data) == 0 {
+		return false, nil
+	}
+	savedIndex, err := helmrepoindex.ByteArrayToSavedIndex([]byte(instance.Status.Data))
+	if err != nil {
+		klog.Errorf("json unmarshal failed, repo: %s,  error: %s", instance.GetTrueName(), err)
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), chartVerificationTimeout)
+	defer cancel()
+	changed, pending := savedIndex.VerifyCharts(ctx, instance, chartVerificationBatch)
+	if !changed {
+		return pending, nil
+	}
+
+	data, err := savedIndex.Bytes()
+	if err != nil {
+		klog.Errorf("json marshal failed, error: %s", err)
+		return false, err
+	}
+	instance.Status.Data = string(data)
+	if err = r.Client.Status().Update(context.TODO(), instance); err != nil {
+		klog.Errorf("update status failed, error: %s", err)
+		return false, err
+	}
+	return pending, nil
+}
@@ -18,8 +18,10 @@ package user
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -252,6 +254,10 @@ func (r *Reconciler) encryptPassword(ctx context.Context, user *iamv1alpha2.User
 			user.Annotations = make(map[string]string)
 		}
 		user.Annotations[iamv1alpha2.LastPasswordChangeTimeAnnotation] = time.Now().UTC().Format(time.RFC3339)
+		if err = r.recordPasswordHistory(user); err != nil {
+			klog.Error(err)
+			return err
+		}
 		// ensure plain text password won't be kept anywhere
 		delete(user.Annotations, corev1.LastAppliedConfigAnnotation)
 		err = r.Update(ctx, user, &client.UpdateOptions{})
@@ -452,10 +458,52 @@ func (r *Reconciler) deleteLoginRecords(ctx context.Context, user *iamv1alpha2.U
 	return r.Client.DeleteAllOf(ctx, loginRecord, client.MatchingLabels{iamv1alpha2.UserReferenceLabel: user.Name})
 }
 
+// recordPasswordHistory keeps the recent encrypted passwords to prevent them from being reused,
+// the current password is included.
+func (r *Reconciler) recordPasswordHistory(user *iamv1alpha2.User) error {
+	policy := r.passwordPolicy()
+	if policy == nil || policy.HistorySize <= 0 {
+		delete(user.Annotations, iamv1alpha2.PasswordHistoryAnnotation)
+		return nil
+	}
+	history, err := PasswordHistory(user)
+	if err != nil {
+		return err
+	}
+	history = append([]string{user.Spec.EncryptedPassword}, history...)
+	if len(history) > policy.HistorySize {
+		history = history[:policy.HistorySize]
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	user.Annotations[iamv1alpha2.PasswordHistoryAnnotation] = string(data)
+	return nil
+}
+
+func (r *Reconciler) passwordPolicy() *authentication.PasswordPolicy {
+	if r.AuthenticationOptions == nil {
+		return nil
+	}
+	return r.AuthenticationOptions.PasswordPolicy
+}
+
+// PasswordHistory returns the recent encrypted passwords of the user, the latest comes first.
+func PasswordHistory(user *iamv1alpha2.User) ([]string, error) {
+	var history []string
+	if data := user.Annotations[iamv1alpha2.PasswordHistoryAnnotation]; data != "" {
+		if err := json.Unmarshal([]byte(data), &history); err != nil {
+			return nil, fmt.Errorf("invalid password history: %v", err)
+		}
+	}
+	return history, nil
+}
+
 // syncUserStatus Update the user status
 func (r *Reconciler) syncUserStatus(ctx context.Context, user *iamv1alpha2.User) error {
-	// skip status sync if the user is disabled
-	if user.Status.State == iamv1alpha2.UserDisabled {
+	// skip status sync if the user is disabled or locked
+	if user.Status.State == iamv1alpha2.UserDisabled || user.Status.State == iamv1alpha2.UserLocked {
 		return nil
 	}
 
@@ -526,6 +574,18 @@ func (r *Reconciler) syncUserStatus(ctx context.Context, user *iamv1alpha2.User)
 		return err
 	}
 
+	// lock user if the consecutive failed login attempts reach the lockout threshold
+	if policy := r.passwordPolicy(); policy != nil && policy.LockoutThreshold > 0 {
+		if failed := consecutiveFailedLoginAttempts(user, records.Items); failed >= policy.LockoutThreshold {
+			user.Status = iamv1alpha2.UserStatus{
+				State:              iamv1alpha2.UserLocked,
+				Reason:             fmt.Sprintf("%d consecutive failed login attempts", failed),
+				LastTransitionTime: &metav1.Time{Time: time.Now()},
+			}
+			return r.Update(ctx, user, &client.UpdateOptions{})
+		}
+	}
+
 	// count failed login attempts during last AuthenticateRateLimiterDuration
 	now := time.Now()
 	failedLoginAttempts := 0
@@ -555,6 +615,25 @@ func (r *Reconciler) syncUserStatus(ctx context.Context, user *iamv1alpha2.User)
 	return nil
 }
 
+// consecutiveFailedLoginAttempts counts the failed login attempts since the last successful login,
+// the attempts before the last state transition are ignored, e.g. unlocked by administrators.
+func consecutiveFailedLoginAttempts(user *iamv1alpha2.User, records []iamv1alpha2.LoginRecord) int {
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreationTimestamp.After(records[j].CreationTimestamp.Time)
+	})
+	failed := 0
+	for _, loginRecord := range records {
+		if user.Status.LastTransitionTime != nil && !loginRecord.CreationTimestamp.After(user.Status.LastTransitionTime.Time) {
+			break
+		}
+		if loginRecord.Spec.Success {
+			break
+		}
+		failed++
+	}
+	return failed
+}
+
 func encrypt(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
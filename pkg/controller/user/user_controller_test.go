@@ -148,3 +148,47 @@ func TestDoNothing(t *testing.T) {
 	user = updateEvent.Object.(*iamv1alpha2.User)
 	assert.Equal(t, iamv1alpha2.UserActive, user.Status.State)
 }
+
+func TestConsecutiveFailedLoginAttempts(t *testing.T) {
+	now := time.Now()
+	newRecord := func(ago time.Duration, success bool) iamv1alpha2.LoginRecord {
+		return iamv1alpha2.LoginRecord{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Time{Time: now.Add(-ago)}},
+			Spec:       iamv1alpha2.LoginRecordSpec{Success: success},
+		}
+	}
+	user := newUser("admin")
+	records := []iamv1alpha2.LoginRecord{
+		newRecord(time.Minute, false),
+		newRecord(5*time.Minute, true),
+		newRecord(2*time.Minute, false),
+		newRecord(3*time.Minute, false),
+		newRecord(10*time.Minute, false),
+	}
+	assert.Equal(t, 3, consecutiveFailedLoginAttempts(user, records))
+
+	// the records before the last transition are ignored
+	user.Status.LastTransitionTime = &metav1.Time{Time: now.Add(-150 * time.Second)}
+	assert.Equal(t, 2, consecutiveFailedLoginAttempts(user, records))
+}
+
+func TestPasswordReused(t *testing.T) {
+	var history []string
+	for _, password := range []string{"P@88w0rd3", "P@88w0rd2", "P@88w0rd1"} {
+		encrypted, err := encrypt(password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		history = append(history, encrypted)
+	}
+	user := newUser("admin")
+	user.Spec.EncryptedPassword = history[0]
+	user.Annotations = map[string]string{
+		iamv1alpha2.PasswordHistoryAnnotation: fmt.Sprintf(`["%s","%s","%s"]`, history[0], history[1], history[2]),
+	}
+	assert.True(t, passwordReused(user, "P@88w0rd3", 1))
+	assert.False(t, passwordReused(user, "P@88w0rd2", 1))
+	assert.True(t, passwordReused(user, "P@88w0rd2", 3))
+	assert.True(t, passwordReused(user, "P@88w0rd1", 3))
+	assert.False(t, passwordReused(user, "P@88w0rd4", 3))
+}
@@ -22,10 +22,14 @@ import (
 	"net/http"
 	"net/mail"
 
+	"golang.org/x/crypto/bcrypt"
+	admissionv1 "k8s.io/api/admission/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"kubesphere.io/api/iam/v1alpha2"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication"
 )
 
 type EmailValidator struct {
@@ -75,3 +79,63 @@ func (a *EmailValidator) InjectDecoder(d *admission.Decoder) error {
 	a.decoder = d
 	return nil
 }
+
+// PasswordValidator validates the plain text password against the password policy,
+// the password will be encrypted by the user controller after admitted.
+type PasswordValidator struct {
+	PasswordPolicy *authentication.PasswordPolicy
+	decoder        *admission.Decoder
+}
+
+func (a *PasswordValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	user := &v1alpha2.User{}
+	err := a.decoder.Decode(req, user)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// the password is not changed
+	if a.PasswordPolicy == nil || user.Spec.EncryptedPassword == "" || isEncrypted(user.Spec.EncryptedPassword) {
+		return admission.Allowed("")
+	}
+
+	if err = a.PasswordPolicy.Validate(user.Spec.EncryptedPassword); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	if req.Operation == admissionv1.Update && a.PasswordPolicy.HistorySize > 0 {
+		old := &v1alpha2.User{}
+		if err = a.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if passwordReused(old, user.Spec.EncryptedPassword, a.PasswordPolicy.HistorySize) {
+			return admission.Denied(fmt.Sprintf("password must not be the same as the last %d passwords", a.PasswordPolicy.HistorySize))
+		}
+	}
+
+	return admission.Allowed("")
+}
+
+// passwordReused returns whether the password is one of the recent passwords of the user.
+func passwordReused(user *v1alpha2.User, password string, historySize int) bool {
+	// the history may be absent or outdated, the current password is always checked
+	history, _ := PasswordHistory(user)
+	if len(history) == 0 || history[0] != user.Spec.EncryptedPassword {
+		history = append([]string{user.Spec.EncryptedPassword}, history...)
+	}
+	if len(history) > historySize {
+		history = history[:historySize]
+	}
+	for _, encrypted := range history {
+		if encrypted != "" && bcrypt.CompareHashAndPassword([]byte(encrypted), []byte(password)) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// InjectDecoder injects the decoder.
+func (a *PasswordValidator) InjectDecoder(d *admission.Decoder) error {
+	a.decoder = d
+	return nil
+}
@@ -17,11 +17,14 @@ limitations under the License.
 package v1alpha2
 
 import (
+	"net/http"
+
 	"github.com/emicklei/go-restful/v3"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"kubesphere.io/kubesphere/pkg/simple/client/gpu"
 
+	"kubesphere.io/kubesphere/pkg/api"
 	kubesphereconfig "kubesphere.io/kubesphere/pkg/apiserver/config"
 	"kubesphere.io/kubesphere/pkg/apiserver/runtime"
 )
@@ -32,7 +35,7 @@ const (
 
 var GroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1alpha2"}
 
-func AddToContainer(c *restful.Container, config *kubesphereconfig.Config) error {
+func AddToContainer(c *restful.Container, config *kubesphereconfig.Config, revisions *kubesphereconfig.RevisionTracker) error {
 	webservice := runtime.NewWebService(GroupVersion)
 
 	webservice.Route(webservice.GET("/configs/oauth").
@@ -47,6 +50,13 @@ func AddToContainer(c *restful.Container, config *kubesphereconfig.Config) error
 			response.WriteAsJson(config.ToMap())
 		}))
 
+	webservice.Route(webservice.GET("/configs/revision").
+		Doc("Information about the revision of the server configuration in use and the latest one rejected").
+		Returns(http.StatusOK, api.StatusOK, kubesphereconfig.RevisionStatus{}).
+		To(func(request *restful.Request, response *restful.Response) {
+			response.WriteEntity(revisions.Status())
+		}))
+
 	webservice.Route(webservice.GET("/configs/gpu/kinds").
 		Doc("Get all supported GPU kinds.").
 		To(func(request *restful.Request, response *restful.Response) {
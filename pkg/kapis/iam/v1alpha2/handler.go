@@ -22,6 +22,7 @@ import (
 
 	authuser "k8s.io/apiserver/pkg/authentication/user"
 
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
 	"kubesphere.io/kubesphere/pkg/apiserver/request"
 	"kubesphere.io/kubesphere/pkg/models/auth"
 
@@ -58,18 +59,20 @@ type PasswordReset struct {
 }
 
 type iamHandler struct {
-	am         am.AccessManagementInterface
-	im         im.IdentityManagementInterface
-	group      group.GroupOperator
-	authorizer authorizer.Authorizer
+	am                  am.AccessManagementInterface
+	im                  im.IdentityManagementInterface
+	group               group.GroupOperator
+	personalAccessToken auth.PersonalAccessTokenOperator
+	authorizer          authorizer.Authorizer
 }
 
-func newIAMHandler(im im.IdentityManagementInterface, am am.AccessManagementInterface, group group.GroupOperator, authorizer authorizer.Authorizer) *iamHandler {
+func newIAMHandler(im im.IdentityManagementInterface, am am.AccessManagementInterface, group group.GroupOperator, personalAccessToken auth.PersonalAccessTokenOperator, authorizer authorizer.Authorizer) *iamHandler {
 	return &iamHandler{
-		am:         am,
-		im:         im,
-		group:      group,
-		authorizer: authorizer,
+		am:                  am,
+		im:                  im,
+		group:               group,
+		personalAccessToken: personalAccessToken,
+		authorizer:          authorizer,
 	}
 }
 
@@ -633,6 +636,100 @@ func (h *iamHandler) ModifyPassword(request *restful.Request, response *restful.
 	response.WriteEntity(servererr.None)
 }
 
+// requireOwner writes a forbidden error and returns false unless the current user is the owner of the tokens,
+// the personal access tokens of a user are not managed by the others, even the user managers.
+func requireOwner(request *restful.Request, response *restful.Response, username string) bool {
+	operator, ok := apirequest.UserFrom(request.Request.Context())
+	if !ok {
+		err := errors.NewInternalError(fmt.Errorf("cannot obtain user info"))
+		api.HandleInternalError(response, request, err)
+		return false
+	}
+	if operator.GetName() != username {
+		api.HandleForbidden(response, request, fmt.Errorf("personal access tokens of user %s are only accessible to the user", username))
+		return false
+	}
+	return true
+}
+
+func (h *iamHandler) ListPersonalAccessTokens(request *restful.Request, response *restful.Response) {
+	username := request.PathParameter("user")
+	if !requireOwner(request, response, username) {
+		return
+	}
+
+	tokens, err := h.personalAccessToken.List(username)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+
+	items := make([]interface{}, 0, len(tokens))
+	for _, item := range tokens {
+		items = append(items, item)
+	}
+	response.WriteEntity(api.ListResult{Items: items, TotalItems: len(items)})
+}
+
+func (h *iamHandler) CreatePersonalAccessToken(request *restful.Request, response *restful.Response) {
+	username := request.PathParameter("user")
+	var tokenRequest auth.PersonalAccessTokenRequest
+	if err := request.ReadEntity(&tokenRequest); err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+
+	if !requireOwner(request, response, username) {
+		return
+	}
+	operator, _ := apirequest.UserFrom(request.Request.Context())
+
+	// personal access tokens are not allowed to issue new tokens, otherwise the scopes can be escalated
+	if len(operator.GetExtra()[token.ExtraTokenType]) > 0 {
+		api.HandleForbidden(response, request, fmt.Errorf("personal access token is not allowed to create tokens"))
+		return
+	}
+
+	created, err := h.personalAccessToken.Create(username, &tokenRequest)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+
+	response.WriteEntity(created)
+}
+
+func (h *iamHandler) DescribePersonalAccessToken(request *restful.Request, response *restful.Response) {
+	username := request.PathParameter("user")
+	id := request.PathParameter("personalaccesstoken")
+	if !requireOwner(request, response, username) {
+		return
+	}
+
+	pat, err := h.personalAccessToken.Get(username, id)
+	if err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+
+	response.WriteEntity(pat)
+}
+
+func (h *iamHandler) RevokePersonalAccessToken(request *restful.Request, response *restful.Response) {
+	username := request.PathParameter("user")
+	id := request.PathParameter("personalaccesstoken")
+	if !requireOwner(request, response, username) {
+		return
+	}
+
+	if err := h.personalAccessToken.Revoke(username, id); err != nil {
+		api.HandleError(response, request, err)
+		return
+	}
+
+	response.WriteEntity(servererr.None)
+}
+
 func (h *iamHandler) DeleteUser(request *restful.Request, response *restful.Response) {
 	username := request.PathParameter("user")
 
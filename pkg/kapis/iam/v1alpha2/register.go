@@ -32,6 +32,7 @@ import (
 	"kubesphere.io/kubesphere/pkg/api"
 	"kubesphere.io/kubesphere/pkg/apiserver/runtime"
 	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/models/auth"
 	"kubesphere.io/kubesphere/pkg/models/iam/am"
 	"kubesphere.io/kubesphere/pkg/models/iam/group"
 	"kubesphere.io/kubesphere/pkg/models/iam/im"
@@ -44,9 +45,9 @@ const (
 
 var GroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1alpha2"}
 
-func AddToContainer(container *restful.Container, im im.IdentityManagementInterface, am am.AccessManagementInterface, group group.GroupOperator, authorizer authorizer.Authorizer) error {
+func AddToContainer(container *restful.Container, im im.IdentityManagementInterface, am am.AccessManagementInterface, group group.GroupOperator, personalAccessToken auth.PersonalAccessTokenOperator, authorizer authorizer.Authorizer) error {
 	ws := runtime.NewWebService(GroupVersion)
-	handler := newIAMHandler(im, am, group, authorizer)
+	handler := newIAMHandler(im, am, group, personalAccessToken, authorizer)
 
 	// users
 	ws.Route(ws.POST("/users").
@@ -94,6 +95,35 @@ func AddToContainer(container *restful.Container, im im.IdentityManagementInterf
 		Returns(http.StatusOK, api.StatusOK, api.ListResult{Items: []interface{}{iamv1alpha2.LoginRecord{}}}).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.UserResourceTag}))
 
+	// personal access tokens
+	ws.Route(ws.GET("/users/{user}/personalaccesstokens").
+		To(handler.ListPersonalAccessTokens).
+		Doc("List personal access tokens of the specified user.").
+		Param(ws.PathParameter("user", "username")).
+		Returns(http.StatusOK, api.StatusOK, api.ListResult{Items: []interface{}{auth.PersonalAccessToken{}}}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.UserResourceTag}))
+	ws.Route(ws.POST("/users/{user}/personalaccesstokens").
+		To(handler.CreatePersonalAccessToken).
+		Doc("Create a personal access token for the specified user, the token is only returned in the response.").
+		Param(ws.PathParameter("user", "username")).
+		Reads(auth.PersonalAccessTokenRequest{}).
+		Returns(http.StatusOK, api.StatusOK, auth.PersonalAccessToken{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.UserResourceTag}))
+	ws.Route(ws.GET("/users/{user}/personalaccesstokens/{personalaccesstoken}").
+		To(handler.DescribePersonalAccessToken).
+		Doc("Retrieve the specified personal access token.").
+		Param(ws.PathParameter("user", "username")).
+		Param(ws.PathParameter("personalaccesstoken", "id of the personal access token")).
+		Returns(http.StatusOK, api.StatusOK, auth.PersonalAccessToken{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.UserResourceTag}))
+	ws.Route(ws.DELETE("/users/{user}/personalaccesstokens/{personalaccesstoken}").
+		To(handler.RevokePersonalAccessToken).
+		Doc("Revoke the specified personal access token.").
+		Param(ws.PathParameter("user", "username")).
+		Param(ws.PathParameter("personalaccesstoken", "id of the personal access token")).
+		Returns(http.StatusOK, api.StatusOK, errors.None).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.UserResourceTag}))
+
 	// clustermembers
 	ws.Route(ws.POST("/clustermembers").
 		To(handler.CreateClusterMembers).
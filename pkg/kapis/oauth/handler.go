@@ -35,6 +35,7 @@ import (
 
 	"kubesphere.io/kubesphere/pkg/api"
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication"
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider"
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
 	"kubesphere.io/kubesphere/pkg/apiserver/query"
@@ -199,7 +200,7 @@ func (h *handler) discovery(req *restful.Request, response *restful.Response) {
 
 func (h *handler) keys(req *restful.Request, response *restful.Response) {
 	jwks := jose.JSONWebKeySet{
-		Keys: []jose.JSONWebKey{*h.tokenOperator.Keys().SigningKeyPub},
+		Keys: h.tokenOperator.Keys().PublicKeys,
 	}
 	response.WriteEntity(jwks)
 }
@@ -318,7 +319,7 @@ func (h *handler) authorize(req *restful.Request, response *restful.Response) {
 		return
 	}
 
-	result, err := h.issueTokenTo(authenticated)
+	result, err := h.issueTokenTo(authenticated, clientID)
 	if err != nil {
 		response.WriteHeaderAndEntity(http.StatusInternalServerError, oauth.NewServerError(err))
 		return
@@ -341,7 +342,7 @@ func (h *handler) oauthCallback(req *restful.Request, response *restful.Response
 		return
 	}
 
-	result, err := h.issueTokenTo(authenticated)
+	result, err := h.issueTokenTo(authenticated, "")
 	if err != nil {
 		response.WriteHeaderAndEntity(http.StatusInternalServerError, oauth.NewServerError(err))
 		return
@@ -421,7 +422,7 @@ func (h *handler) passwordGrant(provider, username string, password string, req
 	authenticated, provider, err := h.passwordAuthenticator.Authenticate(req.Request.Context(), provider, username, password)
 	if err != nil {
 		switch err {
-		case auth.AccountIsNotActiveError:
+		case auth.AccountIsNotActiveError, auth.AccountIsLockedError:
 			response.WriteHeaderAndEntity(http.StatusBadRequest, oauth.NewInvalidGrant(err))
 			return
 		case auth.IncorrectPasswordError:
@@ -440,7 +441,9 @@ func (h *handler) passwordGrant(provider, username string, password string, req
 		}
 	}
 
-	result, err := h.issueTokenTo(authenticated)
+	// the client is given if the user is authenticated by the token endpoint
+	clientID, _ := req.BodyParameter("client_id")
+	result, err := h.issueTokenTo(authenticated, clientID)
 	if err != nil {
 		response.WriteHeaderAndEntity(http.StatusInternalServerError, oauth.NewServerError(err))
 		return
@@ -454,10 +457,15 @@ func (h *handler) passwordGrant(provider, username string, password string, req
 	response.WriteEntity(result)
 }
 
-func (h *handler) issueTokenTo(user user.Info) (*oauth.Token, error) {
+// issueTokenTo issues the tokens to the user through the OAuth client, the client is the audience of the tokens if it's given.
+func (h *handler) issueTokenTo(user user.Info, clientID string) (*oauth.Token, error) {
+	var audience jwt.ClaimStrings
+	if clientID != "" {
+		audience = jwt.ClaimStrings{clientID}
+	}
 	accessToken, err := h.tokenOperator.IssueTo(&token.IssueRequest{
 		User:      user,
-		Claims:    token.Claims{TokenType: token.AccessToken},
+		Claims:    token.Claims{TokenType: token.AccessToken, RegisteredClaims: jwt.RegisteredClaims{Audience: audience}},
 		ExpiresIn: h.options.OAuthOptions.AccessTokenMaxAge,
 	})
 	if err != nil {
@@ -465,7 +473,7 @@ func (h *handler) issueTokenTo(user user.Info) (*oauth.Token, error) {
 	}
 	refreshToken, err := h.tokenOperator.IssueTo(&token.IssueRequest{
 		User:      user,
-		Claims:    token.Claims{TokenType: token.RefreshToken},
+		Claims:    token.Claims{TokenType: token.RefreshToken, RegisteredClaims: jwt.RegisteredClaims{Audience: audience}},
 		ExpiresIn: h.options.OAuthOptions.AccessTokenMaxAge + h.options.OAuthOptions.AccessTokenInactivityTimeout,
 	})
 	if err != nil {
@@ -528,7 +536,31 @@ func (h *handler) refreshTokenGrant(req *restful.Request, response *restful.Resp
 		authenticated = &user.DefaultInfo{Name: result.Items[0].(*iamv1alpha2.User).Name}
 	}
 
-	result, err := h.issueTokenTo(authenticated)
+	// the expired password is not carried to the new tokens once the password is changed
+	if len(authenticated.GetExtra()[iamv1alpha2.ExtraPasswordExpired]) > 0 && verified.IssuedAt != nil {
+		userInfo, err := h.im.DescribeUser(authenticated.GetName())
+		if err != nil {
+			response.WriteHeaderAndEntity(http.StatusInternalServerError, oauth.NewServerError(err))
+			return
+		}
+		if auth.PasswordChangedSince(userInfo, verified.IssuedAt.Time) {
+			extra := make(map[string][]string)
+			for key, value := range authenticated.GetExtra() {
+				if key != iamv1alpha2.ExtraPasswordExpired {
+					extra[key] = value
+				}
+			}
+			authenticated = &user.DefaultInfo{
+				Name:   authenticated.GetName(),
+				UID:    authenticated.GetUID(),
+				Groups: authenticated.GetGroups(),
+				Extra:  extra,
+			}
+		}
+	}
+
+	clientID, _ := req.BodyParameter("client_id")
+	result, err := h.issueTokenTo(authenticated, clientID)
 	if err != nil {
 		response.WriteHeaderAndEntity(http.StatusInternalServerError, oauth.NewServerError(err))
 		return
@@ -564,7 +596,8 @@ func (h *handler) codeGrant(req *restful.Request, response *restful.Response) {
 		}
 	}()
 
-	result, err := h.issueTokenTo(authorizeContext.User)
+	clientID, _ := req.BodyParameter("client_id")
+	result, err := h.issueTokenTo(authorizeContext.User, clientID)
 	if err != nil {
 		response.WriteHeaderAndEntity(http.StatusInternalServerError, oauth.NewServerError(err))
 		return
@@ -680,3 +713,27 @@ func (h *handler) loginByIdentityProvider(req *restful.Request, response *restfu
 
 	h.passwordGrant(idp, username, password, req, response)
 }
+
+// identityProviderMetadata returns the metadata of KubeSphere for the identity provider,
+// e.g. the SAML 2.0 service provider metadata which should be imported by the remote server.
+func (h *handler) identityProviderMetadata(req *restful.Request, response *restful.Response) {
+	idp := req.PathParameter("identityprovider")
+	provider, err := identityprovider.GetOAuthProvider(idp)
+	if err != nil {
+		api.HandleNotFound(response, req, err)
+		return
+	}
+	metadataProvider, ok := provider.(identityprovider.MetadataProvider)
+	if !ok {
+		api.HandleNotFound(response, req, fmt.Errorf("identity provider %s does not publish metadata", idp))
+		return
+	}
+	metadata, err := metadataProvider.Metadata()
+	if err != nil {
+		api.HandleInternalError(response, req, err)
+		return
+	}
+	response.Header().Set(restful.HEADER_ContentType, contentTypeXML)
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(metadata)
+}
@@ -31,7 +31,10 @@ import (
 	"kubesphere.io/kubesphere/pkg/models/iam/im"
 )
 
-const contentTypeFormData = "application/x-www-form-urlencoded"
+const (
+	contentTypeFormData = "application/x-www-form-urlencoded"
+	contentTypeXML      = "application/xml"
+)
 
 // AddToContainer ks-apiserver includes a built-in OAuth server. Users obtain OAuth access tokens to authenticate themselves to the API.
 // The OAuth server supports standard authorization code grant and the implicit grant OAuth authorization flows.
@@ -141,6 +144,27 @@ func AddToContainer(c *restful.Container, im im.IdentityManagementInterface,
 		Returns(http.StatusOK, api.StatusOK, oauth.Token{}).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AuthenticationTag}))
 
+	// The identity providers which support the HTTP-POST binding (e.g. SAML 2.0) post
+	// the authentication response to the callback URL.
+	ws.Route(ws.POST("/callback/{callback}").
+		Consumes(contentTypeFormData).
+		Doc("OAuth callback API for the identity providers which post the authentication response, "+
+			"the path param callback is config by identity provider").
+		Param(ws.FormParameter("SAMLResponse", "The base64 encoded SAML response.").Required(false)).
+		Param(ws.FormParameter("RelayState", "The exact value received from the client.").Required(false)).
+		To(handler.oauthCallback).
+		Returns(http.StatusOK, api.StatusOK, oauth.Token{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AuthenticationTag}))
+
+	// Metadata of KubeSphere for the identity provider, e.g. the SAML 2.0 service provider metadata.
+	ws.Route(ws.GET("/metadata/{identityprovider}").
+		Produces(contentTypeXML).
+		Doc("The metadata of KubeSphere for the specified identity provider, it should be imported by the identity provider.").
+		Param(ws.PathParameter("identityprovider", "The identity provider name")).
+		To(handler.identityProviderMetadata).
+		Returns(http.StatusOK, api.StatusOK, "").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AuthenticationTag}))
+
 	// https://openid.net/specs/openid-connect-rpinitiated-1_0.html
 	ws.Route(ws.GET("/logout").
 		Doc("This endpoint takes an ID token and logs the user out of KubeSphere if the "+
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -41,6 +42,7 @@ import (
 	"kubesphere.io/kubesphere/pkg/server/errors"
 	"kubesphere.io/kubesphere/pkg/server/params"
 	openpitrixoptions "kubesphere.io/kubesphere/pkg/simple/client/openpitrix"
+	"kubesphere.io/kubesphere/pkg/simple/client/openpitrix/helmrepoindex"
 	"kubesphere.io/kubesphere/pkg/simple/client/s3"
 	"kubesphere.io/kubesphere/pkg/utils/clusterclient"
 	"kubesphere.io/kubesphere/pkg/utils/idutils"
@@ -114,10 +116,11 @@ func (h *openpitrixHandler) CreateRepo(req *restful.Request, resp *restful.Respo
 			},
 		},
 		Spec: v1alpha1.HelmRepoSpec{
-			Name:        createRepoRequest.Name,
-			Url:         parsedUrl.String(),
-			SyncPeriod:  syncPeriod,
-			Description: stringutils.ShortenString(createRepoRequest.Description, 512),
+			Name:         createRepoRequest.Name,
+			Url:          parsedUrl.String(),
+			SyncPeriod:   syncPeriod,
+			Description:  stringutils.ShortenString(createRepoRequest.Description, 512),
+			Verification: createRepoRequest.Verification,
 		},
 	}
 
@@ -125,7 +128,8 @@ func (h *openpitrixHandler) CreateRepo(req *restful.Request, resp *restful.Respo
 		repo.Annotations[v1alpha1.RepoSyncPeriod] = createRepoRequest.SyncPeriod
 	}
 
-	if strings.HasPrefix(createRepoRequest.URL, "https://") || strings.HasPrefix(createRepoRequest.URL, "http://") {
+	if strings.HasPrefix(createRepoRequest.URL, "https://") || strings.HasPrefix(createRepoRequest.URL, "http://") ||
+		helmrepoindex.IsOCIRepo(createRepoRequest.URL) {
 		if userInfo != nil {
 			repo.Spec.Credential.Username = userInfo.Username()
 			repo.Spec.Credential.Password, _ = userInfo.Password()
@@ -301,7 +305,12 @@ func (h *openpitrixHandler) ListRepoEvents(req *restful.Request, resp *restful.R
 }
 
 func handleOpenpitrixError(resp *restful.Response, err error) {
-	if status.Code(err) == codes.NotFound {
+	if verr, ok := err.(*openpitrix.ValuesValidationError); ok {
+		klog.V(4).Infoln(err)
+		resp.WriteHeaderAndEntity(http.StatusBadRequest, verr)
+		return
+	}
+	if status.Code(err) == codes.NotFound || apierrors.IsNotFound(err) {
 		klog.V(4).Infoln(err)
 		api.HandleNotFound(resp, nil, err)
 		return
@@ -380,7 +389,7 @@ func (h *openpitrixHandler) CreateApp(req *restful.Request, resp *restful.Respon
 	}
 
 	if err != nil {
-		if status.Code(err) == codes.InvalidArgument {
+		if status.Code(err) == codes.InvalidArgument || status.Code(err) == codes.FailedPrecondition {
 			api.HandleBadRequest(resp, nil, err)
 			return
 		}
@@ -587,6 +596,10 @@ func (h *openpitrixHandler) ModifyAppVersion(req *restful.Request, resp *restful
 
 	if err != nil {
 		klog.Errorln(err)
+		if status.Code(err) == codes.FailedPrecondition {
+			api.HandleBadRequest(resp, nil, err)
+			return
+		}
 		api.HandleError(resp, nil, err)
 		return
 	}
@@ -631,6 +644,24 @@ func (h *openpitrixHandler) GetAppVersionFiles(req *restful.Request, resp *restf
 	resp.WriteEntity(result)
 }
 
+func (h *openpitrixHandler) GetAppVersionValuesSchema(req *restful.Request, resp *restful.Response) {
+	versionId := req.PathParameter("version")
+
+	result, err := h.openpitrix.GetAppVersionValuesSchema(versionId)
+
+	if err != nil {
+		klog.Errorln(err)
+		if apierrors.IsNotFound(err) {
+			api.HandleNotFound(resp, nil, err)
+		} else {
+			api.HandleBadRequest(resp, nil, err)
+		}
+		return
+	}
+
+	resp.WriteEntity(result)
+}
+
 // app version audit
 func (h *openpitrixHandler) ListAppVersionAudits(req *restful.Request, resp *restful.Response) {
 	limit, offset := params.ParsePaging(req)
@@ -766,15 +797,95 @@ func (h *openpitrixHandler) UpgradeApplication(req *restful.Request, resp *restf
 	}
 
 	upgradeClusterRequest.Namespace = namespace
+	upgradeClusterRequest.Workspace = req.PathParameter("workspace")
+	upgradeClusterRequest.ClusterName = req.PathParameter("cluster")
 	user, _ := request.UserFrom(req.Request.Context())
 	if user != nil {
 		upgradeClusterRequest.Username = user.GetName()
 	}
 
+	// preview the changes of the upgrade
+	if dryRun, _ := strconv.ParseBool(req.QueryParameter("dry_run")); dryRun {
+		diff, err := h.openpitrix.PreviewUpgradeApplication(upgradeClusterRequest, applicationId)
+		if err != nil {
+			klog.Errorln(err)
+			handleOpenpitrixError(resp, err)
+			return
+		}
+		resp.WriteEntity(diff)
+		return
+	}
+
 	err = h.openpitrix.UpgradeApplication(upgradeClusterRequest, applicationId)
 	if err != nil {
 		klog.Errorln(err)
-		api.HandleInternalError(resp, nil, err)
+		handleOpenpitrixError(resp, err)
+		return
+	}
+
+	resp.WriteEntity(errors.None)
+}
+
+func (h *openpitrixHandler) ListApplicationRevisions(req *restful.Request, resp *restful.Response) {
+	clusterName := req.PathParameter("cluster")
+	workspace := req.PathParameter("workspace")
+	applicationId := req.PathParameter("application")
+	namespace := req.PathParameter("namespace")
+
+	revisions, err := h.openpitrix.ListApplicationRevisions(workspace, clusterName, namespace, applicationId)
+	if err != nil {
+		klog.Errorln(err)
+		handleOpenpitrixError(resp, err)
+		return
+	}
+
+	resp.WriteEntity(revisions)
+}
+
+func (h *openpitrixHandler) DescribeApplicationRevision(req *restful.Request, resp *restful.Response) {
+	clusterName := req.PathParameter("cluster")
+	workspace := req.PathParameter("workspace")
+	applicationId := req.PathParameter("application")
+	namespace := req.PathParameter("namespace")
+	revision, err := strconv.Atoi(req.PathParameter("revision"))
+	if err != nil {
+		api.HandleBadRequest(resp, nil, err)
+		return
+	}
+
+	rev, err := h.openpitrix.DescribeApplicationRevision(workspace, clusterName, namespace, applicationId, revision)
+	if err != nil {
+		klog.Errorln(err)
+		handleOpenpitrixError(resp, err)
+		return
+	}
+
+	resp.WriteEntity(rev)
+}
+
+func (h *openpitrixHandler) RollbackApplication(req *restful.Request, resp *restful.Response) {
+	namespace := req.PathParameter("namespace")
+	applicationId := req.PathParameter("application")
+	var rollbackClusterRequest openpitrix.RollbackClusterRequest
+	err := req.ReadEntity(&rollbackClusterRequest)
+	if err != nil {
+		klog.V(4).Infoln(err)
+		api.HandleBadRequest(resp, nil, err)
+		return
+	}
+
+	rollbackClusterRequest.Namespace = namespace
+	rollbackClusterRequest.Workspace = req.PathParameter("workspace")
+	rollbackClusterRequest.ClusterName = req.PathParameter("cluster")
+	user, _ := request.UserFrom(req.Request.Context())
+	if user != nil {
+		rollbackClusterRequest.Username = user.GetName()
+	}
+
+	err = h.openpitrix.RollbackApplication(rollbackClusterRequest, applicationId)
+	if err != nil {
+		klog.Errorln(err)
+		handleOpenpitrixError(resp, err)
 		return
 	}
 
@@ -823,11 +934,23 @@ func (h *openpitrixHandler) CreateApplication(req *restful.Request, resp *restfu
 		createClusterRequest.Username = user.GetName()
 	}
 
+	// preview the release to be installed
+	if dryRun, _ := strconv.ParseBool(req.QueryParameter("dry_run")); dryRun {
+		diff, err := h.openpitrix.PreviewApplication(workspace, clusterName, namespace, createClusterRequest)
+		if err != nil {
+			klog.Errorln(err)
+			handleOpenpitrixError(resp, err)
+			return
+		}
+		resp.WriteEntity(diff)
+		return
+	}
+
 	err = h.openpitrix.CreateApplication(workspace, clusterName, namespace, createClusterRequest)
 
 	if err != nil {
 		klog.Errorln(err)
-		api.HandleInternalError(resp, nil, err)
+		handleOpenpitrixError(resp, err)
 		return
 	}
 
@@ -379,6 +379,14 @@ func AddToContainer(c *restful.Container, ksInfomrers informers.InformerFactory,
 		Param(webservice.PathParameter("version", "app template version id")).
 		Param(webservice.PathParameter("app", "app template id")))
 
+	webservice.Route(webservice.GET("/apps/{app}/versions/{version}/schema").
+		To(handler.GetAppVersionValuesSchema).
+		Doc("Get the values.schema.json of the app template version, which can be used to render the values form").
+		Returns(http.StatusOK, api.StatusOK, openpitrix.GetAppVersionValuesSchemaResponse{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.OpenpitrixAppTemplateTag}).
+		Param(webservice.PathParameter("version", "app template version id")).
+		Param(webservice.PathParameter("app", "app template id")))
+
 	webservice.Route(webservice.GET("/apps/{app}/versions/{version}/files").
 		Deprecate().
 		To(handler.GetAppVersionFiles).
@@ -547,9 +555,11 @@ func AddToContainer(c *restful.Container, ksInfomrers informers.InformerFactory,
 		Consumes(mimePatch...).
 		To(handler.UpgradeApplication).
 		Doc("Upgrade application").
+		Param(webservice.QueryParameter("dry_run", "Preview the changes without applying them, the diff of the resources is returned")).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
 		Reads(openpitrix.UpgradeClusterRequest{}).
 		Returns(http.StatusOK, api.StatusOK, errors.Error{}).
+		Returns(http.StatusBadRequest, "the values violate the values.schema.json of the chart", openpitrix.ValuesValidationError{}).
 		Param(webservice.PathParameter("cluster", "the name of the cluster.").Required(true)).
 		Param(webservice.PathParameter("namespace", "the name of the project").Required(true)).
 		Param(webservice.PathParameter("application", "the id of the application").Required(true)))
@@ -558,27 +568,33 @@ func AddToContainer(c *restful.Container, ksInfomrers informers.InformerFactory,
 		Consumes(mimePatch...).
 		To(handler.UpgradeApplication).
 		Doc("Upgrade application").
+		Param(webservice.QueryParameter("dry_run", "Preview the changes without applying them, the diff of the resources is returned")).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
 		Reads(openpitrix.UpgradeClusterRequest{}).
 		Returns(http.StatusOK, api.StatusOK, errors.Error{}).
+		Returns(http.StatusBadRequest, "the values violate the values.schema.json of the chart", openpitrix.ValuesValidationError{}).
 		Param(webservice.PathParameter("namespace", "the name of the project").Required(true)).
 		Param(webservice.PathParameter("application", "the id of the application").Required(true)))
 
 	webservice.Route(webservice.POST("/workspaces/{workspace}/clusters/{cluster}/namespaces/{namespace}/applications").
 		To(handler.CreateApplication).
 		Doc("Deploy a new application").
+		Param(webservice.QueryParameter("dry_run", "Preview the changes without applying them, the diff of the resources is returned")).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.OpenpitrixTag}).
 		Reads(openpitrix.CreateClusterRequest{}).
 		Returns(http.StatusOK, api.StatusOK, errors.Error{}).
+		Returns(http.StatusBadRequest, "the values violate the values.schema.json of the chart", openpitrix.ValuesValidationError{}).
 		Param(webservice.PathParameter("cluster", "the name of the cluster.").Required(true)).
 		Param(webservice.PathParameter("namespace", "the name of the project").Required(true)))
 
 	webservice.Route(webservice.POST("/workspaces/{workspace}/namespaces/{namespace}/applications").
 		To(handler.CreateApplication).
 		Doc("Deploy a new application").
+		Param(webservice.QueryParameter("dry_run", "Preview the changes without applying them, the diff of the resources is returned")).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.OpenpitrixTag}).
 		Reads(openpitrix.CreateClusterRequest{}).
 		Returns(http.StatusOK, api.StatusOK, errors.Error{}).
+		Returns(http.StatusBadRequest, "the values violate the values.schema.json of the chart", openpitrix.ValuesValidationError{}).
 		Param(webservice.PathParameter("namespace", "the name of the project").Required(true)))
 
 	webservice.Route(webservice.GET("/workspaces/{workspace}/clusters/{cluster}/namespaces/{namespace}/applications/{application}").
@@ -598,6 +614,61 @@ func AddToContainer(c *restful.Container, ksInfomrers informers.InformerFactory,
 		Param(webservice.PathParameter("namespace", "the name of the project").Required(true)).
 		Param(webservice.PathParameter("application", "the id of the application").Required(true)))
 
+	webservice.Route(webservice.GET("/workspaces/{workspace}/clusters/{cluster}/namespaces/{namespace}/applications/{application}/revisions").
+		To(handler.ListApplicationRevisions).
+		Returns(http.StatusOK, api.StatusOK, []openpitrix.ReleaseRevision{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
+		Doc("List the revision history of the specified application, the latest revision comes first").
+		Param(webservice.PathParameter("cluster", "the name of the cluster.").Required(true)).
+		Param(webservice.PathParameter("namespace", "the name of the project").Required(true)).
+		Param(webservice.PathParameter("application", "the id of the application").Required(true)))
+
+	webservice.Route(webservice.GET("/workspaces/{workspace}/clusters/{cluster}/namespaces/{namespace}/applications/{application}/revisions/{revision}").
+		To(handler.DescribeApplicationRevision).
+		Returns(http.StatusOK, api.StatusOK, openpitrix.ReleaseRevision{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
+		Doc("Describe the specified revision of the application, including the values and manifest").
+		Param(webservice.PathParameter("cluster", "the name of the cluster.").Required(true)).
+		Param(webservice.PathParameter("namespace", "the name of the project").Required(true)).
+		Param(webservice.PathParameter("application", "the id of the application").Required(true)).
+		Param(webservice.PathParameter("revision", "the revision of the application").Required(true)))
+
+	webservice.Route(webservice.POST("/workspaces/{workspace}/clusters/{cluster}/namespaces/{namespace}/applications/{application}/rollback").
+		To(handler.RollbackApplication).
+		Doc("Rollback application to the specified revision").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
+		Reads(openpitrix.RollbackClusterRequest{}).
+		Returns(http.StatusOK, api.StatusOK, errors.Error{}).
+		Param(webservice.PathParameter("cluster", "the name of the cluster.").Required(true)).
+		Param(webservice.PathParameter("namespace", "the name of the project").Required(true)).
+		Param(webservice.PathParameter("application", "the id of the application").Required(true)))
+
+	webservice.Route(webservice.GET("/workspaces/{workspace}/namespaces/{namespace}/applications/{application}/revisions").
+		To(handler.ListApplicationRevisions).
+		Returns(http.StatusOK, api.StatusOK, []openpitrix.ReleaseRevision{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
+		Doc("List the revision history of the specified application, the latest revision comes first").
+		Param(webservice.PathParameter("namespace", "the name of the project").Required(true)).
+		Param(webservice.PathParameter("application", "the id of the application").Required(true)))
+
+	webservice.Route(webservice.GET("/workspaces/{workspace}/namespaces/{namespace}/applications/{application}/revisions/{revision}").
+		To(handler.DescribeApplicationRevision).
+		Returns(http.StatusOK, api.StatusOK, openpitrix.ReleaseRevision{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
+		Doc("Describe the specified revision of the application, including the values and manifest").
+		Param(webservice.PathParameter("namespace", "the name of the project").Required(true)).
+		Param(webservice.PathParameter("application", "the id of the application").Required(true)).
+		Param(webservice.PathParameter("revision", "the revision of the application").Required(true)))
+
+	webservice.Route(webservice.POST("/workspaces/{workspace}/namespaces/{namespace}/applications/{application}/rollback").
+		To(handler.RollbackApplication).
+		Doc("Rollback application to the specified revision").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
+		Reads(openpitrix.RollbackClusterRequest{}).
+		Returns(http.StatusOK, api.StatusOK, errors.Error{}).
+		Param(webservice.PathParameter("namespace", "the name of the project").Required(true)).
+		Param(webservice.PathParameter("application", "the id of the application").Required(true)))
+
 	webservice.Route(webservice.DELETE("/workspaces/{workspace}/namespaces/{namespace}/applications/{application}").
 		To(handler.DeleteApplication).
 		Doc("Delete the specified application").
@@ -22,19 +22,24 @@ import (
 
 	"github.com/emicklei/go-restful/v3"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8sinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 
+	"kubesphere.io/kubesphere/pkg/api"
+	requestctx "kubesphere.io/kubesphere/pkg/apiserver/request"
 	"kubesphere.io/kubesphere/pkg/models/workloads"
 	"kubesphere.io/kubesphere/pkg/server/errors"
 )
 
 type operationHandler struct {
-	jobRunner workloads.JobRunner
+	jobRunner       workloads.JobRunner
+	cronJobOperator workloads.CronJobOperator
 }
 
-func newOperationHandler(client kubernetes.Interface) *operationHandler {
+func newOperationHandler(client kubernetes.Interface, informers k8sinformers.SharedInformerFactory) *operationHandler {
 	return &operationHandler{
-		jobRunner: workloads.NewJobRunner(client),
+		jobRunner:       workloads.NewJobRunner(client),
+		cronJobOperator: workloads.NewCronJobOperator(informers, client),
 	}
 }
 
@@ -64,3 +69,59 @@ func (r *operationHandler) handleJobReRun(request *restful.Request, response *re
 
 	response.WriteAsJson(errors.None)
 }
+
+func (r *operationHandler) handleCronJobOperation(request *restful.Request, response *restful.Response) {
+	var result interface{}
+	var err error
+
+	cronJob := request.PathParameter("cronjob")
+	namespace := request.PathParameter("namespace")
+	action := request.QueryParameter("action")
+
+	switch action {
+	case "suspend":
+		result, err = r.cronJobOperator.Suspend(namespace, cronJob, true)
+	case "resume":
+		result, err = r.cronJobOperator.Suspend(namespace, cronJob, false)
+	case "trigger":
+		var username string
+		if user, ok := requestctx.UserFrom(request.Request.Context()); ok {
+			username = user.GetName()
+		}
+		result, err = r.cronJobOperator.Trigger(namespace, cronJob, username)
+	case "prune":
+		result, err = r.cronJobOperator.Prune(namespace, cronJob)
+	default:
+		api.HandleBadRequest(response, request, fmt.Errorf("invalid operation %s", action))
+		return
+	}
+	if err != nil {
+		handleCronJobError(response, request, err)
+		return
+	}
+
+	response.WriteEntity(result)
+}
+
+func (r *operationHandler) handleListCronJobRuns(request *restful.Request, response *restful.Response) {
+	runs, err := r.cronJobOperator.ListRuns(request.PathParameter("namespace"), request.PathParameter("cronjob"))
+	if err != nil {
+		handleCronJobError(response, request, err)
+		return
+	}
+
+	response.WriteEntity(runs)
+}
+
+func handleCronJobError(response *restful.Response, request *restful.Request, err error) {
+	switch {
+	case k8serr.IsNotFound(err):
+		api.HandleNotFound(response, request, err)
+	case k8serr.IsBadRequest(err):
+		api.HandleBadRequest(response, request, err)
+	case k8serr.IsConflict(err):
+		api.HandleConflict(response, request, err)
+	default:
+		api.HandleInternalError(response, request, err)
+	}
+}
@@ -20,11 +20,14 @@ import (
 	"net/http"
 
 	"github.com/emicklei/go-restful/v3"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 
 	"kubesphere.io/kubesphere/pkg/api"
 	"kubesphere.io/kubesphere/pkg/apiserver/runtime"
+	"kubesphere.io/kubesphere/pkg/models/workloads"
 	"kubesphere.io/kubesphere/pkg/server/errors"
 )
 
@@ -34,11 +37,11 @@ const (
 
 var GroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1alpha2"}
 
-func AddToContainer(c *restful.Container, client kubernetes.Interface) error {
+func AddToContainer(c *restful.Container, client kubernetes.Interface, informers k8sinformers.SharedInformerFactory) error {
 
 	webservice := runtime.NewWebService(GroupVersion)
 
-	handler := newOperationHandler(client)
+	handler := newOperationHandler(client, informers)
 
 	webservice.Route(webservice.POST("/namespaces/{namespace}/jobs/{job}").
 		To(handler.handleJobReRun).
@@ -50,6 +53,21 @@ func AddToContainer(c *restful.Container, client kubernetes.Interface) error {
 		Param(webservice.QueryParameter("resourceVersion", "version of job, rerun when the version matches").Required(true)).
 		Returns(http.StatusOK, api.StatusOK, errors.Error{}))
 
+	webservice.Route(webservice.POST("/namespaces/{namespace}/cronjobs/{cronjob}").
+		To(handler.handleCronJobOperation).
+		Doc("Suspend or resume the scheduling of a cronjob, trigger a run of it right now, or prune its run history by the rules in its annotations").
+		Param(webservice.PathParameter("cronjob", "cronjob name")).
+		Param(webservice.PathParameter("namespace", "the name of the namespace where the cronjob runs in")).
+		Param(webservice.QueryParameter("action", "one of \"suspend\", \"resume\", \"trigger\" and \"prune\"").Required(true)).
+		Returns(http.StatusOK, api.StatusOK, batchv1.CronJob{}))
+
+	webservice.Route(webservice.GET("/namespaces/{namespace}/cronjobs/{cronjob}/runs").
+		To(handler.handleListCronJobRuns).
+		Doc("List the runs of a cronjob with their status, durations and pods, newest first").
+		Param(webservice.PathParameter("cronjob", "cronjob name")).
+		Param(webservice.PathParameter("namespace", "the name of the namespace where the cronjob runs in")).
+		Returns(http.StatusOK, api.StatusOK, []workloads.JobRun{}))
+
 	c.Add(webservice)
 
 	return nil
@@ -29,6 +29,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"kubesphere.io/kubesphere/pkg/api"
+	requestctx "kubesphere.io/kubesphere/pkg/apiserver/request"
 	"kubesphere.io/kubesphere/pkg/informers"
 	"kubesphere.io/kubesphere/pkg/models/components"
 	"kubesphere.io/kubesphere/pkg/models/git"
@@ -49,6 +50,7 @@ type resourceHandler struct {
 	componentsGetter    components.ComponentsGetter
 	resourceQuotaGetter quotas.ResourceQuotaGetter
 	revisionGetter      revisions.RevisionGetter
+	revisionOperator    revisions.RevisionOperator
 	routerOperator      routers.RouterOperator
 	gitVerifier         git.GitVerifier
 	registryGetter      registries.RegistryGetter
@@ -60,9 +62,10 @@ func newResourceHandler(k8sClient kubernetes.Interface, factory informers.Inform
 
 	return &resourceHandler{
 		resourcesGetter:     resource.NewResourceGetter(factory),
-		componentsGetter:    components.NewComponentsGetter(factory.KubernetesSharedInformerFactory()),
+		componentsGetter:    components.NewComponentsGetter(factory.KubernetesSharedInformerFactory(), nil),
 		resourceQuotaGetter: quotas.NewResourceQuotaGetter(factory.KubernetesSharedInformerFactory()),
 		revisionGetter:      revisions.NewRevisionGetter(factory.KubernetesSharedInformerFactory()),
+		revisionOperator:    revisions.NewRevisionOperator(k8sClient, factory.KubernetesSharedInformerFactory()),
 		routerOperator:      routers.NewRouterOperator(k8sClient, factory.KubernetesSharedInformerFactory()),
 		gitVerifier:         git.NewGitVerifier(factory.KubernetesSharedInformerFactory()),
 		registryGetter:      registries.NewRegistryGetter(factory.KubernetesSharedInformerFactory()),
@@ -206,6 +209,83 @@ func (r *resourceHandler) handleGetStatefulSetRevision(request *restful.Request,
 	response.WriteAsJson(result)
 }
 
+func (r *resourceHandler) handleListWorkloadRevisions(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	kind := request.PathParameter("workloads")
+	name := request.PathParameter("name")
+
+	result, err := r.revisionOperator.ListRevisions(kind, namespace, name)
+	if err != nil {
+		handleRevisionError(request, response, err)
+		return
+	}
+
+	response.WriteAsJson(result)
+}
+
+func (r *resourceHandler) handleDiffWorkloadRevisions(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	kind := request.PathParameter("workloads")
+	name := request.PathParameter("name")
+	from, err := strconv.ParseInt(request.PathParameter("revision"), 10, 64)
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	var to int64
+	if target := request.QueryParameter("target"); target != "" {
+		if to, err = strconv.ParseInt(target, 10, 64); err != nil {
+			api.HandleBadRequest(response, request, err)
+			return
+		}
+	}
+
+	result, err := r.revisionOperator.DiffRevisions(kind, namespace, name, from, to)
+	if err != nil {
+		handleRevisionError(request, response, err)
+		return
+	}
+
+	response.WriteAsJson(result)
+}
+
+func (r *resourceHandler) handleRollbackWorkload(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	kind := request.PathParameter("workloads")
+	name := request.PathParameter("name")
+	revision, err := strconv.ParseInt(request.PathParameter("revision"), 10, 64)
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+
+	var username string
+	if user, ok := requestctx.UserFrom(request.Request.Context()); ok {
+		username = user.GetName()
+	}
+
+	if err = r.revisionOperator.Rollback(kind, namespace, name, revision, username); err != nil {
+		handleRevisionError(request, response, err)
+		return
+	}
+
+	response.WriteAsJson(errors.None)
+}
+
+func handleRevisionError(request *restful.Request, response *restful.Response, err error) {
+	switch {
+	case k8serr.IsNotFound(err):
+		api.HandleNotFound(response, request, err)
+	case k8serr.IsBadRequest(err):
+		api.HandleBadRequest(response, request, err)
+	case k8serr.IsConflict(err):
+		api.HandleConflict(response, request, err)
+	default:
+		klog.Error(err)
+		api.HandleInternalError(response, request, err)
+	}
+}
+
 // Get ingress controller service for specified namespace
 func (r *resourceHandler) handleGetRouter(request *restful.Request, response *restful.Response) {
 	namespace := request.PathParameter("namespace")
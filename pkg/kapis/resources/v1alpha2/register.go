@@ -34,6 +34,7 @@ import (
 	"kubesphere.io/kubesphere/pkg/models"
 	gitmodel "kubesphere.io/kubesphere/pkg/models/git"
 	registriesmodel "kubesphere.io/kubesphere/pkg/models/registries"
+	"kubesphere.io/kubesphere/pkg/models/revisions"
 	"kubesphere.io/kubesphere/pkg/server/errors"
 	"kubesphere.io/kubesphere/pkg/server/params"
 )
@@ -190,6 +191,34 @@ func AddToContainer(c *restful.Container, k8sClient kubernetes.Interface, factor
 		Param(webservice.PathParameter("revision", "the revision of the statefulset")).
 		Returns(http.StatusOK, api.StatusOK, appsv1.StatefulSet{}))
 
+	webservice.Route(webservice.GET("/namespaces/{namespace}/{workloads}/{name}/revisions").
+		To(handler.handleListWorkloadRevisions).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
+		Doc("List the revisions of the workload with change-cause and images, the latest revision first").
+		Param(webservice.PathParameter("namespace", "the namespace of the workload")).
+		Param(webservice.PathParameter("workloads", "the workload type, one of deployments, statefulsets and daemonsets")).
+		Param(webservice.PathParameter("name", "the name of the workload")).
+		Returns(http.StatusOK, api.StatusOK, []revisions.Revision{}))
+	webservice.Route(webservice.GET("/namespaces/{namespace}/{workloads}/{name}/revisions/{revision}/diff").
+		To(handler.handleDiffWorkloadRevisions).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
+		Doc("Compare the pod template of the revision with the target revision").
+		Param(webservice.PathParameter("namespace", "the namespace of the workload")).
+		Param(webservice.PathParameter("workloads", "the workload type, one of deployments, statefulsets and daemonsets")).
+		Param(webservice.PathParameter("name", "the name of the workload")).
+		Param(webservice.PathParameter("revision", "the revision to compare")).
+		Param(webservice.QueryParameter("target", "the target revision, defaults to the current revision").Required(false)).
+		Returns(http.StatusOK, api.StatusOK, revisions.RevisionDiff{}))
+	webservice.Route(webservice.POST("/namespaces/{namespace}/{workloads}/{name}/revisions/{revision}/rollback").
+		To(handler.handleRollbackWorkload).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
+		Doc("Roll back the workload to the pod template of the revision, the user is recorded in the annotations of the workload").
+		Param(webservice.PathParameter("namespace", "the namespace of the workload")).
+		Param(webservice.PathParameter("workloads", "the workload type, one of deployments, statefulsets and daemonsets")).
+		Param(webservice.PathParameter("name", "the name of the workload")).
+		Param(webservice.PathParameter("revision", "the revision to roll back to")).
+		Returns(http.StatusOK, api.StatusOK, errors.Error{}))
+
 	webservice.Route(webservice.GET("/namespaces/{namespace}/router").
 		Deprecate().
 		To(handler.handleGetRouter).
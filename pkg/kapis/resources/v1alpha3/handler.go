@@ -19,6 +19,7 @@ package v1alpha3
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/emicklei/go-restful/v3"
@@ -30,25 +31,37 @@ import (
 	"kubesphere.io/kubesphere/pkg/apiserver/query"
 	"kubesphere.io/kubesphere/pkg/models/components"
 	v2 "kubesphere.io/kubesphere/pkg/models/registries/v2"
+	"kubesphere.io/kubesphere/pkg/models/registries/vulnerability"
 	"kubesphere.io/kubesphere/pkg/models/resources/v1alpha2"
 	resourcev1alpha2 "kubesphere.io/kubesphere/pkg/models/resources/v1alpha2/resource"
 	resourcev1alpha3 "kubesphere.io/kubesphere/pkg/models/resources/v1alpha3/resource"
+	"kubesphere.io/kubesphere/pkg/models/workloads"
 	"kubesphere.io/kubesphere/pkg/server/params"
 )
 
+const (
+	// default page size of the image repositories and tags, the digest of each tag may be requested one by one
+	defaultImageListLimit = 20
+	// max page size of the image repositories and tags
+	maxImageListLimit = 100
+)
+
 type Handler struct {
 	resourceGetterV1alpha3  *resourcev1alpha3.ResourceGetter
 	resourcesGetterV1alpha2 *resourcev1alpha2.ResourceGetter
 	componentsGetter        components.ComponentsGetter
 	registryHelper          v2.RegistryHelper
+	diagnosisOperator       workloads.DiagnosisOperator
 }
 
-func New(resourceGetterV1alpha3 *resourcev1alpha3.ResourceGetter, resourcesGetterV1alpha2 *resourcev1alpha2.ResourceGetter, componentsGetter components.ComponentsGetter) *Handler {
+func New(resourceGetterV1alpha3 *resourcev1alpha3.ResourceGetter, resourcesGetterV1alpha2 *resourcev1alpha2.ResourceGetter, componentsGetter components.ComponentsGetter,
+	vulnerabilityDatabase *vulnerability.Database, diagnosisOperator workloads.DiagnosisOperator) *Handler {
 	return &Handler{
 		resourceGetterV1alpha3:  resourceGetterV1alpha3,
 		resourcesGetterV1alpha2: resourcesGetterV1alpha2,
 		componentsGetter:        componentsGetter,
-		registryHelper:          v2.NewRegistryHelper(),
+		registryHelper:          v2.NewRegistryHelper(vulnerabilityDatabase),
+		diagnosisOperator:       diagnosisOperator,
 	}
 }
 
@@ -88,11 +101,20 @@ func (h *Handler) handleGetResources(request *restful.Request, response *restful
 
 // handleListResources retrieves resources
 func (h *Handler) handleListResources(request *restful.Request, response *restful.Response) {
-	query := query.ParseQueryParameter(request)
+	q := query.ParseQueryParameter(request)
 	resourceType := request.PathParameter("resources")
 	namespace := request.PathParameter("namespace")
 
-	result, err := h.resourceGetterV1alpha3.List(resourceType, namespace, query)
+	if q.Watch {
+		h.handleWatchResources(resourceType, namespace, q, request, response)
+		return
+	}
+	if q.As == query.AsTable {
+		h.handleListResourcesAsTable(resourceType, namespace, q, request, response)
+		return
+	}
+
+	result, err := h.resourceGetterV1alpha3.List(resourceType, namespace, q)
 	if err == nil {
 		response.WriteEntity(result)
 		return
@@ -105,7 +127,7 @@ func (h *Handler) handleListResources(request *restful.Request, response *restfu
 	}
 
 	// fallback to v1alpha2
-	result, err = h.fallback(resourceType, namespace, query)
+	result, err = h.fallback(resourceType, namespace, q)
 	if err != nil {
 		if err == resourcev1alpha2.ErrResourceNotSupported {
 			api.HandleNotFound(response, request, err)
@@ -118,6 +140,41 @@ func (h *Handler) handleListResources(request *restful.Request, response *restfu
 	response.WriteEntity(result)
 }
 
+// handleWatchResources streams the changes of the resources matching the query, the resources served by v1alpha2 can't be watched.
+func (h *Handler) handleWatchResources(resourceType, namespace string, q *query.Query, request *restful.Request, response *restful.Response) {
+	watcher, err := h.resourceGetterV1alpha3.Watch(resourceType, namespace, q)
+	if err != nil {
+		switch err {
+		case resourcev1alpha3.ErrResourceNotSupported:
+			api.HandleNotFound(response, request, err)
+		case resourcev1alpha3.ErrWatchNotSupported:
+			api.HandleBadRequest(response, request, err)
+		default:
+			api.HandleError(response, request, err)
+		}
+		return
+	}
+	api.ServeWatch(watcher, request, response)
+}
+
+// handleListResourcesAsTable presents the resources as a table, the resources served by v1alpha2 can't be presented as tables.
+func (h *Handler) handleListResourcesAsTable(resourceType, namespace string, q *query.Query, request *restful.Request, response *restful.Response) {
+	table, err := h.resourceGetterV1alpha3.Table(resourceType, namespace, q)
+	if err != nil {
+		switch err {
+		case resourcev1alpha3.ErrResourceNotSupported:
+			api.HandleNotFound(response, request, err)
+		case resourcev1alpha3.ErrTableNotSupported:
+			api.HandleBadRequest(response, request, err)
+		default:
+			klog.Errorf("%s, resource type: %s", err, resourceType)
+			api.HandleError(response, request, err)
+		}
+		return
+	}
+	response.WriteEntity(table)
+}
+
 func (h *Handler) fallback(resourceType string, namespace string, q *query.Query) (*api.ListResult, error) {
 	orderBy := string(q.SortBy)
 	limit, offset := q.Pagination.Limit, q.Pagination.Offset
@@ -202,6 +259,22 @@ func (h *Handler) handleGetComponents(request *restful.Request, response *restfu
 	response.WriteEntity(result)
 }
 
+// handleGetAbnormalWorkloads diagnoses the workloads of the cluster, of a workspace or of a namespace,
+// and returns the abnormal ones with the reasons.
+func (h *Handler) handleGetAbnormalWorkloads(request *restful.Request, response *restful.Response) {
+	workspace := request.PathParameter("workspace")
+	namespace := request.PathParameter("namespace")
+
+	result, err := h.diagnosisOperator.Diagnose(workspace, namespace, query.ParseQueryParameter(request))
+	if err != nil {
+		klog.Error(err)
+		api.HandleInternalError(response, nil, err)
+		return
+	}
+
+	response.WriteEntity(result)
+}
+
 // handleVerifyImageRepositorySecret verifies image secret against registry, it takes k8s.io/api/core/v1/types.Secret
 // as input, and authenticate registry with credential specified. Returns http.StatusOK if authenticate successfully,
 // returns http.StatusUnauthorized if failed.
@@ -275,6 +348,139 @@ func (h *Handler) handleGetRepositoryTags(request *restful.Request, response *re
 	response.WriteHeaderAndJson(http.StatusOK, tags, restful.MIME_JSON)
 }
 
+// handleInspectImage lists the layers, exposed ports, labels and referrers (e.g. SBOMs) of the image,
+// and the vulnerabilities found by the local vulnerability database if it is configured.
+func (h *Handler) handleInspectImage(request *restful.Request, response *restful.Response) {
+	secretName := request.QueryParameter("secret")
+	namespace := request.PathParameter("namespace")
+	image := request.QueryParameter("image")
+
+	if len(image) == 0 {
+		api.HandleBadRequest(response, request, fmt.Errorf("empty image name"))
+		return
+	}
+
+	secret, err := h.getImagePullSecret(namespace, secretName)
+	if err != nil {
+		handleSecretError(request, response, err)
+		return
+	}
+
+	inspection, err := h.registryHelper.Inspect(request.Request.Context(), secret, image)
+	if err != nil {
+		canonicalizeRegistryError(request, response, err)
+		return
+	}
+
+	response.WriteHeaderAndJson(http.StatusOK, inspection, restful.MIME_JSON)
+}
+
+// handleListImageRegistries lists the registries referenced by the image pull secrets of the namespace
+func (h *Handler) handleListImageRegistries(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+
+	q := query.New()
+	q.Filters[query.ParameterFieldSelector] = query.Value(fmt.Sprintf("type=%s", v1.SecretTypeDockerConfigJson))
+	result, err := h.resourceGetterV1alpha3.List("secrets", namespace, q)
+	if err != nil {
+		api.HandleInternalError(response, request, err)
+		return
+	}
+
+	secrets := make([]*v1.Secret, 0, len(result.Items))
+	for _, item := range result.Items {
+		if secret, ok := item.(*v1.Secret); ok {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	response.WriteHeaderAndJson(http.StatusOK, h.registryHelper.ListRegistries(secrets), restful.MIME_JSON)
+}
+
+// handleListImageRepositories lists repositories of the registry of the image pull secret,
+// supports Docker Hub, Harbor and Distribution v2 registries.
+func (h *Handler) handleListImageRepositories(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	secretName := request.QueryParameter("secret")
+	page, limit := parsePage(request)
+
+	secret, err := h.getImagePullSecret(namespace, secretName)
+	if err != nil {
+		handleSecretError(request, response, err)
+		return
+	}
+
+	repositories, err := h.registryHelper.ListRepositories(secret, request.QueryParameter("q"), page, limit)
+	if err != nil {
+		canonicalizeRegistryError(request, response, err)
+		return
+	}
+
+	response.WriteHeaderAndJson(http.StatusOK, repositories, restful.MIME_JSON)
+}
+
+// handleListImageTags lists tags of the repository with their digests and push time, paged and filtered by the keyword.
+func (h *Handler) handleListImageTags(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	secretName := request.QueryParameter("secret")
+	repository := request.QueryParameter("repository")
+	page, limit := parsePage(request)
+
+	if len(repository) == 0 {
+		api.HandleBadRequest(response, request, fmt.Errorf("empty repository name"))
+		return
+	}
+
+	secret, err := h.getImagePullSecret(namespace, secretName)
+	if err != nil {
+		handleSecretError(request, response, err)
+		return
+	}
+
+	tags, err := h.registryHelper.ListTags(secret, repository, request.QueryParameter("q"), page, limit)
+	if err != nil {
+		canonicalizeRegistryError(request, response, err)
+		return
+	}
+
+	response.WriteHeaderAndJson(http.StatusOK, tags, restful.MIME_JSON)
+}
+
+// getImagePullSecret returns nil if the secret name is empty, which means anonymous fetching
+func (h *Handler) getImagePullSecret(namespace, secretName string) (*v1.Secret, error) {
+	if len(secretName) == 0 {
+		return nil, nil
+	}
+	object, err := h.resourceGetterV1alpha3.Get("secrets", namespace, secretName)
+	if err != nil {
+		return nil, err
+	}
+	return object.(*v1.Secret), nil
+}
+
+func handleSecretError(request *restful.Request, response *restful.Response, err error) {
+	if errors.IsNotFound(err) {
+		api.HandleNotFound(response, request, err)
+		return
+	}
+	api.HandleInternalError(response, request, err)
+}
+
+func parsePage(request *restful.Request) (int, int) {
+	page, err := strconv.Atoi(request.QueryParameter(query.ParameterPage))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(request.QueryParameter(query.ParameterLimit))
+	if err != nil || limit < 1 {
+		limit = defaultImageListLimit
+	}
+	if limit > maxImageListLimit {
+		limit = maxImageListLimit
+	}
+	return page, limit
+}
+
 func canonicalizeRegistryError(request *restful.Request, response *restful.Response, err error) {
 	if strings.Contains(err.Error(), "Unauthorized") {
 		api.HandleUnauthorized(response, request, err)
@@ -243,7 +243,7 @@ func prepare() (*Handler, error) {
 		}
 	}
 
-	handler := New(resourcev1alpha3.NewResourceGetter(fakeInformerFactory, nil), resourcev1alpha2.NewResourceGetter(fakeInformerFactory), components.NewComponentsGetter(fakeInformerFactory.KubernetesSharedInformerFactory()))
+	handler := New(resourcev1alpha3.NewResourceGetter(fakeInformerFactory, nil), resourcev1alpha2.NewResourceGetter(fakeInformerFactory), components.NewComponentsGetter(fakeInformerFactory.KubernetesSharedInformerFactory(), nil), nil, nil)
 
 	return handler, nil
 }
@@ -287,6 +287,28 @@ func TestHandleGetComponents(t *testing.T) {
 	}
 }
 
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		target string
+		page   int
+		limit  int
+	}{
+		{"/repositories", 1, defaultImageListLimit},
+		{"/repositories?page=2&limit=50", 2, 50},
+		{"/repositories?page=0&limit=-1", 1, defaultImageListLimit},
+		{"/repositories?limit=100000", 1, maxImageListLimit},
+	}
+	for _, tt := range tests {
+		request, _, err := buildReqAndRes(http.MethodGet, tt.target, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page, limit := parsePage(request); page != tt.page || limit != tt.limit {
+			t.Errorf("%s: expected page %d and limit %d, got %d and %d", tt.target, tt.page, tt.limit, page, limit)
+		}
+	}
+}
+
 // build req and res in *restful
 func buildReqAndRes(method, target string, param map[string]string, body io.Reader) (*restful.Request, *restful.Response, error) {
 	//build req
@@ -21,6 +21,7 @@ import (
 	"github.com/emicklei/go-restful/v3"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 
 	"kubesphere.io/kubesphere/pkg/api"
@@ -30,8 +31,10 @@ import (
 	"kubesphere.io/kubesphere/pkg/informers"
 	"kubesphere.io/kubesphere/pkg/models/components"
 	v2 "kubesphere.io/kubesphere/pkg/models/registries/v2"
+	"kubesphere.io/kubesphere/pkg/models/registries/vulnerability"
 	resourcev1alpha2 "kubesphere.io/kubesphere/pkg/models/resources/v1alpha2/resource"
 	resourcev1alpha3 "kubesphere.io/kubesphere/pkg/models/resources/v1alpha3/resource"
+	"kubesphere.io/kubesphere/pkg/models/workloads"
 
 	"net/http"
 )
@@ -52,10 +55,13 @@ func Resource(resource string) schema.GroupResource {
 	return GroupVersion.WithResource(resource).GroupResource()
 }
 
-func AddToContainer(c *restful.Container, informerFactory informers.InformerFactory, cache cache.Cache) error {
+func AddToContainer(c *restful.Container, informerFactory informers.InformerFactory, k8sClient kubernetes.Interface, cache cache.Cache, vulnerabilityOptions *vulnerability.Options,
+	featureChecker *components.FeatureChecker) error {
 
 	webservice := runtime.NewWebService(GroupVersion)
-	handler := New(resourcev1alpha3.NewResourceGetter(informerFactory, cache), resourcev1alpha2.NewResourceGetter(informerFactory), components.NewComponentsGetter(informerFactory.KubernetesSharedInformerFactory()))
+	handler := New(resourcev1alpha3.NewResourceGetter(informerFactory, cache), resourcev1alpha2.NewResourceGetter(informerFactory),
+		components.NewComponentsGetter(informerFactory.KubernetesSharedInformerFactory(), featureChecker), vulnerability.NewDatabase(vulnerabilityOptions),
+		workloads.NewDiagnosisOperator(informerFactory.KubernetesSharedInformerFactory(), k8sClient))
 
 	webservice.Route(webservice.GET("/{resources}").
 		To(handler.handleListResources).
@@ -67,6 +73,9 @@ func AddToContainer(c *restful.Container, informerFactory informers.InformerFact
 		Param(webservice.QueryParameter(query.ParameterLimit, "limit").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
 		Param(webservice.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
+		Param(webservice.QueryParameter(query.ParameterWatch, "watch the changes of the resources matching the query instead of listing, e.g. watch=true").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterResourceVersion, "resource version the watch resumes from, e.g. the resource version of the latest bookmark").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterAs, "present the resources as a meta.k8s.io/v1 Table, whose columns can be sorted by, e.g. as=Table&sortBy=restarts").Required(false)).
 		Returns(http.StatusOK, ok, api.ListResult{}))
 
 	webservice.Route(webservice.GET("/{resources}/{name}").
@@ -88,6 +97,9 @@ func AddToContainer(c *restful.Container, informerFactory informers.InformerFact
 		Param(webservice.QueryParameter(query.ParameterLimit, "limit").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterAscending, "sort parameters, e.g. reverse=true").Required(false).DefaultValue("ascending=false")).
 		Param(webservice.QueryParameter(query.ParameterOrderBy, "sort parameters, e.g. orderBy=createTime")).
+		Param(webservice.QueryParameter(query.ParameterWatch, "watch the changes of the resources matching the query instead of listing, e.g. watch=true").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterResourceVersion, "resource version the watch resumes from, e.g. the resource version of the latest bookmark").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterAs, "present the resources as a meta.k8s.io/v1 Table, whose columns can be sorted by, e.g. as=Table&sortBy=restarts").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterFieldSelector, "field selector used for filtering, you can use the = , == and != operators with field selectors( = and == mean the same thing), e.g. fieldSelector=type=kubernetes.io/dockerconfigjson, multiple separated by comma").Required(false)).
 		Returns(http.StatusOK, ok, api.ListResult{}))
 
@@ -103,12 +115,12 @@ func AddToContainer(c *restful.Container, informerFactory informers.InformerFact
 	webservice.Route(webservice.GET("/components").
 		To(handler.handleGetComponents).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagComponentStatus}).
-		Doc("List the system components.").
+		Doc("List the system components, and the features of kubesphere enabled with their dependencies, versions and last errors.").
 		Returns(http.StatusOK, ok, []v1alpha2.ComponentStatus{}))
 	webservice.Route(webservice.GET("/components/{component}").
 		To(handler.handleGetComponentStatus).
 		Metadata(restfulspec.KeyOpenAPITags, []string{tagComponentStatus}).
-		Doc("Describe the specified system component.").
+		Doc("Describe the specified system component or feature, e.g. ks-apiserver, devops.").
 		Param(webservice.PathParameter("component", "component name")).
 		Returns(http.StatusOK, ok, v1alpha2.ComponentStatus{}))
 	webservice.Route(webservice.GET("/componenthealth").
@@ -117,6 +129,36 @@ func AddToContainer(c *restful.Container, informerFactory informers.InformerFact
 		Doc("Get the health status of system components.").
 		Returns(http.StatusOK, ok, v1alpha2.HealthStatus{}))
 
+	webservice.Route(webservice.GET("/abnormalworkloads").
+		To(handler.handleGetAbnormalWorkloads).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Diagnose the workloads of the cluster, and list the abnormal ones with the reasons, e.g. CrashLoopBackOff, ImagePullBackOff, OOMKilled.").
+		Param(webservice.QueryParameter(workloads.DiagnosisFilterKind, "kind of the workloads, e.g. Deployment, StatefulSet, DaemonSet, Job").Required(false)).
+		Param(webservice.QueryParameter(workloads.DiagnosisFilterReason, "reason of any problem of the workloads, e.g. reason=OOMKilled").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterPage, "page").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
+		Param(webservice.QueryParameter(query.ParameterLimit, "limit").Required(false)).
+		Returns(http.StatusOK, ok, workloads.DiagnosisResult{}))
+	webservice.Route(webservice.GET("/workspaces/{workspace}/abnormalworkloads").
+		To(handler.handleGetAbnormalWorkloads).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagClusteredResource}).
+		Doc("Diagnose the workloads of the namespaces of the workspace, and list the abnormal ones with the reasons.").
+		Param(webservice.PathParameter("workspace", "the name of the workspace")).
+		Param(webservice.QueryParameter(workloads.DiagnosisFilterKind, "kind of the workloads, e.g. Deployment, StatefulSet, DaemonSet, Job").Required(false)).
+		Param(webservice.QueryParameter(workloads.DiagnosisFilterReason, "reason of any problem of the workloads, e.g. reason=OOMKilled").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterPage, "page").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
+		Param(webservice.QueryParameter(query.ParameterLimit, "limit").Required(false)).
+		Returns(http.StatusOK, ok, workloads.DiagnosisResult{}))
+	webservice.Route(webservice.GET("/namespaces/{namespace}/abnormalworkloads").
+		To(handler.handleGetAbnormalWorkloads).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagNamespacedResource}).
+		Doc("Diagnose the workloads of the namespace, and list the abnormal ones with the reasons.").
+		Param(webservice.PathParameter("namespace", "the name of the project")).
+		Param(webservice.QueryParameter(workloads.DiagnosisFilterKind, "kind of the workloads, e.g. Deployment, StatefulSet, DaemonSet, Job").Required(false)).
+		Param(webservice.QueryParameter(workloads.DiagnosisFilterReason, "reason of any problem of the workloads, e.g. reason=OOMKilled").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterPage, "page").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
+		Param(webservice.QueryParameter(query.ParameterLimit, "limit").Required(false)).
+		Returns(http.StatusOK, ok, workloads.DiagnosisResult{}))
+
 	webservice.Route(webservice.POST("/namespaces/{namespace}/registrysecrets/{secret}/verify").
 		To(handler.handleVerifyImageRepositorySecret).
 		Param(webservice.PathParameter("namespace", "Namespace of the image repository secret to create.").Required(true)).
@@ -145,6 +187,45 @@ func AddToContainer(c *restful.Container, informerFactory informers.InformerFact
 		Doc("List repository tags, this is an experimental API, use it by your own caution.").
 		Returns(http.StatusOK, ok, v2.RepositoryTags{}))
 
+	webservice.Route(webservice.GET("/namespaces/{namespace}/imageregistries").
+		To(handler.handleListImageRegistries).
+		Param(webservice.PathParameter("namespace", "Namespace of the image repository secrets.").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagNamespacedResource}).
+		Doc("List the registries referenced by the image repository secrets of the namespace.").
+		Returns(http.StatusOK, ok, []v2.ImageRegistry{}))
+
+	webservice.Route(webservice.GET("/namespaces/{namespace}/imagerepositories").
+		To(handler.handleListImageRepositories).
+		Param(webservice.PathParameter("namespace", "Namespace of the image repository secret.").Required(true)).
+		Param(webservice.QueryParameter("secret", "Secret name of the image repository credential, the repositories of its registry are listed.").Required(false)).
+		Param(webservice.QueryParameter("q", "Keyword of the repository name.").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterPage, "page").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
+		Param(webservice.QueryParameter(query.ParameterLimit, "limit").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagNamespacedResource}).
+		Doc("List repositories of Docker Hub, Harbor or Distribution v2 registries.").
+		Returns(http.StatusOK, ok, v2.RepositoryList{}))
+
+	webservice.Route(webservice.GET("/namespaces/{namespace}/imagetags").
+		To(handler.handleListImageTags).
+		Param(webservice.PathParameter("namespace", "Namespace of the image repository secret.").Required(true)).
+		Param(webservice.QueryParameter("repository", "Repository to query, e.g. calico/cni.").Required(true)).
+		Param(webservice.QueryParameter("secret", "Secret name of the image repository credential, left empty means anonymous fetch.").Required(false)).
+		Param(webservice.QueryParameter("q", "Keyword of the tag name.").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterPage, "page").Required(false).DataFormat("page=%d").DefaultValue("page=1")).
+		Param(webservice.QueryParameter(query.ParameterLimit, "limit").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagNamespacedResource}).
+		Doc("List tags of the repository with their digests and push time.").
+		Returns(http.StatusOK, ok, v2.TagList{}))
+
+	webservice.Route(webservice.GET("/namespaces/{namespace}/imageinspection").
+		To(handler.handleInspectImage).
+		Param(webservice.PathParameter("namespace", "Namespace of the image repository secret.").Required(true)).
+		Param(webservice.QueryParameter("secret", "Secret name of the image repository credential, left empty means anonymous fetch.").Required(false)).
+		Param(webservice.QueryParameter("image", "Image name to inspect, e.g. kubesphere/ks-apiserver:v3.1.1").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagNamespacedResource}).
+		Doc("Inspect the layers, exposed ports, labels, SBOMs and vulnerabilities of the image.").
+		Returns(http.StatusOK, ok, v2.ImageInspection{}))
+
 	c.Add(webservice)
 
 	return nil
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/emicklei/go-restful/v3"
+	"k8s.io/apiserver/pkg/apis/audit"
+
+	"kubesphere.io/kubesphere/pkg/api"
+	"kubesphere.io/kubesphere/pkg/apiserver/request"
+	resourcev1beta1 "kubesphere.io/kubesphere/pkg/models/resources/v1beta1"
+)
+
+// The annotations of the audit event of a bulk request, which summarize the results.
+const (
+	auditAnnotationOperation = "bulk.kubesphere.io/operation"
+	auditAnnotationDryRun    = "bulk.kubesphere.io/dry-run"
+	auditAnnotationTotal     = "bulk.kubesphere.io/total"
+	auditAnnotationSucceeded = "bulk.kubesphere.io/succeeded"
+	auditAnnotationFailed    = "bulk.kubesphere.io/failed"
+)
+
+type handler struct {
+	bulkOperator resourcev1beta1.BulkOperator
+}
+
+func newHandler(bulkOperator resourcev1beta1.BulkOperator) *handler {
+	return &handler{bulkOperator: bulkOperator}
+}
+
+func (h *handler) handleBulk(req *restful.Request, resp *restful.Response) {
+	bulk := &resourcev1beta1.BulkRequest{}
+	if err := req.ReadEntity(bulk); err != nil {
+		api.HandleBadRequest(resp, req, err)
+		return
+	}
+
+	user, ok := request.UserFrom(req.Request.Context())
+	if !ok {
+		api.HandleUnauthorized(resp, req, fmt.Errorf("the user is not authenticated"))
+		return
+	}
+
+	result, err := h.bulkOperator.Do(req.Request.Context(), user, bulk)
+	if err != nil {
+		api.HandleError(resp, req, err)
+		return
+	}
+
+	// a single audit event of the request summarizes all the resources operated
+	if event := request.AuditEventFrom(req.Request.Context()); event != nil {
+		event.Verb = bulk.Operation
+		event.ObjectRef = &audit.ObjectReference{
+			APIGroup:   bulk.Group,
+			APIVersion: bulk.Version,
+			Resource:   bulk.Resource,
+			Namespace:  bulk.Namespace,
+		}
+		event.Annotations = map[string]string{
+			auditAnnotationOperation: bulk.Operation,
+			auditAnnotationDryRun:    strconv.FormatBool(bulk.DryRun),
+			auditAnnotationTotal:     strconv.Itoa(result.Total),
+			auditAnnotationSucceeded: strconv.Itoa(result.Succeeded),
+			auditAnnotationFailed:    strconv.Itoa(result.Failed),
+		}
+	}
+
+	resp.WriteEntity(result)
+}
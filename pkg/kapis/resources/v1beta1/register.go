@@ -0,0 +1,57 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"net/http"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"kubesphere.io/kubesphere/pkg/api"
+	"kubesphere.io/kubesphere/pkg/apiserver/authorization/authorizer"
+	"kubesphere.io/kubesphere/pkg/apiserver/runtime"
+	resourcev1beta1 "kubesphere.io/kubesphere/pkg/models/resources/v1beta1"
+)
+
+const (
+	GroupName = "resources.kubesphere.io"
+
+	tagBulkOperation = "Bulk Operation"
+
+	// BulkPath is authorized by the handler for each resource operated, instead of the request.
+	BulkPath = "/kapis/resources.kubesphere.io/v1beta1/bulk"
+)
+
+var GroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1beta1"}
+
+func AddToContainer(c *restful.Container, resourceManager resourcev1beta1.ResourceManager, authorizer authorizer.Authorizer) error {
+	webservice := runtime.NewWebService(GroupVersion)
+	handler := newHandler(resourcev1beta1.NewBulkOperator(resourceManager, authorizer))
+
+	webservice.Route(webservice.POST("/bulk").
+		To(handler.handleBulk).
+		Metadata(restfulspec.KeyOpenAPITags, []string{tagBulkOperation}).
+		Doc("Delete, patch, scale or restart the resources listed or selected by the label selector, each resource is authorized "+
+			"and operated separately, and the result of each resource is returned.").
+		Reads(resourcev1beta1.BulkRequest{}).
+		Returns(http.StatusOK, api.StatusOK, resourcev1beta1.BulkResult{}))
+
+	c.Add(webservice)
+	return nil
+}
@@ -85,6 +85,16 @@ func (h *tenantHandler) ListWorkspaceTemplates(req *restful.Request, resp *restf
 		return
 	}
 
+	if queryParam.Watch {
+		watcher, err := h.tenant.WatchWorkspaceTemplates(user, queryParam)
+		if err != nil {
+			api.HandleError(resp, req, err)
+			return
+		}
+		api.ServeWatch(watcher, req, resp)
+		return
+	}
+
 	result, err := h.tenant.ListWorkspaceTemplates(user, queryParam)
 
 	if err != nil {
@@ -136,6 +146,16 @@ func (h *tenantHandler) ListNamespaces(req *restful.Request, resp *restful.Respo
 		workspaceMember = requestUser
 	}
 
+	if queryParam.Watch {
+		watcher, err := h.tenant.WatchNamespaces(workspaceMember, workspace, queryParam)
+		if err != nil {
+			api.HandleError(resp, req, err)
+			return
+		}
+		api.ServeWatch(watcher, req, resp)
+		return
+	}
+
 	result, err := h.tenant.ListNamespaces(workspaceMember, workspace, queryParam)
 	if err != nil {
 		api.HandleInternalError(resp, nil, err)
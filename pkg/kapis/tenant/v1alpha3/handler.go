@@ -73,6 +73,16 @@ func (h *tenantHandler) ListWorkspaces(req *restful.Request, resp *restful.Respo
 		return
 	}
 
+	if queryParam.Watch {
+		watcher, err := h.tenant.WatchWorkspaces(user, queryParam)
+		if err != nil {
+			api.HandleError(resp, req, err)
+			return
+		}
+		api.ServeWatch(watcher, req, resp)
+		return
+	}
+
 	result, err := h.tenant.ListWorkspaces(user, queryParam)
 	if err != nil {
 		api.HandleInternalError(resp, nil, err)
@@ -18,9 +18,11 @@ package v1alpha2
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"kubesphere.io/kubesphere/pkg/api"
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
 	"kubesphere.io/kubesphere/pkg/apiserver/authorization/authorizer"
 	requestctx "kubesphere.io/kubesphere/pkg/apiserver/request"
 
@@ -30,6 +32,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
+	"kubesphere.io/kubesphere/pkg/models/auth"
 	"kubesphere.io/kubesphere/pkg/models/terminal"
 )
 
@@ -45,10 +48,11 @@ type terminalHandler struct {
 	authorizer authorizer.Authorizer
 }
 
-func newTerminalHandler(client kubernetes.Interface, authorizer authorizer.Authorizer, config *rest.Config, options *terminal.Options) *terminalHandler {
+func newTerminalHandler(client kubernetes.Interface, authorizer authorizer.Authorizer, config *rest.Config, options *terminal.Options,
+	tokenOperator auth.TokenManagementInterface) *terminalHandler {
 	return &terminalHandler{
 		authorizer: authorizer,
-		terminaler: terminal.NewTerminaler(client, config, options),
+		terminaler: terminal.NewTerminaler(client, config, options, tokenOperator),
 	}
 }
 
@@ -123,3 +127,28 @@ func (t *terminalHandler) handleShellAccessToNode(request *restful.Request, resp
 
 	t.terminaler.HandleShellAccessToNode(nodename, conn)
 }
+
+// handleKubectlSession connects the terminal to an ephemeral kubectl pod of the user,
+// which accesses the cluster as the user, so that no other authorization is required.
+func (t *terminalHandler) handleKubectlSession(request *restful.Request, response *restful.Response) {
+	username := request.PathParameter("user")
+
+	user, ok := requestctx.UserFrom(request.Request.Context())
+	if !ok || user.GetName() != username {
+		api.HandleForbidden(response, request, fmt.Errorf("the kubectl session of user %s is not allowed", username))
+		return
+	}
+	// the session gets an unscoped token of the user, the scopes of the personal access tokens would be escalated
+	if len(user.GetExtra()[token.ExtraTokenType]) > 0 {
+		api.HandleForbidden(response, request, terminal.ErrKubectlSessionScopedToken)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(response.ResponseWriter, request.Request, nil)
+	if err != nil {
+		klog.Warning(err)
+		return
+	}
+
+	t.terminaler.HandleKubectlSession(user, conn)
+}
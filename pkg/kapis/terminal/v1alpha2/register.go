@@ -28,6 +28,7 @@ import (
 	"kubesphere.io/kubesphere/pkg/apiserver/runtime"
 	"kubesphere.io/kubesphere/pkg/constants"
 	"kubesphere.io/kubesphere/pkg/models"
+	"kubesphere.io/kubesphere/pkg/models/auth"
 	"kubesphere.io/kubesphere/pkg/models/terminal"
 )
 
@@ -37,11 +38,12 @@ const (
 
 var GroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1alpha2"}
 
-func AddToContainer(c *restful.Container, client kubernetes.Interface, authorizer authorizer.Authorizer, config *rest.Config, options *terminal.Options) error {
+func AddToContainer(c *restful.Container, client kubernetes.Interface, authorizer authorizer.Authorizer, config *rest.Config, options *terminal.Options,
+	tokenOperator auth.TokenManagementInterface) error {
 
 	webservice := runtime.NewWebService(GroupVersion)
 
-	handler := newTerminalHandler(client, authorizer, config, options)
+	handler := newTerminalHandler(client, authorizer, config, options, tokenOperator)
 
 	webservice.Route(webservice.GET("/namespaces/{namespace}/pods/{pod}/exec").
 		To(handler.handleTerminalSession).
@@ -59,6 +61,12 @@ func AddToContainer(c *restful.Container, client kubernetes.Interface, authorize
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.TerminalTag}).
 		Writes(models.PodInfo{}))
 
+	webservice.Route(webservice.GET("/users/{user}/kubectl").
+		To(handler.handleKubectlSession).
+		Param(webservice.PathParameter("user", "the name of the user, who must be the current user")).
+		Doc("create a kubectl session in an ephemeral pod with the kubeconfig of the user, the pod is deleted once the session is closed").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.TerminalTag}))
+
 	c.Add(webservice)
 
 	return nil
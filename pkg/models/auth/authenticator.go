@@ -33,6 +33,8 @@ import (
 	"k8s.io/klog/v2"
 
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider"
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
+	"kubesphere.io/kubesphere/pkg/models/iam/group"
 
 	iamv1alpha2 "kubesphere.io/api/iam/v1alpha2"
 
@@ -43,6 +45,7 @@ var (
 	RateLimitExceededError  = fmt.Errorf("auth rate limit exceeded")
 	IncorrectPasswordError  = fmt.Errorf("incorrect password")
 	AccountIsNotActiveError = fmt.Errorf("account is not active")
+	AccountIsLockedError    = fmt.Errorf("account is locked")
 )
 
 // PasswordAuthenticator is an interface implemented by authenticator which take a
@@ -94,6 +97,21 @@ func mappedUser(idp string, identity identityprovider.Identity) *iamv1alpha2.Use
 	}
 }
 
+// syncGroups synchronizes the groups of the identity to GroupBindings, the login will not be
+// blocked if the synchronization failed.
+func syncGroups(synchronizer group.Synchronizer, providerOptions *oauth.IdentityProviderOptions, username string, identity identityprovider.Identity) {
+	if providerOptions.GroupSync == nil {
+		return
+	}
+	groupIdentity, ok := identity.(identityprovider.GroupIdentity)
+	if !ok || groupIdentity.GetGroups() == nil {
+		return
+	}
+	if err := synchronizer.SyncUserGroups(providerOptions.Name, providerOptions.GroupSync, username, groupIdentity.GetGroups()); err != nil {
+		klog.Errorf("failed to synchronize groups of user %s from identity provider %s: %v", username, providerOptions.Name, err)
+	}
+}
+
 // findUser returns the user associated with the username or email
 func (u *userGetter) findUser(username string) (*iamv1alpha2.User, error) {
 	if _, err := mail.ParseAddress(username); err != nil {
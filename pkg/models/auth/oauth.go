@@ -36,21 +36,24 @@ import (
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication/identityprovider"
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
 	iamv1alpha2listers "kubesphere.io/kubesphere/pkg/client/listers/iam/v1alpha2"
+	"kubesphere.io/kubesphere/pkg/models/iam/group"
 )
 
 type oauthAuthenticator struct {
-	ksClient   kubesphere.Interface
-	userGetter *userGetter
-	options    *authentication.Options
+	ksClient          kubesphere.Interface
+	userGetter        *userGetter
+	groupSynchronizer group.Synchronizer
+	options           *authentication.Options
 }
 
 func NewOAuthAuthenticator(ksClient kubesphere.Interface,
 	userLister iamv1alpha2listers.UserLister,
 	options *authentication.Options) OAuthAuthenticator {
 	authenticator := &oauthAuthenticator{
-		ksClient:   ksClient,
-		userGetter: &userGetter{userLister: userLister},
-		options:    options,
+		ksClient:          ksClient,
+		userGetter:        &userGetter{userLister: userLister},
+		groupSynchronizer: group.NewSynchronizer(ksClient),
+		options:           options,
 	}
 	return authenticator
 }
@@ -95,6 +98,7 @@ func (o *oauthAuthenticator) Authenticate(_ context.Context, provider string, re
 			// state not active
 			return nil, "", AccountIsNotActiveError
 		}
+		syncGroups(o.groupSynchronizer, providerOptions, user.GetName(), authenticated)
 		return &authuser.DefaultInfo{Name: user.GetName()}, providerOptions.Name, nil
 	}
 
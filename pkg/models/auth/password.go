@@ -20,6 +20,7 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -34,21 +35,24 @@ import (
 	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
 	kubesphere "kubesphere.io/kubesphere/pkg/client/clientset/versioned"
 	iamv1alpha2listers "kubesphere.io/kubesphere/pkg/client/listers/iam/v1alpha2"
+	"kubesphere.io/kubesphere/pkg/models/iam/group"
 )
 
 type passwordAuthenticator struct {
-	ksClient    kubesphere.Interface
-	userGetter  *userGetter
-	authOptions *authentication.Options
+	ksClient          kubesphere.Interface
+	userGetter        *userGetter
+	groupSynchronizer group.Synchronizer
+	authOptions       *authentication.Options
 }
 
 func NewPasswordAuthenticator(ksClient kubesphere.Interface,
 	userLister iamv1alpha2listers.UserLister,
 	options *authentication.Options) PasswordAuthenticator {
 	passwordAuthenticator := &passwordAuthenticator{
-		ksClient:    ksClient,
-		userGetter:  &userGetter{userLister: userLister},
-		authOptions: options,
+		ksClient:          ksClient,
+		userGetter:        &userGetter{userLister: userLister},
+		groupSynchronizer: group.NewSynchronizer(ksClient),
+		authOptions:       options,
 	}
 	return passwordAuthenticator
 }
@@ -80,6 +84,9 @@ func (p *passwordAuthenticator) authByKubeSphere(username, password string) (aut
 		if user.Status.State == iamv1alpha2.UserAuthLimitExceeded {
 			klog.Errorf("%s, username: %s", RateLimitExceededError, username)
 			return nil, "", RateLimitExceededError
+		} else if user.Status.State == iamv1alpha2.UserLocked {
+			klog.Errorf("%s, username: %s", AccountIsLockedError, username)
+			return nil, "", AccountIsLockedError
 		} else {
 			// state not active
 			klog.Errorf("%s, username: %s", AccountIsNotActiveError, username)
@@ -103,12 +110,39 @@ func (p *passwordAuthenticator) authByKubeSphere(username, password string) (aut
 				iamv1alpha2.ExtraUninitialized: {uninitialized},
 			}
 		}
+		// the expired password must be changed after login
+		if p.passwordExpired(user) {
+			if u.Extra == nil {
+				u.Extra = make(map[string][]string)
+			}
+			u.Extra[iamv1alpha2.ExtraPasswordExpired] = []string{"true"}
+		}
 		return u, "", nil
 	}
 
 	return nil, "", IncorrectPasswordError
 }
 
+// passwordExpired returns whether the password of user is expired according to the password policy
+func (p *passwordAuthenticator) passwordExpired(user *iamv1alpha2.User) bool {
+	return p.authOptions.PasswordPolicy.Expired(lastPasswordChange(user), time.Now())
+}
+
+// PasswordChangedSince returns whether the password of user is changed at or after the given time,
+// the expired password recorded in the tokens issued before that is stale.
+func PasswordChangedSince(user *iamv1alpha2.User, since time.Time) bool {
+	return !lastPasswordChange(user).Before(since)
+}
+
+func lastPasswordChange(user *iamv1alpha2.User) time.Time {
+	if value := user.Annotations[iamv1alpha2.LastPasswordChangeTimeAnnotation]; value != "" {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t
+		}
+	}
+	return user.CreationTimestamp.Time
+}
+
 // authByProvider authenticate by the third-party identity provider user
 func (p *passwordAuthenticator) authByProvider(provider, username, password string) (authuser.Info, string, error) {
 	providerOptions, err := p.authOptions.OAuthOptions.IdentityProviderOptions(provider)
@@ -137,6 +171,7 @@ func (p *passwordAuthenticator) authByProvider(provider, username, password stri
 	}
 
 	if linkedAccount != nil {
+		syncGroups(p.groupSynchronizer, providerOptions, linkedAccount.Name, authenticated)
 		return &authuser.DefaultInfo{Name: linkedAccount.Name}, provider, nil
 	}
 
@@ -150,6 +185,7 @@ func (p *passwordAuthenticator) authByProvider(provider, username, password stri
 			klog.Error(err)
 			return nil, "", err
 		}
+		syncGroups(p.groupSynchronizer, providerOptions, linkedAccount.Name, authenticated)
 		return &authuser.DefaultInfo{Name: linkedAccount.Name}, provider, nil
 	}
 
@@ -22,12 +22,14 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"kubesphere.io/kubesphere/pkg/server/options"
 
 	"github.com/mitchellh/mapstructure"
 	"golang.org/x/crypto/bcrypt"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apiserver/pkg/authentication/user"
 	authuser "k8s.io/apiserver/pkg/authentication/user"
 	iamv1alpha2 "kubesphere.io/api/iam/v1alpha2"
@@ -50,6 +52,18 @@ func TestEncryptPassword(t *testing.T) {
 	}
 }
 
+func TestPasswordChangedSince(t *testing.T) {
+	issuedAt := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	u := &iamv1alpha2.User{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(issuedAt.AddDate(0, 0, -1))}}
+	if PasswordChangedSince(u, issuedAt) {
+		t.Errorf("the password is not changed after the user was created")
+	}
+	u.Annotations = map[string]string{iamv1alpha2.LastPasswordChangeTimeAnnotation: issuedAt.Add(time.Hour).Format(time.RFC3339)}
+	if !PasswordChangedSince(u, issuedAt) {
+		t.Errorf("the password is changed after the token was issued")
+	}
+}
+
 func hashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
 	return string(bytes), err
@@ -0,0 +1,219 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication"
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
+	"kubesphere.io/kubesphere/pkg/apiserver/authorization/scope"
+	"kubesphere.io/kubesphere/pkg/simple/client/cache"
+)
+
+var personalAccessTokenResource = schema.GroupResource{Group: "iam.kubesphere.io", Resource: "personalaccesstokens"}
+
+// PersonalAccessToken is a long-lived api token owned by a user.
+type PersonalAccessToken struct {
+	// ID is the unique identifier of the token, it's also used as the jti claim of the token.
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	// Scopes restrict the requests that can be performed with the token, empty means no restriction.
+	Scopes              []string   `json:"scopes,omitempty"`
+	CreationTimestamp   time.Time  `json:"creationTimestamp"`
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+	// Token is only returned once when the token is created.
+	Token string `json:"token,omitempty"`
+}
+
+type PersonalAccessTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes,omitempty"`
+	// ExpiresIn is the lifetime of the token in seconds, 0 means never expire.
+	ExpiresIn int64 `json:"expiresIn,omitempty"`
+}
+
+// PersonalAccessTokenOperator manages the personal access tokens of users,
+// the tokens are verified by TokenManagementInterface.Verify.
+type PersonalAccessTokenOperator interface {
+	// Create issues a personal access token for the specified user
+	Create(username string, request *PersonalAccessTokenRequest) (*PersonalAccessToken, error)
+	// List lists all the valid personal access tokens of the specified user
+	List(username string) ([]PersonalAccessToken, error)
+	// Get retrieves the specified personal access token
+	Get(username, id string) (*PersonalAccessToken, error)
+	// Revoke revokes the specified personal access token
+	Revoke(username, id string) error
+}
+
+type personalAccessTokenOperator struct {
+	issuer  token.Issuer
+	options *authentication.Options
+	cache   cache.Interface
+}
+
+func NewPersonalAccessTokenOperator(cache cache.Interface, issuer token.Issuer, options *authentication.Options) PersonalAccessTokenOperator {
+	return &personalAccessTokenOperator{
+		issuer:  issuer,
+		options: options,
+		cache:   cache,
+	}
+}
+
+func (p *personalAccessTokenOperator) Create(username string, request *PersonalAccessTokenRequest) (*PersonalAccessToken, error) {
+	if request.Name == "" {
+		return nil, errors.NewBadRequest("token name must not be empty")
+	}
+	if _, err := scope.Parse(request.Scopes); err != nil {
+		return nil, errors.NewBadRequest(err.Error())
+	}
+	if request.ExpiresIn < 0 {
+		return nil, errors.NewBadRequest("expiresIn must not be negative")
+	}
+	expiresIn := time.Duration(request.ExpiresIn) * time.Second
+	if maxAge := p.options.PersonalAccessTokenMaxAge; maxAge > 0 && (expiresIn == 0 || expiresIn > maxAge) {
+		return nil, errors.NewBadRequest(fmt.Sprintf("the lifetime of token must not be greater than %s", maxAge))
+	}
+
+	tokens, err := p.List(username)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range tokens {
+		if item.Name == request.Name {
+			return nil, errors.NewAlreadyExists(personalAccessTokenResource, request.Name)
+		}
+	}
+
+	now := time.Now()
+	pat := &PersonalAccessToken{
+		ID:                rand.String(16),
+		Name:              request.Name,
+		Username:          username,
+		Scopes:            request.Scopes,
+		CreationTimestamp: now,
+	}
+	if expiresIn > 0 {
+		expiration := now.Add(expiresIn)
+		pat.ExpirationTimestamp = &expiration
+	}
+
+	issueRequest := &token.IssueRequest{
+		User:      &user.DefaultInfo{Name: username},
+		ExpiresIn: expiresIn,
+		Claims: token.Claims{
+			TokenType: token.PersonalAccessToken,
+			Scopes:    request.Scopes,
+			Name:      request.Name,
+		},
+	}
+	issueRequest.ID = pat.ID
+	tokenStr, err := p.issuer.IssueTo(issueRequest)
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	data, err := json.Marshal(pat)
+	if err != nil {
+		return nil, err
+	}
+	if err = p.cache.Set(personalAccessTokenCacheKey(username, pat.ID), string(data), expiresIn); err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	pat.Token = tokenStr
+	return pat, nil
+}
+
+func (p *personalAccessTokenOperator) List(username string) ([]PersonalAccessToken, error) {
+	keys, err := p.cache.Keys(personalAccessTokenCacheKey(username, "*"))
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	tokens := make([]PersonalAccessToken, 0, len(keys))
+	for _, key := range keys {
+		pat, err := p.Get(username, strings.TrimPrefix(key, personalAccessTokenCacheKey(username, "")))
+		if err != nil {
+			// the token may expire between Keys and Get
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		tokens = append(tokens, *pat)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreationTimestamp.After(tokens[j].CreationTimestamp)
+	})
+	return tokens, nil
+}
+
+func (p *personalAccessTokenOperator) Get(username, id string) (*PersonalAccessToken, error) {
+	key := personalAccessTokenCacheKey(username, id)
+	if exist, err := p.cache.Exists(key); err != nil {
+		klog.Error(err)
+		return nil, err
+	} else if !exist {
+		return nil, errors.NewNotFound(personalAccessTokenResource, id)
+	}
+	data, err := p.cache.Get(key)
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	pat := &PersonalAccessToken{}
+	if err = json.Unmarshal([]byte(data), pat); err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	return pat, nil
+}
+
+func (p *personalAccessTokenOperator) Revoke(username, id string) error {
+	key := personalAccessTokenCacheKey(username, id)
+	if exist, err := p.cache.Exists(key); err != nil {
+		klog.Error(err)
+		return err
+	} else if !exist {
+		return errors.NewNotFound(personalAccessTokenResource, id)
+	}
+	if err := p.cache.Del(key); err != nil {
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+// personalAccessTokenCacheKey is separated from the session tokens, so that the personal access tokens
+// will not be revoked when the user logs out.
+func personalAccessTokenCacheKey(username, id string) string {
+	return fmt.Sprintf("kubesphere:user:%s:personalaccesstoken:%s", username, id)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication"
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
+	"kubesphere.io/kubesphere/pkg/simple/client/cache"
+)
+
+func TestPersonalAccessTokenOperator(t *testing.T) {
+	options := &authentication.Options{
+		MaximumClockSkew:          10 * time.Second,
+		JwtSecret:                 "test-secret",
+		PersonalAccessTokenMaxAge: 24 * time.Hour,
+		OAuthOptions: &oauth.Options{
+			Issuer:            "kubesphere",
+			AccessTokenMaxAge: time.Hour,
+		},
+	}
+	issuer, err := token.NewIssuer(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheClient, err := cache.NewInMemoryCache(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := NewPersonalAccessTokenOperator(cacheClient, issuer, options)
+	tokenOperator := NewTokenOperator(cacheClient, issuer, options)
+
+	if _, err = operator.Create("admin", &PersonalAccessTokenRequest{Name: "ci", ExpiresIn: 48 * 3600}); !errors.IsBadRequest(err) {
+		t.Errorf("expected bad request when the lifetime exceeds the limit, got %v", err)
+	}
+	if _, err = operator.Create("admin", &PersonalAccessTokenRequest{Name: "ci", ExpiresIn: 3600, Scopes: []string{"all"}}); !errors.IsBadRequest(err) {
+		t.Errorf("expected bad request for invalid scopes, got %v", err)
+	}
+
+	created, err := operator.Create("admin", &PersonalAccessTokenRequest{Name: "ci", ExpiresIn: 3600, Scopes: []string{"read-only"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Token == "" || created.ExpirationTimestamp == nil {
+		t.Fatalf("unexpected token: %+v", created)
+	}
+	if _, err = operator.Create("admin", &PersonalAccessTokenRequest{Name: "ci", ExpiresIn: 3600}); !errors.IsAlreadyExists(err) {
+		t.Errorf("expected already exists error, got %v", err)
+	}
+
+	verified, err := tokenOperator.Verify(created.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified.TokenType != token.PersonalAccessToken || verified.ID != created.ID || verified.Scopes[0] != "read-only" {
+		t.Errorf("unexpected verified response: %+v", verified)
+	}
+
+	// logout must not revoke the personal access tokens
+	if err = tokenOperator.RevokeAllUserTokens("admin"); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := operator.List("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].ID != created.ID || tokens[0].Token != "" {
+		t.Errorf("unexpected tokens: %+v", tokens)
+	}
+
+	if err = operator.Revoke("admin", created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tokenOperator.Verify(created.Token); err == nil {
+		t.Errorf("expected revoked token to be rejected")
+	}
+	if _, err = operator.Get("admin", created.ID); !errors.IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestPersonalAccessTokenWithoutID(t *testing.T) {
+	options := &authentication.Options{
+		JwtSecret:    "test-secret",
+		OAuthOptions: &oauth.Options{Issuer: "kubesphere"},
+	}
+	issuer, err := token.NewIssuer(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheClient, err := cache.NewInMemoryCache(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenStr, err := issuer.IssueTo(&token.IssueRequest{
+		User:   &user.DefaultInfo{Name: "admin"},
+		Claims: token.Claims{TokenType: token.PersonalAccessToken},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewTokenOperator(cacheClient, issuer, options).Verify(tokenStr); err == nil {
+		t.Errorf("expected forged personal access token to be rejected")
+	}
+}
@@ -79,6 +79,12 @@ func (t *tokenOperator) Verify(tokenStr string) (*token.VerifiedResponse, error)
 	if err != nil {
 		return nil, err
 	}
+	if response.TokenType == token.PersonalAccessToken {
+		if err := t.personalAccessTokenValidate(response.User.GetName(), response.ID); err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
 	if t.options.OAuthOptions.AccessTokenMaxAge == 0 ||
 		response.TokenType == token.StaticToken {
 		return response, nil
@@ -136,6 +142,21 @@ func (t *tokenOperator) tokenCacheValidate(username, token string) error {
 	return nil
 }
 
+// personalAccessTokenValidate verify that the personal access token has not been revoked
+func (t *tokenOperator) personalAccessTokenValidate(username, id string) error {
+	if id == "" {
+		return errors.New("personal access token without id")
+	}
+	if exist, err := t.cache.Exists(personalAccessTokenCacheKey(username, id)); err != nil {
+		return err
+	} else if !exist {
+		err = errors.New("personal access token not found in cache")
+		klog.V(4).Info(fmt.Errorf("%s: %s", err, id))
+		return err
+	}
+	return nil
+}
+
 // cacheToken cache the token for a period of time
 func (t *tokenOperator) cacheToken(username, token string, duration time.Duration) error {
 	key := fmt.Sprintf("kubesphere:user:%s:token:%s", username, token)
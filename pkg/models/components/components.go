@@ -36,10 +36,12 @@ type ComponentsGetter interface {
 
 type componentsGetter struct {
 	informers informers.SharedInformerFactory
+	// features are the features of kubesphere enabled in the config, nil if they are not reported
+	features *FeatureChecker
 }
 
-func NewComponentsGetter(informers informers.SharedInformerFactory) ComponentsGetter {
-	return &componentsGetter{informers: informers}
+func NewComponentsGetter(informers informers.SharedInformerFactory, features *FeatureChecker) ComponentsGetter {
+	return &componentsGetter{informers: informers, features: features}
 }
 
 func (c *componentsGetter) GetComponentStatus(name string) (v1alpha2.ComponentStatus, error) {
@@ -55,6 +57,11 @@ func (c *componentsGetter) GetComponentStatus(name string) (v1alpha2.ComponentSt
 	}
 
 	if err != nil {
+		if c.features != nil {
+			if component, ok := c.features.Status(name); ok {
+				return component, nil
+			}
+		}
 		return v1alpha2.ComponentStatus{}, err
 	}
 
@@ -179,5 +186,9 @@ func (c *componentsGetter) GetAllComponentsStatus() ([]v1alpha2.ComponentStatus,
 		}
 	}
 
+	if c.features != nil {
+		components = append(components, c.features.Statuses()...)
+	}
+
 	return components, err
 }
@@ -244,7 +244,7 @@ func TestGetSystemHealthStatus(t *testing.T) {
 				informer.Core().V1().Nodes().Informer().GetIndexer().Add(obj)
 			}
 
-			c := NewComponentsGetter(informer)
+			c := NewComponentsGetter(informer, nil)
 			healthStatus, err := c.GetSystemHealthStatus()
 			if err != nil {
 				t.Fatal(err)
@@ -357,7 +357,7 @@ func TestGetComponentStatus(t *testing.T) {
 				informer.Core().V1().Pods().Informer().GetIndexer().Add(obj)
 			}
 
-			c := NewComponentsGetter(informer)
+			c := NewComponentsGetter(informer, nil)
 			healthStatus, err := c.GetComponentStatus(test.name)
 			if err == nil && test.expectedError {
 				t.Fatalf("expected error while got nothing")
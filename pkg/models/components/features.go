@@ -0,0 +1,309 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/kubesphere/pkg/api/resource/v1alpha2"
+	"kubesphere.io/kubesphere/pkg/server/healthz"
+)
+
+const (
+	// ComponentKindFeature is the kind of the components of the features enabled in the config.
+	ComponentKindFeature = "feature"
+
+	versionLabel = "app.kubernetes.io/version"
+)
+
+// Feature is a feature of kubesphere enabled in the config, e.g. devops, logging.
+type Feature struct {
+	Name string
+	// Namespace the workloads of the feature run in, the feature has no workloads if empty.
+	Namespace string
+	// Selector of the workloads in the namespace.
+	Selector      labels.Selector
+	LatestVersion string
+	// Dependencies are the backends the feature depends on, e.g. elasticsearch of logging.
+	Dependencies []healthz.HealthChecker
+}
+
+// NewFeature creates a feature with its options, which are nil for the features without workloads.
+func NewFeature(name string, options *FeatureOptions, dependencies ...healthz.HealthChecker) Feature {
+	feature := Feature{Name: name, Selector: labels.Everything(), Dependencies: dependencies}
+	if options != nil {
+		feature.Namespace = options.Namespace
+		feature.LatestVersion = options.LatestVersion
+		if selector, err := labels.Parse(options.Selector); err == nil {
+			feature.Selector = selector
+		}
+	}
+	return feature
+}
+
+// featureState is the result of the last check of a feature.
+type featureState struct {
+	dependencies  []v1alpha2.DependencyStatus
+	lastError     string
+	lastErrorTime *time.Time
+	lastCheckTime *time.Time
+}
+
+// FeatureChecker checks the dependencies and the workloads of the features periodically,
+// and reports the status of the features with their versions.
+type FeatureChecker struct {
+	pods     corev1listers.PodLister
+	interval time.Duration
+	features []Feature
+
+	mutex  sync.RWMutex
+	states map[string]*featureState
+}
+
+func NewFeatureChecker(informers informers.SharedInformerFactory, interval time.Duration, features ...Feature) *FeatureChecker {
+	initMetrics.Do(registerMetrics)
+	for i := range features {
+		if features[i].Selector == nil {
+			features[i].Selector = labels.Everything()
+		}
+	}
+	return &FeatureChecker{
+		pods:     informers.Core().V1().Pods().Lister(),
+		interval: interval,
+		features: features,
+		states:   make(map[string]*featureState),
+	}
+}
+
+// Run checks the features until the stopCh is closed.
+func (c *FeatureChecker) Run(stopCh <-chan struct{}) {
+	wait.Until(c.Check, c.interval, stopCh)
+}
+
+// Check checks all the features concurrently, and updates the metrics.
+func (c *FeatureChecker) Check() {
+	var wg sync.WaitGroup
+	for i := range c.features {
+		wg.Add(1)
+		go func(feature *Feature) {
+			defer wg.Done()
+			c.check(feature)
+		}(&c.features[i])
+	}
+	wg.Wait()
+	c.updateMetrics()
+}
+
+func (c *FeatureChecker) check(feature *Feature) {
+	var lastError string
+	dependencies := make([]v1alpha2.DependencyStatus, 0, len(feature.Dependencies))
+	for _, dependency := range feature.Dependencies {
+		status := v1alpha2.DependencyStatus{Name: dependency.Name(), Reachable: true}
+		if err := checkDependency(dependency); err != nil {
+			status.Reachable = false
+			status.Error = err.Error()
+			if lastError == "" {
+				lastError = fmt.Sprintf("dependency %s is unreachable: %v", dependency.Name(), err)
+			}
+		}
+		dependencies = append(dependencies, status)
+	}
+	if component := c.workloadStatus(feature); lastError == "" && component.HealthyBackends < component.TotalBackends {
+		lastError = fmt.Sprintf("%d of %d pods in namespace %s are not ready",
+			component.TotalBackends-component.HealthyBackends, component.TotalBackends, feature.Namespace)
+	}
+	if lastError != "" {
+		klog.V(4).Infof("feature %s is unhealthy: %s", feature.Name, lastError)
+	}
+
+	now := time.Now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	state, ok := c.states[feature.Name]
+	if !ok {
+		state = &featureState{}
+		c.states[feature.Name] = state
+	}
+	state.dependencies = dependencies
+	state.lastCheckTime = &now
+	// the last error is kept after the feature recovers
+	if lastError != "" {
+		state.lastError = lastError
+		state.lastErrorTime = &now
+	}
+}
+
+func checkDependency(dependency healthz.HealthChecker) error {
+	ctx, cancel := context.WithTimeout(context.Background(), healthz.CheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	if err != nil {
+		return err
+	}
+	return dependency.Check(req)
+}
+
+// Status returns the status of the feature, false if the feature is not enabled.
+func (c *FeatureChecker) Status(name string) (v1alpha2.ComponentStatus, bool) {
+	for i := range c.features {
+		if c.features[i].Name == name {
+			return c.status(&c.features[i]), true
+		}
+	}
+	return v1alpha2.ComponentStatus{}, false
+}
+
+// Statuses returns the status of all the features enabled.
+func (c *FeatureChecker) Statuses() []v1alpha2.ComponentStatus {
+	statuses := make([]v1alpha2.ComponentStatus, 0, len(c.features))
+	for i := range c.features {
+		statuses = append(statuses, c.status(&c.features[i]))
+	}
+	return statuses
+}
+
+func (c *FeatureChecker) status(feature *Feature) v1alpha2.ComponentStatus {
+	component := c.workloadStatus(feature)
+	component.UpgradeAvailable = upgradeAvailable(component.Version, feature.LatestVersion)
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if state, ok := c.states[feature.Name]; ok {
+		component.Dependencies = state.dependencies
+		component.LastError = state.lastError
+		component.LastErrorTime = state.lastErrorTime
+		component.LastCheckTime = state.lastCheckTime
+	}
+	return component
+}
+
+// workloadStatus counts the ready pods of the feature, and reads the version of the feature from them.
+func (c *FeatureChecker) workloadStatus(feature *Feature) v1alpha2.ComponentStatus {
+	component := v1alpha2.ComponentStatus{
+		Name:          feature.Name,
+		Namespace:     feature.Namespace,
+		Kind:          ComponentKindFeature,
+		LatestVersion: feature.LatestVersion,
+	}
+	if feature.Namespace == "" {
+		return component
+	}
+	if !feature.Selector.Empty() {
+		component.Label = feature.Selector.String()
+	}
+
+	pods, err := c.pods.Pods(feature.Namespace).List(feature.Selector)
+	if err != nil {
+		klog.Errorln(err)
+		return component
+	}
+	versions := make(map[string]int)
+	for _, pod := range pods {
+		component.TotalBackends++
+		if pod.Status.Phase == corev1.PodRunning && isAllContainersReady(pod) {
+			component.HealthyBackends++
+		}
+		if component.StartedAt.IsZero() || pod.CreationTimestamp.Time.Before(component.StartedAt) {
+			component.StartedAt = pod.CreationTimestamp.Time
+		}
+		if version := podVersion(pod); version != "" {
+			versions[version]++
+		}
+	}
+	// the version of most of the pods, as the pods of a feature may run different images, e.g. sidecars
+	for version, count := range versions {
+		if count > versions[component.Version] || (count == versions[component.Version] && version > component.Version) {
+			component.Version = version
+		}
+	}
+	return component
+}
+
+// podVersion returns the version label of the pod, or the image tag of its first container.
+func podVersion(pod *corev1.Pod) string {
+	if version := pod.Labels[versionLabel]; version != "" {
+		return version
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return ""
+	}
+	image := strings.SplitN(pod.Spec.Containers[0].Image, "@", 2)[0]
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// upgradeAvailable returns true if the version running is older than the latest version,
+// the versions not following semver, e.g. latest, are never upgradable.
+func upgradeAvailable(version, latestVersion string) bool {
+	if version == "" || latestVersion == "" {
+		return false
+	}
+	current, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	latest, err := semver.NewVersion(latestVersion)
+	if err != nil {
+		return false
+	}
+	return current.LessThan(latest)
+}
+
+// isHealthy returns true if the dependencies of the feature are reachable and its pods are ready.
+func isHealthy(component v1alpha2.ComponentStatus) bool {
+	for _, dependency := range component.Dependencies {
+		if !dependency.Reachable {
+			return false
+		}
+	}
+	return component.HealthyBackends == component.TotalBackends
+}
+
+func (c *FeatureChecker) updateMetrics() {
+	componentInfo.Reset()
+	componentDependencyReachable.Reset()
+	for _, component := range c.Statuses() {
+		componentInfo.WithLabelValues(component.Name, component.Version, component.LatestVersion).Set(1)
+		componentHealthy.WithLabelValues(component.Name).Set(boolToFloat64(isHealthy(component)))
+		componentUpgradeAvailable.WithLabelValues(component.Name).Set(boolToFloat64(component.UpgradeAvailable))
+		for _, dependency := range component.Dependencies {
+			componentDependencyReachable.WithLabelValues(component.Name, dependency.Name).Set(boolToFloat64(dependency.Reachable))
+		}
+	}
+}
+
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
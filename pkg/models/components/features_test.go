@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"kubesphere.io/kubesphere/pkg/server/healthz"
+)
+
+func featurePod(name, namespace, image string, labels map[string]string, ready bool) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "main", Image: image}}},
+		Status: v1.PodStatus{
+			Phase:             v1.PodRunning,
+			ContainerStatuses: []v1.ContainerStatus{{Name: "main", Ready: ready}},
+		},
+	}
+}
+
+func TestFeatureChecker(t *testing.T) {
+	informer := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	devopsLabels := map[string]string{"app.kubernetes.io/name": "ks-devops"}
+	for _, pod := range []*v1.Pod{
+		featurePod("devops-apiserver", "kubesphere-devops-system", "kubesphere/devops-apiserver:v3.3.0", devopsLabels, true),
+		featurePod("devops-controller", "kubesphere-devops-system", "kubesphere/devops-controller:v3.3.0", devopsLabels, false),
+		featurePod("jenkins", "kubesphere-devops-system", "registry:5000/kubesphere/ks-jenkins:v3.3.0-2.319.1", nil, true),
+		featurePod("fluent-bit", "kubesphere-logging-system", "kubesphere/fluent-bit:v1.9.4",
+			map[string]string{"app.kubernetes.io/version": "v1.9.4"}, true),
+	} {
+		if err := informer.Core().V1().Pods().Informer().GetIndexer().Add(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	unreachable := true
+	jenkins := healthz.NamedCheck("jenkins", func(_ *http.Request) error {
+		if unreachable {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+	checker := NewFeatureChecker(informer, 0,
+		NewFeature(FeatureDevOps, &FeatureOptions{
+			Namespace:     "kubesphere-devops-system",
+			Selector:      "app.kubernetes.io/name=ks-devops",
+			LatestVersion: "v3.4.0",
+		}, jenkins),
+		NewFeature(FeatureLogging, &FeatureOptions{Namespace: "kubesphere-logging-system", LatestVersion: "v1.9.4"}),
+		NewFeature(FeatureMetering, nil),
+	)
+	checker.Check()
+
+	devops, ok := checker.Status(FeatureDevOps)
+	if !ok {
+		t.Fatal("expected the status of devops")
+	}
+	if devops.Kind != ComponentKindFeature || devops.TotalBackends != 2 || devops.HealthyBackends != 1 {
+		t.Errorf("expected the pods of devops to be selected, got %+v", devops)
+	}
+	if devops.Version != "v3.3.0" || !devops.UpgradeAvailable {
+		t.Errorf("expected devops v3.3.0 to be upgradable to v3.4.0, got %s, %v", devops.Version, devops.UpgradeAvailable)
+	}
+	if len(devops.Dependencies) != 1 || devops.Dependencies[0].Reachable || devops.LastError == "" || devops.LastErrorTime == nil {
+		t.Errorf("expected jenkins to be unreachable, got %+v", devops)
+	}
+
+	// the last error is kept after devops recovers
+	unreachable = false
+	recovered := featurePod("devops-controller", "kubesphere-devops-system", "kubesphere/devops-controller:v3.3.0", devopsLabels, true)
+	if err := informer.Core().V1().Pods().Informer().GetIndexer().Update(recovered); err != nil {
+		t.Fatal(err)
+	}
+	lastErrorTime := *devops.LastErrorTime
+	checker.Check()
+	devops, _ = checker.Status(FeatureDevOps)
+	if !devops.Dependencies[0].Reachable || !isHealthy(devops) || devops.LastError == "" || !devops.LastErrorTime.Equal(lastErrorTime) {
+		t.Errorf("expected jenkins to be reachable with the last error, got %+v", devops)
+	}
+
+	logging, _ := checker.Status(FeatureLogging)
+	if logging.Version != "v1.9.4" || logging.UpgradeAvailable || logging.LastError != "" || !isHealthy(logging) {
+		t.Errorf("expected logging to be healthy and up to date, got %+v", logging)
+	}
+	metering, _ := checker.Status(FeatureMetering)
+	if metering.TotalBackends != 0 || !isHealthy(metering) {
+		t.Errorf("expected metering without workloads, got %+v", metering)
+	}
+	if _, ok = checker.Status(FeatureServiceMesh); ok {
+		t.Errorf("expected no status of the features not enabled")
+	}
+
+	getter := NewComponentsGetter(informer, checker)
+	if components, err := getter.GetAllComponentsStatus(); err != nil || len(components) != 3 {
+		t.Errorf("expected the features in the components, got %v, %v", components, err)
+	}
+	if component, err := getter.GetComponentStatus(FeatureDevOps); err != nil || component.Name != FeatureDevOps {
+		t.Errorf("expected the status of devops, got %+v, %v", component, err)
+	}
+}
+
+func TestPodVersion(t *testing.T) {
+	tests := map[string]string{
+		"kubesphere/ks-apiserver:v3.4.0":                         "v3.4.0",
+		"registry:5000/kubesphere/ks-apiserver":                  "",
+		"registry:5000/kubesphere/jenkins:2.319":                 "2.319",
+		"kubesphere/ks-apiserver:v3.4.0@sha256:0123456789abcdef": "v3.4.0",
+	}
+	for image, expected := range tests {
+		if version := podVersion(featurePod("pod", "default", image, nil, true)); version != expected {
+			t.Errorf("expected version %q of image %s, got %q", expected, image, version)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"sync"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+
+	"kubesphere.io/kubesphere/pkg/utils/metrics"
+)
+
+var (
+	componentHealthy = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "ks_server_component_healthy",
+			Help:           "Whether the feature of kubesphere enabled is healthy, i.e. its dependencies are reachable and its pods are ready, broken out for each component.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"component"},
+	)
+	componentDependencyReachable = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "ks_server_component_dependency_reachable",
+			Help:           "Whether the dependency of the feature of kubesphere enabled is reachable, broken out for each component and dependency.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"component", "dependency"},
+	)
+	componentUpgradeAvailable = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "ks_server_component_upgrade_available",
+			Help:           "Whether the version running of the feature of kubesphere enabled is older than the latest version, broken out for each component.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"component"},
+	)
+	componentInfo = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "ks_server_component_info",
+			Help:           "The version running and the latest version of the feature of kubesphere enabled, broken out for each component.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"component", "version", "latest_version"},
+	)
+
+	initMetrics sync.Once
+)
+
+func registerMetrics() {
+	metrics.MustRegister(componentHealthy, componentDependencyReachable, componentUpgradeAvailable, componentInfo)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+const (
+	FeatureLogging      = "logging"
+	FeatureAuditing     = "auditing"
+	FeatureDevOps       = "devops"
+	FeatureServiceMesh  = "servicemesh"
+	FeatureAlerting     = "alerting"
+	FeatureNotification = "notification"
+	FeatureMetering     = "metering"
+	FeatureGateway      = "gateway"
+	FeatureEdgeRuntime  = "edgeruntime"
+)
+
+type Options struct {
+	// CheckInterval is the interval the dependencies of the features enabled are checked at.
+	CheckInterval time.Duration `json:"checkInterval,omitempty" yaml:"checkInterval,omitempty"`
+	// Features are the workloads and the latest versions of the features, keyed by the feature names,
+	// they override the default ones of the features installed by ks-installer.
+	Features map[string]*FeatureOptions `json:"features,omitempty" yaml:"features,omitempty"`
+}
+
+type FeatureOptions struct {
+	// Namespace the workloads of the feature run in, the version of the feature is read from them.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Selector of the workloads in the namespace, e.g. app=ks-jenkins, all the pods are selected if empty.
+	Selector string `json:"selector,omitempty" yaml:"selector,omitempty"`
+	// LatestVersion of the feature, an upgrade is available if the version running is older.
+	LatestVersion string `json:"latestVersion,omitempty" yaml:"latestVersion,omitempty"`
+}
+
+// defaultFeatures are the workloads of the features installed by ks-installer.
+var defaultFeatures = map[string]*FeatureOptions{
+	FeatureLogging:      {Namespace: constants.KubeSphereLoggingNamespace},
+	FeatureAuditing:     {Namespace: constants.KubeSphereLoggingNamespace},
+	FeatureDevOps:       {Namespace: constants.KubesphereDevOpsNamespace},
+	FeatureServiceMesh:  {Namespace: constants.IstioNamespace},
+	FeatureAlerting:     {Namespace: constants.KubeSphereMonitoringNamespace},
+	FeatureNotification: {Namespace: constants.KubeSphereMonitoringNamespace},
+	FeatureGateway:      {Namespace: constants.IngressControllerNamespace, Selector: "app.kubernetes.io/name=ingress-nginx"},
+	FeatureEdgeRuntime:  {Namespace: constants.KubeEdgeNamespace},
+}
+
+func NewComponentsOptions() *Options {
+	return &Options{
+		CheckInterval: time.Minute,
+	}
+}
+
+// FeatureOptions returns the options of the feature, the default ones are returned if not configured.
+func (s *Options) FeatureOptions(name string) *FeatureOptions {
+	if options, ok := s.Features[name]; ok && options != nil {
+		return options
+	}
+	return defaultFeatures[name]
+}
+
+func (s *Options) Validate() []error {
+	var errs []error
+	if s.CheckInterval < 10*time.Second {
+		errs = append(errs, fmt.Errorf("the check interval of components must be at least 10s"))
+	}
+	for name, feature := range s.Features {
+		if feature == nil {
+			continue
+		}
+		if _, err := labels.Parse(feature.Selector); err != nil {
+			errs = append(errs, fmt.Errorf("invalid selector of feature %s: %v", name, err))
+		}
+		if feature.LatestVersion != "" {
+			if _, err := semver.NewVersion(feature.LatestVersion); err != nil {
+				errs = append(errs, fmt.Errorf("invalid latest version of feature %s: %v", name, err))
+			}
+		}
+	}
+	return errs
+}
+
+func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
+	fs.DurationVar(&s.CheckInterval, "component-check-interval", c.CheckInterval, ""+
+		"The interval the dependencies of the features enabled are checked at, e.g. elasticsearch of logging.")
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extensions
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	extensionsv1alpha1 "kubesphere.io/api/extensions/v1alpha1"
+)
+
+const defaultServicePort = 443
+
+// Backend is where the requests of an APIService are proxied to.
+type Backend struct {
+	Endpoint  *url.URL
+	Transport http.RoundTripper
+	AuthMode  extensionsv1alpha1.AuthMode
+}
+
+// NewBackend validates the spec of the APIService and builds its Backend. The proxyClientCert is presented
+// to the backends of the RequestHeader auth mode, which verify the X-Remote-* headers are set by ks-apiserver.
+func NewBackend(spec *extensionsv1alpha1.APIServiceSpec, proxyClientCert *tls.Certificate) (*Backend, error) {
+	if spec.Group == "" || spec.Version == "" {
+		return nil, fmt.Errorf("group and version must be specified")
+	}
+	if strings.Contains(spec.Group, "/") || strings.Contains(spec.Version, "/") {
+		return nil, fmt.Errorf("invalid group version %s/%s", spec.Group, spec.Version)
+	}
+
+	var endpoint *url.URL
+	switch {
+	case spec.Service != nil && spec.URL != nil:
+		return nil, fmt.Errorf("only one of service and url can be specified")
+	case spec.Service != nil:
+		port := int32(defaultServicePort)
+		if spec.Service.Port != nil {
+			port = *spec.Service.Port
+		}
+		endpoint = &url.URL{
+			Scheme: "https",
+			Host:   fmt.Sprintf("%s.%s.svc:%d", spec.Service.Name, spec.Service.Namespace, port),
+			Path:   spec.Service.Path,
+		}
+	case spec.URL != nil:
+		var err error
+		if endpoint, err = url.Parse(*spec.URL); err != nil {
+			return nil, fmt.Errorf("invalid url: %v", err)
+		}
+		if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid url %s: must be an absolute http or https url", *spec.URL)
+		}
+		if endpoint.RawQuery != "" || endpoint.Fragment != "" {
+			return nil, fmt.Errorf("invalid url %s: query and fragment are not allowed", *spec.URL)
+		}
+	default:
+		return nil, fmt.Errorf("either service or url must be specified")
+	}
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/")
+
+	authMode := spec.AuthMode
+	switch authMode {
+	case "":
+		authMode = extensionsv1alpha1.AuthModePassthrough
+	case extensionsv1alpha1.AuthModePassthrough, extensionsv1alpha1.AuthModeRequestHeader, extensionsv1alpha1.AuthModeNone:
+	default:
+		return nil, fmt.Errorf("unsupported auth mode %s", authMode)
+	}
+	// the credentials and the identities of the users must not be sent in plain text
+	switch {
+	case endpoint.Scheme == "https":
+	case authMode == extensionsv1alpha1.AuthModePassthrough && !spec.InsecurePassthrough:
+		return nil, fmt.Errorf("the backend of auth mode %s must be served by https unless insecurePassthrough is set", authMode)
+	case authMode == extensionsv1alpha1.AuthModeRequestHeader:
+		return nil, fmt.Errorf("the backend of auth mode %s must be served by https", authMode)
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: spec.InsecureSkipTLSVerify}
+	if authMode == extensionsv1alpha1.AuthModeRequestHeader && proxyClientCert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*proxyClientCert}
+	}
+	if len(spec.CABundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(spec.CABundle) {
+			return nil, fmt.Errorf("no valid certificate found in caBundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &Backend{Endpoint: endpoint, Transport: transport, AuthMode: authMode}, nil
+}
+
+// URL returns the url of the backend which the request with the path is proxied to,
+// /kapis/{group} is replaced with the path of the endpoint, as the generic proxies do.
+func (b *Backend) URL(group string, reqURL *url.URL) *url.URL {
+	u := *reqURL
+	u.Scheme = b.Endpoint.Scheme
+	u.Host = b.Endpoint.Host
+	u.Path = b.Endpoint.Path + strings.TrimPrefix(reqURL.Path, "/kapis/"+group)
+	u.RawPath = ""
+	return &u
+}
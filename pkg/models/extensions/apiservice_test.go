@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extensions
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+
+	extensionsv1alpha1 "kubesphere.io/api/extensions/v1alpha1"
+)
+
+func TestNewBackend(t *testing.T) {
+	httpURL := "http://devops-apiserver.kubesphere-devops-system:9090"
+	httpsURL := "https://devops-apiserver.kubesphere-devops-system:9443"
+	proxyClientCert := &tls.Certificate{Certificate: [][]byte{[]byte("front-proxy")}}
+
+	tests := []struct {
+		name       string
+		spec       extensionsv1alpha1.APIServiceSpec
+		wantErr    bool
+		clientCert bool
+	}{
+		{name: "passthrough over https", spec: extensionsv1alpha1.APIServiceSpec{URL: &httpsURL}},
+		{name: "passthrough over http", spec: extensionsv1alpha1.APIServiceSpec{URL: &httpURL}, wantErr: true},
+		{name: "insecure passthrough", spec: extensionsv1alpha1.APIServiceSpec{URL: &httpURL, InsecurePassthrough: true}},
+		{name: "none over http", spec: extensionsv1alpha1.APIServiceSpec{URL: &httpURL, AuthMode: extensionsv1alpha1.AuthModeNone}},
+		{
+			name:    "request header over http",
+			spec:    extensionsv1alpha1.APIServiceSpec{URL: &httpURL, AuthMode: extensionsv1alpha1.AuthModeRequestHeader, InsecurePassthrough: true},
+			wantErr: true,
+		},
+		{
+			name:       "request header over https",
+			spec:       extensionsv1alpha1.APIServiceSpec{URL: &httpsURL, AuthMode: extensionsv1alpha1.AuthModeRequestHeader},
+			clientCert: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Group, tt.spec.Version = "devops.kubesphere.io", "v1alpha3"
+			backend, err := NewBackend(&tt.spec, proxyClientCert)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewBackend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			certificates := backend.Transport.(*http.Transport).TLSClientConfig.Certificates
+			if (len(certificates) > 0) != tt.clientCert {
+				t.Errorf("unexpected client certificates %v", certificates)
+			}
+		})
+	}
+}
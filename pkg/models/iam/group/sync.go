@@ -0,0 +1,292 @@
+/*
+Copyright 2023 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+
+	iamv1alpha2 "kubesphere.io/api/iam/v1alpha2"
+	tenantv1alpha1 "kubesphere.io/api/tenant/v1alpha1"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
+	kubesphere "kubesphere.io/kubesphere/pkg/client/clientset/versioned"
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+// Synchronizer synchronizes the groups of identity provider to Group and GroupBinding,
+// the synchronized objects are labeled with the identity provider, others are never touched.
+type Synchronizer interface {
+	// SyncUserGroups reconciles the GroupBindings of the user with the groups from the identity provider,
+	// it's called when the user login.
+	SyncUserGroups(idp string, options *oauth.GroupSyncOptions, username string, groups []string) error
+	// SyncGroups reconciles all the Groups and GroupBindings of the identity provider,
+	// members is a map from the group name of identity provider to the usernames.
+	SyncGroups(idp string, options *oauth.GroupSyncOptions, members map[string][]string) error
+}
+
+// maxDeletionRatio is the max ratio of the synchronized groups that can be deleted in one synchronization,
+// more deletions usually mean that the identity provider returned a partial result, e.g. the search base
+// of LDAP is changed, the deletions are skipped until the result is confirmed.
+const maxDeletionRatio = 0.5
+
+type synchronizer struct {
+	ksclient kubesphere.Interface
+}
+
+func NewSynchronizer(ksclient kubesphere.Interface) Synchronizer {
+	return &synchronizer{ksclient: ksclient}
+}
+
+func (s *synchronizer) SyncUserGroups(idp string, options *oauth.GroupSyncOptions, username string, groups []string) error {
+	expected := make(map[string]string)
+	for _, group := range groups {
+		if name, ok := groupName(options, group); ok {
+			expected[name] = group
+		}
+	}
+	for name, displayName := range expected {
+		if err := s.ensureGroup(idp, options, name, displayName); err != nil {
+			return err
+		}
+	}
+
+	groupBindings, err := s.listGroupBindings(idp, labels.Set{iamv1alpha2.UserReferenceLabel: username})
+	if err != nil {
+		return err
+	}
+	bound := sets.New[string]()
+	for _, groupBinding := range groupBindings {
+		group := groupBinding.GroupRef.Name
+		if _, ok := expected[group]; !ok || bound.Has(group) {
+			if err = s.deleteGroupBinding(groupBinding.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		bound.Insert(group)
+	}
+	for name := range expected {
+		if !bound.Has(name) {
+			if err = s.createGroupBinding(idp, options.Workspace, name, username); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *synchronizer) SyncGroups(idp string, options *oauth.GroupSyncOptions, members map[string][]string) error {
+	expected := make(map[string]sets.Set[string])
+	for group, usernames := range members {
+		name, ok := groupName(options, group)
+		if !ok {
+			continue
+		}
+		if err := s.ensureGroup(idp, options, name, group); err != nil {
+			return err
+		}
+		if expected[name] == nil {
+			expected[name] = sets.New[string]()
+		}
+		expected[name].Insert(usernames...)
+	}
+
+	if len(expected) == 0 {
+		klog.Warningf("no group is synchronized from identity provider %s, skip deleting the existing groups", idp)
+		return nil
+	}
+
+	groups, err := s.ksclient.IamV1alpha2().Groups().List(context.Background(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{iamv1alpha2.IdentifyProviderLabel: idp}).String(),
+	})
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	stale := sets.New[string]()
+	for _, group := range groups.Items {
+		if _, ok := expected[group.Name]; !ok {
+			stale.Insert(group.Name)
+		}
+	}
+	// the stale groups and their GroupBindings are kept if too many groups would be deleted
+	kept := sets.New[string]()
+	if float64(stale.Len()) > float64(len(groups.Items))*maxDeletionRatio {
+		klog.Warningf("%d of %d groups of identity provider %s are missing, skip deleting groups: %s",
+			stale.Len(), len(groups.Items), idp, strings.Join(sets.List(stale), ","))
+		kept = stale
+		stale = sets.New[string]()
+	}
+	// the GroupBindings will be removed by the group controller
+	for _, name := range sets.List(stale) {
+		if err = s.ksclient.IamV1alpha2().Groups().Delete(context.Background(), name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			klog.Error(err)
+			return err
+		}
+	}
+
+	groupBindings, err := s.listGroupBindings(idp, labels.Set{})
+	if err != nil {
+		return err
+	}
+	bound := make(map[string]sets.Set[string])
+	for _, groupBinding := range groupBindings {
+		group := groupBinding.GroupRef.Name
+		username := groupBinding.Labels[iamv1alpha2.UserReferenceLabel]
+		if kept.Has(group) {
+			continue
+		}
+		if !expected[group].Has(username) || bound[group].Has(username) {
+			if err = s.deleteGroupBinding(groupBinding.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		if bound[group] == nil {
+			bound[group] = sets.New[string]()
+		}
+		bound[group].Insert(username)
+	}
+	for group, usernames := range expected {
+		for _, username := range sets.List(usernames) {
+			if !bound[group].Has(username) {
+				if err = s.createGroupBinding(idp, options.Workspace, group, username); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ensureGroup creates the group if not exists, the existing group which is not synchronized from
+// the identity provider will not be taken over unless it's mapped explicitly.
+func (s *synchronizer) ensureGroup(idp string, options *oauth.GroupSyncOptions, name, displayName string) error {
+	group, err := s.ksclient.IamV1alpha2().Groups().Get(context.Background(), name, metav1.GetOptions{})
+	if err == nil {
+		if len(options.Mapping) == 0 && group.Labels[iamv1alpha2.IdentifyProviderLabel] != idp {
+			err = fmt.Errorf("group %s already exists and is not synchronized from identity provider %s", name, idp)
+			klog.Error(err)
+			return err
+		}
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		klog.Error(err)
+		return err
+	}
+	group = &iamv1alpha2.Group{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				iamv1alpha2.IdentifyProviderLabel: idp,
+				tenantv1alpha1.WorkspaceLabel:     options.Workspace,
+			},
+			Annotations: map[string]string{
+				constants.DisplayNameAnnotationKey: displayName,
+			},
+		},
+	}
+	if _, err = s.ksclient.IamV1alpha2().Groups().Create(context.Background(), group, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *synchronizer) listGroupBindings(idp string, selector labels.Set) ([]iamv1alpha2.GroupBinding, error) {
+	selector = labels.Merge(selector, labels.Set{iamv1alpha2.IdentifyProviderLabel: idp})
+	groupBindings, err := s.ksclient.IamV1alpha2().GroupBindings().List(context.Background(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	return groupBindings.Items, nil
+}
+
+func (s *synchronizer) createGroupBinding(idp, workspace, group, username string) error {
+	groupBinding := &iamv1alpha2.GroupBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-%s-", group, username),
+			Labels: map[string]string{
+				iamv1alpha2.UserReferenceLabel:    username,
+				iamv1alpha2.GroupReferenceLabel:   group,
+				iamv1alpha2.IdentifyProviderLabel: idp,
+				tenantv1alpha1.WorkspaceLabel:     workspace,
+			},
+		},
+		Users: []string{username},
+		GroupRef: iamv1alpha2.GroupRef{
+			APIGroup: iamv1alpha2.SchemeGroupVersion.Group,
+			Kind:     iamv1alpha2.ResourcePluralGroup,
+			Name:     group,
+		},
+	}
+	if _, err := s.ksclient.IamV1alpha2().GroupBindings().Create(context.Background(), groupBinding, metav1.CreateOptions{}); err != nil {
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *synchronizer) deleteGroupBinding(name string) error {
+	if err := s.ksclient.IamV1alpha2().GroupBindings().Delete(context.Background(), name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		klog.Error(err)
+		return err
+	}
+	return nil
+}
+
+// groupName returns the name of Group mapped to the group of identity provider,
+// false means that the group should not be synchronized.
+func groupName(options *oauth.GroupSyncOptions, group string) (string, bool) {
+	if len(options.Mapping) > 0 {
+		name, ok := options.Mapping[group]
+		return name, ok
+	}
+	name := sanitizeName(options.Prefix + group)
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		klog.Warningf("group %s of identity provider is ignored: %s", group, strings.Join(errs, ","))
+		return "", false
+	}
+	return name, true
+}
+
+// sanitizeName converts the group name to a valid DNS-1123 label, the group name is also used as label value.
+func sanitizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(name))
+	if len(name) > validation.DNS1123LabelMaxLength {
+		name = name[:validation.DNS1123LabelMaxLength]
+	}
+	return strings.Trim(name, "-")
+}
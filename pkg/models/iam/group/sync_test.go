@@ -0,0 +1,193 @@
+/*
+Copyright 2023 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	k8stesting "k8s.io/client-go/testing"
+
+	iamv1alpha2 "kubesphere.io/api/iam/v1alpha2"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/oauth"
+	fakeks "kubesphere.io/kubesphere/pkg/client/clientset/versioned/fake"
+)
+
+func newFakeClient(objects ...runtime.Object) *fakeks.Clientset {
+	client := fakeks.NewSimpleClientset(objects...)
+	// the fake client does not support generateName
+	client.PrependReactor("create", "groupbindings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		groupBinding := action.(k8stesting.CreateAction).GetObject().(*iamv1alpha2.GroupBinding)
+		if groupBinding.Name == "" {
+			groupBinding.Name = groupBinding.GenerateName + rand.String(5)
+		}
+		return false, nil, nil
+	})
+	return client
+}
+
+func bindings(t *testing.T, client *fakeks.Clientset) []string {
+	groupBindings, err := client.IamV1alpha2().GroupBindings().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := make([]string, 0)
+	for _, groupBinding := range groupBindings.Items {
+		result = append(result, fmt.Sprintf("%s/%s", groupBinding.GroupRef.Name, groupBinding.Users[0]))
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestSyncUserGroups(t *testing.T) {
+	manual := &iamv1alpha2.GroupBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "manual-user1",
+			Labels: map[string]string{iamv1alpha2.UserReferenceLabel: "user1"},
+		},
+		GroupRef: iamv1alpha2.GroupRef{Name: "manual"},
+		Users:    []string{"user1"},
+	}
+	client := newFakeClient(manual)
+	synchronizer := NewSynchronizer(client)
+	options := &oauth.GroupSyncOptions{Workspace: "ws1", Prefix: "ldap-"}
+
+	if err := synchronizer.SyncUserGroups("ldap", options, "user1", []string{"Developers", "admins"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"ldap-admins/user1", "ldap-developers/user1", "manual/user1"}
+	if got := bindings(t, client); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+	group, err := client.IamV1alpha2().Groups().Get(context.Background(), "ldap-developers", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.Labels[iamv1alpha2.IdentifyProviderLabel] != "ldap" {
+		t.Errorf("unexpected labels: %v", group.Labels)
+	}
+
+	// user1 leaves the admins group, the manual binding must be kept
+	if err = synchronizer.SyncUserGroups("ldap", options, "user1", []string{"Developers"}); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"ldap-developers/user1", "manual/user1"}
+	if got := bindings(t, client); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+
+	// existing group not synchronized from the identity provider
+	if err = synchronizer.SyncUserGroups("oidc", &oauth.GroupSyncOptions{Workspace: "ws1", Prefix: "ldap-"}, "user2", []string{"developers"}); err == nil {
+		t.Errorf("expected error when group is conflicted")
+	}
+}
+
+func TestSyncGroups(t *testing.T) {
+	client := newFakeClient()
+	synchronizer := NewSynchronizer(client)
+	options := &oauth.GroupSyncOptions{Workspace: "ws1", Mapping: map[string]string{"developers": "dev", "testers": "test"}}
+
+	if err := synchronizer.SyncGroups("ldap", options, map[string][]string{
+		"developers": {"user1", "user2"},
+		"testers":    {"user2"},
+		"unmapped":   {"user3"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"dev/user1", "dev/user2", "test/user2"}
+	if got := bindings(t, client); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+
+	if err := synchronizer.SyncGroups("ldap", options, map[string][]string{
+		"developers": {"user1"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"dev/user1"}
+	if got := bindings(t, client); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+	if _, err := client.IamV1alpha2().Groups().Get(context.Background(), "test", metav1.GetOptions{}); err == nil {
+		t.Errorf("group test should be deleted")
+	}
+
+	// an empty result must not delete the synchronized groups
+	if err := synchronizer.SyncGroups("ldap", options, map[string][]string{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := bindings(t, client); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+}
+
+func TestSyncGroupsMaxDeletions(t *testing.T) {
+	client := newFakeClient()
+	synchronizer := NewSynchronizer(client)
+	options := &oauth.GroupSyncOptions{Workspace: "ws1"}
+
+	if err := synchronizer.SyncGroups("ldap", options, map[string][]string{
+		"a": {"user1"},
+		"b": {"user1"},
+		"c": {"user2"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// a partial result, the groups b and c are kept with their GroupBindings
+	if err := synchronizer.SyncGroups("ldap", options, map[string][]string{
+		"a": {"user2"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"a/user2", "b/user1", "c/user2"}
+	if got := bindings(t, client); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+	for _, name := range []string{"b", "c"} {
+		if _, err := client.IamV1alpha2().Groups().Get(context.Background(), name, metav1.GetOptions{}); err != nil {
+			t.Errorf("group %s should be kept: %v", name, err)
+		}
+	}
+}
+
+func TestGroupName(t *testing.T) {
+	tests := []struct {
+		options  *oauth.GroupSyncOptions
+		group    string
+		expected string
+		ok       bool
+	}{
+		{options: &oauth.GroupSyncOptions{}, group: "developers", expected: "developers", ok: true},
+		{options: &oauth.GroupSyncOptions{Prefix: "oidc-"}, group: "Domain Admins", expected: "oidc-domain-admins", ok: true},
+		{options: &oauth.GroupSyncOptions{}, group: "/", expected: "", ok: false},
+		{options: &oauth.GroupSyncOptions{Mapping: map[string]string{"admins": "administrators"}}, group: "admins", expected: "administrators", ok: true},
+		{options: &oauth.GroupSyncOptions{Mapping: map[string]string{"admins": "administrators"}}, group: "developers", expected: "", ok: false},
+	}
+	for _, tt := range tests {
+		name, ok := groupName(tt.options, tt.group)
+		if name != tt.expected || ok != tt.ok {
+			t.Errorf("groupName(%s) = %s, %v, want %s, %v", tt.group, name, ok, tt.expected, tt.ok)
+		}
+	}
+}
@@ -69,10 +69,16 @@ func (im *imOperator) UpdateUser(new *iamv1alpha2.User) (*iamv1alpha2.User, erro
 		klog.Error(err)
 		return nil, err
 	}
-	// keep encrypted password and user status
+	// keep encrypted password, password history and user status
 	new.Spec.EncryptedPassword = old.Spec.EncryptedPassword
+	if history, ok := old.Annotations[iamv1alpha2.PasswordHistoryAnnotation]; ok {
+		if new.Annotations == nil {
+			new.Annotations = make(map[string]string)
+		}
+		new.Annotations[iamv1alpha2.PasswordHistoryAnnotation] = history
+	}
 	status := old.Status
-	// only support enable or disable
+	// only support enable or disable, the locked user is unlocked by enabling
 	if new.Status.State == iamv1alpha2.UserDisabled || new.Status.State == iamv1alpha2.UserActive {
 		status.State = new.Status.State
 		status.LastTransitionTime = &metav1.Time{Time: time.Now()}
@@ -177,5 +183,6 @@ func ensurePasswordNotOutput(user *iamv1alpha2.User) *iamv1alpha2.User {
 	out := user.DeepCopy()
 	// ensure encrypted password will not be output
 	out.Spec.EncryptedPassword = ""
+	delete(out.Annotations, iamv1alpha2.PasswordHistoryAnnotation)
 	return out
 }
@@ -28,6 +28,8 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	k8sinformers "k8s.io/client-go/informers"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -63,6 +65,7 @@ type ApplicationInterface interface {
 	DoAppVersionAction(versionId string, request *ActionRequest) error
 	ListAppVersionAudits(conditions *params.Conditions, orderBy string, reverse bool, limit, offset int) (*models.PageableResponse, error)
 	GetAppVersionFiles(versionId string, request *GetAppVersionFilesRequest) (*GetAppVersionPackageFilesResponse, error)
+	GetAppVersionValuesSchema(versionId string) (*GetAppVersionValuesSchemaResponse, error)
 	ListAppVersionReviews(conditions *params.Conditions, orderBy string, reverse bool, limit, offset int) (*models.PageableResponse, error)
 	ListAppVersions(conditions *params.Conditions, orderBy string, reverse bool, limit, offset int) (*models.PageableResponse, error)
 }
@@ -70,6 +73,7 @@ type ApplicationInterface interface {
 type applicationOperator struct {
 	backingStoreClient s3.Interface
 	informers          externalversions.SharedInformerFactory
+	cmLister           corev1listers.ConfigMapLister
 
 	appClient        v1alpha13.HelmApplicationInterface
 	appVersionClient v1alpha13.HelmApplicationVersionInterface
@@ -84,10 +88,11 @@ type applicationOperator struct {
 	cachedRepos reposcache.ReposCache
 }
 
-func newApplicationOperator(cached reposcache.ReposCache, informers externalversions.SharedInformerFactory, ksClient versioned.Interface, storeClient s3.Interface) ApplicationInterface {
+func newApplicationOperator(cached reposcache.ReposCache, k8sFactory k8sinformers.SharedInformerFactory, informers externalversions.SharedInformerFactory, ksClient versioned.Interface, storeClient s3.Interface) ApplicationInterface {
 	op := &applicationOperator{
 		backingStoreClient: storeClient,
 		informers:          informers,
+		cmLister:           k8sFactory.Core().V1().ConfigMaps().Lister(),
 		repoLister:         informers.Application().V1alpha1().HelmRepos().Lister(),
 
 		appClient:        ksClient.ApplicationV1alpha1().HelmApplications(),
@@ -243,6 +248,11 @@ func (c *applicationOperator) CreateApp(req *CreateAppRequest) (*CreateAppRespon
 		klog.Errorf("load package %s/%s failed, error: %s", req.Isv, req.Name, err)
 		return nil, err
 	}
+	verification, err := c.verifyPackage(req.Isv, req.VersionPackage, req.VersionProvenance, req.VersionSignature)
+	if err != nil {
+		klog.Errorf("verify package %s/%s failed, error: %s", req.Isv, req.Name, err)
+		return nil, err
+	}
 
 	// create helm application
 	name := idutils.GetUuid36(v1alpha1.HelmApplicationIdPrefix)
@@ -285,6 +295,13 @@ func (c *applicationOperator) CreateApp(req *CreateAppRequest) (*CreateAppRespon
 		klog.V(4).Infof("helm application version %s/%s created, app version id: %s", req.Isv, req.Name, ver.Name)
 	}
 
+	if verification != nil {
+		if err = c.updateVerificationStatus(ver.Name, verification); err != nil {
+			klog.Errorf("update verification status of app version %s failed, error: %s", ver.Name, err)
+			return nil, err
+		}
+	}
+
 	return &CreateAppResponse{
 		AppID:     app.GetHelmApplicationId(),
 		VersionID: ver.GetHelmApplicationVersionId(),
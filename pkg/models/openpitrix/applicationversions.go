@@ -81,6 +81,11 @@ func (c *applicationOperator) CreateAppVersion(request *CreateAppVersionRequest)
 		klog.Errorf("get app %s failed, error: %s", request.AppId, err)
 		return nil, err
 	}
+	verification, err := c.verifyPackage(app.GetWorkspace(), request.Package, request.Provenance, request.Signature)
+	if err != nil {
+		klog.Errorf("verify package failed, error: %s", err)
+		return nil, err
+	}
 	chartPackage := request.Package.String()
 	version := buildApplicationVersion(app, chrt, &chartPackage, request.Username)
 	version, err = c.createApplicationVersion(version)
@@ -90,6 +95,13 @@ func (c *applicationOperator) CreateAppVersion(request *CreateAppVersionRequest)
 		return nil, err
 	}
 
+	if verification != nil {
+		if err = c.updateVerificationStatus(version.Name, verification); err != nil {
+			klog.Errorf("update verification status of app version %s failed, error: %s", version.Name, err)
+			return nil, err
+		}
+	}
+
 	klog.V(4).Infof("create helm app version %s success", request.Name)
 
 	return &CreateAppVersionResponse{
@@ -165,6 +177,7 @@ func (c *applicationOperator) ModifyAppVersion(id string, request *ModifyAppVers
 
 	versionCopy := version.DeepCopy()
 	spec := &versionCopy.Spec
+	var verification *v1alpha1.ChartVerificationStatus
 
 	// extract information from chart package
 	if len(request.Package) > 0 {
@@ -178,6 +191,12 @@ func (c *applicationOperator) ModifyAppVersion(id string, request *ModifyAppVers
 			return err
 		}
 
+		verification, err = c.verifyPackage(version.GetWorkspace(), request.Package, request.Provenance, request.Signature)
+		if err != nil {
+			klog.Errorf("verify package failed, error: %s", err)
+			return err
+		}
+
 		// chart name must match with the original one
 		if spec.Name != chart.GetName() {
 			return fmt.Errorf("chart name not match, current name: %s, original name: %s", chart.GetName(), spec.Name)
@@ -249,6 +268,14 @@ func (c *applicationOperator) ModifyAppVersion(id string, request *ModifyAppVers
 		klog.Error(err)
 		return err
 	}
+
+	// the package is replaced, so is the verification status
+	if len(request.Package) > 0 {
+		if err = c.updateVerificationStatus(id, verification); err != nil {
+			klog.Errorf("update verification status of app version %s failed, error: %s", id, err)
+			return err
+		}
+	}
 	return nil
 }
 
@@ -542,6 +569,27 @@ func (c *applicationOperator) GetAppVersionFiles(versionId string, request *GetA
 	return res, nil
 }
 
+// GetAppVersionValuesSchema returns the values.schema.json in the chart, which can be used to render the values form
+func (c *applicationOperator) GetAppVersionValuesSchema(versionId string) (*GetAppVersionValuesSchemaResponse, error) {
+	version, err := c.getAppVersionByVersionIdWithData(versionId)
+	if err != nil {
+		klog.Errorf("get app version %s chart data failed: %v", versionId, err)
+		return nil, err
+	}
+
+	schema, subchartSchemas, err := helmrepoindex.LoadValuesSchema(version.Spec.Data)
+	if err != nil {
+		klog.Errorf("load values schema of app version %s failed, error: %s", versionId, err)
+		return nil, err
+	}
+
+	return &GetAppVersionValuesSchemaResponse{
+		Schema:          schema,
+		SubchartSchemas: subchartSchemas,
+		VersionId:       versionId,
+	}, nil
+}
+
 func (c *applicationOperator) getAppVersionByVersionIdWithData(versionId string) (*v1alpha1.HelmApplicationVersion, error) {
 	if version, exists, err := c.cachedRepos.GetAppVersionWithData(versionId); exists {
 		if err != nil {
@@ -30,4 +30,6 @@ var (
 
 	loadRepoInfoFailed = errors.New("load repo info failed")
 	downloadFileFailed = errors.New("download file failed")
+
+	valuesValidationFailed = errors.New("values don't meet the specifications of the schema")
 )
@@ -27,6 +27,8 @@ import (
 	"kubesphere.io/kubesphere/pkg/apiserver/query"
 
 	"github.com/go-openapi/strfmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -34,6 +36,7 @@ import (
 	"k8s.io/client-go/informers"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	"kubesphere.io/api/application/v1alpha1"
 
@@ -44,7 +47,9 @@ import (
 	"kubesphere.io/kubesphere/pkg/constants"
 	"kubesphere.io/kubesphere/pkg/models"
 	"kubesphere.io/kubesphere/pkg/server/params"
+	"kubesphere.io/kubesphere/pkg/simple/client/openpitrix/helmrepoindex"
 	"kubesphere.io/kubesphere/pkg/simple/client/openpitrix/helmwrapper"
+	"kubesphere.io/kubesphere/pkg/simple/client/s3"
 	"kubesphere.io/kubesphere/pkg/utils/clusterclient"
 	"kubesphere.io/kubesphere/pkg/utils/idutils"
 	"kubesphere.io/kubesphere/pkg/utils/reposcache"
@@ -59,25 +64,34 @@ type ReleaseInterface interface {
 	ModifyApplication(request ModifyClusterAttributesRequest) error
 	DeleteApplication(workspace, clusterName, namespace, id string) error
 	UpgradeApplication(request UpgradeClusterRequest, applicationId string) error
+	ListApplicationRevisions(workspace, clusterName, namespace, applicationId string) ([]*ReleaseRevision, error)
+	DescribeApplicationRevision(workspace, clusterName, namespace, applicationId string, revision int) (*ReleaseRevision, error)
+	RollbackApplication(request RollbackClusterRequest, applicationId string) error
+	PreviewApplication(workspace, clusterName, namespace string, request CreateClusterRequest) (*ReleaseDiff, error)
+	PreviewUpgradeApplication(request UpgradeClusterRequest, applicationId string) (*ReleaseDiff, error)
 }
 
 type releaseOperator struct {
-	informers        informers.SharedInformerFactory
-	rlsClient        typed_v1alpha1.HelmReleaseInterface
-	rlsLister        listers_v1alpha1.HelmReleaseLister
-	appVersionLister listers_v1alpha1.HelmApplicationVersionLister
-	cachedRepos      reposcache.ReposCache
-	clusterClients   clusterclient.ClusterClients
+	informers          informers.SharedInformerFactory
+	rlsClient          typed_v1alpha1.HelmReleaseInterface
+	rlsLister          listers_v1alpha1.HelmReleaseLister
+	appVersionLister   listers_v1alpha1.HelmApplicationVersionLister
+	repoLister         listers_v1alpha1.HelmRepoLister
+	cachedRepos        reposcache.ReposCache
+	clusterClients     clusterclient.ClusterClients
+	backingStoreClient s3.Interface
 }
 
-func newReleaseOperator(cached reposcache.ReposCache, k8sFactory informers.SharedInformerFactory, ksFactory externalversions.SharedInformerFactory, ksClient versioned.Interface, cc clusterclient.ClusterClients) ReleaseInterface {
+func newReleaseOperator(cached reposcache.ReposCache, k8sFactory informers.SharedInformerFactory, ksFactory externalversions.SharedInformerFactory, ksClient versioned.Interface, cc clusterclient.ClusterClients, storeClient s3.Interface) ReleaseInterface {
 	c := &releaseOperator{
-		informers:        k8sFactory,
-		rlsClient:        ksClient.ApplicationV1alpha1().HelmReleases(),
-		rlsLister:        ksFactory.Application().V1alpha1().HelmReleases().Lister(),
-		cachedRepos:      cached,
-		clusterClients:   cc,
-		appVersionLister: ksFactory.Application().V1alpha1().HelmApplicationVersions().Lister(),
+		informers:          k8sFactory,
+		rlsClient:          ksClient.ApplicationV1alpha1().HelmReleases(),
+		rlsLister:          ksFactory.Application().V1alpha1().HelmReleases().Lister(),
+		cachedRepos:        cached,
+		clusterClients:     cc,
+		appVersionLister:   ksFactory.Application().V1alpha1().HelmApplicationVersions().Lister(),
+		repoLister:         ksFactory.Application().V1alpha1().HelmRepos().Lister(),
+		backingStoreClient: storeClient,
 	}
 
 	return c
@@ -112,12 +126,17 @@ func (c *releaseOperator) UpgradeApplication(request UpgradeClusterRequest, appl
 		klog.Errorf("get helm application version %s/%s failed, error: %s", request.AppId, request.VersionId, err)
 		return err
 	}
+	if err = c.checkVerification(version); err != nil {
+		klog.Errorf("helm application version %s/%s can not be deployed, error: %s", request.AppId, request.VersionId, err)
+		return err
+	}
 
 	newRls := oldRls.DeepCopy()
 	newRls.Spec.ApplicationId = request.AppId
 	newRls.Spec.ApplicationVersionId = request.VersionId
 
 	newRls.Spec.Version += 1
+	newRls.Spec.RollbackRevision = 0
 	newRls.Spec.RepoId = version.GetHelmRepoId()
 	newRls.Spec.ChartVersion = version.GetChartVersion()
 	newRls.Spec.ChartAppVersion = version.GetChartAppVersion()
@@ -125,6 +144,9 @@ func (c *releaseOperator) UpgradeApplication(request UpgradeClusterRequest, appl
 	if request.Conf != "" {
 		newRls.Spec.Values = strfmt.Base64(request.Conf)
 	}
+	if err = c.validateValues(request.VersionId, newRls.Spec.Values); err != nil {
+		return err
+	}
 
 	patch := client.MergeFrom(oldRls)
 	data, _ := patch.Data(newRls)
@@ -148,6 +170,13 @@ func (c *releaseOperator) CreateApplication(workspace, clusterName, namespace st
 		klog.Errorf("get helm application version %s failed, error: %v", request.Name, err)
 		return err
 	}
+	if err = c.checkVerification(version); err != nil {
+		klog.Errorf("helm application version %s can not be deployed, error: %v", request.VersionId, err)
+		return err
+	}
+	if err = c.validateValues(request.VersionId, []byte(request.Conf)); err != nil {
+		return err
+	}
 
 	exists, err := c.releaseExists(workspace, clusterName, namespace, request.Name)
 
@@ -342,26 +371,12 @@ func (c *releaseOperator) DescribeApplication(workspace, clusterName, namespace,
 
 	app := &Application{}
 
-	var clusterConfig string
 	if rls != nil {
 		// TODO check clusterName, workspace, namespace
-		if clusterName != "" {
-			cluster, err := c.clusterClients.Get(clusterName)
-			if err != nil {
-				klog.Errorf("get cluster config failed, error: %s", err)
-				return nil, err
-			}
-			if !c.clusterClients.IsHostCluster(cluster) {
-				clusterConfig, err = c.clusterClients.GetClusterKubeconfig(rls.GetRlsCluster())
-				if err != nil {
-					klog.Errorf("get cluster config failed, error: %s", err)
-					return nil, err
-				}
-			}
+		hw, err := c.helmWrapper(clusterName, namespace, rls.Spec.Name)
+		if err != nil {
+			return nil, err
 		}
-
-		// If clusterConfig is empty, this application will be installed in current host.
-		hw := helmwrapper.NewHelmWrapper(clusterConfig, namespace, rls.Spec.Name)
 		manifest, err := hw.Manifest()
 		if err != nil {
 			klog.Errorf("get manifest failed, error: %s", err)
@@ -376,6 +391,307 @@ func (c *releaseOperator) DescribeApplication(workspace, clusterName, namespace,
 	return app, nil
 }
 
+// helmWrapper returns the helm wrapper of the release in the cluster
+func (c *releaseOperator) helmWrapper(clusterName, namespace, name string, options ...helmwrapper.Option) (helmwrapper.HelmWrapper, error) {
+	var clusterConfig string
+	if clusterName != "" {
+		cluster, err := c.clusterClients.Get(clusterName)
+		if err != nil {
+			klog.Errorf("get cluster config failed, error: %s", err)
+			return nil, err
+		}
+		if !c.clusterClients.IsHostCluster(cluster) {
+			clusterConfig, err = c.clusterClients.GetClusterKubeconfig(clusterName)
+			if err != nil {
+				klog.Errorf("get cluster config failed, error: %s", err)
+				return nil, err
+			}
+		}
+	}
+
+	// If clusterConfig is empty, this application will be installed in current host.
+	return helmwrapper.NewHelmWrapper(clusterConfig, namespace, name, options...), nil
+}
+
+// getRelease returns the release in the workspace, cluster and namespace, the releases in the others are not found.
+func (c *releaseOperator) getRelease(workspace, clusterName, namespace, applicationId string) (*v1alpha1.HelmRelease, error) {
+	rls, err := c.rlsLister.Get(applicationId)
+	if err != nil {
+		return nil, err
+	}
+	if rls.GetWorkspace() != workspace || rls.GetRlsCluster() != clusterName || rls.GetRlsNamespace() != namespace {
+		return nil, apierrors.NewNotFound(v1alpha1.Resource("helmreleases"), applicationId)
+	}
+	return rls, nil
+}
+
+func (c *releaseOperator) ListApplicationRevisions(workspace, clusterName, namespace, applicationId string) ([]*ReleaseRevision, error) {
+	rls, err := c.getRelease(workspace, clusterName, namespace, applicationId)
+	if err != nil {
+		klog.Errorf("get release %s/%s failed, error: %s", namespace, applicationId, err)
+		return nil, err
+	}
+
+	hw, err := c.helmWrapper(clusterName, namespace, rls.Spec.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := hw.History()
+	if err != nil {
+		klog.Errorf("get history of release %s/%s failed, error: %s", namespace, applicationId, err)
+		return nil, err
+	}
+
+	revisions := make([]*ReleaseRevision, 0, len(releases))
+	for _, r := range releases {
+		revisions = append(revisions, convertReleaseRevision(r, false))
+	}
+	return revisions, nil
+}
+
+func (c *releaseOperator) DescribeApplicationRevision(workspace, clusterName, namespace, applicationId string, revision int) (*ReleaseRevision, error) {
+	rls, err := c.getRelease(workspace, clusterName, namespace, applicationId)
+	if err != nil {
+		klog.Errorf("get release %s/%s failed, error: %s", namespace, applicationId, err)
+		return nil, err
+	}
+
+	hw, err := c.helmWrapper(clusterName, namespace, rls.Spec.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := hw.Revision(revision)
+	if err != nil {
+		klog.Errorf("get revision %d of release %s/%s failed, error: %s", revision, namespace, applicationId, err)
+		return nil, err
+	}
+
+	return convertReleaseRevision(r, true), nil
+}
+
+// RollbackApplication rolls back the release to a previous revision, the chart version and values of the revision
+// are written back to the spec, so the release is consistent with what is running in the cluster.
+func (c *releaseOperator) RollbackApplication(request RollbackClusterRequest, applicationId string) error {
+	oldRls, err := c.getRelease(request.Workspace, request.ClusterName, request.Namespace, applicationId)
+	if err != nil {
+		klog.Errorf("get release %s/%s failed, error: %s", request.Namespace, applicationId, err)
+		return err
+	}
+
+	switch oldRls.Status.State {
+	case v1alpha1.StateActive, v1alpha1.HelmStatusUpgraded, v1alpha1.HelmStatusCreated, v1alpha1.HelmStatusFailed:
+		// no operation
+	default:
+		return errors.New("can not rollback application now")
+	}
+
+	if request.Revision <= 0 {
+		return errors.New("invalid revision")
+	}
+
+	hw, err := c.helmWrapper(request.ClusterName, request.Namespace, oldRls.Spec.Name)
+	if err != nil {
+		return err
+	}
+
+	r, err := hw.Revision(request.Revision)
+	if err != nil {
+		klog.Errorf("get revision %d of release %s/%s failed, error: %s", request.Revision, request.Namespace, applicationId, err)
+		return err
+	}
+
+	var chartVersion, chartAppVersion string
+	if r.Chart != nil && r.Chart.Metadata != nil {
+		chartVersion = r.Chart.Metadata.Version
+		chartAppVersion = r.Chart.Metadata.AppVersion
+	}
+
+	version := c.findAppVersion(oldRls.Spec.ApplicationId, chartVersion)
+	if version == nil {
+		return fmt.Errorf("app version %s of application %s not found", chartVersion, oldRls.Spec.ApplicationId)
+	}
+
+	values, err := yaml.Marshal(r.Config)
+	if err != nil {
+		return err
+	}
+
+	newRls := oldRls.DeepCopy()
+	newRls.Spec.Version += 1
+	newRls.Spec.RollbackRevision = request.Revision
+	newRls.Spec.ApplicationVersionId = version.GetHelmApplicationVersionId()
+	newRls.Spec.RepoId = version.GetHelmRepoId()
+	newRls.Spec.ChartVersion = chartVersion
+	newRls.Spec.ChartAppVersion = chartAppVersion
+	newRls.Spec.Values = values
+
+	patch := client.MergeFrom(oldRls)
+	data, _ := patch.Data(newRls)
+
+	_, err = c.rlsClient.Patch(context.TODO(), applicationId, patch.Type(), data, metav1.PatchOptions{})
+	if err != nil {
+		klog.Errorf("patch release %s/%s failed, error: %s", request.Namespace, applicationId, err)
+		return err
+	} else {
+		klog.V(2).Infof("rollback release %s/%s to revision %d", request.Namespace, applicationId, request.Revision)
+	}
+
+	return nil
+}
+
+// PreviewApplication renders the release to be installed, all the resources are created.
+func (c *releaseOperator) PreviewApplication(workspace, clusterName, namespace string, request CreateClusterRequest) (*ReleaseDiff, error) {
+	exists, err := c.releaseExists(workspace, clusterName, namespace, request.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.Errorf("get helm release %s failed, error: %v", request.Name, err)
+		return nil, err
+	}
+	if exists {
+		err = fmt.Errorf("release %s exists", request.Name)
+		klog.Error(err)
+		return nil, err
+	}
+
+	hw, err := c.helmWrapper(clusterName, namespace, request.Name,
+		helmwrapper.SetAnnotations(map[string]string{constants.CreatorAnnotationKey: request.Username}),
+		helmwrapper.SetLabels(map[string]string{v1alpha1.ApplicationInstance: request.Name}))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.previewRelease(hw, request.VersionId, request.Conf, "")
+}
+
+// PreviewUpgradeApplication renders the release to be upgraded and compares it with the live release.
+func (c *releaseOperator) PreviewUpgradeApplication(request UpgradeClusterRequest, applicationId string) (*ReleaseDiff, error) {
+	rls, err := c.getRelease(request.Workspace, request.ClusterName, request.Namespace, applicationId)
+	if err != nil {
+		klog.Errorf("get release %s/%s failed, error: %s", request.Namespace, applicationId, err)
+		return nil, err
+	}
+
+	hw, err := c.helmWrapper(rls.GetRlsCluster(), request.Namespace, rls.Spec.Name,
+		helmwrapper.SetAnnotations(map[string]string{constants.CreatorAnnotationKey: rls.GetCreator()}),
+		helmwrapper.SetLabels(map[string]string{v1alpha1.ApplicationInstance: rls.GetTrueName()}))
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := hw.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	// the server uses the old conf if the client doesn't have one
+	values := request.Conf
+	if values == "" {
+		values = string(rls.Spec.Values)
+	}
+	return c.previewRelease(hw, request.VersionId, values, live)
+}
+
+// previewRelease renders the app version with the values, and compares the resources with the live manifest.
+// The errors of rendering and validation are returned in the result.
+func (c *releaseOperator) previewRelease(hw helmwrapper.HelmWrapper, versionId, values, live string) (*ReleaseDiff, error) {
+	version, err := c.getAppVersionWithData(versionId)
+	if err != nil {
+		klog.Errorf("get app version %s chart data failed: %v", versionId, err)
+		return nil, err
+	}
+
+	diff := &ReleaseDiff{}
+	diff.ValuesErrors, err = helmrepoindex.ValidateValues(version.Spec.Data, []byte(values))
+	if err != nil {
+		diff.Error = err.Error()
+		return diff, nil
+	}
+	if len(diff.ValuesErrors) > 0 {
+		diff.Error = valuesValidationFailed.Error()
+		return diff, nil
+	}
+
+	diff.Manifest, err = hw.Render(version.GetTrueName(), string(version.Spec.Data), values)
+	if err != nil {
+		diff.Error = err.Error()
+		return diff, nil
+	}
+
+	diff.Resources, err = helmwrapper.DiffManifest(live, diff.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// validateValues validates the values of the release against the values.schema.json of the chart, the values are
+// validated by helm again when the release is installed, so it's skipped if the chart can't be loaded now.
+func (c *releaseOperator) validateValues(versionId string, values []byte) error {
+	version, err := c.getAppVersionWithData(versionId)
+	if err != nil {
+		klog.Warningf("get app version %s chart data failed, skip validating values: %v", versionId, err)
+		return nil
+	}
+
+	valuesErrors, err := helmrepoindex.ValidateValues(version.Spec.Data, values)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if len(valuesErrors) > 0 {
+		return &ValuesValidationError{Message: valuesValidationFailed.Error(), Errors: valuesErrors}
+	}
+	return nil
+}
+
+// getAppVersionWithData returns the app version with the chart data, from the repo or the app store.
+func (c *releaseOperator) getAppVersionWithData(versionId string) (*v1alpha1.HelmApplicationVersion, error) {
+	if version, exists, err := c.cachedRepos.GetAppVersionWithData(versionId); exists {
+		if err != nil {
+			return nil, err
+		}
+		return version, nil
+	}
+
+	version, err := c.appVersionLister.Get(versionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.backingStoreClient == nil {
+		return nil, downloadFileFailed
+	}
+	data, err := c.backingStoreClient.Read(dataKeyInStorage(version.GetWorkspace(), versionId))
+	if err != nil {
+		klog.Errorf("load chart data for app version: %s failed, error : %s", versionId, err)
+		return nil, downloadFileFailed
+	}
+	version = version.DeepCopy()
+	version.Spec.Data = data
+
+	return version, nil
+}
+
+// findAppVersion returns the version of the application whose chart version is chartVersion
+func (c *releaseOperator) findAppVersion(appId, chartVersion string) *v1alpha1.HelmApplicationVersion {
+	versions, exists := c.cachedRepos.ListAppVersionsByAppId(appId)
+	if !exists {
+		var err error
+		versions, err = c.appVersionLister.List(labels.SelectorFromSet(map[string]string{constants.ChartApplicationIdLabelKey: appId}))
+		if err != nil {
+			klog.Errorf("list app versions of %s failed, error: %s", appId, err)
+			return nil
+		}
+	}
+
+	for _, version := range versions {
+		if version.GetChartVersion() == chartVersion {
+			return version
+		}
+	}
+	return nil
+}
+
 func (c *releaseOperator) DeleteApplication(workspace, clusterName, namespace, id string) error {
 
 	_, err := c.rlsLister.Get(id)
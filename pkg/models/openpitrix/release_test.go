@@ -24,6 +24,9 @@ import (
 	"kubesphere.io/kubesphere/pkg/utils/reposcache"
 
 	"github.com/go-openapi/strfmt"
+	"helm.sh/helm/v3/pkg/chart"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
@@ -71,7 +74,7 @@ func TestOpenPitrixRelease(t *testing.T) {
 		}
 	}
 
-	rlsOperator := newReleaseOperator(reposcache.NewReposCache(), fakeInformerFactory.KubernetesSharedInformerFactory(), fakeInformerFactory.KubeSphereSharedInformerFactory(), ksClient, nil)
+	rlsOperator := newReleaseOperator(reposcache.NewReposCache(), fakeInformerFactory.KubernetesSharedInformerFactory(), fakeInformerFactory.KubeSphereSharedInformerFactory(), ksClient, nil, nil)
 
 	req := CreateClusterRequest{
 		Name:      "test-rls",
@@ -122,6 +125,35 @@ func TestOpenPitrixRelease(t *testing.T) {
 	}
 	_ = describeRls
 
+	// find the app version to roll back to
+	rlsOp := rlsOperator.(*releaseOperator)
+	version := rlsOp.findAppVersion(createAppResp.AppID, "0.1.0")
+	if version == nil || version.Name != createAppResp.VersionID {
+		t.Errorf("app version 0.1.0 of app %s not found", createAppResp.AppID)
+	}
+	if rlsOp.findAppVersion(createAppResp.AppID, "100.0.0") != nil {
+		t.Errorf("unexpected app version")
+	}
+
+	// the release is not found in the other workspaces, clusters and namespaces
+	if _, err = rlsOp.getRelease(testWorkspace, "", "default", rlsId); err != nil {
+		t.Errorf("failed to get release, error: %s", err)
+	}
+	if _, err = rlsOperator.ListApplicationRevisions("other-workspace", "", "default", rlsId); !apierrors.IsNotFound(err) {
+		t.Errorf("expected release not found in other workspace, got %v", err)
+	}
+	if _, err = rlsOperator.DescribeApplicationRevision(testWorkspace, "other-cluster", "default", rlsId, 1); !apierrors.IsNotFound(err) {
+		t.Errorf("expected release not found in other cluster, got %v", err)
+	}
+	upgrade := UpgradeClusterRequest{Workspace: testWorkspace, ClusterName: "other-cluster", Namespace: "default"}
+	if _, err = rlsOperator.PreviewUpgradeApplication(upgrade, rlsId); !apierrors.IsNotFound(err) {
+		t.Errorf("expected release not found in other cluster, got %v", err)
+	}
+	rollback := RollbackClusterRequest{Workspace: testWorkspace, Namespace: "kube-system", Revision: 1}
+	if err = rlsOperator.RollbackApplication(rollback, rlsId); !apierrors.IsNotFound(err) {
+		t.Errorf("expected release not found in other namespace, got %v", err)
+	}
+
 	//delete release
 	err = rlsOperator.DeleteApplication(testWorkspace, "", "default", rlsId)
 	if err != nil {
@@ -129,3 +161,27 @@ func TestOpenPitrixRelease(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestConvertReleaseRevision(t *testing.T) {
+	r := &helmrelease.Release{
+		Version: 2,
+		Info: &helmrelease.Info{
+			Status:      helmrelease.StatusDeployed,
+			Description: "Rollback to 1",
+		},
+		Chart:    &chart.Chart{Metadata: &chart.Metadata{Name: "nginx", Version: "0.1.0", AppVersion: "1.21"}},
+		Config:   map[string]interface{}{"replicaCount": 2},
+		Manifest: "kind: Deployment",
+	}
+
+	rev := convertReleaseRevision(r, false)
+	if rev.Revision != 2 || rev.Status != "deployed" || rev.ChartVersion != "0.1.0" || rev.AppVersion != "1.21" {
+		t.Errorf("unexpected revision %+v", rev)
+	}
+	if rev.Values != "replicaCount: 2\n" || rev.Manifest != "" {
+		t.Errorf("unexpected values or manifest %+v", rev)
+	}
+	if rev = convertReleaseRevision(r, true); rev.Manifest != r.Manifest {
+		t.Errorf("manifest not found")
+	}
+}
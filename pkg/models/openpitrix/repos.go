@@ -17,6 +17,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/url"
+	"reflect"
 	"sort"
 	"strings"
 	"time"
@@ -215,7 +216,8 @@ func (c *repoOperator) ModifyRepo(id string, request *ModifyRepoRequest) error {
 		// trim the credential from url
 		parsedUrl.User = nil
 		cred := &v1alpha1.HelmRepoCredential{}
-		if strings.HasPrefix(*request.URL, "https://") || strings.HasPrefix(*request.URL, "http://") {
+		if strings.HasPrefix(*request.URL, "https://") || strings.HasPrefix(*request.URL, "http://") ||
+			helmrepoindex.IsOCIRepo(*request.URL) {
 			if userInfo != nil {
 				cred.Password, _ = userInfo.Password()
 				cred.Username = userInfo.Username()
@@ -243,6 +245,18 @@ func (c *repoOperator) ModifyRepo(id string, request *ModifyRepoRequest) error {
 		repoCopy.Spec.Version += 1
 	}
 
+	// modify the keys to verify the charts, the charts will be verified again in the next sync
+	if request.Verification != nil {
+		if *request.Verification == (v1alpha1.ChartVerification{}) {
+			repoCopy.Spec.Verification = nil
+		} else {
+			repoCopy.Spec.Verification = request.Verification
+		}
+		if !reflect.DeepEqual(repo.Spec.Verification, repoCopy.Spec.Verification) {
+			repoCopy.Spec.Version += 1
+		}
+	}
+
 	patch := client.MergeFrom(repo)
 	data, err := patch.Data(repoCopy)
 	if err != nil {
@@ -14,7 +14,14 @@ limitations under the License.
 package openpitrix
 
 import (
+	"encoding/json"
+
 	"github.com/go-openapi/strfmt"
+
+	"kubesphere.io/api/application/v1alpha1"
+
+	"kubesphere.io/kubesphere/pkg/simple/client/openpitrix/helmrepoindex"
+	"kubesphere.io/kubesphere/pkg/simple/client/openpitrix/helmwrapper"
 )
 
 type ModifyAppRequest struct {
@@ -68,6 +75,12 @@ type ModifyAppVersionRequest struct {
 	// package of app to replace other
 	Package []byte `json:"package,omitempty"`
 
+	// provenance file (.prov) of the package
+	Provenance []byte `json:"provenance,omitempty"`
+
+	// base64 encoded cosign signature of the package
+	Signature string `json:"signature,omitempty"`
+
 	// filename map to file_content
 	PackageFiles map[string][]byte `json:"package_files,omitempty"`
 
@@ -302,6 +315,12 @@ type CreateAppRequest struct {
 	// required, version with specific app package
 	VersionPackage strfmt.Base64 `json:"version_package,omitempty"`
 
+	// optional, provenance file (.prov) of the package
+	VersionProvenance strfmt.Base64 `json:"version_provenance,omitempty"`
+
+	// optional, base64 encoded cosign signature of the package
+	VersionSignature string `json:"version_signature,omitempty"`
+
 	// optional, vmbased/helm
 	VersionType string `json:"version_type,omitempty"`
 
@@ -386,6 +405,9 @@ type AppVersion struct {
 	VersionId string `json:"version_id,omitempty"`
 
 	ClusterTotal *int `json:"cluster_total,omitempty"`
+
+	// result of the signature verification, nil if the chart has not been verified
+	Verification *v1alpha1.ChartVerificationStatus `json:"verification,omitempty"`
 }
 
 type CreateAppVersionResponse struct {
@@ -431,12 +453,30 @@ type CreateAppVersionRequest struct {
 	// package of app of specific version
 	Package strfmt.Base64 `json:"package,omitempty"`
 
+	// optional, provenance file (.prov) of the package
+	Provenance strfmt.Base64 `json:"provenance,omitempty"`
+
+	// optional, base64 encoded cosign signature of the package
+	Signature string `json:"signature,omitempty"`
+
 	// optional: vmbased/helm
 	Type string `json:"type,omitempty"`
 
 	Username string `json:"-"`
 }
 
+type GetAppVersionValuesSchemaResponse struct {
+
+	// values.schema.json of the chart, empty if the chart has no schema
+	Schema json.RawMessage `json:"schema,omitempty"`
+
+	// values.schema.json of the subcharts, indexed by the path of the subchart values
+	SubchartSchemas map[string]json.RawMessage `json:"subchart_schemas,omitempty"`
+
+	// version id
+	VersionId string `json:"version_id,omitempty"`
+}
+
 type GetAppVersionFilesRequest struct {
 	Files []string `json:"files,omitempty"`
 }
@@ -603,6 +643,9 @@ type CreateRepoRequest struct {
 
 	// required, visibility eg:[public|private]
 	Visibility string `json:"visibility,omitempty"`
+
+	// keys to verify the signatures of the charts in the repository
+	Verification *v1alpha1.ChartVerification `json:"verification,omitempty"`
 }
 
 type RepoCategorySet []*ResourceCategory
@@ -639,6 +682,9 @@ type ModifyRepoRequest struct {
 
 	// visibility eg:[public|private]
 	Visibility *string `json:"visibility,omitempty"`
+
+	// keys to verify the signatures of the charts in the repository, the verification is disabled if all the fields are empty
+	Verification *v1alpha1.ChartVerification `json:"verification,omitempty"`
 }
 
 type RepoActionRequest struct {
@@ -781,6 +827,12 @@ type UpgradeClusterRequest struct {
 	// release namespace
 	Namespace string `json:"namespace,omitempty"`
 
+	// workspace of the release
+	Workspace string `json:"-"`
+
+	// cluster name of the release
+	ClusterName string `json:"-"`
+
 	// cluster id
 	ClusterId string `json:"cluster_id"`
 
@@ -802,6 +854,77 @@ type UpgradeClusterRequest struct {
 	Username string `json:"-"`
 }
 
+type RollbackClusterRequest struct {
+	// release namespace
+	Namespace string `json:"namespace,omitempty"`
+
+	// workspace of the release
+	Workspace string `json:"-"`
+
+	// cluster name
+	ClusterName string `json:"-"`
+
+	// required, revision of the release to roll back to
+	Revision int `json:"revision"`
+
+	Username string `json:"-"`
+}
+
+type ReleaseRevision struct {
+
+	// revision of the release
+	Revision int `json:"revision"`
+
+	// status of the revision, deployed, superseded, failed...
+	Status string `json:"status,omitempty"`
+
+	// chart name
+	ChartName string `json:"chart_name,omitempty"`
+
+	// chart version
+	ChartVersion string `json:"chart_version,omitempty"`
+
+	// app version of the chart
+	AppVersion string `json:"app_version,omitempty"`
+
+	// values of the revision, in yaml
+	Values string `json:"values,omitempty"`
+
+	// description of the revision
+	Description string `json:"description,omitempty"`
+
+	// the time when the revision was deployed
+	Updated *strfmt.DateTime `json:"updated,omitempty"`
+
+	// rendered manifest of the revision
+	Manifest string `json:"manifest,omitempty"`
+}
+
+type ReleaseDiff struct {
+
+	// rendered manifest of the release to be installed or upgraded
+	Manifest string `json:"manifest,omitempty"`
+
+	// changes of the resources compared with the live release
+	Resources []helmwrapper.ResourceDiff `json:"resources,omitempty"`
+
+	// error of rendering the chart or validating the resources
+	Error string `json:"error,omitempty"`
+
+	// fields of the values which violate the values.schema.json of the chart
+	ValuesErrors []helmrepoindex.ValuesError `json:"valuesErrors,omitempty"`
+}
+
+// ValuesValidationError is returned if the values of the release violate the values.schema.json of the chart
+type ValuesValidationError struct {
+	Message string                      `json:"message"`
+	Errors  []helmrepoindex.ValuesError `json:"errors"`
+}
+
+func (e *ValuesValidationError) Error() string {
+	return e.Message
+}
+
 type Cluster struct {
 
 	// additional info
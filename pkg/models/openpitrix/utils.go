@@ -26,9 +26,11 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-openapi/strfmt"
+	helmrelease "helm.sh/helm/v3/pkg/release"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/yaml"
 
 	"kubesphere.io/api/application/v1alpha1"
 
@@ -265,6 +267,32 @@ func convertApplication(rls *v1alpha1.HelmRelease, rlsInfos []*resource.Info) *A
 	return app
 }
 
+// convertReleaseRevision converts the helm release to a revision, the manifest is included only if withManifest is true
+func convertReleaseRevision(r *helmrelease.Release, withManifest bool) *ReleaseRevision {
+	out := &ReleaseRevision{Revision: r.Version}
+	if r.Info != nil {
+		out.Status = r.Info.Status.String()
+		out.Description = r.Info.Description
+		ut := strfmt.DateTime(r.Info.LastDeployed.Time)
+		out.Updated = &ut
+	}
+	if r.Chart != nil && r.Chart.Metadata != nil {
+		out.ChartName = r.Chart.Metadata.Name
+		out.ChartVersion = r.Chart.Metadata.Version
+		out.AppVersion = r.Chart.Metadata.AppVersion
+	}
+	if len(r.Config) > 0 {
+		values, err := yaml.Marshal(r.Config)
+		if err == nil {
+			out.Values = string(values)
+		}
+	}
+	if withManifest {
+		out.Manifest = r.Manifest
+	}
+	return out
+}
+
 func convertApp(app *v1alpha1.HelmApplication, versions []*v1alpha1.HelmApplicationVersion, ctg *v1alpha1.HelmCategory, rlsCount int) *App {
 	if app == nil {
 		return nil
@@ -417,6 +445,7 @@ func convertAppVersion(in *v1alpha1.HelmApplicationVersion) *AppVersion {
 	out.Name = in.GetVersionName()
 	out.PackageName = fmt.Sprintf("%s-%s.tgz", in.GetTrueName(), in.GetChartVersion())
 	out.VersionId = in.GetHelmApplicationVersionId()
+	out.Verification = in.Status.Verification
 	return &out
 }
 
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openpitrix
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/api/application/v1alpha1"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/simple/client/openpitrix/helmrepoindex"
+)
+
+const (
+	// The keys to verify the charts uploaded to a workspace are stored in the configmap
+	// chart-keyring-<workspace> in the namespace kubesphere-system.
+	chartKeyringPrefix = "chart-keyring-"
+
+	chartKeyringKey  = "keyring"
+	chartCosignKey   = "cosign.pub"
+	chartRequiredKey = "required"
+)
+
+// workspaceChartVerification returns the keys to verify the charts uploaded to the workspace,
+// nil is returned if no keys are configured.
+func (c *applicationOperator) workspaceChartVerification(workspace string) (*v1alpha1.ChartVerification, error) {
+	if c.cmLister == nil || workspace == "" {
+		return nil, nil
+	}
+	cm, err := c.cmLister.ConfigMaps(constants.KubeSphereNamespace).Get(chartKeyringPrefix + workspace)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		klog.Errorf("get chart keyring of workspace %s failed, error: %s", workspace, err)
+		return nil, err
+	}
+	required, _ := strconv.ParseBool(cm.Data[chartRequiredKey])
+	return &v1alpha1.ChartVerification{
+		Keyring:   cm.Data[chartKeyringKey],
+		CosignKey: cm.Data[chartCosignKey],
+		Required:  required,
+	}, nil
+}
+
+// verifyPackage verifies the chart package uploaded to the workspace. The package is rejected if the signature is
+// invalid, or the workspace requires signed charts and the package is unsigned.
+func (c *applicationOperator) verifyPackage(workspace string, pkg, provenance []byte, signature string) (*v1alpha1.ChartVerificationStatus, error) {
+	keys, err := c.workspaceChartVerification(workspace)
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil && len(provenance) == 0 && signature == "" {
+		return nil, nil
+	}
+
+	verification := helmrepoindex.VerifyChart(pkg, provenance, []byte(signature), keys)
+	if err = helmrepoindex.CheckVerification(verification, keys != nil && keys.Required); err != nil {
+		klog.V(4).Infof("chart package uploaded to workspace %s rejected: %s", workspace, verification.Message)
+		return nil, chartNotVerifiedError(err)
+	}
+	return verification, nil
+}
+
+// updateVerificationStatus saves the verification status of the app version, the status may be initialized by
+// the app version controller at the same time.
+func (c *applicationOperator) updateVerificationStatus(versionId string, verification *v1alpha1.ChartVerificationStatus) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		version, err := c.appVersionClient.Get(context.TODO(), versionId, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		version.Status.Verification = verification
+		_, err = c.appVersionClient.UpdateStatus(context.TODO(), version, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// checkVerification checks whether the app version can be deployed
+func (c *releaseOperator) checkVerification(version *v1alpha1.HelmApplicationVersion) error {
+	required := false
+	if repoId := version.GetHelmRepoId(); repoId != "" && repoId != v1alpha1.AppStoreRepoId {
+		repo, err := c.repoLister.Get(repoId)
+		if err != nil {
+			klog.Errorf("get helm repo %s failed, error: %s", repoId, err)
+			return err
+		}
+		required = repo.VerificationRequired()
+	}
+	if err := helmrepoindex.CheckVerification(version.Status.Verification, required); err != nil {
+		return chartNotVerifiedError(err)
+	}
+	return nil
+}
+
+func chartNotVerifiedError(err error) error {
+	return status.Error(codes.FailedPrecondition, err.Error())
+}
@@ -0,0 +1,504 @@
+// Copyright 2023 The KubeSphere Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package v2
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+const (
+	RegistryTypeDockerHub    = "dockerhub"
+	RegistryTypeHarbor       = "harbor"
+	RegistryTypeDistribution = "distribution"
+
+	dockerHubAPI = "https://hub.docker.com"
+	// max page size of the Docker Hub and Harbor APIs
+	maxPageSize = 100
+)
+
+var dockerHubRegistries = map[string]bool{
+	"docker.io":               true,
+	"index.docker.io":         true,
+	"registry-1.docker.io":    true,
+	"registry.hub.docker.com": true,
+}
+
+// catalog lists the repositories and tags of a registry with the API of its type. The Distribution v2
+// API has no search and returns no push time, so the results are filtered and paged in memory.
+type catalog interface {
+	Type() string
+	ListRepositories(keyword string, page, limit int) (*RepositoryList, error)
+	ListTags(repository name.Repository, keyword string, page, limit int) (*TagList, error)
+}
+
+// catalog detects the type of the registry, Docker Hub by its host, Harbor by its ping API,
+// and falls back to Distribution v2.
+func (r *registryer) catalog(registry name.Registry) catalog {
+	if dockerHubRegistries[registry.RegistryStr()] {
+		return &dockerHubCatalog{registryer: r}
+	}
+	if r.isHarbor(registry) {
+		return &harborCatalog{registryer: r, registry: registry}
+	}
+	return &distributionCatalog{registryer: r, registry: registry}
+}
+
+func (r *registryer) ListRepositories(registry, keyword string, page, limit int) (*RepositoryList, error) {
+	reg, err := name.NewRegistry(registry, r.opts.name...)
+	if err != nil {
+		return nil, err
+	}
+	return r.catalog(reg).ListRepositories(keyword, page, limit)
+}
+
+func (r *registryer) ListTags(repository, keyword string, page, limit int) (*TagList, error) {
+	repo, err := name.NewRepository(repository, r.opts.name...)
+	if err != nil {
+		return nil, err
+	}
+	return r.catalog(repo.Registry).ListTags(repo, keyword, page, limit)
+}
+
+// credential returns the username and password of the authenticator for the APIs other than Distribution v2
+func (r *registryer) credential() (string, string, error) {
+	auth, err := r.opts.auth.Authorization()
+	if err != nil {
+		return "", "", err
+	}
+	if auth.Username == "" && auth.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", err
+		}
+		username, password, _ := strings.Cut(string(decoded), ":")
+		return username, password, nil
+	}
+	return auth.Username, auth.Password, nil
+}
+
+func (r *registryer) isHarbor(registry name.Registry) bool {
+	resp, err := r.doRequest(http.MethodGet, fmt.Sprintf("%s://%s/api/v2.0/ping", registry.Scheme(), registry.RegistryStr()), nil, nil)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+func (r *registryer) doRequest(method, u string, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(r.opts.ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	return (&http.Client{Transport: r.opts.transport}).Do(req)
+}
+
+// getJSON decodes the response of the GET request into v, and returns the response headers
+func (r *registryer) getJSON(u string, header http.Header, v interface{}) (http.Header, error) {
+	resp, err := r.doRequest(http.MethodGet, u, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = transport.CheckError(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return resp.Header, json.NewDecoder(resp.Body).Decode(v)
+}
+
+// pageRange returns the range of the page in a list of total items, limit <= 0 means no paging
+func pageRange(total, page, limit int) (int, int) {
+	if limit <= 0 {
+		return 0, total
+	}
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * limit
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+func matchKeyword(s, keyword string) bool {
+	return keyword == "" || strings.Contains(strings.ToLower(s), strings.ToLower(keyword))
+}
+
+func clampPageSize(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return page, limit
+}
+
+type distributionCatalog struct {
+	*registryer
+	registry name.Registry
+}
+
+func (c *distributionCatalog) Type() string {
+	return RegistryTypeDistribution
+}
+
+func (c *distributionCatalog) ListRepositories(keyword string, page, limit int) (*RepositoryList, error) {
+	repositories, err := remote.Catalog(c.opts.ctx, c.registry, c.opts.remote...)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []Repository
+	for _, repository := range repositories {
+		if matchKeyword(repository, keyword) {
+			matched = append(matched, Repository{Name: repository})
+		}
+	}
+	start, end := pageRange(len(matched), page, limit)
+	return &RepositoryList{
+		Registry:   c.registry.RegistryStr(),
+		Type:       c.Type(),
+		Items:      append(make([]Repository, 0), matched[start:end]...),
+		TotalItems: len(matched),
+	}, nil
+}
+
+func (c *distributionCatalog) ListTags(repository name.Repository, keyword string, page, limit int) (*TagList, error) {
+	tags, err := remote.ListWithContext(c.opts.ctx, repository, c.opts.remote...)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []string
+	for _, tag := range tags {
+		if matchKeyword(tag, keyword) {
+			matched = append(matched, tag)
+		}
+	}
+	start, end := pageRange(len(matched), page, limit)
+
+	items := make([]Tag, 0, end-start)
+	for _, tag := range matched[start:end] {
+		item := Tag{Name: tag}
+		// only the digest is available, the push time is not recorded by Distribution v2
+		if desc, err := remote.Head(repository.Tag(tag), c.opts.remote...); err == nil {
+			item.Digest = desc.Digest.String()
+			item.Size = desc.Size
+		}
+		items = append(items, item)
+	}
+	return &TagList{
+		Registry:   repository.RegistryStr(),
+		Repository: repository.RepositoryStr(),
+		Type:       c.Type(),
+		Items:      items,
+		TotalItems: len(matched),
+	}, nil
+}
+
+type harborCatalog struct {
+	*registryer
+	registry name.Registry
+}
+
+type harborRepository struct {
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	ArtifactCount int       `json:"artifact_count"`
+	PullCount     int       `json:"pull_count"`
+	UpdateTime    time.Time `json:"update_time"`
+}
+
+type harborArtifact struct {
+	Digest   string    `json:"digest"`
+	Size     int64     `json:"size"`
+	PushTime time.Time `json:"push_time"`
+	Tags     []struct {
+		Name     string    `json:"name"`
+		PushTime time.Time `json:"push_time"`
+	} `json:"tags"`
+}
+
+func (c *harborCatalog) Type() string {
+	return RegistryTypeHarbor
+}
+
+func (c *harborCatalog) header() (http.Header, error) {
+	username, password, err := c.credential()
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	if username != "" {
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+	}
+	return header, nil
+}
+
+func (c *harborCatalog) ListRepositories(keyword string, page, limit int) (*RepositoryList, error) {
+	header, err := c.header()
+	if err != nil {
+		return nil, err
+	}
+	page, limit = clampPageSize(page, limit)
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("page_size", strconv.Itoa(limit))
+	if keyword != "" {
+		query.Set("q", "name=~"+keyword)
+	}
+
+	var repositories []harborRepository
+	respHeader, err := c.getJSON(fmt.Sprintf("%s://%s/api/v2.0/repositories?%s", c.registry.Scheme(), c.registry.RegistryStr(), query.Encode()), header, &repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &RepositoryList{
+		Registry: c.registry.RegistryStr(),
+		Type:     c.Type(),
+		Items:    make([]Repository, 0, len(repositories)),
+	}
+	for _, repository := range repositories {
+		updatedAt := repository.UpdateTime
+		list.Items = append(list.Items, Repository{
+			Name:        repository.Name,
+			Description: repository.Description,
+			PullCount:   repository.PullCount,
+			UpdatedAt:   &updatedAt,
+		})
+	}
+	list.TotalItems, _ = strconv.Atoi(respHeader.Get("X-Total-Count"))
+	return list, nil
+}
+
+// ListTags lists all artifacts of the repository, as Harbor pages artifacts rather than tags
+func (c *harborCatalog) ListTags(repository name.Repository, keyword string, page, limit int) (*TagList, error) {
+	header, err := c.header()
+	if err != nil {
+		return nil, err
+	}
+	project, repo, ok := strings.Cut(repository.RepositoryStr(), "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid harbor repository %s, expected <project>/<repository>", repository.RepositoryStr())
+	}
+
+	var matched []Tag
+	for p := 1; ; p++ {
+		var artifacts []harborArtifact
+		// slashes in the repository name have to be escaped twice
+		u := fmt.Sprintf("%s://%s/api/v2.0/projects/%s/repositories/%s/artifacts?with_tag=true&page=%d&page_size=%d",
+			c.registry.Scheme(), c.registry.RegistryStr(), url.PathEscape(project), url.PathEscape(url.PathEscape(repo)), p, maxPageSize)
+		if _, err = c.getJSON(u, header, &artifacts); err != nil {
+			return nil, err
+		}
+		for _, artifact := range artifacts {
+			for _, tag := range artifact.Tags {
+				if !matchKeyword(tag.Name, keyword) {
+					continue
+				}
+				pushedAt := tag.PushTime
+				matched = append(matched, Tag{Name: tag.Name, Digest: artifact.Digest, Size: artifact.Size, PushedAt: &pushedAt})
+			}
+		}
+		if len(artifacts) < maxPageSize {
+			break
+		}
+	}
+
+	start, end := pageRange(len(matched), page, limit)
+	return &TagList{
+		Registry:   repository.RegistryStr(),
+		Repository: repository.RepositoryStr(),
+		Type:       c.Type(),
+		Items:      append(make([]Tag, 0), matched[start:end]...),
+		TotalItems: len(matched),
+	}, nil
+}
+
+type dockerHubCatalog struct {
+	*registryer
+}
+
+type dockerHubPage struct {
+	Count   int             `json:"count"`
+	Results json.RawMessage `json:"results"`
+}
+
+type dockerHubRepository struct {
+	Name        string    `json:"name"`
+	Namespace   string    `json:"namespace"`
+	Description string    `json:"description"`
+	PullCount   int       `json:"pull_count"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+type dockerHubTag struct {
+	Name          string    `json:"name"`
+	Digest        string    `json:"digest"`
+	FullSize      int64     `json:"full_size"`
+	TagLastPushed time.Time `json:"tag_last_pushed"`
+	LastUpdated   time.Time `json:"last_updated"`
+}
+
+func (c *dockerHubCatalog) Type() string {
+	return RegistryTypeDockerHub
+}
+
+// login exchanges the credential for a JWT of the Docker Hub API, private repositories are not
+// visible to anonymous users.
+func (c *dockerHubCatalog) login() (string, http.Header, error) {
+	username, password, err := c.credential()
+	if err != nil || username == "" {
+		return "", http.Header{}, err
+	}
+
+	body, err := json.Marshal(map[string]string{"username": username, "password": password})
+	if err != nil {
+		return "", nil, err
+	}
+	resp, err := c.doRequest(http.MethodPost, dockerHubAPI+"/v2/users/login", bytes.NewReader(body), http.Header{"Content-Type": []string{"application/json"}})
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if err = transport.CheckError(resp, http.StatusOK); err != nil {
+		return "", nil, err
+	}
+
+	token := struct {
+		Token string `json:"token"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", nil, err
+	}
+	return username, http.Header{"Authorization": []string{"JWT " + token.Token}}, nil
+}
+
+// ListRepositories lists the repositories of the namespace of the user, Docker Hub has no catalog API
+func (c *dockerHubCatalog) ListRepositories(keyword string, page, limit int) (*RepositoryList, error) {
+	username, header, err := c.login()
+	if err != nil {
+		return nil, err
+	}
+	if username == "" {
+		return nil, fmt.Errorf("listing repositories of Docker Hub requires credential")
+	}
+
+	page, limit = clampPageSize(page, limit)
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("page_size", strconv.Itoa(limit))
+	if keyword != "" {
+		query.Set("name", keyword)
+	}
+
+	result := &dockerHubPage{}
+	if _, err = c.getJSON(fmt.Sprintf("%s/v2/repositories/%s/?%s", dockerHubAPI, url.PathEscape(username), query.Encode()), header, result); err != nil {
+		return nil, err
+	}
+	var repositories []dockerHubRepository
+	if err = json.Unmarshal(result.Results, &repositories); err != nil {
+		return nil, err
+	}
+
+	list := &RepositoryList{
+		Registry:   DefaultRegistry,
+		Type:       c.Type(),
+		Items:      make([]Repository, 0, len(repositories)),
+		TotalItems: result.Count,
+	}
+	for _, repository := range repositories {
+		updatedAt := repository.LastUpdated
+		list.Items = append(list.Items, Repository{
+			Name:        repository.Namespace + "/" + repository.Name,
+			Description: repository.Description,
+			PullCount:   repository.PullCount,
+			UpdatedAt:   &updatedAt,
+		})
+	}
+	return list, nil
+}
+
+func (c *dockerHubCatalog) ListTags(repository name.Repository, keyword string, page, limit int) (*TagList, error) {
+	_, header, err := c.login()
+	if err != nil {
+		return nil, err
+	}
+
+	page, limit = clampPageSize(page, limit)
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("page_size", strconv.Itoa(limit))
+	if keyword != "" {
+		query.Set("name", keyword)
+	}
+
+	result := &dockerHubPage{}
+	// official images are in the library namespace, e.g. library/nginx
+	if _, err = c.getJSON(fmt.Sprintf("%s/v2/repositories/%s/tags?%s", dockerHubAPI, repository.RepositoryStr(), query.Encode()), header, result); err != nil {
+		return nil, err
+	}
+	var tags []dockerHubTag
+	if err = json.Unmarshal(result.Results, &tags); err != nil {
+		return nil, err
+	}
+
+	list := &TagList{
+		Registry:   repository.RegistryStr(),
+		Repository: repository.RepositoryStr(),
+		Type:       c.Type(),
+		Items:      make([]Tag, 0, len(tags)),
+		TotalItems: result.Count,
+	}
+	for _, tag := range tags {
+		pushedAt := tag.TagLastPushed
+		if pushedAt.IsZero() {
+			pushedAt = tag.LastUpdated
+		}
+		list.Items = append(list.Items, Tag{Name: tag.Name, Digest: tag.Digest, Size: tag.FullSize, PushedAt: &pushedAt})
+	}
+	return list, nil
+}
+
+// registryOfSecret returns the registry host of the auth entry, which may be an URL like https://index.docker.io/v1/
+func registryOfSecret(server string) string {
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		return u.Host
+	}
+	host, _, _ := strings.Cut(server, "/")
+	return host
+}
@@ -0,0 +1,210 @@
+// Copyright 2023 The KubeSphere Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+func newHarborServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2.0/ping" {
+			if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "Harbor12345" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		switch r.URL.EscapedPath() {
+		case "/api/v2.0/ping":
+			w.Write([]byte("Pong"))
+		case "/api/v2.0/repositories":
+			if q := r.URL.Query().Get("q"); q != "name=~nginx" {
+				t.Errorf("unexpected query %s", q)
+			}
+			w.Header().Set("X-Total-Count", "1")
+			w.Write([]byte(`[{"name": "library/nginx", "pull_count": 3, "update_time": "2023-03-01T00:00:00Z"}]`))
+		case "/api/v2.0/projects/library/repositories/kubesphere%252Fnginx/artifacts":
+			w.Write([]byte(`[
+  {"digest": "sha256:2", "size": 20, "tags": [{"name": "1.23", "push_time": "2023-03-02T00:00:00Z"}, {"name": "latest", "push_time": "2023-03-02T00:00:00Z"}]},
+  {"digest": "sha256:1", "size": 10, "tags": [{"name": "1.22", "push_time": "2023-03-01T00:00:00Z"}]}
+]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newDistributionServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/_catalog":
+			json.NewEncoder(w).Encode(map[string][]string{"repositories": {"calico/cni", "calico/node", "kubesphere/ks-apiserver"}})
+		case r.URL.Path == "/v2/calico/cni/tags/list":
+			json.NewEncoder(w).Encode(map[string]interface{}{"name": "calico/cni", "tags": []string{"v3.20.0", "v3.21.0", "v3.22.0"}})
+		case strings.HasPrefix(r.URL.Path, "/v2/calico/cni/manifests/") && r.Method == http.MethodHead:
+			tag := strings.TrimPrefix(r.URL.Path, "/v2/calico/cni/manifests/")
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Header().Set("Docker-Content-Digest", fmt.Sprintf("sha256:%064x", len(tag)+int(tag[len(tag)-3])))
+			w.Header().Set("Content-Length", "100")
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestHarborCatalog(t *testing.T) {
+	server := newHarborServer(t)
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "http://")
+
+	registryer := NewRegistryer(WithAuth(&authn.Basic{Username: "admin", Password: "Harbor12345"}), Insecure)
+
+	repositories, err := registryer.ListRepositories(registry, "nginx", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repositories.Type != RegistryTypeHarbor || repositories.TotalItems != 1 || len(repositories.Items) != 1 ||
+		repositories.Items[0].Name != "library/nginx" || repositories.Items[0].UpdatedAt == nil {
+		t.Errorf("unexpected repositories %+v", repositories)
+	}
+
+	tags, err := registryer.ListTags(registry+"/library/kubesphere/nginx", "1.2", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags.TotalItems != 2 || len(tags.Items) != 1 {
+		t.Fatalf("unexpected tags %+v", tags)
+	}
+	if tag := tags.Items[0]; tag.Name != "1.23" || tag.Digest != "sha256:2" || tag.Size != 20 || tag.PushedAt == nil {
+		t.Errorf("unexpected tag %+v", tag)
+	}
+
+	anonymous := NewRegistryer(Insecure)
+	if _, err = anonymous.ListRepositories(registry, "", 1, 10); err == nil {
+		t.Error("expected unauthorized error")
+	}
+}
+
+func TestDistributionCatalog(t *testing.T) {
+	server := newDistributionServer()
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "http://")
+
+	registryer := NewRegistryer(Insecure)
+
+	repositories, err := registryer.ListRepositories(registry, "calico", 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &RepositoryList{
+		Registry:   registry,
+		Type:       RegistryTypeDistribution,
+		Items:      []Repository{{Name: "calico/node"}},
+		TotalItems: 2,
+	}
+	if diff := cmp.Diff(expected, repositories); diff != "" {
+		t.Errorf("unexpected repositories: %s", diff)
+	}
+
+	tags, err := registryer.ListTags(registry+"/calico/cni", "v3.2", 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags.TotalItems != 3 || len(tags.Items) != 2 || tags.Items[0].Name != "v3.20.0" || tags.Items[0].Digest == "" {
+		t.Errorf("unexpected tags %+v", tags)
+	}
+}
+
+func TestPageRange(t *testing.T) {
+	tests := []struct {
+		total, page, limit int
+		start, end         int
+	}{
+		{10, 1, 3, 0, 3},
+		{10, 4, 3, 9, 10},
+		{10, 5, 3, 10, 10},
+		{10, 0, 3, 0, 3},
+		{10, 1, 0, 0, 10},
+	}
+	for _, tt := range tests {
+		if start, end := pageRange(tt.total, tt.page, tt.limit); start != tt.start || end != tt.end {
+			t.Errorf("pageRange(%d, %d, %d) = %d, %d, want %d, %d", tt.total, tt.page, tt.limit, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestRegistryOfSecret(t *testing.T) {
+	for server, expected := range map[string]string{
+		"https://index.docker.io/v1/": "index.docker.io",
+		"harbor.example.com":          "harbor.example.com",
+		"harbor.example.com:8443/v2":  "harbor.example.com:8443",
+		"http://10.0.0.1:5000":        "10.0.0.1:5000",
+	} {
+		if got := registryOfSecret(server); got != expected {
+			t.Errorf("registryOfSecret(%s) = %s, want %s", server, got, expected)
+		}
+	}
+}
+
+func TestCheckRegistry(t *testing.T) {
+	tests := []struct {
+		secret     string
+		repository string
+		allowed    bool
+	}{
+		{"https://index.docker.io/v1/", "library/nginx", true},
+		{"docker.io", "registry-1.docker.io/library/nginx", true},
+		{"harbor.example.com:8443", "harbor.example.com:8443/library/nginx", true},
+		{"harbor.example.com", "evil.example.com/library/nginx", false},
+		{"harbor.example.com", "library/nginx", false},
+		{"", "evil.example.com/library/nginx", true},
+	}
+	for _, tt := range tests {
+		secretAuth, err := NewSecretAuthenticator(nil)
+		if tt.secret != "" {
+			secretAuth, err = NewSecretAuthenticator(buildSecret(tt.secret, "admin", "P@88w0rd", false))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		repo, err := name.NewRepository(tt.repository)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = checkRegistry(secretAuth, repo.Registry); (err == nil) != tt.allowed {
+			t.Errorf("checkRegistry(%s, %s) = %v, allowed: %v", tt.secret, tt.repository, err, tt.allowed)
+		}
+	}
+}
+
+func TestInspectOtherRegistry(t *testing.T) {
+	helper := NewRegistryHelper(nil)
+	// the credentials of the secret are never sent to the registry of the image
+	if _, err := helper.Inspect(context.Background(), buildSecret("harbor.example.com", "admin", "P@88w0rd", false), "evil.example.com/library/nginx:latest"); err == nil || !strings.Contains(err.Error(), "differs from registry") {
+		t.Errorf("expected the registry of the image to be rejected, got %v", err)
+	}
+}
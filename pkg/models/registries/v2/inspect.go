@@ -0,0 +1,246 @@
+// Copyright 2023 The KubeSphere Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/kubesphere/pkg/models/registries/vulnerability"
+)
+
+// suffixes of the tags which cosign attaches to an image, e.g. sha256-<hex>.sbom
+var cosignAttachments = map[string]string{
+	"sbom": ReferrerKindSBOM,
+	"sig":  ReferrerKindSignature,
+	"att":  ReferrerKindAttestation,
+}
+
+// referrersIndex is the response of the OCI referrers API, and the referrers tag schema
+type referrersIndex struct {
+	Manifests []referrerDescriptor `json:"manifests"`
+}
+
+// referrerDescriptor is v1.Descriptor with the artifactType field
+type referrerDescriptor struct {
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Size         int64             `json:"size"`
+	Digest       string            `json:"digest"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// Inspect returns the layers, ports, labels and referrers of the image, the OS packages of the image
+// are scanned if database is not nil.
+func (r *registryer) Inspect(image string, database *vulnerability.Database) (*ImageInspection, error) {
+	img, ref, err := r.getImage(image)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	config, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	inspection := &ImageInspection{
+		Image:        ref.Name(),
+		Digest:       digest.String(),
+		MediaType:    string(manifest.MediaType),
+		OS:           config.OS,
+		Architecture: config.Architecture,
+		Size:         manifest.Config.Size,
+		Layers:       make([]ImageLayer, 0, len(manifest.Layers)),
+		Labels:       config.Config.Labels,
+	}
+	if !config.Created.IsZero() {
+		created := config.Created.Time
+		inspection.Created = &created
+	}
+
+	// the history entries of empty layers have no layer in the manifest
+	var history []v1.History
+	for _, h := range config.History {
+		if !h.EmptyLayer {
+			history = append(history, h)
+		}
+	}
+	for i, layer := range manifest.Layers {
+		l := ImageLayer{
+			Digest:    layer.Digest.String(),
+			MediaType: string(layer.MediaType),
+			Size:      layer.Size,
+		}
+		if len(history) == len(manifest.Layers) {
+			l.CreatedBy = history[i].CreatedBy
+		}
+		inspection.Layers = append(inspection.Layers, l)
+		inspection.Size += layer.Size
+	}
+
+	for port := range config.Config.ExposedPorts {
+		inspection.ExposedPorts = append(inspection.ExposedPorts, port)
+	}
+	sort.Strings(inspection.ExposedPorts)
+
+	inspection.Referrers = r.referrers(ref.Context(), digest)
+
+	if database != nil {
+		summary, err := database.Scan(r.opts.ctx, digest.String(), img)
+		if err != nil {
+			klog.Errorf("failed to scan image %s: %v", image, err)
+			inspection.ScanError = err.Error()
+		}
+		inspection.Vulnerabilities = summary
+	}
+
+	return inspection, nil
+}
+
+// referrers lists the artifacts attached to the image, registries which don't support the referrers API
+// fall back to the referrers tag schema. The cosign attachments are found by their tags. Errors are logged
+// only, as a registry may forbid any of the requests.
+func (r *registryer) referrers(repo name.Repository, digest v1.Hash) []ImageReferrer {
+	referrers := make([]ImageReferrer, 0)
+
+	index, err := r.referrersAPI(repo, digest)
+	if err != nil {
+		klog.V(4).Infof("referrers API of %s is not available: %v", repo, err)
+		index, err = r.referrersTag(repo, digest)
+		if err != nil {
+			klog.V(4).Infof("failed to get referrers of %s@%s: %v", repo, digest, err)
+		}
+	}
+	if index != nil {
+		for _, m := range index.Manifests {
+			referrers = append(referrers, ImageReferrer{
+				Digest:       m.Digest,
+				MediaType:    m.MediaType,
+				ArtifactType: m.ArtifactType,
+				Size:         m.Size,
+				Annotations:  m.Annotations,
+				Kind:         referrerKind(m.ArtifactType),
+			})
+		}
+	}
+
+	for suffix, kind := range cosignAttachments {
+		tag := repo.Tag(fmt.Sprintf("%s-%s.%s", digest.Algorithm, digest.Hex, suffix))
+		desc, err := remote.Head(tag, r.opts.remote...)
+		if err != nil {
+			continue
+		}
+		referrers = append(referrers, ImageReferrer{
+			Digest:    desc.Digest.String(),
+			MediaType: string(desc.MediaType),
+			Size:      desc.Size,
+			Kind:      kind,
+			Tag:       tag.TagStr(),
+		})
+	}
+
+	sort.SliceStable(referrers, func(i, j int) bool {
+		return referrers[i].Kind < referrers[j].Kind
+	})
+	return referrers
+}
+
+// referrersAPI requests GET /v2/<name>/referrers/<digest>, which is not supported by remote yet
+func (r *registryer) referrersAPI(repo name.Repository, digest v1.Hash) (*referrersIndex, error) {
+	scopes := []string{repo.Scope(transport.PullScope)}
+	tr, err := transport.NewWithContext(r.opts.ctx, repo.Registry, r.opts.auth, r.opts.transport, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	u := url.URL{
+		Scheme: repo.Registry.Scheme(),
+		Host:   repo.RegistryStr(),
+		Path:   fmt.Sprintf("/v2/%s/referrers/%s", repo.RepositoryStr(), digest),
+	}
+	req, err := http.NewRequestWithContext(r.opts.ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.oci.image.index.v1+json")
+
+	resp, err := (&http.Client{Transport: tr}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = transport.CheckError(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	index := &referrersIndex{}
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// referrersTag reads the index tagged as <alg>-<hex> which is maintained by the clients pushing the
+// artifacts, for registries without the referrers API.
+func (r *registryer) referrersTag(repo name.Repository, digest v1.Hash) (*referrersIndex, error) {
+	desc, err := remote.Get(repo.Tag(fmt.Sprintf("%s-%s", digest.Algorithm, digest.Hex)), r.opts.remote...)
+	if err != nil {
+		if terr, ok := err.(*transport.Error); ok && terr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !desc.MediaType.IsIndex() {
+		return nil, nil
+	}
+
+	index := &referrersIndex{}
+	if err = json.Unmarshal(desc.Manifest, index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func referrerKind(artifactType string) string {
+	t := strings.ToLower(artifactType)
+	switch {
+	case strings.Contains(t, "spdx"), strings.Contains(t, "cyclonedx"), strings.Contains(t, "sbom"):
+		return ReferrerKindSBOM
+	case strings.Contains(t, "signature"), strings.Contains(t, "cosign.artifact.sig"), strings.Contains(t, "notary"):
+		return ReferrerKindSignature
+	case strings.Contains(t, "in-toto"), strings.Contains(t, "attestation"), strings.Contains(t, "vex"):
+		return ReferrerKindAttestation
+	default:
+		return ReferrerKindOther
+	}
+}
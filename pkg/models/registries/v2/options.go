@@ -37,6 +37,11 @@ type options struct {
 	name     []name.Option
 	remote   []remote.Option
 	platform *v1.Platform
+
+	// kept for the requests which are not supported by remote, e.g. the referrers API
+	auth      authn.Authenticator
+	transport http.RoundTripper
+	ctx       context.Context
 }
 
 func makeOptions(opts ...Option) options {
@@ -44,6 +49,9 @@ func makeOptions(opts ...Option) options {
 		remote: []remote.Option{
 			remote.WithAuth(authn.Anonymous),
 		},
+		auth:      authn.Anonymous,
+		transport: http.DefaultTransport,
+		ctx:       context.Background(),
 	}
 	for _, o := range opts {
 		o(&opt)
@@ -59,6 +67,7 @@ type Option func(*options)
 func WithTransport(t http.RoundTripper) Option {
 	return func(o *options) {
 		o.remote = append(o.remote, remote.WithTransport(t))
+		o.transport = t
 	}
 }
 
@@ -73,6 +82,7 @@ func WithAuth(auth authn.Authenticator) Option {
 	return func(o *options) {
 		// Replace the default keychain at position 0.
 		o.remote[0] = remote.WithAuth(auth)
+		o.auth = auth
 	}
 }
 
@@ -80,6 +90,7 @@ func WithAuth(auth authn.Authenticator) Option {
 func WithContext(ctx context.Context) Option {
 	return func(o *options) {
 		o.remote = append(o.remote, remote.WithContext(ctx))
+		o.ctx = ctx
 	}
 }
 
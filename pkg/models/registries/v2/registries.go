@@ -17,6 +17,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"kubesphere.io/kubesphere/pkg/models/registries/vulnerability"
 )
 
 type Registryer interface {
@@ -25,6 +27,15 @@ type Registryer interface {
 
 	// get image config
 	Config(image string) (*v1.ConfigFile, error)
+
+	// list repositories of the registry whose names contain the keyword
+	ListRepositories(registry, keyword string, page, limit int) (*RepositoryList, error)
+
+	// list tags of the repository which contain the keyword, with their digests and push time
+	ListTags(repository, keyword string, page, limit int) (*TagList, error)
+
+	// inspect image layers, ports, labels, referrers and vulnerabilities
+	Inspect(image string, database *vulnerability.Database) (*ImageInspection, error)
 }
 
 type registryer struct {
@@ -14,7 +14,15 @@
 package v2
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
 	corev1 "k8s.io/api/core/v1"
+
+	"kubesphere.io/kubesphere/pkg/models/registries/vulnerability"
 )
 
 type RegistryHelper interface {
@@ -26,12 +34,28 @@ type RegistryHelper interface {
 
 	// list all tags of given repository, experimental
 	ListRepositoryTags(secret *corev1.Secret, repository string) (RepositoryTags, error)
+
+	// list the registries referenced by the image pull secrets, other secrets are skipped
+	ListRegistries(secrets []*corev1.Secret) []ImageRegistry
+
+	// list repositories of the registry of the secret, paged by page and limit, filtered by the keyword
+	ListRepositories(secret *corev1.Secret, keyword string, page, limit int) (*RepositoryList, error)
+
+	// list tags of the repository with their digests and push time, paged by page and limit, filtered by the keyword
+	ListTags(secret *corev1.Secret, repository, keyword string, page, limit int) (*TagList, error)
+
+	// inspect image layers, ports, labels, SBOMs and other referrers, and the vulnerabilities if the
+	// vulnerability database is configured, until ctx is done or inspectTimeout is reached
+	Inspect(ctx context.Context, secret *corev1.Secret, image string) (*ImageInspection, error)
 }
 
-type registryHelper struct{}
+type registryHelper struct {
+	// nil if vulnerability scanning is disabled
+	database *vulnerability.Database
+}
 
-func NewRegistryHelper() RegistryHelper {
-	return &registryHelper{}
+func NewRegistryHelper(database *vulnerability.Database) RegistryHelper {
+	return &registryHelper{database: database}
 }
 
 func (r *registryHelper) Auth(secret *corev1.Secret) (bool, error) {
@@ -63,3 +87,99 @@ func (r *registryHelper) ListRepositoryTags(secret *corev1.Secret, image string)
 	registryer := NewRegistryer(secretAuth.Options()...)
 	return registryer.ListRepositoryTags(image)
 }
+
+func (r *registryHelper) ListRegistries(secrets []*corev1.Secret) []ImageRegistry {
+	registries := make([]ImageRegistry, 0)
+	for _, secret := range secrets {
+		if secret.Type != corev1.SecretTypeDockerConfigJson {
+			continue
+		}
+		secretAuth, err := NewSecretAuthenticator(secret)
+		if err != nil {
+			continue
+		}
+		registry := ImageRegistry{Secret: secret.Name, Registry: secretAuth.Registry()}
+		if auth, err := secretAuth.Authorization(); err == nil {
+			registry.Username = auth.Username
+		}
+		registries = append(registries, registry)
+	}
+	return registries
+}
+
+func (r *registryHelper) ListRepositories(secret *corev1.Secret, keyword string, page, limit int) (*RepositoryList, error) {
+	secretAuth, err := NewSecretAuthenticator(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := secretAuth.Registry()
+	if registry == "" {
+		registry = DefaultRegistry
+	}
+	registryer := NewRegistryer(secretAuth.Options()...)
+	return registryer.ListRepositories(registry, keyword, page, limit)
+}
+
+func (r *registryHelper) ListTags(secret *corev1.Secret, repository, keyword string, page, limit int) (*TagList, error) {
+	secretAuth, err := NewSecretAuthenticator(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := name.NewRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkRegistry(secretAuth, repo.Registry); err != nil {
+		return nil, err
+	}
+
+	registryer := NewRegistryer(secretAuth.Options()...)
+	return registryer.ListTags(repository, keyword, page, limit)
+}
+
+// checkRegistry returns an error unless the registry is the one of the secret,
+// so that the credentials of the secret are never sent to a registry specified by the user.
+func checkRegistry(secretAuth SecretAuthenticator, registry name.Registry) error {
+	// anonymous
+	if secretAuth.Registry() == "" {
+		return nil
+	}
+	if normalizeRegistry(registry.RegistryStr()) != normalizeRegistry(secretAuth.Registry()) {
+		return fmt.Errorf("registry %s differs from registry %s of the secret", registry.RegistryStr(), secretAuth.Registry())
+	}
+	return nil
+}
+
+// normalizeRegistry makes the aliases of Docker Hub the same
+func normalizeRegistry(registry string) string {
+	registry = strings.ToLower(registry)
+	if dockerHubRegistries[registry] {
+		return DefaultRegistry
+	}
+	return registry
+}
+
+// inspectTimeout is how long an image is inspected for, including the layers downloaded to scan it
+const inspectTimeout = 2 * time.Minute
+
+func (r *registryHelper) Inspect(ctx context.Context, secret *corev1.Secret, image string) (*ImageInspection, error) {
+	secretAuth, err := NewSecretAuthenticator(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkRegistry(secretAuth, ref.Context().Registry); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, inspectTimeout)
+	defer cancel()
+	registryer := NewRegistryer(append(secretAuth.Options(), WithContext(ctx))...)
+	return registryer.Inspect(image, r.database)
+}
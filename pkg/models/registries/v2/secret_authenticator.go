@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -37,6 +38,9 @@ type SecretAuthenticator interface {
 	Auth() (bool, error)
 
 	Authorization() (*authn.AuthConfig, error)
+
+	// Registry returns the registry host of the secret, empty if the secret is nil
+	Registry() string
 }
 
 type secretAuthenticator struct {
@@ -82,8 +86,23 @@ func NewSecretAuthenticator(secret *v1.Secret) (SecretAuthenticator, error) {
 	return sa, nil
 }
 
+// registry returns the first registry of the secret in order, so that the secret
+// is always authenticated with the same registry if it has more than one.
+func (s *secretAuthenticator) registry() (string, bool) {
+	registries := make([]string, 0, len(s.auths))
+	for k := range s.auths {
+		registries = append(registries, k)
+	}
+	if len(registries) == 0 {
+		return "", false
+	}
+	sort.Strings(registries)
+	return registries[0], true
+}
+
 func (s *secretAuthenticator) Authorization() (*authn.AuthConfig, error) {
-	for _, v := range s.auths {
+	if k, ok := s.registry(); ok {
+		v := s.auths[k]
 		return &authn.AuthConfig{
 			Username: v.Username,
 			Password: v.Password,
@@ -93,8 +112,15 @@ func (s *secretAuthenticator) Authorization() (*authn.AuthConfig, error) {
 	return &authn.AuthConfig{}, nil
 }
 
+func (s *secretAuthenticator) Registry() string {
+	if k, ok := s.registry(); ok {
+		return registryOfSecret(k)
+	}
+	return ""
+}
+
 func (s *secretAuthenticator) Auth() (bool, error) {
-	for k := range s.auths {
+	if k, ok := s.registry(); ok {
 		return s.AuthRegistry(k)
 	}
 	return false, fmt.Errorf("no registry found in secret")
@@ -147,7 +173,7 @@ func (s *secretAuthenticator) Options() []Option {
 }
 
 func (s *secretAuthenticator) registryScheme() string {
-	for registry := range s.auths {
+	if registry, ok := s.registry(); ok {
 		u, err := url.Parse(registry)
 		if err == nil {
 			return u.Scheme
@@ -79,6 +79,29 @@ func TestSecretAuthenticator(t *testing.T) {
 	}
 }
 
+func TestSecretAuthenticatorRegistries(t *testing.T) {
+	secret := buildSecret("harbor.example.com", "admin", "Harbor12345", false)
+	secret.Data[v1.DockerConfigJsonKey] = []byte(`{"auths":{
+		"registry.example.com":{"username":"foo","password":"bar"},
+		"http://harbor.example.com":{"username":"admin","password":"Harbor12345"},
+		"quay.io":{"username":"guest","password":"guest"}}}`)
+
+	// the first registry in order is used whatever the order of the map is
+	for i := 0; i < 10; i++ {
+		secretAuthenticator, err := NewSecretAuthenticator(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if registry := secretAuthenticator.Registry(); registry != "harbor.example.com" {
+			t.Fatalf("expected registry harbor.example.com, got %s", registry)
+		}
+		auth, err := secretAuthenticator.Authorization()
+		if err != nil || auth.Username != "admin" {
+			t.Fatalf("expected the credential of harbor.example.com, got %v, %v", auth, err)
+		}
+	}
+}
+
 func TestAuthn(t *testing.T) {
 	testCases := []struct {
 		name      string
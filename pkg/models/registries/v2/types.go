@@ -14,7 +14,11 @@
 package v2
 
 import (
+	"time"
+
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"kubesphere.io/kubesphere/pkg/models/registries/vulnerability"
 )
 
 // DockerConfig represents the config file used by the docker CLI.
@@ -44,7 +48,94 @@ type RepositoryTags struct {
 	Tags       []string `json:"tags"`
 }
 
+// ImageRegistry is a registry referenced by an image pull secret
+type ImageRegistry struct {
+	Secret   string `json:"secret"`
+	Registry string `json:"registry"`
+	Username string `json:"username,omitempty"`
+}
+
+type Repository struct {
+	// Name of the repository without the registry, e.g. library/nginx
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	PullCount   int        `json:"pullCount,omitempty"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+}
+
+type RepositoryList struct {
+	Registry string `json:"registry"`
+	// Type is one of dockerhub, harbor and distribution
+	Type       string       `json:"type"`
+	Items      []Repository `json:"items"`
+	TotalItems int          `json:"totalItems"`
+}
+
+type Tag struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+	// PushedAt is not available for the Distribution v2 registries
+	PushedAt *time.Time `json:"pushedAt,omitempty"`
+}
+
+type TagList struct {
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	// Type is one of dockerhub, harbor and distribution
+	Type       string `json:"type"`
+	Items      []Tag  `json:"items"`
+	TotalItems int    `json:"totalItems"`
+}
+
 // ImageConfig wraps v1.ConfigFile to avoid direct dependency
 type ImageConfig struct {
 	*v1.ConfigFile `json:",inline"`
 }
+
+// ImageInspection describes what is inside an image
+type ImageInspection struct {
+	Image        string     `json:"image"`
+	Digest       string     `json:"digest"`
+	MediaType    string     `json:"mediaType"`
+	OS           string     `json:"os,omitempty"`
+	Architecture string     `json:"architecture,omitempty"`
+	Created      *time.Time `json:"created,omitempty"`
+	// Size is the sum of the compressed layers and the config
+	Size         int64             `json:"size"`
+	Layers       []ImageLayer      `json:"layers"`
+	ExposedPorts []string          `json:"exposedPorts,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	// Referrers are the artifacts attached to the image, e.g. SBOMs, signatures and attestations
+	Referrers []ImageReferrer `json:"referrers"`
+	// Vulnerabilities is nil if vulnerability scanning is disabled or failed
+	Vulnerabilities *vulnerability.Summary `json:"vulnerabilities,omitempty"`
+	ScanError       string                 `json:"scanError,omitempty"`
+}
+
+type ImageLayer struct {
+	Digest    string `json:"digest"`
+	MediaType string `json:"mediaType"`
+	Size      int64  `json:"size"`
+	// CreatedBy is the command which created the layer, read from the image history
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+const (
+	ReferrerKindSBOM        = "sbom"
+	ReferrerKindSignature   = "signature"
+	ReferrerKindAttestation = "attestation"
+	ReferrerKindOther       = "other"
+)
+
+type ImageReferrer struct {
+	Digest       string            `json:"digest"`
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Size         int64             `json:"size"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	// Kind is one of sbom, signature, attestation and other
+	Kind string `json:"kind"`
+	// Tag is set if the referrer is found by a tag, e.g. the cosign attachments
+	Tag string `json:"tag,omitempty"`
+}
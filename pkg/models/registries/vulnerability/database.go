@@ -0,0 +1,261 @@
+// Copyright 2023 The KubeSphere Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package vulnerability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/klog/v2"
+)
+
+type Severity string
+
+const (
+	SeverityCritical Severity = "Critical"
+	SeverityHigh     Severity = "High"
+	SeverityMedium   Severity = "Medium"
+	SeverityLow      Severity = "Low"
+	SeverityUnknown  Severity = "Unknown"
+
+	// max count of the scanned images cached in memory
+	maxCachedScans = 512
+	// max count of the images scanned at the same time, as the layers are downloaded and decompressed
+	maxConcurrentScans = 2
+)
+
+var severityOrder = map[Severity]int{
+	SeverityCritical: 0,
+	SeverityHigh:     1,
+	SeverityMedium:   2,
+	SeverityLow:      3,
+	SeverityUnknown:  4,
+}
+
+// Vulnerability is an entry of the vulnerability database
+type Vulnerability struct {
+	// ID of the vulnerability, e.g. CVE-2023-0286
+	ID string `json:"id"`
+	// OS is the ID in os-release of the distribution the entry belongs to, e.g. alpine, debian.
+	// The entry applies to all distributions if it is empty.
+	OS string `json:"os,omitempty"`
+	// Package is the name of the affected binary or source package
+	Package string `json:"package"`
+	// IntroducedVersion is the first affected version, all versions before FixedVersion are affected if it is empty
+	IntroducedVersion string `json:"introducedVersion,omitempty"`
+	// FixedVersion is the first version which is not affected, all versions are affected if it is empty
+	FixedVersion string   `json:"fixedVersion,omitempty"`
+	Severity     Severity `json:"severity,omitempty"`
+	Title        string   `json:"title,omitempty"`
+	URL          string   `json:"url,omitempty"`
+}
+
+// databaseFile is the format of the local vulnerability database file
+type databaseFile struct {
+	UpdatedAt       time.Time       `json:"updatedAt,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
+// Finding is a vulnerability of a package installed in the image
+type Finding struct {
+	Vulnerability    `json:",inline"`
+	InstalledVersion string `json:"installedVersion"`
+}
+
+// Summary is the result of the vulnerability scan of an image
+type Summary struct {
+	OS                string           `json:"os,omitempty"`
+	Packages          int              `json:"packages"`
+	DatabaseUpdatedAt *time.Time       `json:"databaseUpdatedAt,omitempty"`
+	ScannedAt         time.Time        `json:"scannedAt"`
+	Total             int              `json:"total"`
+	Severities        map[Severity]int `json:"severities"`
+	Vulnerabilities   []Finding        `json:"vulnerabilities"`
+}
+
+// Database is the vulnerability database loaded from a local file, the file is reloaded
+// once it is modified so that the database could be updated without a restart.
+type Database struct {
+	path string
+
+	mutex   sync.Mutex
+	modTime time.Time
+	file    *databaseFile
+	// vulnerabilities indexed by package name
+	index map[string][]Vulnerability
+	// scan results indexed by image digest, reset when the database is reloaded
+	scans map[string]*Summary
+
+	// the concurrent scans of an image share the result
+	scanning singleflight.Group
+	// bounds the images scanned at the same time
+	scanners *semaphore.Weighted
+}
+
+// NewDatabase returns nil if the database path is not configured
+func NewDatabase(options *Options) *Database {
+	if options == nil || options.DatabasePath == "" {
+		return nil
+	}
+	return &Database{path: options.DatabasePath, scanners: semaphore.NewWeighted(maxConcurrentScans)}
+}
+
+// Scan scans the OS packages of the image, the results are cached by the digest of the image.
+// At most maxConcurrentScans images are scanned at the same time, the concurrent scans of the
+// same image wait for the result of the first one, until ctx is done.
+func (d *Database) Scan(ctx context.Context, digest string, img v1.Image) (*Summary, error) {
+	if err := d.reload(); err != nil {
+		return nil, err
+	}
+
+	d.mutex.Lock()
+	if summary, ok := d.scans[digest]; ok {
+		d.mutex.Unlock()
+		return summary, nil
+	}
+	d.mutex.Unlock()
+
+	ch := d.scanning.DoChan(digest, func() (interface{}, error) {
+		return d.scan(ctx, digest, img)
+	})
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*Summary), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (d *Database) scan(ctx context.Context, digest string, img v1.Image) (*Summary, error) {
+	if err := d.scanners.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	defer d.scanners.Release(1)
+
+	osID, packages, err := ReadPackages(img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packages of image %s: %v", digest, err)
+	}
+	summary := d.Match(osID, packages)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if len(d.scans) >= maxCachedScans {
+		d.scans = make(map[string]*Summary)
+	}
+	d.scans[digest] = summary
+	return summary, nil
+}
+
+// Match returns the vulnerabilities of the packages installed on the OS
+func (d *Database) Match(osID string, packages []Package) *Summary {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	summary := &Summary{
+		OS:              osID,
+		Packages:        len(packages),
+		ScannedAt:       time.Now(),
+		Severities:      make(map[Severity]int),
+		Vulnerabilities: make([]Finding, 0),
+	}
+	if d.file != nil && !d.file.UpdatedAt.IsZero() {
+		updatedAt := d.file.UpdatedAt
+		summary.DatabaseUpdatedAt = &updatedAt
+	}
+
+	for _, pkg := range packages {
+		seen := make(map[string]bool)
+		candidates := d.index[pkg.Name]
+		if pkg.Source != "" && pkg.Source != pkg.Name {
+			candidates = append(candidates[:len(candidates):len(candidates)], d.index[pkg.Source]...)
+		}
+		for _, v := range candidates {
+			if seen[v.ID] || (v.OS != "" && v.OS != osID) || !affected(pkg.Version, v) {
+				continue
+			}
+			seen[v.ID] = true
+			if _, ok := severityOrder[v.Severity]; !ok {
+				v.Severity = SeverityUnknown
+			}
+			summary.Vulnerabilities = append(summary.Vulnerabilities, Finding{Vulnerability: v, InstalledVersion: pkg.Version})
+			summary.Severities[v.Severity]++
+		}
+	}
+	summary.Total = len(summary.Vulnerabilities)
+
+	sort.SliceStable(summary.Vulnerabilities, func(i, j int) bool {
+		a, b := summary.Vulnerabilities[i], summary.Vulnerabilities[j]
+		if a.Severity != b.Severity {
+			return severityOrder[a.Severity] < severityOrder[b.Severity]
+		}
+		return a.ID < b.ID
+	})
+	return summary
+}
+
+func affected(version string, v Vulnerability) bool {
+	if v.IntroducedVersion != "" && compareVersions(version, v.IntroducedVersion) < 0 {
+		return false
+	}
+	return v.FixedVersion == "" || compareVersions(version, v.FixedVersion) < 0
+}
+
+func (d *Database) reload() error {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return fmt.Errorf("failed to load vulnerability database: %v", err)
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.file != nil && info.ModTime().Equal(d.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return fmt.Errorf("failed to load vulnerability database: %v", err)
+	}
+	file := &databaseFile{}
+	if err = json.Unmarshal(data, file); err != nil {
+		return fmt.Errorf("invalid vulnerability database %s: %v", d.path, err)
+	}
+
+	d.load(file)
+	d.modTime = info.ModTime()
+	klog.V(2).Infof("loaded %d vulnerabilities from %s", len(file.Vulnerabilities), d.path)
+	return nil
+}
+
+func (d *Database) load(file *databaseFile) {
+	index := make(map[string][]Vulnerability)
+	for _, v := range file.Vulnerabilities {
+		index[v.Package] = append(index[v.Package], v)
+	}
+	d.file = file
+	d.index = index
+	d.scans = make(map[string]*Summary)
+}
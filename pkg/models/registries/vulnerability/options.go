@@ -0,0 +1,52 @@
+// Copyright 2023 The KubeSphere Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package vulnerability
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+type Options struct {
+	// DatabasePath is the path of the local vulnerability database file, image vulnerability
+	// scanning is disabled if it is empty. The file is reloaded once it is modified.
+	DatabasePath string `json:"databasePath,omitempty" yaml:"databasePath,omitempty"`
+}
+
+func NewVulnerabilityOptions() *Options {
+	return &Options{}
+}
+
+func (s *Options) Validate() []error {
+	var errs []error
+	if s.DatabasePath != "" {
+		if _, err := os.Stat(s.DatabasePath); err != nil {
+			errs = append(errs, fmt.Errorf("invalid vulnerability database path: %v", err))
+		}
+	}
+	return errs
+}
+
+func (s *Options) ApplyTo(options *Options) {
+	if s.DatabasePath != "" {
+		options.DatabasePath = s.DatabasePath
+	}
+}
+
+func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
+	fs.StringVar(&s.DatabasePath, "vulnerability-database", c.DatabasePath, ""+
+		"Path of the local vulnerability database used to scan images, leave it empty to disable image scanning.")
+}
@@ -0,0 +1,263 @@
+// Copyright 2023 The KubeSphere Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package vulnerability
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	osReleaseFile    = "etc/os-release"
+	usrOSReleaseFile = "usr/lib/os-release"
+	apkDatabaseFile  = "lib/apk/db/installed"
+	dpkgStatusFile   = "var/lib/dpkg/status"
+	// distroless images keep one status file per package
+	dpkgStatusDir = "var/lib/dpkg/status.d/"
+
+	whiteoutPrefix = ".wh."
+
+	// max size of the package databases read from the image
+	maxFileSize = 64 << 20
+	// max size of the layers downloaded to scan an image, compressed, and the size of the
+	// archives read from them, uncompressed
+	maxLayersSize       = 512 << 20
+	maxUncompressedSize = 2 << 30
+)
+
+// Package is an OS package installed in the image
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Source is the source package, the vulnerabilities of debian based images are tracked by it
+	Source string `json:"source,omitempty"`
+}
+
+// ReadPackages walks the layers of the image and returns the ID of the OS read from os-release,
+// and the packages found in the apk or dpkg database. Only the package databases are read from
+// the layers, other files are skipped. The images whose layers are larger than maxLayersSize
+// are not scanned.
+func ReadPackages(img v1.Image) (string, []Package, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var size int64
+	for _, layer := range layers {
+		layerSize, err := layer.Size()
+		if err != nil {
+			return "", nil, err
+		}
+		size += layerSize
+	}
+	if size > maxLayersSize {
+		return "", nil, fmt.Errorf("size %d of the layers exceeds the limit %d", size, int64(maxLayersSize))
+	}
+
+	files := make(map[string][]byte)
+	remaining := int64(maxUncompressedSize)
+	for _, layer := range layers {
+		if remaining, err = readLayer(layer, files, remaining); err != nil {
+			return "", nil, err
+		}
+	}
+
+	osRelease, ok := files[osReleaseFile]
+	if !ok {
+		osRelease = files[usrOSReleaseFile]
+	}
+	osID := parseOSRelease(osRelease)
+
+	var packages []Package
+	if data, ok := files[apkDatabaseFile]; ok {
+		packages = append(packages, parseAPKDatabase(data)...)
+	}
+	if data, ok := files[dpkgStatusFile]; ok {
+		packages = append(packages, parseDPKGStatus(data)...)
+	}
+	for name, data := range files {
+		if strings.HasPrefix(name, dpkgStatusDir) {
+			packages = append(packages, parseDPKGStatus(data)...)
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return osID, packages, nil
+}
+
+// readLayer applies the package databases of the layer onto files, the files of
+// the upper layers override the lower ones, whiteouts remove them. At most remaining bytes
+// are read from the archive of the layer, the bytes left are returned.
+func readLayer(layer v1.Layer, files map[string][]byte, remaining int64) (int64, error) {
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	limited := &io.LimitedReader{R: rc, N: remaining}
+
+	// whiteouts only apply to the lower layers
+	var whiteouts []string
+	layerFiles := make(map[string][]byte)
+
+	tr := tar.NewReader(limited)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if limited.N <= 0 {
+				return 0, fmt.Errorf("uncompressed size of the layers exceeds the limit %d", int64(maxUncompressedSize))
+			}
+			return 0, err
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		dir, base := path.Split(name)
+		if strings.HasPrefix(base, whiteoutPrefix) {
+			whiteouts = append(whiteouts, dir+strings.TrimPrefix(base, whiteoutPrefix))
+			continue
+		}
+
+		if header.Typeflag != tar.TypeReg || !isPackageDatabase(name) {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxFileSize))
+		if err != nil {
+			return 0, err
+		}
+		layerFiles[name] = data
+	}
+
+	for _, removed := range whiteouts {
+		for file := range files {
+			if file == removed || strings.HasPrefix(file, removed+"/") {
+				delete(files, file)
+			}
+		}
+	}
+	for name, data := range layerFiles {
+		files[name] = data
+	}
+	return limited.N, nil
+}
+
+func isPackageDatabase(name string) bool {
+	switch name {
+	case osReleaseFile, usrOSReleaseFile, apkDatabaseFile, dpkgStatusFile:
+		return true
+	}
+	return strings.HasPrefix(name, dpkgStatusDir)
+}
+
+func parseOSRelease(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "ID=") {
+			return strings.Trim(strings.TrimPrefix(line, "ID="), `"'`)
+		}
+	}
+	return ""
+}
+
+// parseAPKDatabase parses the package records of lib/apk/db/installed, which are separated by blank lines
+func parseAPKDatabase(data []byte) []Package {
+	var packages []Package
+	var pkg Package
+	flush := func() {
+		if pkg.Name != "" && pkg.Version != "" {
+			packages = append(packages, pkg)
+		}
+		pkg = Package{}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		if len(line) < 2 || line[1] != ':' {
+			continue
+		}
+		switch line[0] {
+		case 'P':
+			pkg.Name = line[2:]
+		case 'V':
+			pkg.Version = line[2:]
+		case 'o':
+			pkg.Source = line[2:]
+		}
+	}
+	flush()
+	return packages
+}
+
+// parseDPKGStatus parses the installed packages of var/lib/dpkg/status
+func parseDPKGStatus(data []byte) []Package {
+	var packages []Package
+	var pkg Package
+	installed := true
+	flush := func() {
+		if pkg.Name != "" && pkg.Version != "" && installed {
+			packages = append(packages, pkg)
+		}
+		pkg = Package{}
+		installed = true
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Package":
+			pkg.Name = value
+		case "Version":
+			pkg.Version = value
+		case "Source":
+			// e.g. "openssl (3.0.8-1)", the version of the source package is ignored
+			if fields := strings.Fields(value); len(fields) > 0 {
+				pkg.Source = fields[0]
+			}
+		case "Status":
+			installed = strings.HasSuffix(value, " installed")
+		}
+	}
+	flush()
+	return packages
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 The KubeSphere Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package vulnerability
+
+import (
+	"strconv"
+	"strings"
+)
+
+// compareVersions compares two package versions with the dpkg algorithm, which
+// also orders the apk versions (e.g. 3.0.8-r0) properly.
+// It returns -1 if a < b, 0 if a == b and 1 if a > b.
+func compareVersions(a, b string) int {
+	epochA, upstreamA, revisionA := splitVersion(a)
+	epochB, upstreamB, revisionB := splitVersion(b)
+	if epochA != epochB {
+		if epochA < epochB {
+			return -1
+		}
+		return 1
+	}
+	if c := compareFragment(upstreamA, upstreamB); c != 0 {
+		return c
+	}
+	return compareFragment(revisionA, revisionB)
+}
+
+// splitVersion splits [epoch:]upstream[-revision]
+func splitVersion(v string) (int, string, string) {
+	epoch := 0
+	if i := strings.Index(v, ":"); i > 0 {
+		if e, err := strconv.Atoi(v[:i]); err == nil {
+			epoch = e
+			v = v[i+1:]
+		}
+	}
+	revision := ""
+	if i := strings.LastIndex(v, "-"); i >= 0 {
+		revision = v[i+1:]
+		v = v[:i]
+	}
+	return epoch, v, revision
+}
+
+func compareFragment(a, b string) int {
+	for a != "" || b != "" {
+		// compare the non-digit prefixes
+		var nonDigitA, nonDigitB string
+		nonDigitA, a = splitPrefix(a, isNotDigit)
+		nonDigitB, b = splitPrefix(b, isNotDigit)
+		if c := compareNonDigits(nonDigitA, nonDigitB); c != 0 {
+			return c
+		}
+
+		// then the numeric prefixes
+		var digitA, digitB string
+		digitA, a = splitPrefix(a, isDigit)
+		digitB, b = splitPrefix(b, isDigit)
+		digitA = strings.TrimLeft(digitA, "0")
+		digitB = strings.TrimLeft(digitB, "0")
+		if len(digitA) != len(digitB) {
+			if len(digitA) < len(digitB) {
+				return -1
+			}
+			return 1
+		}
+		if c := strings.Compare(digitA, digitB); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareNonDigits(a, b string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var ca, cb int
+		if i < len(a) {
+			ca = order(a[i])
+		}
+		if i < len(b) {
+			cb = order(b[i])
+		}
+		if ca != cb {
+			if ca < cb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// order sorts '~' before anything, even the end of the fragment, and letters before the other characters
+func order(c byte) int {
+	switch {
+	case c == '~':
+		return -1
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+func splitPrefix(s string, match func(byte) bool) (string, string) {
+	i := 0
+	for i < len(s) && match(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNotDigit(c byte) bool {
+	return !isDigit(c)
+}
@@ -0,0 +1,276 @@
+// Copyright 2023 The KubeSphere Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package vulnerability
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"3.0.8-r0", "3.0.8-r0", 0},
+		{"3.0.7-r0", "3.0.8-r0", -1},
+		{"3.0.10-r0", "3.0.8-r0", 1},
+		{"3.0.8-r1", "3.0.8-r0", 1},
+		{"1.2.3", "1:1.0", -1},
+		{"2.36-9+deb12u1", "2.36-9+deb12u3", -1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0a", "1.0", 1},
+		{"1.01", "1.1", 0},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.expected {
+			t.Errorf("compareVersions(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+type fakeLayer struct {
+	v1.Layer
+	files map[string]string
+	size  int64
+}
+
+func (l *fakeLayer) Size() (int64, error) {
+	return l.size, nil
+}
+
+func (l *fakeLayer) Uncompressed() (io.ReadCloser, error) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for name, content := range l.files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return io.NopCloser(buf), nil
+}
+
+type fakeImage struct {
+	v1.Image
+	layers []v1.Layer
+}
+
+func (i *fakeImage) Layers() ([]v1.Layer, error) {
+	return i.layers, nil
+}
+
+const apkInstalled = `C:Q1abc=
+P:musl
+V:1.2.3-r4
+o:musl
+
+P:libcrypto3
+V:3.0.7-r0
+o:openssl
+`
+
+const dpkgStatus = `Package: libssl3
+Status: install ok installed
+Source: openssl (3.0.8-1)
+Version: 3.0.8-1
+
+Package: removed
+Status: deinstall ok config-files
+Version: 1.0
+`
+
+func TestReadPackages(t *testing.T) {
+	img := &fakeImage{layers: []v1.Layer{
+		&fakeLayer{files: map[string]string{
+			"etc/os-release":       "NAME=\"Debian\"\nID=debian\n",
+			"var/lib/dpkg/status":  dpkgStatus,
+			"usr/bin/unrelated.sh": "echo",
+		}},
+		&fakeLayer{files: map[string]string{
+			"./etc/.wh.os-release":     "",
+			"var/lib/dpkg/.wh.status":  "",
+			"etc/os-release":           "ID=\"alpine\"\n",
+			"lib/apk/db/installed":     apkInstalled,
+			"lib/apk/db/unrelated.txt": "",
+		}},
+	}}
+
+	osID, packages, err := ReadPackages(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Package{
+		{Name: "libcrypto3", Version: "3.0.7-r0", Source: "openssl"},
+		{Name: "musl", Version: "1.2.3-r4", Source: "musl"},
+	}
+	if osID != "alpine" {
+		t.Errorf("unexpected os %s", osID)
+	}
+	if diff := cmp.Diff(expected, packages); diff != "" {
+		t.Errorf("unexpected packages: %s", diff)
+	}
+
+	if got := parseDPKGStatus([]byte(dpkgStatus)); !cmp.Equal(got, []Package{{Name: "libssl3", Version: "3.0.8-1", Source: "openssl"}}) {
+		t.Errorf("unexpected dpkg packages: %v", got)
+	}
+
+	// the layers are not downloaded if they are too large
+	img.layers = append(img.layers, &fakeLayer{size: maxLayersSize + 1})
+	if _, _, err = ReadPackages(img); err == nil {
+		t.Error("expected the layers to exceed the limit")
+	}
+}
+
+const database = `{
+  "updatedAt": "2023-03-01T00:00:00Z",
+  "vulnerabilities": [
+    {"id": "CVE-2023-0286", "os": "alpine", "package": "openssl", "fixedVersion": "3.0.8-r0", "severity": "High"},
+    {"id": "CVE-2022-3358", "os": "alpine", "package": "openssl", "introducedVersion": "3.0.0-r0", "fixedVersion": "3.0.6-r0", "severity": "High"},
+    {"id": "CVE-2023-0464", "os": "debian", "package": "openssl", "fixedVersion": "3.0.9-1", "severity": "Medium"},
+    {"id": "CVE-2020-28928", "package": "musl", "fixedVersion": "1.2.2", "severity": "Medium"},
+    {"id": "CVE-2023-9999", "os": "alpine", "package": "musl", "severity": "Critical"}
+  ]
+}`
+
+func TestDatabaseMatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	if err := os.WriteFile(path, []byte(database), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if NewDatabase(&Options{}) != nil {
+		t.Error("expected nil database without path")
+	}
+	db := NewDatabase(&Options{DatabasePath: path})
+	if err := db.reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	summary := db.Match("alpine", []Package{
+		{Name: "libcrypto3", Version: "3.0.7-r0", Source: "openssl"},
+		{Name: "libssl3", Version: "3.0.7-r0", Source: "openssl"},
+		{Name: "musl", Version: "1.2.3-r4", Source: "musl"},
+	})
+
+	var ids []string
+	for _, f := range summary.Vulnerabilities {
+		ids = append(ids, f.ID+"/"+f.InstalledVersion)
+	}
+	expected := []string{"CVE-2023-9999/1.2.3-r4", "CVE-2023-0286/3.0.7-r0", "CVE-2023-0286/3.0.7-r0"}
+	if diff := cmp.Diff(expected, ids); diff != "" {
+		t.Errorf("unexpected vulnerabilities: %s", diff)
+	}
+	if summary.Total != 3 || summary.Severities[SeverityHigh] != 2 || summary.Severities[SeverityCritical] != 1 {
+		t.Errorf("unexpected summary: %d %v", summary.Total, summary.Severities)
+	}
+	if summary.DatabaseUpdatedAt == nil || summary.Packages != 3 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+// blockingImage blocks reading the layers until released
+type blockingImage struct {
+	fakeImage
+	calls    int32
+	started  chan struct{}
+	released chan struct{}
+}
+
+func (i *blockingImage) Layers() ([]v1.Layer, error) {
+	if atomic.AddInt32(&i.calls, 1) == 1 {
+		close(i.started)
+	}
+	<-i.released
+	return i.fakeImage.Layers()
+}
+
+func newBlockingImage(released chan struct{}) *blockingImage {
+	return &blockingImage{
+		fakeImage: fakeImage{layers: []v1.Layer{&fakeLayer{files: map[string]string{
+			"etc/os-release":       "ID=alpine\n",
+			"lib/apk/db/installed": apkInstalled,
+		}}}},
+		started:  make(chan struct{}),
+		released: released,
+	}
+}
+
+func TestDatabaseScan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	if err := os.WriteFile(path, []byte(database), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db := NewDatabase(&Options{DatabasePath: path})
+
+	released := make(chan struct{})
+	images := make([]*blockingImage, maxConcurrentScans)
+	summaries := make([]*Summary, 3)
+	var wg sync.WaitGroup
+	for i := range images {
+		images[i] = newBlockingImage(released)
+		digest := "sha256:" + string(rune('a'+i))
+		// the concurrent scans of the same image share the result
+		for j := range summaries {
+			wg.Add(1)
+			go func(img *blockingImage, j int) {
+				defer wg.Done()
+				summary, err := db.Scan(context.Background(), digest, img)
+				if err != nil {
+					t.Errorf("failed to scan %s: %v", digest, err)
+				}
+				if img == images[0] {
+					summaries[j] = summary
+				}
+			}(images[i], j)
+		}
+		<-images[i].started
+	}
+
+	// all the scanners are busy
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := db.Scan(ctx, "sha256:z", newBlockingImage(released)); err != context.DeadlineExceeded {
+		t.Errorf("expected the scan to wait for a scanner until ctx is done, got %v", err)
+	}
+
+	close(released)
+	wg.Wait()
+	for _, img := range images {
+		if calls := atomic.LoadInt32(&img.calls); calls != 1 {
+			t.Errorf("expected the image to be scanned once, got %d", calls)
+		}
+	}
+	if summaries[0] == nil || summaries[0] != summaries[1] || summaries[0] != summaries[2] {
+		t.Errorf("expected the same summary, got %v", summaries)
+	}
+	if summaries[0] != nil && summaries[0].Total != 2 {
+		t.Errorf("unexpected summary: %+v", summaries[0])
+	}
+}
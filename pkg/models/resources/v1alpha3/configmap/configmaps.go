@@ -52,6 +52,10 @@ func (d *configmapsGetter) List(namespace string, query *query.Query) (*api.List
 	return v1alpha3.DefaultList(result, query, d.compare, d.filter), nil
 }
 
+func (d *configmapsGetter) Match(object runtime.Object, query *query.Query) bool {
+	return v1alpha3.DefaultMatch(object, query, d.filter)
+}
+
 func (d *configmapsGetter) compare(left runtime.Object, right runtime.Object, field query.Field) bool {
 
 	leftCM, ok := left.(*corev1.ConfigMap)
@@ -61,6 +61,10 @@ func (d *daemonSetGetter) List(namespace string, query *query.Query) (*api.ListR
 	return v1alpha3.DefaultList(result, query, d.compare, d.filter), nil
 }
 
+func (d *daemonSetGetter) Match(object runtime.Object, query *query.Query) bool {
+	return v1alpha3.DefaultMatch(object, query, d.filter)
+}
+
 func (d *daemonSetGetter) compare(left runtime.Object, right runtime.Object, field query.Field) bool {
 
 	leftDaemonSet, ok := left.(*appsv1.DaemonSet)
@@ -17,9 +17,11 @@ limitations under the License.
 package deployment
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 
@@ -63,6 +65,55 @@ func (d *deploymentsGetter) List(namespace string, query *query.Query) (*api.Lis
 	return v1alpha3.DefaultList(result, query, d.compare, d.filter), nil
 }
 
+func (d *deploymentsGetter) Match(object runtime.Object, query *query.Query) bool {
+	return v1alpha3.DefaultMatch(object, query, d.filter)
+}
+
+// TableColumns defines the columns of the deployments like `kubectl get deployments`.
+func (d *deploymentsGetter) TableColumns() []v1alpha3.TableColumn {
+	return []v1alpha3.TableColumn{
+		v1alpha3.NameColumn(),
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Ready", Type: "string", Description: "The number of replicas ready of the desired"},
+			Cell: deploymentCell(func(deployment *v1.Deployment) interface{} {
+				return fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, desiredReplicas(deployment))
+			}),
+			SortValue: deploymentCell(func(deployment *v1.Deployment) interface{} {
+				return int64(deployment.Status.ReadyReplicas)
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Up-to-date", Type: "integer", Description: "The number of replicas updated to the desired state"},
+			Cell: deploymentCell(func(deployment *v1.Deployment) interface{} {
+				return int64(deployment.Status.UpdatedReplicas)
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Available", Type: "integer", Description: "The number of replicas available"},
+			Cell: deploymentCell(func(deployment *v1.Deployment) interface{} {
+				return int64(deployment.Status.AvailableReplicas)
+			}),
+		},
+		v1alpha3.AgeColumn(),
+	}
+}
+
+func deploymentCell(cell func(deployment *v1.Deployment) interface{}) func(runtime.Object) interface{} {
+	return func(object runtime.Object) interface{} {
+		if deployment, ok := object.(*v1.Deployment); ok {
+			return cell(deployment)
+		}
+		return nil
+	}
+}
+
+func desiredReplicas(deployment *v1.Deployment) int32 {
+	if deployment.Spec.Replicas == nil {
+		return 1
+	}
+	return *deployment.Spec.Replicas
+}
+
 func (d *deploymentsGetter) compare(left runtime.Object, right runtime.Object, field query.Field) bool {
 
 	leftDeployment, ok := left.(*v1.Deployment)
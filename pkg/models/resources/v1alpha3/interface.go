@@ -20,6 +20,7 @@ import (
 	"sort"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
 
@@ -38,6 +39,12 @@ type Interface interface {
 	List(namespace string, query *query.Query) (*api.ListResult, error)
 }
 
+// Matcher is implemented by the getters which tell whether an object is listed by the query without
+// listing the others, so that the changes of the objects are sent to the watches one by one.
+type Matcher interface {
+	Match(object runtime.Object, query *query.Query) bool
+}
+
 // CompareFunc return true is left great than right
 type CompareFunc func(runtime.Object, runtime.Object, query.Field) bool
 
@@ -87,6 +94,21 @@ func DefaultList(objects []runtime.Object, q *query.Query, compareFunc CompareFu
 	}
 }
 
+// DefaultMatch returns true if the object is selected by the label selector and the filters of the query,
+// like the objects listed by DefaultList.
+func DefaultMatch(object runtime.Object, q *query.Query, filterFunc FilterFunc) bool {
+	accessor, err := meta.Accessor(object)
+	if err != nil || !q.Selector().Matches(labels.Set(accessor.GetLabels())) {
+		return false
+	}
+	for field, value := range q.Filters {
+		if !filterFunc(object, query.Filter{Field: field, Value: value}) {
+			return false
+		}
+	}
+	return true
+}
+
 // DefaultObjectMetaCompare return true is left great than right
 func DefaultObjectMetaCompare(left, right metav1.ObjectMeta, sortBy query.Field) bool {
 	switch sortBy {
@@ -62,6 +62,10 @@ func (d *jobsGetter) List(namespace string, query *query.Query) (*api.ListResult
 	return v1alpha3.DefaultList(result, query, d.compare, d.filter), nil
 }
 
+func (d *jobsGetter) Match(object runtime.Object, query *query.Query) bool {
+	return v1alpha3.DefaultMatch(object, query, d.filter)
+}
+
 func (d *jobsGetter) compare(left runtime.Object, right runtime.Object, field query.Field) bool {
 
 	leftJob, ok := left.(*batchv1.Job)
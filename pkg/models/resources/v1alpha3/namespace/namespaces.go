@@ -54,6 +54,10 @@ func (n namespacesGetter) List(_ string, query *query.Query) (*api.ListResult, e
 	return v1alpha3.DefaultList(result, query, n.compare, n.filter), nil
 }
 
+func (n namespacesGetter) Match(object runtime.Object, query *query.Query) bool {
+	return v1alpha3.DefaultMatch(object, query, n.filter)
+}
+
 func (n namespacesGetter) filter(item runtime.Object, filter query.Filter) bool {
 	namespace, ok := item.(*v1.Namespace)
 	if !ok {
@@ -19,9 +19,11 @@ package node
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
@@ -47,6 +49,7 @@ const (
 	statusRunning                                   = "running"
 	statusWarning                                   = "warning"
 	statusUnschedulable                             = "unschedulable"
+	nodeRoleLabelPrefix                             = "node-role.kubernetes.io/"
 )
 
 type nodesGetter struct {
@@ -144,6 +147,75 @@ func (c *nodesGetter) List(_ string, q *query.Query) (*api.ListResult, error) {
 	}, nil
 }
 
+// TableColumns defines the columns of the nodes like `kubectl get nodes -o wide`.
+func (c *nodesGetter) TableColumns() []v1alpha3.TableColumn {
+	return []v1alpha3.TableColumn{
+		v1alpha3.NameColumn(),
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Status", Type: "string", Description: "The readiness of the node"},
+			Cell: nodeCell(func(node *v1.Node) interface{} {
+				status := "Unknown"
+				for _, condition := range node.Status.Conditions {
+					if condition.Type == v1.NodeReady {
+						if condition.Status == v1.ConditionTrue {
+							status = "Ready"
+						} else {
+							status = "NotReady"
+						}
+					}
+				}
+				if node.Spec.Unschedulable {
+					status += ",SchedulingDisabled"
+				}
+				return status
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Roles", Type: "string", Description: "The roles of the node"},
+			Cell: nodeCell(func(node *v1.Node) interface{} {
+				var roles []string
+				for key := range node.Labels {
+					if strings.HasPrefix(key, nodeRoleLabelPrefix) && len(key) > len(nodeRoleLabelPrefix) {
+						roles = append(roles, strings.TrimPrefix(key, nodeRoleLabelPrefix))
+					}
+				}
+				if len(roles) == 0 {
+					return "<none>"
+				}
+				sort.Strings(roles)
+				return strings.Join(roles, ",")
+			}),
+		},
+		v1alpha3.AgeColumn(),
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Version", Type: "string", Description: "The version of the kubelet"},
+			Cell: nodeCell(func(node *v1.Node) interface{} {
+				return node.Status.NodeInfo.KubeletVersion
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Internal-IP", Type: "string", Priority: 1, Description: "The internal IP address of the node"},
+			Cell: nodeCell(func(node *v1.Node) interface{} {
+				for _, address := range node.Status.Addresses {
+					if address.Type == v1.NodeInternalIP {
+						return address.Address
+					}
+				}
+				return "<none>"
+			}),
+		},
+	}
+}
+
+func nodeCell(cell func(node *v1.Node) interface{}) func(runtime.Object) interface{} {
+	return func(object runtime.Object) interface{} {
+		if node, ok := object.(*v1.Node); ok {
+			return cell(node)
+		}
+		return nil
+	}
+}
+
 func (c *nodesGetter) compare(left runtime.Object, right runtime.Object, field query.Field) bool {
 	leftNode, ok := left.(*v1.Node)
 	if !ok {
@@ -22,6 +22,7 @@ import (
 
 	snapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v4/informers/externalversions"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
@@ -74,6 +75,77 @@ func (p *persistentVolumeClaimGetter) List(namespace string, query *query.Query)
 	return v1alpha3.DefaultList(result, query, p.compare, p.filter), nil
 }
 
+// TableColumns defines the columns of the persistent volume claims like `kubectl get pvc`.
+func (p *persistentVolumeClaimGetter) TableColumns() []v1alpha3.TableColumn {
+	return []v1alpha3.TableColumn{
+		v1alpha3.NameColumn(),
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Status", Type: "string", Description: "The phase of the claim"},
+			Cell: pvcCell(func(pvc *v1.PersistentVolumeClaim) interface{} {
+				if pvc.DeletionTimestamp != nil {
+					return "Terminating"
+				}
+				return string(pvc.Status.Phase)
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Volume", Type: "string", Description: "The volume bound to the claim"},
+			Cell: pvcCell(func(pvc *v1.PersistentVolumeClaim) interface{} {
+				return pvc.Spec.VolumeName
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Capacity", Type: "string", Description: "The capacity of the volume bound"},
+			Cell: pvcCell(func(pvc *v1.PersistentVolumeClaim) interface{} {
+				if storage, ok := pvc.Status.Capacity[v1.ResourceStorage]; ok && pvc.Spec.VolumeName != "" {
+					return storage.String()
+				}
+				return ""
+			}),
+			SortValue: pvcCell(func(pvc *v1.PersistentVolumeClaim) interface{} {
+				storage := pvc.Status.Capacity[v1.ResourceStorage]
+				return storage.Value()
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Access Modes", Type: "string", Description: "The access modes of the volume bound"},
+			Cell: pvcCell(func(pvc *v1.PersistentVolumeClaim) interface{} {
+				modes := make([]string, 0, len(pvc.Status.AccessModes))
+				for _, mode := range pvc.Status.AccessModes {
+					modes = append(modes, accessModeShortNames[mode])
+				}
+				return strings.Join(modes, ",")
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "StorageClass", Type: "string", Description: "The storage class of the claim"},
+			Cell: pvcCell(func(pvc *v1.PersistentVolumeClaim) interface{} {
+				if pvc.Spec.StorageClassName == nil {
+					return ""
+				}
+				return *pvc.Spec.StorageClassName
+			}),
+		},
+		v1alpha3.AgeColumn(),
+	}
+}
+
+var accessModeShortNames = map[v1.PersistentVolumeAccessMode]string{
+	v1.ReadWriteOnce:    "RWO",
+	v1.ReadOnlyMany:     "ROX",
+	v1.ReadWriteMany:    "RWX",
+	v1.ReadWriteOncePod: "RWOP",
+}
+
+func pvcCell(cell func(pvc *v1.PersistentVolumeClaim) interface{}) func(runtime.Object) interface{} {
+	return func(object runtime.Object) interface{} {
+		if pvc, ok := object.(*v1.PersistentVolumeClaim); ok {
+			return cell(pvc)
+		}
+		return nil
+	}
+}
+
 func (p *persistentVolumeClaimGetter) compare(left, right runtime.Object, field query.Field) bool {
 	leftSnapshot, ok := left.(*v1.PersistentVolumeClaim)
 	if !ok {
@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
@@ -70,6 +71,82 @@ func (p *podsGetter) List(namespace string, query *query.Query) (*api.ListResult
 	return v1alpha3.DefaultList(result, query, p.compare, p.filter), nil
 }
 
+func (p *podsGetter) Match(object runtime.Object, query *query.Query) bool {
+	return v1alpha3.DefaultMatch(object, query, p.filter)
+}
+
+// TableColumns defines the columns of the pods like `kubectl get pods -o wide`.
+func (p *podsGetter) TableColumns() []v1alpha3.TableColumn {
+	return []v1alpha3.TableColumn{
+		v1alpha3.NameColumn(),
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Ready", Type: "string", Description: "The number of containers ready"},
+			Cell: podCell(func(pod *corev1.Pod) interface{} {
+				return fmt.Sprintf("%d/%d", readyContainers(pod), len(pod.Spec.Containers))
+			}),
+			SortValue: podCell(func(pod *corev1.Pod) interface{} {
+				return int64(readyContainers(pod))
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Status", Type: "string", Description: "The status of the pod"},
+			Cell: podCell(func(pod *corev1.Pod) interface{} {
+				reason, _ := p.getPodStatus(pod)
+				return reason
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Restarts", Type: "integer", Description: "The number of times the containers have been restarted"},
+			Cell: podCell(func(pod *corev1.Pod) interface{} {
+				var restarts int64
+				for _, container := range pod.Status.ContainerStatuses {
+					restarts += int64(container.RestartCount)
+				}
+				return restarts
+			}),
+		},
+		v1alpha3.AgeColumn(),
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "IP", Type: "string", Priority: 1, Description: "The IP address allocated to the pod"},
+			Cell: podCell(func(pod *corev1.Pod) interface{} {
+				return noneIfEmpty(pod.Status.PodIP)
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Node", Type: "string", Priority: 1, Description: "The node the pod is scheduled to"},
+			Cell: podCell(func(pod *corev1.Pod) interface{} {
+				return noneIfEmpty(pod.Spec.NodeName)
+			}),
+		},
+	}
+}
+
+func podCell(cell func(pod *corev1.Pod) interface{}) func(runtime.Object) interface{} {
+	return func(object runtime.Object) interface{} {
+		if pod, ok := object.(*corev1.Pod); ok {
+			return cell(pod)
+		}
+		return nil
+	}
+}
+
+func readyContainers(pod *corev1.Pod) int {
+	ready := 0
+	for _, container := range pod.Status.ContainerStatuses {
+		if container.Ready {
+			ready++
+		}
+	}
+	return ready
+}
+
+func noneIfEmpty(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
 func (p *podsGetter) compare(left runtime.Object, right runtime.Object, field query.Field) bool {
 
 	leftPod, ok := left.(*corev1.Pod)
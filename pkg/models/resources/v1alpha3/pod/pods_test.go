@@ -178,3 +178,49 @@ func prepare() v1alpha3.Interface {
 
 	return New(informer)
 }
+
+func TestListPodsAsTable(t *testing.T) {
+	newPod := func(name string, restarts int32, ready bool) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}, NodeName: "node1"},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: restarts, Ready: ready}},
+			},
+		}
+	}
+	informer := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	for _, pod := range []*corev1.Pod{newPod("bar1", 3, true), newPod("bar2", 10, false), newPod("bar3", 0, true)} {
+		_ = informer.Core().V1().Pods().Informer().GetIndexer().Add(pod)
+	}
+	getter := New(informer)
+	columns := getter.(v1alpha3.TableConvertor).TableColumns()
+
+	q := &query.Query{
+		Pagination: &query.Pagination{Limit: 2, Offset: 0},
+		SortBy:     query.Field("restarts"),
+		Filters:    map[query.Field]query.Value{},
+	}
+	table, err := v1alpha3.ListTable(getter, columns, "default", q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.TotalItems != 3 || len(table.Rows) != 2 {
+		t.Fatalf("unexpected table size: total %d, rows %d", table.TotalItems, len(table.Rows))
+	}
+	var names []interface{}
+	for _, row := range table.Rows {
+		names = append(names, row.Cells[0])
+	}
+	if diff := cmp.Diff([]interface{}{"bar2", "bar1"}, names); diff != "" {
+		t.Errorf("unexpected order of the rows: %s", diff)
+	}
+	expected := []interface{}{"bar2", "0/1", "Running", int64(10), "<unknown>", "<none>", "node1"}
+	if diff := cmp.Diff(expected, table.Rows[0].Cells); diff != "" {
+		t.Errorf("unexpected cells: %s", diff)
+	}
+	if table.ColumnDefinitions[3].Name != "Restarts" || table.Kind != "Table" {
+		t.Errorf("unexpected table %+v", table.TypeMeta)
+	}
+}
@@ -18,6 +18,8 @@ package resource
 
 import (
 	"errors"
+	"fmt"
+	"sync"
 
 	"kubesphere.io/kubesphere/pkg/models/resources/v1alpha3/volumesnapshotcontent"
 
@@ -29,6 +31,8 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	toolscache "k8s.io/client-go/tools/cache"
 	monitoringdashboardv1alpha2 "kubesphere.io/monitoring-dashboard/api/v1alpha2"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 
@@ -93,11 +97,20 @@ import (
 	"kubesphere.io/kubesphere/pkg/models/resources/v1alpha3/workspacetemplate"
 )
 
-var ErrResourceNotSupported = errors.New("resource is not supported")
+var (
+	ErrResourceNotSupported = errors.New("resource is not supported")
+	ErrWatchNotSupported    = errors.New("watch is not supported by the resource")
+	ErrTableNotSupported    = errors.New("table is not supported by the resource")
+)
 
 type ResourceGetter struct {
 	clusterResourceGetters    map[schema.GroupVersionResource]v1alpha3.Interface
 	namespacedResourceGetters map[schema.GroupVersionResource]v1alpha3.Interface
+
+	factory informers.InformerFactory
+	// sources of the watches, created on demand
+	sourcesMutex sync.Mutex
+	sources      map[schema.GroupVersionResource]*v1alpha3.Source
 }
 
 func NewResourceGetter(factory informers.InformerFactory, cache cache.Cache) *ResourceGetter {
@@ -131,7 +144,7 @@ func NewResourceGetter(factory informers.InformerFactory, cache cache.Cache) *Re
 	namespacedResourceGetters[networkv1alpha1.SchemeGroupVersion.WithResource(networkv1alpha1.ResourcePluralIPPool)] = ippool.New(factory.KubeSphereSharedInformerFactory(), factory.KubernetesSharedInformerFactory())
 	clusterResourceGetters[devopsv1alpha3.SchemeGroupVersion.WithResource(devopsv1alpha3.ResourcePluralDevOpsProject)] = devops.New(factory.KubeSphereSharedInformerFactory())
 	clusterResourceGetters[tenantv1alpha1.SchemeGroupVersion.WithResource(tenantv1alpha1.ResourcePluralWorkspace)] = workspace.New(factory.KubeSphereSharedInformerFactory())
-	clusterResourceGetters[tenantv1alpha1.SchemeGroupVersion.WithResource(tenantv1alpha2.ResourcePluralWorkspaceTemplate)] = workspacetemplate.New(factory.KubeSphereSharedInformerFactory())
+	clusterResourceGetters[tenantv1alpha2.SchemeGroupVersion.WithResource(tenantv1alpha2.ResourcePluralWorkspaceTemplate)] = workspacetemplate.New(factory.KubeSphereSharedInformerFactory())
 	clusterResourceGetters[iamv1alpha2.SchemeGroupVersion.WithResource(iamv1alpha2.ResourcesPluralGlobalRole)] = globalrole.New(factory.KubeSphereSharedInformerFactory())
 	clusterResourceGetters[iamv1alpha2.SchemeGroupVersion.WithResource(iamv1alpha2.ResourcesPluralWorkspaceRole)] = workspacerole.New(factory.KubeSphereSharedInformerFactory())
 	clusterResourceGetters[iamv1alpha2.SchemeGroupVersion.WithResource(iamv1alpha2.ResourcesPluralUser)] = user.New(factory.KubeSphereSharedInformerFactory(), factory.KubernetesSharedInformerFactory())
@@ -165,25 +178,32 @@ func NewResourceGetter(factory informers.InformerFactory, cache cache.Cache) *Re
 	return &ResourceGetter{
 		namespacedResourceGetters: namespacedResourceGetters,
 		clusterResourceGetters:    clusterResourceGetters,
+		factory:                   factory,
+		sources:                   make(map[schema.GroupVersionResource]*v1alpha3.Source),
 	}
 }
 
 // TryResource will retrieve a getter with resource name, it doesn't guarantee find resource with correct group version
 // need to refactor this use schema.GroupVersionResource
 func (r *ResourceGetter) TryResource(clusterScope bool, resource string) v1alpha3.Interface {
+	_, getter := r.tryResource(clusterScope, resource)
+	return getter
+}
+
+func (r *ResourceGetter) tryResource(clusterScope bool, resource string) (schema.GroupVersionResource, v1alpha3.Interface) {
 	if clusterScope {
 		for k, v := range r.clusterResourceGetters {
 			if k.Resource == resource {
-				return v
+				return k, v
 			}
 		}
 	}
 	for k, v := range r.namespacedResourceGetters {
 		if k.Resource == resource {
-			return v
+			return k, v
 		}
 	}
-	return nil
+	return schema.GroupVersionResource{}, nil
 }
 
 func (r *ResourceGetter) Get(resource, namespace, name string) (runtime.Object, error) {
@@ -203,3 +223,105 @@ func (r *ResourceGetter) List(resource, namespace string, query *query.Query) (*
 	}
 	return getter.List(namespace, query)
 }
+
+// Table lists the objects of the resource matching the query as a table.
+func (r *ResourceGetter) Table(resource, namespace string, q *query.Query) (*api.TableResult, error) {
+	getter := r.TryResource(namespace == "", resource)
+	if getter == nil {
+		return nil, ErrResourceNotSupported
+	}
+	convertor, ok := getter.(v1alpha3.TableConvertor)
+	if !ok {
+		return nil, ErrTableNotSupported
+	}
+	return v1alpha3.ListTable(getter, convertor.TableColumns(), namespace, q)
+}
+
+// Watch watches the objects of the resource matching the query.
+func (r *ResourceGetter) Watch(resource, namespace string, q *query.Query) (watch.Interface, error) {
+	getter := r.TryResource(namespace == "", resource)
+	if getter == nil {
+		return nil, ErrResourceNotSupported
+	}
+	// all the objects matching the query are watched
+	q.Pagination = query.NoPagination
+	return r.WatchList(resource, func() (*api.ListResult, error) {
+		return getter.List(namespace, q)
+	}, v1alpha3.WatchOptions{ResourceVersion: q.ResourceVersion, Namespace: namespace, LabelSelector: q.Selector(), Match: matchFunc(getter, q)})
+}
+
+// MatchFunc returns the function telling whether an object of the resource matches the query,
+// nil if the getter of the resource can't match the objects one by one.
+func (r *ResourceGetter) MatchFunc(resource string, q *query.Query) v1alpha3.MatchFunc {
+	return matchFunc(r.TryResource(true, resource), q)
+}
+
+func matchFunc(getter v1alpha3.Interface, q *query.Query) v1alpha3.MatchFunc {
+	matcher, ok := getter.(v1alpha3.Matcher)
+	if !ok {
+		return nil
+	}
+	return func(object runtime.Object) bool {
+		return matcher.Match(object, q)
+	}
+}
+
+// WatchList watches the objects of the resource listed by list, e.g. the objects visible to a user.
+func (r *ResourceGetter) WatchList(resource string, list v1alpha3.ListFunc, options v1alpha3.WatchOptions) (watch.Interface, error) {
+	gvr, getter := r.tryResource(true, resource)
+	if getter == nil {
+		return nil, ErrResourceNotSupported
+	}
+	source, err := r.source(gvr)
+	if err != nil {
+		return nil, err
+	}
+	return source.Watch(list, options)
+}
+
+func (r *ResourceGetter) source(gvr schema.GroupVersionResource) (*v1alpha3.Source, error) {
+	r.sourcesMutex.Lock()
+	defer r.sourcesMutex.Unlock()
+	if source, ok := r.sources[gvr]; ok {
+		return source, nil
+	}
+	informer := r.informerFor(gvr)
+	if informer == nil {
+		return nil, ErrWatchNotSupported
+	}
+	// the informers of the resources listed are started already, see APIServer.waitForResourceSync
+	if !informer.HasSynced() {
+		return nil, fmt.Errorf("%s are not cached", gvr)
+	}
+	source, err := v1alpha3.NewSource(informer)
+	if err != nil {
+		return nil, err
+	}
+	r.sources[gvr] = source
+	return source, nil
+}
+
+// informerFor returns the informer of the resource, the resources cached by the controller-runtime cache are not supported.
+func (r *ResourceGetter) informerFor(gvr schema.GroupVersionResource) toolscache.SharedIndexInformer {
+	if factory := r.factory.KubernetesSharedInformerFactory(); factory != nil {
+		if informer, err := factory.ForResource(gvr); err == nil {
+			return informer.Informer()
+		}
+	}
+	if factory := r.factory.KubeSphereSharedInformerFactory(); factory != nil {
+		if informer, err := factory.ForResource(gvr); err == nil {
+			return informer.Informer()
+		}
+	}
+	if factory := r.factory.SnapshotSharedInformerFactory(); factory != nil {
+		if informer, err := factory.ForResource(gvr); err == nil {
+			return informer.Informer()
+		}
+	}
+	if factory := r.factory.ApiExtensionSharedInformerFactory(); factory != nil {
+		if informer, err := factory.ForResource(gvr); err == nil {
+			return informer.Informer()
+		}
+	}
+	return nil
+}
@@ -60,6 +60,10 @@ func (s *secretSearcher) List(namespace string, query *query.Query) (*api.ListRe
 	return v1alpha3.DefaultList(result, query, s.compare, s.filter), nil
 }
 
+func (s *secretSearcher) Match(object runtime.Object, query *query.Query) bool {
+	return v1alpha3.DefaultMatch(object, query, s.filter)
+}
+
 func (s *secretSearcher) compare(left runtime.Object, right runtime.Object, field query.Field) bool {
 
 	leftSecret, ok := left.(*v1.Secret)
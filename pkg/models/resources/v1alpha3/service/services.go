@@ -17,7 +17,11 @@ limitations under the License.
 package service
 
 import (
+	"fmt"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 
@@ -53,6 +57,87 @@ func (d *servicesGetter) List(namespace string, query *query.Query) (*api.ListRe
 	return v1alpha3.DefaultList(result, query, d.compare, d.filter), nil
 }
 
+func (d *servicesGetter) Match(object runtime.Object, query *query.Query) bool {
+	return v1alpha3.DefaultMatch(object, query, d.filter)
+}
+
+// TableColumns defines the columns of the services like `kubectl get services`.
+func (d *servicesGetter) TableColumns() []v1alpha3.TableColumn {
+	return []v1alpha3.TableColumn{
+		v1alpha3.NameColumn(),
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Type", Type: "string", Description: "The type of the service"},
+			Cell: serviceCell(func(service *corev1.Service) interface{} {
+				return string(service.Spec.Type)
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Cluster-IP", Type: "string", Description: "The IP address allocated to the service in the cluster"},
+			Cell: serviceCell(func(service *corev1.Service) interface{} {
+				return noneIfEmpty(service.Spec.ClusterIP)
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "External-IP", Type: "string", Description: "The addresses the service is exposed on outside the cluster"},
+			Cell:                  serviceCell(externalIPs),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Port(s)", Type: "string", Description: "The ports the service is exposed on"},
+			Cell: serviceCell(func(service *corev1.Service) interface{} {
+				ports := make([]string, 0, len(service.Spec.Ports))
+				for _, port := range service.Spec.Ports {
+					if port.NodePort == 0 {
+						ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+					} else {
+						ports = append(ports, fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol))
+					}
+				}
+				return noneIfEmpty(strings.Join(ports, ","))
+			}),
+		},
+		v1alpha3.AgeColumn(),
+	}
+}
+
+func serviceCell(cell func(service *corev1.Service) interface{}) func(runtime.Object) interface{} {
+	return func(object runtime.Object) interface{} {
+		if service, ok := object.(*corev1.Service); ok {
+			return cell(service)
+		}
+		return nil
+	}
+}
+
+func externalIPs(service *corev1.Service) interface{} {
+	switch service.Spec.Type {
+	case corev1.ServiceTypeExternalName:
+		return service.Spec.ExternalName
+	case corev1.ServiceTypeLoadBalancer:
+		addresses := make([]string, 0, len(service.Status.LoadBalancer.Ingress))
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				addresses = append(addresses, ingress.IP)
+			} else if ingress.Hostname != "" {
+				addresses = append(addresses, ingress.Hostname)
+			}
+		}
+		addresses = append(addresses, service.Spec.ExternalIPs...)
+		if len(addresses) == 0 {
+			return "<pending>"
+		}
+		return strings.Join(addresses, ",")
+	default:
+		return noneIfEmpty(strings.Join(service.Spec.ExternalIPs, ","))
+	}
+}
+
+func noneIfEmpty(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
 func (d *servicesGetter) compare(left runtime.Object, right runtime.Object, field query.Field) bool {
 
 	leftService, ok := left.(*corev1.Service)
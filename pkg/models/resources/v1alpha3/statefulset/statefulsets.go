@@ -59,6 +59,10 @@ func (d *statefulSetGetter) List(namespace string, query *query.Query) (*api.Lis
 	return v1alpha3.DefaultList(result, query, d.compare, d.filter), nil
 }
 
+func (d *statefulSetGetter) Match(object runtime.Object, query *query.Query) bool {
+	return v1alpha3.DefaultMatch(object, query, d.filter)
+}
+
 func (d *statefulSetGetter) compare(left runtime.Object, right runtime.Object, field query.Field) bool {
 
 	leftStatefulSet, ok := left.(*appsv1.StatefulSet)
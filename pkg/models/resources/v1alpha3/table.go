@@ -0,0 +1,217 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"kubesphere.io/kubesphere/pkg/api"
+	"kubesphere.io/kubesphere/pkg/apiserver/query"
+)
+
+// TableColumn defines a column of the table presenting the objects of a resource.
+type TableColumn struct {
+	metav1.TableColumnDefinition
+	// Cell returns the cell of the object in the column.
+	Cell func(object runtime.Object) interface{}
+	// SortValue returns the value the objects are sorted by on the column, the cell is used if it's nil.
+	// Values of int64, float64, string and time.Time are compared by value, others by their string form.
+	SortValue func(object runtime.Object) interface{}
+}
+
+// TableConvertor is implemented by the getters presenting their objects as tables, like `kubectl get` does.
+type TableConvertor interface {
+	TableColumns() []TableColumn
+}
+
+// NameColumn presents the name of the objects.
+func NameColumn() TableColumn {
+	return TableColumn{
+		TableColumnDefinition: metav1.TableColumnDefinition{Name: "Name", Type: "string", Format: "name", Description: "Name of the object"},
+		Cell: func(object runtime.Object) interface{} {
+			if accessor, err := meta.Accessor(object); err == nil {
+				return accessor.GetName()
+			}
+			return ""
+		},
+	}
+}
+
+// AgeColumn presents the time since the objects are created, the oldest objects are the greatest.
+func AgeColumn() TableColumn {
+	creationTimestamp := func(object runtime.Object) time.Time {
+		if accessor, err := meta.Accessor(object); err == nil {
+			return accessor.GetCreationTimestamp().Time
+		}
+		return time.Time{}
+	}
+	return TableColumn{
+		TableColumnDefinition: metav1.TableColumnDefinition{Name: "Age", Type: "string", Description: "Time since the object is created"},
+		Cell: func(object runtime.Object) interface{} {
+			return HumanAge(creationTimestamp(object))
+		},
+		SortValue: func(object runtime.Object) interface{} {
+			return -creationTimestamp(object).UnixNano()
+		},
+	}
+}
+
+// HumanAge returns the time since t in the form of `kubectl get`, e.g. 5d3h.
+func HumanAge(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(t))
+}
+
+// ListTable lists the objects matching the query as a table. Besides the fields supported by the getter,
+// the rows can be sorted by the columns, e.g. ?sortBy=restarts, in which case all the objects are listed
+// before the page is taken.
+func ListTable(getter Interface, columns []TableColumn, namespace string, q *query.Query) (*api.TableResult, error) {
+	column := sortColumn(columns, q.SortBy)
+	if column == nil {
+		result, err := getter.List(namespace, q)
+		if err != nil {
+			return nil, err
+		}
+		return newTable(columns, result.Items, result.TotalItems), nil
+	}
+
+	listQuery := *q
+	listQuery.Pagination = query.NoPagination
+	result, err := getter.List(namespace, &listQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	// the values are taken once rather than on each comparison
+	type row struct {
+		item  interface{}
+		value interface{}
+	}
+	rows := make([]row, 0, len(result.Items))
+	for _, item := range result.Items {
+		if object, ok := item.(runtime.Object); ok {
+			rows = append(rows, row{item: item, value: sortValue(column, object)})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if q.Ascending {
+			return compareValues(rows[i].value, rows[j].value) < 0
+		}
+		return compareValues(rows[i].value, rows[j].value) > 0
+	})
+	items := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, row.item)
+	}
+
+	pagination := q.Pagination
+	if pagination == nil {
+		pagination = query.NoPagination
+	}
+	start, end := pagination.GetValidPagination(len(items))
+	return newTable(columns, items[start:end], len(items)), nil
+}
+
+func sortColumn(columns []TableColumn, sortBy query.Field) *TableColumn {
+	for i := range columns {
+		if strings.EqualFold(columns[i].Name, string(sortBy)) {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+func sortValue(column *TableColumn, object runtime.Object) interface{} {
+	if column.SortValue != nil {
+		return column.SortValue(object)
+	}
+	return column.Cell(object)
+}
+
+func newTable(columns []TableColumn, items []interface{}, total int) *api.TableResult {
+	table := &api.TableResult{TotalItems: total}
+	table.Kind = "Table"
+	table.APIVersion = metav1.SchemeGroupVersion.String()
+	table.ColumnDefinitions = make([]metav1.TableColumnDefinition, 0, len(columns))
+	for _, column := range columns {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, column.TableColumnDefinition)
+	}
+	table.Rows = make([]metav1.TableRow, 0, len(items))
+	for _, item := range items {
+		object, ok := item.(runtime.Object)
+		if !ok {
+			continue
+		}
+		row := metav1.TableRow{Cells: make([]interface{}, 0, len(columns))}
+		for _, column := range columns {
+			row.Cells = append(row.Cells, column.Cell(object))
+		}
+		// the metadata of the objects is included like Kubernetes does by default
+		if accessor, err := meta.Accessor(object); err == nil {
+			partial := meta.AsPartialObjectMetadata(accessor)
+			partial.Kind = "PartialObjectMetadata"
+			partial.APIVersion = metav1.SchemeGroupVersion.String()
+			row.Object = runtime.RawExtension{Object: partial}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+// compareValues returns a positive number if left is greater than right, a negative number if it's less.
+func compareValues(left, right interface{}) int {
+	switch l := left.(type) {
+	case int64:
+		if r, ok := right.(int64); ok {
+			return sign(l > r, l < r)
+		}
+	case float64:
+		if r, ok := right.(float64); ok {
+			return sign(l > r, l < r)
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r)
+		}
+	case time.Time:
+		if r, ok := right.(time.Time); ok {
+			return sign(l.After(r), l.Before(r))
+		}
+	}
+	return strings.Compare(fmt.Sprint(left), fmt.Sprint(right))
+}
+
+func sign(greater, less bool) int {
+	switch {
+	case greater:
+		return 1
+	case less:
+		return -1
+	default:
+		return 0
+	}
+}
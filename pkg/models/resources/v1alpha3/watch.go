@@ -0,0 +1,427 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"kubesphere.io/kubesphere/pkg/api"
+)
+
+const (
+	// maximum number of deletions kept for the watches to resume
+	maxDeletions = 1000
+	// changes notified in the period are coalesced before the objects are listed again
+	coalescePeriod = 100 * time.Millisecond
+	// maximum number of changes queued for a watch, the objects are listed again if the watch falls behind
+	maxPendingChanges = 100
+)
+
+var (
+	// BookmarkPeriod is the interval of the bookmarks, which tell the resource version the watch can resume from
+	BookmarkPeriod = 30 * time.Second
+	// ResyncPeriod is the interval the objects are listed again regardless of the changes notified,
+	// so that the changes of the permissions are applied to the watches scoped by the permissions
+	ResyncPeriod = time.Minute
+)
+
+// ListFunc lists the objects visible to a watch, e.g. the objects matching the query and the permissions of the user.
+type ListFunc func() (*api.ListResult, error)
+
+// MatchFunc tells whether an object changed is listed by the ListFunc of a watch, regardless of the permissions.
+type MatchFunc func(object runtime.Object) bool
+
+type WatchOptions struct {
+	// ResourceVersion the watch resumes from, the objects changed after it are sent,
+	// and all the objects listed are sent as ADDED if it's empty or "0".
+	ResourceVersion string
+	// Namespace and LabelSelector limit the deletions sent on resume, which can't be listed.
+	Namespace     string
+	LabelSelector labels.Selector
+	// Scoped means that the objects visible are scoped by the permissions of the user rather than the namespace,
+	// the objects deleted while the watch is disconnected can't be told, so it can't resume. The objects
+	// not visible when the objects are listed are not sent until they are listed again.
+	Scoped bool
+	// Match makes the changes sent one by one, the objects are only listed again every ResyncPeriod,
+	// all the objects are listed again on every change if it's nil.
+	Match MatchFunc
+}
+
+// change is a change of an object observed by the informer.
+type change struct {
+	object  runtime.Object
+	deleted bool
+}
+
+type deletion struct {
+	object          runtime.Object
+	resourceVersion uint64
+}
+
+// Source notifies the watches of the changes of the objects cached by an informer,
+// and keeps the deletions recently observed so that the watches can resume.
+type Source struct {
+	informer cache.SharedIndexInformer
+
+	mutex sync.Mutex
+	// deletions after the resource version are kept
+	since     uint64
+	deletions []deletion
+	watches   map[*watcher]struct{}
+}
+
+func NewSource(informer cache.SharedIndexInformer) (*Source, error) {
+	s := &Source{
+		informer: informer,
+		since:    parseResourceVersion(informer.LastSyncResourceVersion()),
+		watches:  make(map[*watcher]struct{}),
+	}
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			s.notify(obj, false)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			s.notify(obj, false)
+		},
+		DeleteFunc: s.onDelete,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Source) onDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if object, ok := obj.(runtime.Object); ok {
+		if accessor, err := meta.Accessor(object); err == nil {
+			s.mutex.Lock()
+			s.deletions = append(s.deletions, deletion{object: object, resourceVersion: parseResourceVersion(accessor.GetResourceVersion())})
+			if len(s.deletions) > maxDeletions {
+				s.since = s.deletions[0].resourceVersion
+				s.deletions = s.deletions[1:]
+			}
+			s.mutex.Unlock()
+		}
+	}
+	s.notify(obj, true)
+}
+
+// notify queues the change for the watches matching the objects one by one, and tells the others
+// to list the objects again.
+func (s *Source) notify(obj interface{}, deleted bool) {
+	object, _ := obj.(runtime.Object)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for w := range s.watches {
+		if w.options.Match != nil && object != nil {
+			select {
+			case w.changes <- change{object: object, deleted: deleted}:
+				continue
+			default:
+				// the watch falls behind, the changes are caught up by listing the objects again
+			}
+		}
+		select {
+		case w.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// deletedAfter returns the objects deleted after the resource version.
+func (s *Source) deletedAfter(resourceVersion uint64) []runtime.Object {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var objects []runtime.Object
+	for _, d := range s.deletions {
+		if d.resourceVersion > resourceVersion {
+			objects = append(objects, d.object)
+		}
+	}
+	return objects
+}
+
+// Watch sends the changes of the objects listed by list as ADDED, MODIFIED and DELETED. The changes notified
+// by the informer are sent one by one if options.Match is set, and the objects are listed again every
+// ResyncPeriod, the differences from the objects sent before are sent. Bookmarks are sent every BookmarkPeriod.
+func (s *Source) Watch(list ListFunc, options WatchOptions) (watch.Interface, error) {
+	var from uint64
+	if options.ResourceVersion != "" && options.ResourceVersion != "0" {
+		var err error
+		if from, err = strconv.ParseUint(options.ResourceVersion, 10, 64); err != nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid resource version: %s", options.ResourceVersion))
+		}
+		if options.Scoped {
+			return nil, apierrors.NewResourceExpired("the watch can't resume, please list again")
+		}
+		s.mutex.Lock()
+		since := s.since
+		s.mutex.Unlock()
+		if from < since {
+			return nil, apierrors.NewResourceExpired(fmt.Sprintf("too old resource version: %d (%d)", from, since))
+		}
+	}
+
+	w := &watcher{
+		source:  s,
+		list:    list,
+		options: options,
+		result:  make(chan watch.Event),
+		changes: make(chan change, maxPendingChanges),
+		changed: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		objects: make(map[string]runtime.Object),
+	}
+	s.mutex.Lock()
+	s.watches[w] = struct{}{}
+	s.mutex.Unlock()
+
+	go w.run(from)
+	return w, nil
+}
+
+type watcher struct {
+	source  *Source
+	list    ListFunc
+	options WatchOptions
+	result  chan watch.Event
+	changes chan change
+	// the objects are listed again once changed is notified
+	changed  chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+	// objects sent by namespace/name
+	objects map[string]runtime.Object
+	// resource version of the cache when the objects are listed
+	resourceVersion string
+}
+
+func (w *watcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *watcher) Stop() {
+	w.stopOnce.Do(func() {
+		w.source.mutex.Lock()
+		delete(w.source.watches, w)
+		w.source.mutex.Unlock()
+		close(w.done)
+	})
+}
+
+func (w *watcher) run(from uint64) {
+	defer close(w.result)
+
+	bookmark := time.NewTicker(BookmarkPeriod)
+	defer bookmark.Stop()
+	resync := time.NewTicker(ResyncPeriod)
+	defer resync.Stop()
+
+	if !w.sync(from) {
+		return
+	}
+	for {
+		select {
+		case <-w.done:
+			return
+		case c := <-w.changes:
+			if !w.apply(c) {
+				return
+			}
+		case <-w.changed:
+			select {
+			case <-time.After(coalescePeriod):
+			case <-w.done:
+				return
+			}
+			if !w.sync(0) {
+				return
+			}
+		case <-resync.C:
+			if !w.sync(0) {
+				return
+			}
+		case <-bookmark.C:
+			if w.resourceVersion != "" && !w.send(watch.Bookmark, &metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{ResourceVersion: w.resourceVersion},
+			}) {
+				return
+			}
+		}
+	}
+}
+
+// sync lists the objects and sends the differences, the objects changed after from are sent as MODIFIED
+// if the watch resumes from the resource version, it returns false if the watch is stopped.
+func (w *watcher) sync(from uint64) bool {
+	// the objects listed contain the changes of the resource version at least
+	resourceVersion := w.source.informer.LastSyncResourceVersion()
+	result, err := w.list()
+	if err != nil {
+		w.send(watch.Error, &apierrors.NewInternalError(err).ErrStatus)
+		return false
+	}
+
+	objects := make(map[string]runtime.Object, len(result.Items))
+	for _, item := range result.Items {
+		object, ok := item.(runtime.Object)
+		if !ok {
+			continue
+		}
+		accessor, err := meta.Accessor(object)
+		if err != nil {
+			continue
+		}
+		key := objectKey(accessor)
+		objects[key] = object
+
+		sent, ok := w.objects[key]
+		switch {
+		case !ok && from > 0:
+			// the client has the objects not changed since it disconnected
+			if parseResourceVersion(accessor.GetResourceVersion()) > from && !w.send(watch.Modified, object) {
+				return false
+			}
+		case !ok:
+			if !w.send(watch.Added, object) {
+				return false
+			}
+		case resourceVersionOf(sent) != accessor.GetResourceVersion():
+			if !w.send(watch.Modified, object) {
+				return false
+			}
+		}
+	}
+
+	for key, object := range w.objects {
+		if _, ok := objects[key]; !ok && !w.send(watch.Deleted, object) {
+			return false
+		}
+	}
+	if from > 0 {
+		for _, object := range w.source.deletedAfter(from) {
+			accessor, err := meta.Accessor(object)
+			if err != nil {
+				continue
+			}
+			if w.options.Namespace != "" && accessor.GetNamespace() != w.options.Namespace {
+				continue
+			}
+			if w.options.LabelSelector != nil && !w.options.LabelSelector.Matches(labels.Set(accessor.GetLabels())) {
+				continue
+			}
+			if _, ok := objects[objectKey(accessor)]; !ok && !w.send(watch.Deleted, object) {
+				return false
+			}
+		}
+	}
+
+	w.objects = objects
+	w.resourceVersion = resourceVersion
+	return true
+}
+
+// apply sends the change of the object if it changes the objects visible to the watch,
+// it returns false if the watch is stopped.
+func (w *watcher) apply(c change) bool {
+	accessor, err := meta.Accessor(c.object)
+	if err != nil {
+		return true
+	}
+	key := objectKey(accessor)
+	sent, ok := w.objects[key]
+
+	visible := !c.deleted && w.options.Match(c.object)
+	if w.options.Namespace != "" && accessor.GetNamespace() != w.options.Namespace {
+		visible = false
+	}
+	// the permissions are only evaluated when the objects are listed
+	if w.options.Scoped && !ok {
+		visible = false
+	}
+
+	switch {
+	case visible && !ok:
+		if !w.send(watch.Added, c.object) {
+			return false
+		}
+		w.objects[key] = c.object
+	case visible:
+		// the changes queued before the objects are listed may be older than the objects sent
+		current, previous := parseResourceVersion(accessor.GetResourceVersion()), parseResourceVersion(resourceVersionOf(sent))
+		if accessor.GetResourceVersion() == resourceVersionOf(sent) || (current > 0 && current < previous) {
+			return true
+		}
+		if !w.send(watch.Modified, c.object) {
+			return false
+		}
+		w.objects[key] = c.object
+	case ok:
+		if !w.send(watch.Deleted, c.object) {
+			return false
+		}
+		delete(w.objects, key)
+	}
+	if resourceVersion := accessor.GetResourceVersion(); parseResourceVersion(resourceVersion) > parseResourceVersion(w.resourceVersion) {
+		w.resourceVersion = resourceVersion
+	}
+	return true
+}
+
+func (w *watcher) send(eventType watch.EventType, object runtime.Object) bool {
+	select {
+	case w.result <- watch.Event{Type: eventType, Object: object}:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+func objectKey(accessor metav1.Object) string {
+	if accessor.GetNamespace() == "" {
+		return accessor.GetName()
+	}
+	return accessor.GetNamespace() + "/" + accessor.GetName()
+}
+
+func resourceVersionOf(object runtime.Object) string {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return ""
+	}
+	return accessor.GetResourceVersion()
+}
+
+// parseResourceVersion returns 0 if the resource version is not a number.
+func parseResourceVersion(resourceVersion string) uint64 {
+	value, _ := strconv.ParseUint(resourceVersion, 10, 64)
+	return value
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"kubesphere.io/kubesphere/pkg/api"
+)
+
+func newNamespace(name, resourceVersion string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: resourceVersion, Labels: labels}}
+}
+
+func expectEvent(t *testing.T, w watch.Interface, eventType watch.EventType, name string) {
+	t.Helper()
+	select {
+	case event := <-w.ResultChan():
+		namespace, ok := event.Object.(*corev1.Namespace)
+		if event.Type != eventType || !ok || namespace.Name != name {
+			t.Fatalf("expected %s %s, got %s %+v", eventType, name, event.Type, event.Object)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %s %s", eventType, name)
+	}
+}
+
+func TestSourceWatch(t *testing.T) {
+	client := fake.NewSimpleClientset(newNamespace("foo", "1", map[string]string{"app": "demo"}))
+	factory := informers.NewSharedInformerFactory(client, 0)
+	informer := factory.Core().V1().Namespaces().Informer()
+	source, err := NewSource(informer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, informer.HasSynced)
+
+	selector := labels.SelectorFromSet(labels.Set{"app": "demo"})
+	list := func() (*api.ListResult, error) {
+		namespaces, err := factory.Core().V1().Namespaces().Lister().List(selector)
+		if err != nil {
+			return nil, err
+		}
+		result := &api.ListResult{TotalItems: len(namespaces)}
+		for _, namespace := range namespaces {
+			result.Items = append(result.Items, namespace)
+		}
+		return result, nil
+	}
+
+	w, err := source.Watch(list, WatchOptions{LabelSelector: selector})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	expectEvent(t, w, watch.Added, "foo")
+
+	ctx := context.Background()
+	namespaces := client.CoreV1().Namespaces()
+	if _, err = namespaces.Create(ctx, newNamespace("other", "2", nil), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = namespaces.Create(ctx, newNamespace("bar", "3", map[string]string{"app": "demo"}), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, watch.Added, "bar")
+	if _, err = namespaces.Update(ctx, newNamespace("foo", "4", map[string]string{"app": "demo", "tier": "web"}), metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, watch.Modified, "foo")
+	if err = namespaces.Delete(ctx, "bar", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, watch.Deleted, "bar")
+
+	// resuming from the resource version before the update and the deletion,
+	// the fake client keeps the resource version of the objects deleted
+	resumed, err := source.Watch(list, WatchOptions{ResourceVersion: "2", LabelSelector: selector})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Stop()
+	expectEvent(t, resumed, watch.Modified, "foo")
+	expectEvent(t, resumed, watch.Deleted, "bar")
+
+	if _, err = source.Watch(list, WatchOptions{ResourceVersion: "3", Scoped: true}); !apierrors.IsResourceExpired(err) {
+		t.Errorf("expected resource expired error, got %v", err)
+	}
+	if _, err = source.Watch(list, WatchOptions{ResourceVersion: "latest"}); !apierrors.IsBadRequest(err) {
+		t.Errorf("expected bad request error, got %v", err)
+	}
+}
+
+func TestSourceWatchMatch(t *testing.T) {
+	client := fake.NewSimpleClientset(newNamespace("foo", "1", map[string]string{"app": "demo"}))
+	factory := informers.NewSharedInformerFactory(client, 0)
+	informer := factory.Core().V1().Namespaces().Informer()
+	source, err := NewSource(informer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, informer.HasSynced)
+
+	selector := labels.SelectorFromSet(labels.Set{"app": "demo"})
+	var lists int32
+	list := func() (*api.ListResult, error) {
+		atomic.AddInt32(&lists, 1)
+		namespaces, err := factory.Core().V1().Namespaces().Lister().List(selector)
+		if err != nil {
+			return nil, err
+		}
+		result := &api.ListResult{TotalItems: len(namespaces)}
+		for _, namespace := range namespaces {
+			result.Items = append(result.Items, namespace)
+		}
+		return result, nil
+	}
+	match := func(object runtime.Object) bool {
+		return selector.Matches(labels.Set(object.(*corev1.Namespace).Labels))
+	}
+
+	w, err := source.Watch(list, WatchOptions{LabelSelector: selector, Match: match})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	scoped, err := source.Watch(list, WatchOptions{LabelSelector: selector, Match: match, Scoped: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer scoped.Stop()
+	expectEvent(t, w, watch.Added, "foo")
+	expectEvent(t, scoped, watch.Added, "foo")
+
+	ctx := context.Background()
+	namespaces := client.CoreV1().Namespaces()
+	if _, err = namespaces.Create(ctx, newNamespace("other", "2", nil), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = namespaces.Create(ctx, newNamespace("bar", "3", map[string]string{"app": "demo"}), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, watch.Added, "bar")
+	// the object no longer matching is deleted from the watch
+	if _, err = namespaces.Update(ctx, newNamespace("foo", "4", nil), metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, watch.Deleted, "foo")
+	// bar is not sent by the scoped watch until the objects are listed again
+	expectEvent(t, scoped, watch.Deleted, "foo")
+
+	if n := atomic.LoadInt32(&lists); n != 2 {
+		t.Errorf("expected the objects to be listed once by each watch, got %d lists", n)
+	}
+}
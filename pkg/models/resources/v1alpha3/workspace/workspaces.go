@@ -17,6 +17,7 @@ limitations under the License.
 package workspace
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	tenantv1alpha1 "kubesphere.io/api/tenant/v1alpha1"
@@ -54,6 +55,39 @@ func (d *workspaceGetter) List(_ string, query *query.Query) (*api.ListResult, e
 	return v1alpha3.DefaultList(result, query, d.compare, d.filter), nil
 }
 
+func (d *workspaceGetter) Match(object runtime.Object, query *query.Query) bool {
+	return v1alpha3.DefaultMatch(object, query, d.filter)
+}
+
+// TableColumns defines the columns of the workspaces.
+func (d *workspaceGetter) TableColumns() []v1alpha3.TableColumn {
+	return []v1alpha3.TableColumn{
+		v1alpha3.NameColumn(),
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Manager", Type: "string", Description: "The manager of the workspace"},
+			Cell: workspaceCell(func(workspace *tenantv1alpha1.Workspace) interface{} {
+				return workspace.Spec.Manager
+			}),
+		},
+		{
+			TableColumnDefinition: metav1.TableColumnDefinition{Name: "Network Isolation", Type: "boolean", Description: "Whether the network of the workspace is isolated"},
+			Cell: workspaceCell(func(workspace *tenantv1alpha1.Workspace) interface{} {
+				return workspace.Spec.NetworkIsolation != nil && *workspace.Spec.NetworkIsolation
+			}),
+		},
+		v1alpha3.AgeColumn(),
+	}
+}
+
+func workspaceCell(cell func(workspace *tenantv1alpha1.Workspace) interface{}) func(runtime.Object) interface{} {
+	return func(object runtime.Object) interface{} {
+		if workspace, ok := object.(*tenantv1alpha1.Workspace); ok {
+			return cell(workspace)
+		}
+		return nil
+	}
+}
+
 func (d *workspaceGetter) compare(left runtime.Object, right runtime.Object, field query.Field) bool {
 
 	leftWorkspace, ok := left.(*tenantv1alpha1.Workspace)
@@ -54,6 +54,10 @@ func (d *workspaceGetter) List(_ string, query *query.Query) (*api.ListResult, e
 	return v1alpha3.DefaultList(result, query, d.compare, d.filter), nil
 }
 
+func (d *workspaceGetter) Match(object runtime.Object, query *query.Query) bool {
+	return v1alpha3.DefaultMatch(object, query, d.filter)
+}
+
 func (d *workspaceGetter) compare(left runtime.Object, right runtime.Object, field query.Field) bool {
 
 	leftWorkspace, ok := left.(*tenantv1alpha2.WorkspaceTemplate)
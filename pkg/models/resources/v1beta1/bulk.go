@@ -0,0 +1,441 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authorization/authorizer"
+	"kubesphere.io/kubesphere/pkg/apiserver/query"
+	"kubesphere.io/kubesphere/pkg/apiserver/request"
+)
+
+// The operations of the bulk requests, the resources are patched to be scaled and restarted, same as kubectl.
+const (
+	BulkDelete  = "delete"
+	BulkPatch   = "patch"
+	BulkScale   = "scale"
+	BulkRestart = "restart"
+)
+
+// The status of each resource of a bulk request.
+const (
+	BulkItemSucceeded = "Succeeded"
+	BulkItemFailed    = "Failed"
+	BulkItemForbidden = "Forbidden"
+)
+
+const (
+	defaultBulkConcurrency = 5
+	maxBulkConcurrency     = 20
+	// the max number of the resources of a bulk request, either listed or selected
+	maxBulkItems = 500
+
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+)
+
+type BulkObject struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// BulkRequest applies the operation to the resources of the GroupVersionResource,
+// either listed by Items or selected by LabelSelector in Namespace, all namespaces if empty.
+type BulkRequest struct {
+	Group         string       `json:"group"`
+	Version       string       `json:"version"`
+	Resource      string       `json:"resource"`
+	Namespace     string       `json:"namespace,omitempty"`
+	LabelSelector string       `json:"labelSelector,omitempty"`
+	Items         []BulkObject `json:"items,omitempty"`
+	Operation     string       `json:"operation"`
+	// Patch is the JSON merge patch of the patch operation.
+	Patch json.RawMessage `json:"patch,omitempty"`
+	// Replicas of the scale operation.
+	Replicas *int32 `json:"replicas,omitempty"`
+	// DryRun submits the changes to the server without persisting them.
+	DryRun bool `json:"dryRun,omitempty"`
+	// Concurrency is the max number of the resources operated at the same time.
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+type BulkItemResult struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+type BulkResult struct {
+	Operation string           `json:"operation"`
+	DryRun    bool             `json:"dryRun,omitempty"`
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Items     []BulkItemResult `json:"items"`
+}
+
+func (r *BulkRequest) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+func (r *BulkRequest) Validate() error {
+	if r.Version == "" || r.Resource == "" {
+		return errors.NewBadRequest("the version and the resource are required")
+	}
+	if len(r.Items) == 0 && r.LabelSelector == "" {
+		return errors.NewBadRequest("either the items or the label selector is required")
+	}
+	if len(r.Items) > maxBulkItems {
+		return errors.NewBadRequest(fmt.Sprintf("at most %d items are allowed", maxBulkItems))
+	}
+	if _, err := labels.Parse(r.LabelSelector); err != nil {
+		return errors.NewBadRequest(fmt.Sprintf("invalid label selector: %v", err))
+	}
+	for _, item := range r.Items {
+		if item.Name == "" {
+			return errors.NewBadRequest("the names of the items are required")
+		}
+	}
+	switch r.Operation {
+	case BulkDelete, BulkRestart:
+	case BulkPatch:
+		if len(r.Patch) == 0 || !json.Valid(r.Patch) {
+			return errors.NewBadRequest("a valid JSON merge patch is required")
+		}
+	case BulkScale:
+		if r.Replicas == nil || *r.Replicas < 0 {
+			return errors.NewBadRequest("the replicas must not be negative")
+		}
+	default:
+		return errors.NewBadRequest(fmt.Sprintf("unsupported operation %q", r.Operation))
+	}
+	if r.Concurrency < 0 || r.Concurrency > maxBulkConcurrency {
+		return errors.NewBadRequest(fmt.Sprintf("the concurrency must be between 1 and %d", maxBulkConcurrency))
+	}
+	return nil
+}
+
+// BulkOperator applies an operation to many resources in a request, each resource is authorized for the user.
+type BulkOperator interface {
+	Do(ctx context.Context, user user.Info, bulk *BulkRequest) (*BulkResult, error)
+}
+
+type bulkOperator struct {
+	resourceManager ResourceManager
+	authorizer      authorizer.Authorizer
+}
+
+func NewBulkOperator(resourceManager ResourceManager, authorizer authorizer.Authorizer) BulkOperator {
+	return &bulkOperator{resourceManager: resourceManager, authorizer: authorizer}
+}
+
+// bulkTarget is a resource to operate, object is nil if it's not got yet.
+type bulkTarget struct {
+	namespace string
+	name      string
+	object    client.Object
+}
+
+func (b *bulkOperator) Do(ctx context.Context, user user.Info, bulk *BulkRequest) (*BulkResult, error) {
+	if err := bulk.Validate(); err != nil {
+		return nil, err
+	}
+	gvr := bulk.GroupVersionResource()
+	served, err := b.resourceManager.IsServed(gvr)
+	if err != nil {
+		return nil, err
+	}
+	if !served {
+		return nil, errors.NewNotFound(gvr.GroupResource(), "")
+	}
+	namespaced, err := b.resourceManager.IsNamespaced(gvr)
+	if err != nil {
+		return nil, err
+	}
+	// the namespaces of the cluster scoped resources are ignored, so that they are authorized in the cluster scope
+	if !namespaced {
+		bulk = clusterScoped(bulk)
+	}
+
+	targets, err := b.targets(ctx, user, bulk)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := bulk.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultBulkConcurrency
+	}
+	results := make([]BulkItemResult, len(targets))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range targets {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			results[i] = b.apply(ctx, user, bulk, targets[i])
+		}(i)
+	}
+	wg.Wait()
+
+	result := &BulkResult{Operation: bulk.Operation, DryRun: bulk.DryRun, Total: len(results), Items: results}
+	for _, item := range results {
+		if item.Status == BulkItemSucceeded {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// clusterScoped returns a copy of the request without the namespaces.
+func clusterScoped(bulk *BulkRequest) *BulkRequest {
+	copied := *bulk
+	copied.Namespace = ""
+	copied.Items = make([]BulkObject, 0, len(bulk.Items))
+	for _, item := range bulk.Items {
+		copied.Items = append(copied.Items, BulkObject{Name: item.Name})
+	}
+	return &copied
+}
+
+// targets returns the resources listed, or the resources selected if the user is allowed to list them.
+func (b *bulkOperator) targets(ctx context.Context, user user.Info, bulk *BulkRequest) ([]bulkTarget, error) {
+	if len(bulk.Items) > 0 {
+		targets := make([]bulkTarget, 0, len(bulk.Items))
+		for _, item := range bulk.Items {
+			namespace := item.Namespace
+			if namespace == "" {
+				namespace = bulk.Namespace
+			}
+			targets = append(targets, bulkTarget{namespace: namespace, name: item.Name})
+		}
+		return targets, nil
+	}
+
+	gvr := bulk.GroupVersionResource()
+	allowed, reason, err := b.authorize(user, "list", gvr, bulk.Namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.NewForbidden(gvr.GroupResource(), "", fmt.Errorf("%s", reason))
+	}
+
+	q := query.New()
+	q.LabelSelector = bulk.LabelSelector
+	list, err := b.resourceManager.ListResources(ctx, gvr, bulk.Namespace, q)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) > maxBulkItems {
+		return nil, errors.NewBadRequest(fmt.Sprintf("%d resources are selected, at most %d are allowed", len(objects), maxBulkItems))
+	}
+	targets := make([]bulkTarget, 0, len(objects))
+	for _, object := range objects {
+		o := object.(client.Object)
+		targets = append(targets, bulkTarget{namespace: o.GetNamespace(), name: o.GetName(), object: o})
+	}
+	return targets, nil
+}
+
+func (b *bulkOperator) authorize(user user.Info, verb string, gvr schema.GroupVersionResource, namespace, name string) (bool, string, error) {
+	scope := request.ClusterScope
+	if namespace != "" {
+		scope = request.NamespaceScope
+	}
+	decision, reason, err := b.authorizer.Authorize(authorizer.AttributesRecord{
+		User:            user,
+		Verb:            verb,
+		APIGroup:        gvr.Group,
+		APIVersion:      gvr.Version,
+		Resource:        gvr.Resource,
+		Namespace:       namespace,
+		Name:            name,
+		ResourceRequest: true,
+		ResourceScope:   scope,
+	})
+	if err != nil {
+		return false, "", err
+	}
+	return decision == authorizer.DecisionAllow, reason, nil
+}
+
+// apply applies the operation to the resource if the user is allowed to.
+func (b *bulkOperator) apply(ctx context.Context, user user.Info, bulk *BulkRequest, target bulkTarget) BulkItemResult {
+	result := BulkItemResult{Namespace: target.namespace, Name: target.name}
+	failed := func(err error) BulkItemResult {
+		result.Status = BulkItemFailed
+		result.Message = err.Error()
+		return result
+	}
+	if err := ctx.Err(); err != nil {
+		return failed(err)
+	}
+
+	verb := "patch"
+	if bulk.Operation == BulkDelete {
+		verb = "delete"
+	}
+	gvr := bulk.GroupVersionResource()
+	allowed, reason, err := b.authorize(user, verb, gvr, target.namespace, target.name)
+	if err != nil {
+		return failed(err)
+	}
+	if !allowed {
+		result.Status = BulkItemForbidden
+		result.Message = reason
+		return result
+	}
+
+	object := target.object
+	if object == nil {
+		if object, err = b.resourceManager.GetResource(ctx, gvr, target.namespace, target.name); err != nil {
+			return failed(err)
+		}
+	}
+	// the resource is authorized again in its own namespace, if it's not the one requested
+	if object.GetNamespace() != target.namespace {
+		allowed, reason, err = b.authorize(user, verb, gvr, object.GetNamespace(), object.GetName())
+		if err != nil {
+			return failed(err)
+		}
+		if !allowed {
+			result.Status = BulkItemForbidden
+			result.Message = reason
+			return result
+		}
+	}
+
+	if bulk.Operation == BulkDelete {
+		var opts []client.DeleteOption
+		if bulk.DryRun {
+			opts = append(opts, client.DryRunAll)
+		}
+		if err = b.resourceManager.Delete(ctx, object, opts...); err != nil {
+			return failed(err)
+		}
+		result.Status = BulkItemSucceeded
+		return result
+	}
+
+	patch, err := bulkPatch(bulk, object)
+	if err != nil {
+		return failed(err)
+	}
+	patched, err := mergePatch(object, patch)
+	if err != nil {
+		return failed(err)
+	}
+	var opts []client.PatchOption
+	if bulk.DryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+	if err = b.resourceManager.Patch(ctx, object, patched, opts...); err != nil {
+		return failed(err)
+	}
+	result.Status = BulkItemSucceeded
+	return result
+}
+
+// bulkPatch returns the JSON merge patch of the operation,
+// the resources must have the fields changed, e.g. spec.replicas to be scaled.
+func bulkPatch(bulk *BulkRequest, object client.Object) ([]byte, error) {
+	var content map[string]interface{}
+	if u, ok := object.(runtime.Unstructured); ok {
+		content = u.UnstructuredContent()
+	} else {
+		var err error
+		if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(object); err != nil {
+			return nil, err
+		}
+	}
+
+	switch bulk.Operation {
+	case BulkScale:
+		if _, found, _ := unstructured.NestedFieldNoCopy(content, "spec", "replicas"); !found {
+			return nil, errors.NewBadRequest("the resource can't be scaled")
+		}
+		return json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": *bulk.Replicas},
+		})
+	case BulkRestart:
+		if _, found, _ := unstructured.NestedFieldNoCopy(content, "spec", "template"); !found {
+			return nil, errors.NewBadRequest("the resource can't be restarted")
+		}
+		return json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"annotations": map[string]string{restartedAtAnnotation: time.Now().Format(time.RFC3339)},
+					},
+				},
+			},
+		})
+	default:
+		return bulk.Patch, nil
+	}
+}
+
+// mergePatch returns a copy of the object with the JSON merge patch applied.
+func mergePatch(object client.Object, patch []byte) (client.Object, error) {
+	original, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	data, err := jsonpatch.MergePatch(original, patch)
+	if err != nil {
+		return nil, errors.NewBadRequest(err.Error())
+	}
+
+	patched := object.DeepCopyObject().(client.Object)
+	// reset the copy so that the fields removed by the patch are removed
+	value := reflect.ValueOf(patched).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	if err = json.Unmarshal(data, patched); err != nil {
+		return nil, errors.NewBadRequest(err.Error())
+	}
+	if patched.GetName() != object.GetName() || patched.GetNamespace() != object.GetNamespace() {
+		return nil, errors.NewBadRequest("the name and the namespace can't be patched")
+	}
+	return patched, nil
+}
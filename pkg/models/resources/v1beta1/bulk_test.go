@@ -0,0 +1,229 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
+	"kubesphere.io/kubesphere/pkg/apiserver/authorization/authorizer"
+	"kubesphere.io/kubesphere/pkg/apiserver/authorization/authorizerfactory"
+	"kubesphere.io/kubesphere/pkg/apiserver/authorization/scope"
+	"kubesphere.io/kubesphere/pkg/apiserver/authorization/union"
+)
+
+// fakeCache reads from the fake client.
+type fakeCache struct {
+	cache.Cache
+	reader client.Client
+}
+
+func (c *fakeCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	// the namespace of the cluster scoped resources is ignored, same as the informer cache
+	gvk, err := apiutil.GVKForObject(obj, c.reader.Scheme())
+	if err != nil {
+		return err
+	}
+	mapping, err := c.reader.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		key.Namespace = ""
+	}
+	return c.reader.Get(ctx, key, obj, opts...)
+}
+
+func (c *fakeCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.reader.List(ctx, list, opts...)
+}
+
+func newBulkDeployment(namespace, name string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{"app": "demo"}},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(1)},
+	}
+}
+
+func TestBulkOperator(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRESTMapper(mapper).WithObjects(
+		newBulkDeployment("default", "foo"),
+		newBulkDeployment("default", "bar"),
+		newBulkDeployment("kube-system", "baz"),
+	).Build()
+	// the user is allowed to operate the deployments in the default namespace only
+	allowDefault := authorizer.AuthorizerFunc(func(a authorizer.Attributes) (authorizer.Decision, string, error) {
+		if a.GetNamespace() == "default" {
+			return authorizer.DecisionAllow, "", nil
+		}
+		return authorizer.DecisionDeny, "forbidden", nil
+	})
+	operator := NewBulkOperator(New(fakeClient, &fakeCache{reader: fakeClient}), allowDefault)
+	admin := &user.DefaultInfo{Name: "admin"}
+	ctx := context.Background()
+
+	getReplicas := func(namespace, name string) int32 {
+		deployment := &appsv1.Deployment{}
+		if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, deployment); err != nil {
+			t.Fatal(err)
+		}
+		return *deployment.Spec.Replicas
+	}
+
+	result, err := operator.Do(ctx, admin, &BulkRequest{
+		Group: "apps", Version: "v1", Resource: "deployments", Namespace: "default",
+		LabelSelector: "app=demo", Operation: BulkScale, Replicas: pointer.Int32(3),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || result.Succeeded != 2 || getReplicas("default", "foo") != 3 || getReplicas("default", "bar") != 3 {
+		t.Errorf("expected the deployments selected to be scaled, got %+v", result)
+	}
+
+	result, err = operator.Do(ctx, admin, &BulkRequest{
+		Group: "apps", Version: "v1", Resource: "deployments", Operation: BulkPatch,
+		Patch: json.RawMessage(`{"metadata":{"labels":{"tier":"web"}}}`),
+		Items: []BulkObject{{Namespace: "default", Name: "foo"}, {Namespace: "default", Name: "missing"}, {Namespace: "kube-system", Name: "baz"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{BulkItemSucceeded, BulkItemFailed, BulkItemForbidden}
+	for i, item := range result.Items {
+		if item.Status != expected[i] {
+			t.Errorf("expected %s of %s to be %s, got %+v", result.Operation, item.Name, expected[i], item)
+		}
+	}
+	patched := &appsv1.Deployment{}
+	_ = fakeClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "foo"}, patched)
+	if patched.Labels["tier"] != "web" || patched.Labels["app"] != "demo" {
+		t.Errorf("expected the labels to be merged, got %v", patched.Labels)
+	}
+
+	// listing all the namespaces is not allowed
+	if _, err = operator.Do(ctx, admin, &BulkRequest{
+		Group: "apps", Version: "v1", Resource: "deployments", LabelSelector: "app=demo", Operation: BulkDelete,
+	}); !errors.IsForbidden(err) {
+		t.Errorf("expected forbidden, got %v", err)
+	}
+
+	result, err = operator.Do(ctx, admin, &BulkRequest{
+		Group: "apps", Version: "v1", Resource: "deployments", Namespace: "default",
+		Items: []BulkObject{{Name: "foo"}, {Name: "bar"}}, Operation: BulkDelete, DryRun: true,
+	})
+	if err != nil || result.Succeeded != 2 {
+		t.Fatalf("expected the dry run to succeed, got %+v, %v", result, err)
+	}
+	if err = fakeClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "foo"}, &appsv1.Deployment{}); err != nil {
+		t.Errorf("expected the deployment not to be deleted by the dry run, got %v", err)
+	}
+
+	if _, err = operator.Do(ctx, admin, &BulkRequest{
+		Group: "apps", Version: "v1", Resource: "deployments", Namespace: "default", LabelSelector: "app=demo", Operation: "label",
+	}); !errors.IsBadRequest(err) {
+		t.Errorf("expected bad request of unsupported operations, got %v", err)
+	}
+}
+
+func TestBulkOperatorClusterScoped(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRESTMapper(mapper).WithObjects(
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "admin"}},
+	).Build()
+	// the user is a namespace admin of the default namespace
+	allowDefault := authorizer.AuthorizerFunc(func(a authorizer.Attributes) (authorizer.Decision, string, error) {
+		if a.GetNamespace() == "default" {
+			return authorizer.DecisionAllow, "", nil
+		}
+		return authorizer.DecisionDeny, "forbidden", nil
+	})
+	operator := NewBulkOperator(New(fakeClient, &fakeCache{reader: fakeClient}), allowDefault)
+	ctx := context.Background()
+
+	// the namespace of the cluster scoped resources must not be used to authorize them
+	result, err := operator.Do(ctx, &user.DefaultInfo{Name: "ns-admin"}, &BulkRequest{
+		Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles",
+		Items: []BulkObject{{Namespace: "default", Name: "admin"}}, Operation: BulkDelete,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Items[0].Status != BulkItemForbidden || result.Items[0].Namespace != "" {
+		t.Errorf("expected the cluster role to be forbidden in the cluster scope, got %+v", result.Items[0])
+	}
+	if err = fakeClient.Get(ctx, client.ObjectKey{Name: "admin"}, &rbacv1.ClusterRole{}); err != nil {
+		t.Errorf("expected the cluster role not to be deleted, got %v", err)
+	}
+
+	if _, err = operator.Do(ctx, &user.DefaultInfo{Name: "ns-admin"}, &BulkRequest{
+		Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles", Namespace: "default",
+		LabelSelector: "app=demo", Operation: BulkDelete,
+	}); !errors.IsForbidden(err) {
+		t.Errorf("expected listing the cluster roles to be forbidden, got %v", err)
+	}
+}
+
+func TestBulkOperatorWithScopedToken(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRESTMapper(mapper).WithObjects(
+		newBulkDeployment("default", "foo"),
+	).Build()
+	namespaceLister := informers.NewSharedInformerFactory(k8sfake.NewSimpleClientset(), 0).Core().V1().Namespaces().Lister()
+	// the scopes are enforced on each resource, the same as the authorization filter
+	operator := NewBulkOperator(New(fakeClient, &fakeCache{reader: fakeClient}),
+		union.New(scope.NewAuthorizer(namespaceLister), authorizerfactory.NewAlwaysAllowAuthorizer()))
+	pat := &user.DefaultInfo{Name: "admin", Extra: map[string][]string{
+		token.ExtraTokenType:   {string(token.PersonalAccessToken)},
+		token.ExtraTokenScopes: {scope.APIGroupPrefix + "resources.kubesphere.io"},
+	}}
+
+	result, err := operator.Do(context.Background(), pat, &BulkRequest{
+		Group: "apps", Version: "v1", Resource: "deployments", Namespace: "default",
+		Items: []BulkObject{{Name: "foo"}}, Operation: BulkDelete,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Items[0].Status != BulkItemForbidden {
+		t.Errorf("expected the deployments beyond the scopes of the token to be forbidden, got %+v", result.Items[0])
+	}
+	if err = fakeClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "foo"}, &appsv1.Deployment{}); err != nil {
+		t.Errorf("expected the deployment not to be deleted, got %v", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 type ResourceManager interface {
 	IsServed(schema.GroupVersionResource) (bool, error)
+	IsNamespaced(schema.GroupVersionResource) (bool, error)
 	CreateObjectFromRawData(gvr schema.GroupVersionResource, rawData []byte) (client.Object, error)
 
 	CreateResource(ctx context.Context, object client.Object) error
@@ -33,9 +34,9 @@ type ResourceManager interface {
 	Get(ctx context.Context, namespace, name string, object client.Object) error
 	List(ctx context.Context, namespace string, query *query.Query, object client.ObjectList) error
 	Create(ctx context.Context, object client.Object) error
-	Delete(ctx context.Context, object client.Object) error
+	Delete(ctx context.Context, object client.Object, opts ...client.DeleteOption) error
 	Update(ctx context.Context, old, new client.Object) error
-	Patch(ctx context.Context, old, new client.Object) error
+	Patch(ctx context.Context, old, new client.Object, opts ...client.PatchOption) error
 }
 
 // CompareFunc return true is left greater than right
@@ -172,6 +172,19 @@ func (h *resourceManager) getGVK(gvr schema.GroupVersionResource) (schema.GroupV
 	return gvk, nil
 }
 
+// IsNamespaced returns whether the resource is namespace scoped, according to the RESTMapper.
+func (h *resourceManager) IsNamespaced(gvr schema.GroupVersionResource) (bool, error) {
+	gvk, err := h.getGVK(gvr)
+	if err != nil {
+		return false, err
+	}
+	mapping, err := h.client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, err
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
 func (h *resourceManager) IsServed(gvr schema.GroupVersionResource) (bool, error) {
 	// well-known group version is already registered
 	if h.client.Scheme().IsVersionRegistered(gvr.GroupVersion()) {
@@ -225,8 +238,8 @@ func (h *resourceManager) Create(ctx context.Context, object client.Object) erro
 	return h.client.Create(ctx, object)
 }
 
-func (h *resourceManager) Delete(ctx context.Context, object client.Object) error {
-	return h.client.Delete(ctx, object)
+func (h *resourceManager) Delete(ctx context.Context, object client.Object, opts ...client.DeleteOption) error {
+	return h.client.Delete(ctx, object, opts...)
 }
 
 func (h *resourceManager) Update(ctx context.Context, old, new client.Object) error {
@@ -234,8 +247,8 @@ func (h *resourceManager) Update(ctx context.Context, old, new client.Object) er
 	return h.client.Update(ctx, new)
 }
 
-func (h *resourceManager) Patch(ctx context.Context, old, new client.Object) error {
-	return h.client.Patch(ctx, new, client.MergeFrom(old))
+func (h *resourceManager) Patch(ctx context.Context, old, new client.Object, opts ...client.PatchOption) error {
+	return h.client.Patch(ctx, new, client.MergeFrom(old), opts...)
 }
 
 func compare(left, right runtime.Object, field query.Field) bool {
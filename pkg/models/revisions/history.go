@@ -0,0 +1,369 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revisions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pmezard/go-difflib/difflib"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	KindDeployment  = "deployments"
+	KindStatefulSet = "statefulsets"
+	KindDaemonSet   = "daemonsets"
+
+	ChangeCauseAnnotation        = "kubernetes.io/change-cause"
+	DeploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+	// RollbackByAnnotation records who rolled back the workload, and RollbackRevisionAnnotation the target revision
+	RollbackByAnnotation       = "kubesphere.io/rollback-by"
+	RollbackRevisionAnnotation = "kubesphere.io/rollback-revision"
+)
+
+// Revision is a revision of a Deployment, StatefulSet or DaemonSet
+type Revision struct {
+	Revision int64 `json:"revision"`
+	// Name of the ReplicaSet or ControllerRevision which keeps the revision
+	Name              string      `json:"name"`
+	ChangeCause       string      `json:"changeCause,omitempty"`
+	Images            []string    `json:"images"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+	// Current is true for the latest revision, which the workload is running or rolling out
+	Current bool `json:"current"`
+}
+
+// RevisionDiff is the pod template diff between two revisions
+type RevisionDiff struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// unified diff of the pod templates in YAML, empty if they are the same
+	Diff string `json:"diff"`
+}
+
+type RevisionOperator interface {
+	// ListRevisions lists the revisions of the workload, the latest revision first
+	ListRevisions(kind, namespace, name string) ([]Revision, error)
+	// DiffRevisions compares the pod templates of two revisions, to is the current revision if it is 0
+	DiffRevisions(kind, namespace, name string, from, to int64) (*RevisionDiff, error)
+	// Rollback applies the pod template of the revision to the workload, and records the user in the annotations
+	Rollback(kind, namespace, name string, revision int64, user string) error
+}
+
+type revisionOperator struct {
+	client    kubernetes.Interface
+	informers informers.SharedInformerFactory
+}
+
+func NewRevisionOperator(client kubernetes.Interface, informers informers.SharedInformerFactory) RevisionOperator {
+	return &revisionOperator{client: client, informers: informers}
+}
+
+// workloadRevision is a revision with its pod template
+type workloadRevision struct {
+	Revision
+	template *corev1.PodTemplateSpec
+	// the ControllerRevision of StatefulSets and DaemonSets
+	controllerRevision *appsv1.ControllerRevision
+}
+
+func (o *revisionOperator) ListRevisions(kind, namespace, name string) ([]Revision, error) {
+	history, err := o.history(kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]Revision, 0, len(history))
+	for _, r := range history {
+		revisions = append(revisions, r.Revision)
+	}
+	return revisions, nil
+}
+
+func (o *revisionOperator) DiffRevisions(kind, namespace, name string, from, to int64) (*RevisionDiff, error) {
+	history, err := o.history(kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if to == 0 && len(history) > 0 {
+		to = history[0].Revision.Revision
+	}
+	fromRevision, err := findRevision(kind, name, history, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := findRevision(kind, name, history, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fromData, err := yaml.Marshal(fromRevision.template)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := yaml.Marshal(toRevision.template)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(fromData)),
+		B:        difflib.SplitLines(string(toData)),
+		FromFile: fmt.Sprintf("revision %d", from),
+		ToFile:   fmt.Sprintf("revision %d", to),
+		Context:  3,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RevisionDiff{From: from, To: to, Diff: diff}, nil
+}
+
+func (o *revisionOperator) Rollback(kind, namespace, name string, revision int64, user string) error {
+	history, err := o.history(kind, namespace, name)
+	if err != nil {
+		return err
+	}
+	target, err := findRevision(kind, name, history, revision)
+	if err != nil {
+		return err
+	}
+	if target.Current {
+		return errors.NewBadRequest(fmt.Sprintf("revision %d is the current revision of %s", revision, name))
+	}
+
+	annotations := map[string]string{
+		RollbackByAnnotation:       user,
+		RollbackRevisionAnnotation: strconv.FormatInt(revision, 10),
+		ChangeCauseAnnotation:      fmt.Sprintf("rollback to revision %d by %s", revision, user),
+	}
+
+	switch kind {
+	case KindDeployment:
+		return o.rollbackDeployment(namespace, name, target.template, annotations)
+	default:
+		return o.rollbackControllerRevision(kind, namespace, name, target.controllerRevision, annotations)
+	}
+}
+
+// rollbackDeployment replaces the pod template of the deployment like kubectl rollout undo,
+// the deployment controller then creates a new revision from the ReplicaSet of the target revision.
+func (o *revisionOperator) rollbackDeployment(namespace, name string, template *corev1.PodTemplateSpec, annotations map[string]string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		deploy, err := o.client.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		deploy = deploy.DeepCopy()
+		deploy.Spec.Template = *template.DeepCopy()
+		if deploy.Annotations == nil {
+			deploy.Annotations = make(map[string]string)
+		}
+		for k, v := range annotations {
+			deploy.Annotations[k] = v
+		}
+		_, err = o.client.AppsV1().Deployments(namespace).Update(context.Background(), deploy, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// rollbackControllerRevision applies the patch kept in the ControllerRevision, which replaces the pod template
+func (o *revisionOperator) rollbackControllerRevision(kind, namespace, name string, revision *appsv1.ControllerRevision, annotations map[string]string) error {
+	raw, err := controllerRevisionData(revision)
+	if err != nil {
+		return err
+	}
+	patch := make(map[string]interface{})
+	if err = json.Unmarshal(raw, &patch); err != nil {
+		return err
+	}
+	patch["metadata"] = map[string]interface{}{"annotations": annotations}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case KindStatefulSet:
+		_, err = o.client.AppsV1().StatefulSets(namespace).Patch(context.Background(), name, types.StrategicMergePatchType, data, metav1.PatchOptions{})
+	case KindDaemonSet:
+		_, err = o.client.AppsV1().DaemonSets(namespace).Patch(context.Background(), name, types.StrategicMergePatchType, data, metav1.PatchOptions{})
+	}
+	return err
+}
+
+// history returns the revisions of the workload sorted by revision number, the latest first
+func (o *revisionOperator) history(kind, namespace, name string) ([]*workloadRevision, error) {
+	var history []*workloadRevision
+	var err error
+	switch kind {
+	case KindDeployment:
+		history, err = o.deploymentHistory(namespace, name)
+	case KindStatefulSet:
+		var sts *appsv1.StatefulSet
+		if sts, err = o.informers.Apps().V1().StatefulSets().Lister().StatefulSets(namespace).Get(name); err == nil {
+			history, err = o.controllerRevisionHistory(sts, sts.Spec.Selector)
+		}
+	case KindDaemonSet:
+		var ds *appsv1.DaemonSet
+		if ds, err = o.informers.Apps().V1().DaemonSets().Lister().DaemonSets(namespace).Get(name); err == nil {
+			history, err = o.controllerRevisionHistory(ds, ds.Spec.Selector)
+		}
+	default:
+		return nil, errors.NewBadRequest(fmt.Sprintf("unsupported workload kind %s", kind))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Revision.Revision > history[j].Revision.Revision
+	})
+	if len(history) > 0 {
+		history[0].Current = true
+	}
+	return history, nil
+}
+
+func (o *revisionOperator) deploymentHistory(namespace, name string) ([]*workloadRevision, error) {
+	deploy, err := o.informers.Apps().V1().Deployments().Lister().Deployments(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	replicaSets, err := o.informers.Apps().V1().ReplicaSets().Lister().ReplicaSets(namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []*workloadRevision
+	for _, rs := range replicaSets {
+		if !metav1.IsControlledBy(rs, deploy) {
+			continue
+		}
+		revision, err := strconv.ParseInt(rs.Annotations[DeploymentRevisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		template := rs.Spec.Template.DeepCopy()
+		// the hash label is added by the deployment controller
+		delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+		history = append(history, newWorkloadRevision(revision, &rs.ObjectMeta, template))
+	}
+	return history, nil
+}
+
+func (o *revisionOperator) controllerRevisionHistory(owner metav1.Object, labelSelector *metav1.LabelSelector) ([]*workloadRevision, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	if selector.Empty() {
+		selector = labels.Nothing()
+	}
+	controllerRevisions, err := o.informers.Apps().V1().ControllerRevisions().Lister().ControllerRevisions(owner.GetNamespace()).List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []*workloadRevision
+	for _, cr := range controllerRevisions {
+		if !metav1.IsControlledBy(cr, owner) {
+			continue
+		}
+		template, err := controllerRevisionTemplate(cr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid controller revision %s: %v", cr.Name, err)
+		}
+		r := newWorkloadRevision(cr.Revision, &cr.ObjectMeta, template)
+		r.controllerRevision = cr
+		history = append(history, r)
+	}
+	return history, nil
+}
+
+// controllerRevisionTemplate decodes the pod template from the data of the ControllerRevision, which is
+// a strategic merge patch like {"spec":{"template":{...,"$patch":"replace"}}}
+func controllerRevisionTemplate(cr *appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	data, err := controllerRevisionData(cr)
+	if err != nil {
+		return nil, err
+	}
+	patch := struct {
+		Spec struct {
+			Template map[string]interface{} `json:"template"`
+		} `json:"spec"`
+	}{}
+	if err = json.Unmarshal(data, &patch); err != nil {
+		return nil, err
+	}
+	delete(patch.Spec.Template, "$patch")
+
+	template := &corev1.PodTemplateSpec{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(patch.Spec.Template, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func controllerRevisionData(cr *appsv1.ControllerRevision) ([]byte, error) {
+	if len(cr.Data.Raw) == 0 && cr.Data.Object != nil {
+		return json.Marshal(cr.Data.Object)
+	}
+	return cr.Data.Raw, nil
+}
+
+func newWorkloadRevision(revision int64, meta *metav1.ObjectMeta, template *corev1.PodTemplateSpec) *workloadRevision {
+	images := make([]string, 0, len(template.Spec.Containers))
+	for _, c := range template.Spec.Containers {
+		images = append(images, c.Image)
+	}
+	return &workloadRevision{
+		Revision: Revision{
+			Revision:          revision,
+			Name:              meta.Name,
+			ChangeCause:       meta.Annotations[ChangeCauseAnnotation],
+			Images:            images,
+			CreationTimestamp: meta.CreationTimestamp,
+		},
+		template: template,
+	}
+}
+
+func findRevision(kind, name string, history []*workloadRevision, revision int64) (*workloadRevision, error) {
+	for _, r := range history {
+		if r.Revision.Revision == revision {
+			return r, nil
+		}
+	}
+	return nil, errors.NewNotFound(appsv1.Resource(kind), fmt.Sprintf("%s#%d", name, revision))
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revisions
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var podLabels = map[string]string{"app": "nginx"}
+
+func podTemplate(image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "nginx", Image: image}},
+		},
+	}
+}
+
+func controllerRef(owner metav1.Object, kind string) []metav1.OwnerReference {
+	return []metav1.OwnerReference{*metav1.NewControllerRef(owner, appsv1.SchemeGroupVersion.WithKind(kind))}
+}
+
+func newReplicaSet(deploy *appsv1.Deployment, name, revision, image, changeCause string) *appsv1.ReplicaSet {
+	template := podTemplate(image)
+	template.Labels = map[string]string{"app": "nginx", appsv1.DefaultDeploymentUniqueLabelKey: name}
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			Labels:          template.Labels,
+			OwnerReferences: controllerRef(deploy, "Deployment"),
+			Annotations: map[string]string{
+				DeploymentRevisionAnnotation: revision,
+				ChangeCauseAnnotation:        changeCause,
+			},
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: template},
+	}
+}
+
+func newControllerRevision(t *testing.T, sts *appsv1.StatefulSet, name string, revision int64, image string) *appsv1.ControllerRevision {
+	template, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+		Spec:       podTemplate(image).Spec,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	template["$patch"] = "replace"
+	data, err := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": template}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			Labels:          podLabels,
+			OwnerReferences: controllerRef(sts, "StatefulSet"),
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: revision,
+	}
+}
+
+func newOperator(t *testing.T, objects ...runtime.Object) (*revisionOperator, *fake.Clientset) {
+	client := fake.NewSimpleClientset(objects...)
+	factory := informers.NewSharedInformerFactory(client, 0)
+	for _, obj := range objects {
+		var err error
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			err = factory.Apps().V1().Deployments().Informer().GetIndexer().Add(o)
+		case *appsv1.ReplicaSet:
+			err = factory.Apps().V1().ReplicaSets().Informer().GetIndexer().Add(o)
+		case *appsv1.StatefulSet:
+			err = factory.Apps().V1().StatefulSets().Informer().GetIndexer().Add(o)
+		case *appsv1.ControllerRevision:
+			err = factory.Apps().V1().ControllerRevisions().Informer().GetIndexer().Add(o)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &revisionOperator{client: client, informers: factory}, client
+}
+
+func TestDeploymentRevisions(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", UID: types.UID("nginx")},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: podTemplate("nginx:1.23"),
+		},
+	}
+	other := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", UID: types.UID("other")}}
+
+	operator, client := newOperator(t, deploy,
+		newReplicaSet(deploy, "nginx-1", "1", "nginx:1.21", "create"),
+		newReplicaSet(deploy, "nginx-2", "2", "nginx:1.22", "upgrade to 1.22"),
+		newReplicaSet(deploy, "nginx-3", "3", "nginx:1.23", "upgrade to 1.23"),
+		newReplicaSet(other, "other-1", "1", "nginx:1.20", ""))
+
+	revisions, err := operator.ListRevisions(KindDeployment, "default", "nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 3 || revisions[0].Revision != 3 || !revisions[0].Current || revisions[2].Current ||
+		revisions[1].ChangeCause != "upgrade to 1.22" || revisions[1].Images[0] != "nginx:1.22" {
+		t.Errorf("unexpected revisions %+v", revisions)
+	}
+
+	diff, err := operator.DiffRevisions(KindDeployment, "default", "nginx", 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.To != 3 || !strings.Contains(diff.Diff, "-  - image: nginx:1.21") || !strings.Contains(diff.Diff, "+  - image: nginx:1.23") ||
+		strings.Contains(diff.Diff, appsv1.DefaultDeploymentUniqueLabelKey) {
+		t.Errorf("unexpected diff %s", diff.Diff)
+	}
+
+	if _, err = operator.DiffRevisions(KindDeployment, "default", "nginx", 4, 0); !errors.IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if err = operator.Rollback(KindDeployment, "default", "nginx", 3, "admin"); !errors.IsBadRequest(err) {
+		t.Errorf("expected bad request of rolling back to the current revision, got %v", err)
+	}
+
+	if err = operator.Rollback(KindDeployment, "default", "nginx", 1, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := client.AppsV1().Deployments("default").Get(context.Background(), "nginx", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image := updated.Spec.Template.Spec.Containers[0].Image; image != "nginx:1.21" {
+		t.Errorf("unexpected image %s", image)
+	}
+	if _, ok := updated.Spec.Template.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok {
+		t.Error("unexpected pod template hash label")
+	}
+	if updated.Annotations[RollbackByAnnotation] != "admin" || updated.Annotations[RollbackRevisionAnnotation] != "1" {
+		t.Errorf("unexpected annotations %v", updated.Annotations)
+	}
+}
+
+func TestStatefulSetRevisions(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default", UID: types.UID("nginx")},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: podTemplate("nginx:1.23"),
+		},
+	}
+
+	operator, client := newOperator(t, sts,
+		newControllerRevision(t, sts, "nginx-a", 1, "nginx:1.22"),
+		newControllerRevision(t, sts, "nginx-b", 2, "nginx:1.23"))
+
+	revisions, err := operator.ListRevisions(KindStatefulSet, "default", "nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 || revisions[0].Name != "nginx-b" || revisions[1].Images[0] != "nginx:1.22" {
+		t.Errorf("unexpected revisions %+v", revisions)
+	}
+
+	if err = operator.Rollback(KindStatefulSet, "default", "nginx", 1, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := client.AppsV1().StatefulSets("default").Get(context.Background(), "nginx", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image := updated.Spec.Template.Spec.Containers[0].Image; image != "nginx:1.22" {
+		t.Errorf("unexpected image %s", image)
+	}
+	if updated.Annotations[RollbackByAnnotation] != "admin" {
+		t.Errorf("unexpected annotations %v", updated.Annotations)
+	}
+
+	if _, err = operator.ListRevisions("jobs", "default", "nginx"); !errors.IsBadRequest(err) {
+		t.Errorf("expected bad request of unsupported kind, got %v", err)
+	}
+}
@@ -28,11 +28,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
@@ -88,6 +90,9 @@ type Interface interface {
 	PatchWorkspaceTemplate(user user.Info, workspace string, data json.RawMessage) (*tenantv1alpha2.WorkspaceTemplate, error)
 	DescribeWorkspaceTemplate(workspace string) (*tenantv1alpha2.WorkspaceTemplate, error)
 	ListNamespaces(user user.Info, workspace string, query *query.Query) (*api.ListResult, error)
+	WatchWorkspaces(user user.Info, query *query.Query) (watch.Interface, error)
+	WatchWorkspaceTemplates(user user.Info, query *query.Query) (watch.Interface, error)
+	WatchNamespaces(user user.Info, workspace string, query *query.Query) (watch.Interface, error)
 	ListDevOpsProjects(user user.Info, workspace string, query *query.Query) (*api.ListResult, error)
 	ListFederatedNamespaces(info user.Info, workspace string, param *query.Query) (*api.ListResult, error)
 	CreateNamespace(workspace string, namespace *corev1.Namespace) (*corev1.Namespace, error)
@@ -346,6 +351,83 @@ func (t *tenantOperator) ListFederatedNamespaces(user user.Info, workspace strin
 	return result, nil
 }
 
+// WatchWorkspaces watches the workspaces listed by ListWorkspaces.
+func (t *tenantOperator) WatchWorkspaces(user user.Info, queryParam *query.Query) (watch.Interface, error) {
+	listWS := authorizer.AttributesRecord{
+		User:            user,
+		Verb:            "list",
+		APIGroup:        "*",
+		Resource:        "workspaces",
+		ResourceRequest: true,
+		ResourceScope:   request.GlobalScope,
+	}
+	return t.watch(tenantv1alpha1.ResourcePluralWorkspace, listWS, queryParam, labels.Everything(), func() (*api.ListResult, error) {
+		return t.ListWorkspaces(user, queryParam)
+	})
+}
+
+// WatchWorkspaceTemplates watches the workspace templates listed by ListWorkspaceTemplates.
+func (t *tenantOperator) WatchWorkspaceTemplates(user user.Info, queryParam *query.Query) (watch.Interface, error) {
+	listWS := authorizer.AttributesRecord{
+		User:            user,
+		Verb:            "list",
+		APIGroup:        "*",
+		Resource:        "workspaces",
+		ResourceRequest: true,
+		ResourceScope:   request.GlobalScope,
+	}
+	return t.watch(tenantv1alpha2.ResourcePluralWorkspaceTemplate, listWS, queryParam, labels.Everything(), func() (*api.ListResult, error) {
+		return t.ListWorkspaceTemplates(user, queryParam)
+	})
+}
+
+// WatchNamespaces watches the namespaces listed by ListNamespaces.
+func (t *tenantOperator) WatchNamespaces(user user.Info, workspace string, queryParam *query.Query) (watch.Interface, error) {
+	nsScope := request.ClusterScope
+	selector := labels.Everything()
+	if workspace != "" {
+		nsScope = request.WorkspaceScope
+		selector = labels.SelectorFromSet(labels.Set{tenantv1alpha1.WorkspaceLabel: workspace})
+	}
+	listNS := authorizer.AttributesRecord{
+		User:            user,
+		Verb:            "list",
+		Workspace:       workspace,
+		Resource:        "namespaces",
+		ResourceRequest: true,
+		ResourceScope:   nsScope,
+	}
+	return t.watch("namespaces", listNS, queryParam, selector, func() (*api.ListResult, error) {
+		return t.ListNamespaces(user, workspace, queryParam)
+	})
+}
+
+// watch watches the resource listed by list, the permissions are evaluated each time the resource is listed,
+// and the changes in between are sent one by one if the resource getter matches the objects of the query.
+// The watch is scoped by the role bindings of the user unless the user is allowed to list the resource in the whole scope,
+// and the selector limits the deletions sent on resume to the scope.
+func (t *tenantOperator) watch(resource string, listAttributes authorizer.AttributesRecord, queryParam *query.Query,
+	selector labels.Selector, list resources.ListFunc) (watch.Interface, error) {
+	decision, _, err := t.authorizer.Authorize(listAttributes)
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	queryParam.Pagination = query.NoPagination
+	options := resources.WatchOptions{
+		ResourceVersion: queryParam.ResourceVersion,
+		LabelSelector:   selector,
+		Scoped:          decision != authorizer.DecisionAllow,
+	}
+	if match := t.resourceGetter.MatchFunc(resource, queryParam); match != nil {
+		options.Match = func(object runtime.Object) bool {
+			accessor, err := meta.Accessor(object)
+			return err == nil && selector.Matches(labels.Set(accessor.GetLabels())) && match(object)
+		}
+	}
+	return t.resourceGetter.WatchList(resource, list, options)
+}
+
 func (t *tenantOperator) ListNamespaces(user user.Info, workspace string, queryParam *query.Query) (*api.ListResult, error) {
 	nsScope := request.ClusterScope
 	if workspace != "" {
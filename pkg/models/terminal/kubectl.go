@@ -0,0 +1,263 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/websocket"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+const (
+	// KubectlSessionLabel is set on the pods and the secrets of the kubectl sessions.
+	KubectlSessionLabel = "terminal.kubesphere.io/kubectl-session"
+	// KubectlClientID is the audience of the tokens issued to the kubectl sessions.
+	KubectlClientID = "kubectl"
+
+	kubectlContainerName = "kubectl"
+	kubeconfigVolumeName = "kubeconfig"
+	kubeconfigMountPath  = "/etc/kubesphere/kubectl"
+	kubeconfigKey        = "config"
+	// the same as the cluster name of the kubeconfig of the users
+	kubeconfigClusterName = "local"
+	kubectlStartupTimeout = time.Minute
+)
+
+var (
+	ErrKubectlSessionDisabled    = errors.New("kubectl sessions are not enabled")
+	ErrKubectlSessionScopedToken = errors.New("kubectl sessions are not allowed with personal access tokens")
+)
+
+// HandleKubectlSession starts an ephemeral kubectl pod with the kubeconfig of the user, and connects the terminal to it.
+// The pod, the kubeconfig and the token in it are cleaned up once the terminal is closed.
+func (t *terminaler) HandleKubectlSession(u user.Info, conn *websocket.Conn) {
+	session := &TerminalSession{conn: conn, sizeChan: make(chan remotecommand.TerminalSize)}
+
+	pod, cleanup, err := t.startKubectlSession(u)
+	if err != nil {
+		klog.Warningf("failed to start the kubectl session of user %s: %v", u.GetName(), err)
+		session.Toast(err.Error())
+		session.Close(2, err.Error())
+		return
+	}
+	defer cleanup()
+
+	t.HandleSession("", pod.Namespace, pod.Name, kubectlContainerName, conn)
+}
+
+// startKubectlSession creates the pod of the session and waits until it is running,
+// the cleanup func deletes the pod and revokes the token of the session.
+func (t *terminaler) startKubectlSession(u user.Info) (*v1.Pod, func(), error) {
+	options := t.options.Kubectl
+	if options == nil || !options.Enable || t.tokenOperator == nil {
+		return nil, nil, ErrKubectlSessionDisabled
+	}
+	// the session gets an unscoped token, which must not be issued to the callers with scoped tokens
+	if len(u.GetExtra()[token.ExtraTokenType]) > 0 {
+		return nil, nil, ErrKubectlSessionScopedToken
+	}
+	if err := t.pruneKubectlSessions(u.GetName()); err != nil {
+		return nil, nil, err
+	}
+
+	accessToken, err := t.tokenOperator.IssueTo(&token.IssueRequest{
+		User: u,
+		Claims: token.Claims{
+			TokenType:        token.AccessToken,
+			RegisteredClaims: jwt.RegisteredClaims{Audience: jwt.ClaimStrings{KubectlClientID}},
+		},
+		ExpiresIn: options.MaxAge,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	kubeconfig, err := newKubeconfig(options.Server, u.GetName(), accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx := context.Background()
+	pod, err := t.client.CoreV1().Pods(options.Namespace).Create(ctx, newKubectlPod(options, u.GetName()), metav1.CreateOptions{})
+	if err != nil {
+		t.revokeToken(accessToken)
+		return nil, nil, err
+	}
+	namespace, name := pod.Namespace, pod.Name
+	cleanup := func() {
+		// the secret of the kubeconfig is owned by the pod
+		propagation := metav1.DeletePropagationBackground
+		if err := t.client.CoreV1().Pods(namespace).Delete(context.Background(), name,
+			metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil {
+			klog.Warningf("failed to delete kubectl session pod %s: %v", name, err)
+		}
+		t.revokeToken(accessToken)
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pod.Name,
+			Labels:          pod.Labels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(pod, v1.SchemeGroupVersion.WithKind("Pod"))},
+		},
+		Data: map[string][]byte{kubeconfigKey: kubeconfig},
+	}
+	if _, err = t.client.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	err = wait.PollImmediate(500*time.Millisecond, kubectlStartupTimeout, func() (bool, error) {
+		current, err := t.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		switch current.Status.Phase {
+		case v1.PodRunning:
+			pod = current
+			return true, nil
+		case v1.PodFailed, v1.PodSucceeded:
+			return false, fmt.Errorf("kubectl session pod %s is %s", name, current.Status.Phase)
+		}
+		return false, nil
+	})
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return pod, cleanup, nil
+}
+
+// pruneKubectlSessions deletes the terminated sessions of the user, which are left behind if ks-apiserver
+// restarts during the sessions, and checks the number of the sessions of the user.
+func (t *terminaler) pruneKubectlSessions(username string) error {
+	options := t.options.Kubectl
+	selector := labels.SelectorFromSet(labels.Set{KubectlSessionLabel: "true", constants.UsernameLabelKey: username})
+	pods, err := t.client.CoreV1().Pods(options.Namespace).List(context.Background(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+	sessions := 0
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed {
+			sessions++
+			continue
+		}
+		if err = t.client.CoreV1().Pods(pod.Namespace).Delete(context.Background(), pod.Name, metav1.DeleteOptions{}); err != nil {
+			klog.Warningf("failed to delete kubectl session pod %s: %v", pod.Name, err)
+		}
+	}
+	if sessions >= options.MaxSessionsPerUser {
+		return fmt.Errorf("user %s has too many kubectl sessions, at most %d are allowed", username, options.MaxSessionsPerUser)
+	}
+	return nil
+}
+
+func (t *terminaler) revokeToken(accessToken string) {
+	if err := t.tokenOperator.Revoke(accessToken); err != nil {
+		klog.Warningf("failed to revoke the token of kubectl session: %v", err)
+	}
+}
+
+// newKubeconfig creates the kubeconfig of the user to access the cluster through ks-apiserver with the token.
+func newKubeconfig(server, username, accessToken string) ([]byte, error) {
+	config := clientcmdapi.NewConfig()
+	config.Clusters[kubeconfigClusterName] = &clientcmdapi.Cluster{Server: server}
+	config.AuthInfos[username] = &clientcmdapi.AuthInfo{Token: accessToken}
+	config.Contexts[kubeconfigClusterName] = &clientcmdapi.Context{
+		Cluster:  kubeconfigClusterName,
+		AuthInfo: username,
+	}
+	config.CurrentContext = kubeconfigClusterName
+	return clientcmd.Write(*config)
+}
+
+// newKubectlPod creates the pod of a kubectl session, which has no service account token, no capabilities
+// and no access to the host, and is terminated once the token of the session expires.
+func newKubectlPod(options *KubectlOptions, username string) *v1.Pod {
+	name := fmt.Sprintf("kubectl-session-%s", rand.String(8))
+	deadline := int64(options.MaxAge.Seconds())
+	automountServiceAccountToken := false
+	enableServiceLinks := false
+	allowPrivilegeEscalation := false
+	limits := v1.ResourceList{}
+	if cpu, err := resource.ParseQuantity(options.CPU); err == nil {
+		limits[v1.ResourceCPU] = cpu
+	}
+	if memory, err := resource.ParseQuantity(options.Memory); err == nil {
+		limits[v1.ResourceMemory] = memory
+	}
+
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: options.Namespace,
+			Labels: map[string]string{
+				KubectlSessionLabel:        "true",
+				constants.UsernameLabelKey: username,
+			},
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy:                v1.RestartPolicyNever,
+			ActiveDeadlineSeconds:        &deadline,
+			AutomountServiceAccountToken: &automountServiceAccountToken,
+			EnableServiceLinks:           &enableServiceLinks,
+			Containers: []v1.Container{
+				{
+					Name:  kubectlContainerName,
+					Image: options.Image,
+					Args:  []string{"sleep", strconv.FormatInt(deadline, 10)},
+					Env: []v1.EnvVar{
+						{Name: "KUBECONFIG", Value: kubeconfigMountPath + "/" + kubeconfigKey},
+					},
+					Resources: v1.ResourceRequirements{Limits: limits},
+					SecurityContext: &v1.SecurityContext{
+						AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+						Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+					},
+					VolumeMounts: []v1.VolumeMount{
+						{Name: kubeconfigVolumeName, MountPath: kubeconfigMountPath, ReadOnly: true},
+					},
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name:         kubeconfigVolumeName,
+					VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: name}},
+				},
+			},
+		},
+	}
+}
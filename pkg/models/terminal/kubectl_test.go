@@ -0,0 +1,114 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminal
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/authentication/token"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/models/auth"
+)
+
+func newSessionPod(name, username string, phase v1.PodPhase) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: constants.KubeSphereControlNamespace,
+			Labels:    map[string]string{KubectlSessionLabel: "true", constants.UsernameLabelKey: username},
+		},
+		Status: v1.PodStatus{Phase: phase},
+	}
+}
+
+func TestPruneKubectlSessions(t *testing.T) {
+	options := NewTerminalOptions()
+	options.Kubectl.Enable = true
+	options.Kubectl.MaxSessionsPerUser = 2
+	client := fake.NewSimpleClientset(
+		newSessionPod("running", "admin", v1.PodRunning),
+		newSessionPod("failed", "admin", v1.PodFailed),
+		newSessionPod("other", "alice", v1.PodRunning),
+	)
+	terminaler := &terminaler{client: client, options: options}
+
+	if err := terminaler.pruneKubectlSessions("admin"); err != nil {
+		t.Fatalf("expected a session to be allowed, got %v", err)
+	}
+	pods, _ := client.CoreV1().Pods(constants.KubeSphereControlNamespace).List(context.Background(), metav1.ListOptions{})
+	if len(pods.Items) != 2 {
+		t.Errorf("expected the terminated session to be deleted, got %d pods", len(pods.Items))
+	}
+
+	client.CoreV1().Pods(constants.KubeSphereControlNamespace).Create(context.Background(),
+		newSessionPod("pending", "admin", v1.PodPending), metav1.CreateOptions{})
+	if err := terminaler.pruneKubectlSessions("admin"); err == nil {
+		t.Errorf("expected too many sessions of the user")
+	}
+}
+
+func TestStartKubectlSessionWithScopedToken(t *testing.T) {
+	options := NewTerminalOptions()
+	options.Kubectl.Enable = true
+	client := fake.NewSimpleClientset()
+	terminaler := &terminaler{client: client, options: options, tokenOperator: auth.NewTokenOperator(nil, nil, nil)}
+
+	u := &user.DefaultInfo{Name: "admin", Extra: map[string][]string{token.ExtraTokenType: {string(token.PersonalAccessToken)}}}
+	if _, _, err := terminaler.startKubectlSession(u); err != ErrKubectlSessionScopedToken {
+		t.Errorf("expected the personal access tokens not to start kubectl sessions, got %v", err)
+	}
+	pods, _ := client.CoreV1().Pods(constants.KubeSphereControlNamespace).List(context.Background(), metav1.ListOptions{})
+	if len(pods.Items) != 0 {
+		t.Errorf("expected no kubectl session pods, got %d", len(pods.Items))
+	}
+}
+
+func TestNewKubectlPod(t *testing.T) {
+	options := NewTerminalOptions().Kubectl
+	options.Image = "kubesphere/kubectl:v1.0.0"
+	pod := newKubectlPod(options, "admin")
+
+	if *pod.Spec.AutomountServiceAccountToken || *pod.Spec.ActiveDeadlineSeconds != 7200 {
+		t.Errorf("expected no service account token and a deadline of the max age, got %+v", pod.Spec)
+	}
+	container := pod.Spec.Containers[0]
+	if container.Resources.Limits.Cpu().String() != "500m" || container.Resources.Limits.Memory().String() != "256Mi" {
+		t.Errorf("unexpected limits %v", container.Resources.Limits)
+	}
+	if *container.SecurityContext.AllowPrivilegeEscalation || pod.Spec.Volumes[0].Secret.SecretName != pod.Name {
+		t.Errorf("unexpected pod %+v", pod.Spec)
+	}
+
+	kubeconfig, err := newKubeconfig(options.Server, "admin", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.AuthInfos["admin"].Token != "token" || config.Clusters[config.Contexts[config.CurrentContext].Cluster].Server != options.Server {
+		t.Errorf("unexpected kubeconfig %s", kubeconfig)
+	}
+}
@@ -13,22 +13,71 @@
 // limitations under the License.
 package terminal
 
-import "github.com/spf13/pflag"
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+)
 
 type Options struct {
 	Image   string `json:"image,omitempty" yaml:"image,omitempty"`
 	Timeout int    `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Kubectl runs the web kubectl in an ephemeral pod per terminal session,
+	// instead of exec into the long-running kubectl deployment of the user.
+	Kubectl *KubectlOptions `json:"kubectl,omitempty" yaml:"kubectl,omitempty"`
+}
+
+type KubectlOptions struct {
+	Enable bool `json:"enable" yaml:"enable"`
+	// Image of the kubectl session pods, the KubectlImage of the authentication options is used if empty.
+	Image     string `json:"image,omitempty" yaml:"image,omitempty"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Server is the address of ks-apiserver the kubeconfig of the session points to,
+	// so that kubectl is authenticated and authorized as the user.
+	Server string `json:"server,omitempty" yaml:"server,omitempty"`
+	// MaxAge is the lifetime of the token of a session, the session pod is terminated once the token expires.
+	MaxAge time.Duration `json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+	// MaxSessionsPerUser is the max number of the sessions of a user at the same time.
+	MaxSessionsPerUser int `json:"maxSessionsPerUser,omitempty" yaml:"maxSessionsPerUser,omitempty"`
+	// CPU and memory limits of the session pods, e.g. 500m and 256Mi.
+	CPU    string `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
 }
 
 func NewTerminalOptions() *Options {
 	return &Options{
 		Image:   "alpine:3.15",
 		Timeout: 600,
+		Kubectl: &KubectlOptions{
+			Namespace:          constants.KubeSphereControlNamespace,
+			Server:             "http://ks-apiserver.kubesphere-system.svc",
+			MaxAge:             2 * time.Hour,
+			MaxSessionsPerUser: 3,
+			CPU:                "500m",
+			Memory:             "256Mi",
+		},
 	}
 }
 
 func (s *Options) Validate() []error {
 	var errs []error
+	if s.Kubectl != nil && s.Kubectl.Enable {
+		if s.Kubectl.MaxAge < time.Minute {
+			errs = append(errs, fmt.Errorf("the max age of kubectl sessions must be at least 1m"))
+		}
+		if s.Kubectl.MaxSessionsPerUser < 1 {
+			errs = append(errs, fmt.Errorf("the max kubectl sessions per user must be positive"))
+		}
+		for _, quantity := range []string{s.Kubectl.CPU, s.Kubectl.Memory} {
+			if _, err := resource.ParseQuantity(quantity); err != nil {
+				errs = append(errs, fmt.Errorf("invalid limit %q of kubectl sessions: %v", quantity, err))
+			}
+		}
+	}
 	return errs
 }
 
@@ -37,5 +86,11 @@ func (s *Options) ApplyTo(options *Options) {
 }
 
 func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
-
+	if s.Kubectl == nil || c.Kubectl == nil {
+		return
+	}
+	fs.BoolVar(&s.Kubectl.Enable, "kubectl-session", c.Kubectl.Enable, ""+
+		"Run the web kubectl in an ephemeral pod per terminal session with the kubeconfig of the user.")
+	fs.StringVar(&s.Kubectl.Image, "kubectl-session-image", c.Kubectl.Image, ""+
+		"Image of the kubectl session pods, the kubectl image of the authentication options is used if empty.")
 }
@@ -34,11 +34,14 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/klog/v2"
+
+	"kubesphere.io/kubesphere/pkg/models/auth"
 )
 
 const (
@@ -155,12 +158,14 @@ func (t TerminalSession) Close(status uint32, reason string) {
 type Interface interface {
 	HandleSession(shell, namespace, podName, containerName string, conn *websocket.Conn)
 	HandleShellAccessToNode(nodename string, conn *websocket.Conn)
+	HandleKubectlSession(user user.Info, conn *websocket.Conn)
 }
 
 type terminaler struct {
-	client  kubernetes.Interface
-	config  *rest.Config
-	options *Options
+	client        kubernetes.Interface
+	config        *rest.Config
+	options       *Options
+	tokenOperator auth.TokenManagementInterface
 }
 
 type NodeTerminaler struct {
@@ -174,8 +179,8 @@ type NodeTerminaler struct {
 	client        kubernetes.Interface
 }
 
-func NewTerminaler(client kubernetes.Interface, config *rest.Config, options *Options) Interface {
-	return &terminaler{client: client, config: config, options: options}
+func NewTerminaler(client kubernetes.Interface, config *rest.Config, options *Options, tokenOperator auth.TokenManagementInterface) Interface {
+	return &terminaler{client: client, config: config, options: options, tokenOperator: tokenOperator}
 }
 
 func NewNodeTerminaler(nodename string, options *Options, client kubernetes.Interface) (*NodeTerminaler, error) {
@@ -0,0 +1,330 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	k8sinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+const (
+	// CronJobInstantiateAnnotation marks a Job created from a CronJob by hand, same as `kubectl create job --from`.
+	CronJobInstantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+	// CronJobHistoryTTLAnnotation is the duration, e.g. "72h", a finished run of the CronJob is kept for.
+	CronJobHistoryTTLAnnotation = "cronjob.kubesphere.io/history-ttl"
+	// CronJobHistoryLimitAnnotation is the max number of finished runs of the CronJob kept,
+	// no matter whether they succeeded or failed.
+	CronJobHistoryLimitAnnotation = "cronjob.kubesphere.io/history-limit"
+
+	JobRunRunning   = "running"
+	JobRunCompleted = "completed"
+	JobRunFailed    = "failed"
+
+	// the name of the job is used as a label value of its pods
+	maxJobNameLength = 63
+)
+
+// JobRun is a single run of a CronJob.
+type JobRun struct {
+	Name           string       `json:"name"`
+	Status         string       `json:"status"`
+	Manual         bool         `json:"manual,omitempty"`
+	Creator        string       `json:"creator,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Duration of the run in seconds, up to now if it's still running
+	Duration  int64       `json:"duration"`
+	Active    int32       `json:"active"`
+	Succeeded int32       `json:"succeeded"`
+	Failed    int32       `json:"failed"`
+	Reason    string      `json:"reason,omitempty"`
+	Message   string      `json:"message,omitempty"`
+	Pods      []JobRunPod `json:"pods,omitempty"`
+}
+
+type JobRunPod struct {
+	Name  string          `json:"name"`
+	Phase corev1.PodPhase `json:"phase"`
+	// LogURL is the path to fetch the logs of the pod through ks-apiserver
+	LogURL string `json:"logURL"`
+}
+
+// PruneRules are rules to prune the run history of a CronJob, on top of
+// its successfulJobsHistoryLimit and failedJobsHistoryLimit.
+type PruneRules struct {
+	TTL   time.Duration
+	Limit int
+}
+
+type CronJobOperator interface {
+	// Suspend suspends or resumes the scheduling of the CronJob
+	Suspend(namespace, name string, suspend bool) (*batchv1.CronJob, error)
+	// Trigger creates a Job from the job template of the CronJob right now
+	Trigger(namespace, name, user string) (*batchv1.Job, error)
+	// ListRuns lists the Jobs of the CronJob, newest first
+	ListRuns(namespace, name string) ([]JobRun, error)
+	// Prune deletes the finished Jobs of the CronJob which break its PruneRules,
+	// and returns the names of the deleted Jobs
+	Prune(namespace, name string) ([]string, error)
+}
+
+type cronJobOperator struct {
+	informers k8sinformers.SharedInformerFactory
+	client    kubernetes.Interface
+}
+
+// NewCronJobOperator creates the operator of the CronJobs, which reads the CronJobs, Jobs and Pods from the informers.
+func NewCronJobOperator(informers k8sinformers.SharedInformerFactory, client kubernetes.Interface) CronJobOperator {
+	return &cronJobOperator{informers: informers, client: client}
+}
+
+func (o *cronJobOperator) Suspend(namespace, name string, suspend bool) (*batchv1.CronJob, error) {
+	patch := []byte(fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend))
+	return o.client.BatchV1().CronJobs(namespace).Patch(context.Background(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+func (o *cronJobOperator) Trigger(namespace, name, user string) (*batchv1.Job, error) {
+	cronJob, err := o.informers.Batch().V1().CronJobs().Lister().CronJobs(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := "-manual-" + rand.String(5)
+	prefix := cronJob.Name
+	if len(prefix)+len(suffix) > maxJobNameLength {
+		prefix = prefix[:maxJobNameLength-len(suffix)]
+	}
+
+	annotations := map[string]string{CronJobInstantiateAnnotation: "manual"}
+	for k, v := range cronJob.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	if user != "" {
+		annotations[constants.CreatorAnnotationKey] = user
+	}
+	labels := make(map[string]string, len(cronJob.Spec.JobTemplate.Labels))
+	for k, v := range cronJob.Spec.JobTemplate.Labels {
+		labels[k] = v
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            prefix + suffix,
+			Namespace:       namespace,
+			Labels:          labels,
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob"))},
+		},
+		Spec: *cronJob.Spec.JobTemplate.Spec.DeepCopy(),
+	}
+	return o.client.BatchV1().Jobs(namespace).Create(context.Background(), job, metav1.CreateOptions{})
+}
+
+func (o *cronJobOperator) ListRuns(namespace, name string) ([]JobRun, error) {
+	cronJob, err := o.informers.Batch().V1().CronJobs().Lister().CronJobs(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := o.listJobs(cronJob)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]JobRun, 0, len(jobs))
+	for _, job := range jobs {
+		run := newJobRun(job)
+		if run.Pods, err = o.listJobPods(job); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+func (o *cronJobOperator) Prune(namespace, name string) ([]string, error) {
+	cronJob, err := o.informers.Batch().V1().CronJobs().Lister().CronJobs(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := GetPruneRules(cronJob)
+	if err != nil {
+		return nil, k8serr.NewBadRequest(err.Error())
+	}
+	if rules == nil {
+		return []string{}, nil
+	}
+	jobs, err := o.listJobs(cronJob)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make([]string, 0)
+	finished := 0
+	propagation := metav1.DeletePropagationBackground
+	for _, job := range jobs {
+		status, finishedAt := JobStatus(job)
+		if status == JobRunRunning {
+			continue
+		}
+		finished++
+		expired := rules.TTL > 0 && finishedAt != nil && time.Since(finishedAt.Time) > rules.TTL
+		if !expired && (rules.Limit == 0 || finished <= rules.Limit) {
+			continue
+		}
+		err = o.client.BatchV1().Jobs(namespace).Delete(context.Background(), job.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !k8serr.IsNotFound(err) {
+			return deleted, err
+		}
+		klog.V(4).Infof("pruned job %s/%s of cronjob %s", namespace, job.Name, name)
+		deleted = append(deleted, job.Name)
+	}
+	return deleted, nil
+}
+
+// listJobs returns the Jobs controlled by the CronJob, newest first.
+func (o *cronJobOperator) listJobs(cronJob *batchv1.CronJob) ([]*batchv1.Job, error) {
+	list, err := o.informers.Batch().V1().Jobs().Lister().Jobs(cronJob.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*batchv1.Job, 0)
+	for _, job := range list {
+		if metav1.IsControlledBy(job, cronJob) {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].CreationTimestamp.Equal(&jobs[j].CreationTimestamp) {
+			return jobs[i].Name > jobs[j].Name
+		}
+		return jobs[j].CreationTimestamp.Before(&jobs[i].CreationTimestamp)
+	})
+	return jobs, nil
+}
+
+func (o *cronJobOperator) listJobPods(job *batchv1.Job) ([]JobRunPod, error) {
+	if job.Spec.Selector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := o.informers.Core().V1().Pods().Lister().Pods(job.Namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreationTimestamp.Before(&list[j].CreationTimestamp)
+	})
+	pods := make([]JobRunPod, 0, len(list))
+	for _, pod := range list {
+		pods = append(pods, JobRunPod{
+			Name:   pod.Name,
+			Phase:  pod.Status.Phase,
+			LogURL: fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/log", pod.Namespace, pod.Name),
+		})
+	}
+	return pods, nil
+}
+
+func newJobRun(job *batchv1.Job) JobRun {
+	status, finishedAt := JobStatus(job)
+	run := JobRun{
+		Name:           job.Name,
+		Status:         status,
+		Manual:         job.Annotations[CronJobInstantiateAnnotation] == "manual",
+		Creator:        job.Annotations[constants.CreatorAnnotationKey],
+		StartTime:      job.Status.StartTime,
+		CompletionTime: finishedAt,
+		Active:         job.Status.Active,
+		Succeeded:      job.Status.Succeeded,
+		Failed:         job.Status.Failed,
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			run.Reason = condition.Reason
+			run.Message = condition.Message
+		}
+	}
+	if run.StartTime != nil {
+		end := time.Now()
+		if finishedAt != nil {
+			end = finishedAt.Time
+		}
+		run.Duration = int64(end.Sub(run.StartTime.Time).Seconds())
+	}
+	return run
+}
+
+// JobStatus returns the status of the Job, and when it finished if it's not running.
+func JobStatus(job *batchv1.Job) (string, *metav1.Time) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			if job.Status.CompletionTime != nil {
+				return JobRunCompleted, job.Status.CompletionTime
+			}
+			return JobRunCompleted, condition.LastTransitionTime.DeepCopy()
+		case batchv1.JobFailed:
+			return JobRunFailed, condition.LastTransitionTime.DeepCopy()
+		}
+	}
+	return JobRunRunning, nil
+}
+
+// GetPruneRules returns the PruneRules of the CronJob, or nil if it has none.
+func GetPruneRules(cronJob *batchv1.CronJob) (*PruneRules, error) {
+	rules := &PruneRules{}
+	if value, ok := cronJob.Annotations[CronJobHistoryTTLAnnotation]; ok {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a positive duration", CronJobHistoryTTLAnnotation, value)
+		}
+		rules.TTL = ttl
+	}
+	if value, ok := cronJob.Annotations[CronJobHistoryLimitAnnotation]; ok {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a positive integer", CronJobHistoryLimitAnnotation, value)
+		}
+		rules.Limit = limit
+	}
+	if rules.TTL == 0 && rules.Limit == 0 {
+		return nil, nil
+	}
+	return rules, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+func newCronJob(annotations map[string]string) *batchv1.CronJob {
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default", UID: types.UID("backup"), Annotations: annotations},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "0 * * * *",
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "backup"}},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "backup", Image: "busybox"}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newCronJobRun(cronJob *batchv1.CronJob, name string, created time.Time, condition batchv1.JobConditionType) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+			OwnerReferences:   []metav1.OwnerReference{*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob"))},
+		},
+		Spec: batchv1.JobSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": name}},
+		},
+		Status: batchv1.JobStatus{StartTime: &metav1.Time{Time: created}},
+	}
+	if condition != "" {
+		finished := metav1.NewTime(created.Add(time.Minute))
+		job.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue, LastTransitionTime: finished}}
+		if condition == batchv1.JobComplete {
+			job.Status.CompletionTime = &finished
+			job.Status.Succeeded = 1
+		}
+	}
+	return job
+}
+
+func TestCronJobOperator(t *testing.T) {
+	now := time.Now()
+	cronJob := newCronJob(map[string]string{CronJobHistoryTTLAnnotation: "24h", CronJobHistoryLimitAnnotation: "2"})
+	other := newCronJob(nil)
+	other.Name, other.UID = "other", types.UID("other")
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-4-x7k2p", Namespace: "default", Labels: map[string]string{"job-name": "backup-4"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	client := fake.NewSimpleClientset(cronJob, pod,
+		newCronJobRun(cronJob, "backup-1", now.Add(-48*time.Hour), batchv1.JobComplete),
+		newCronJobRun(cronJob, "backup-2", now.Add(-3*time.Hour), batchv1.JobFailed),
+		newCronJobRun(cronJob, "backup-3", now.Add(-2*time.Hour), batchv1.JobComplete),
+		newCronJobRun(cronJob, "backup-4", now.Add(-time.Hour), ""),
+		newCronJobRun(other, "other", now, batchv1.JobComplete))
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	operator := NewCronJobOperator(informerFactory, client)
+	informerFactory.Batch().V1().CronJobs().Informer()
+	informerFactory.Batch().V1().Jobs().Informer()
+	informerFactory.Core().V1().Pods().Informer()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+
+	updated, err := operator.Suspend("default", "backup", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Spec.Suspend == nil || !*updated.Spec.Suspend {
+		t.Error("expected the cronjob to be suspended")
+	}
+
+	runs, err := operator.ListRuns("default", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, run := range runs {
+		names = append(names, run.Name+"/"+run.Status)
+	}
+	expected := []string{"backup-4/running", "backup-3/completed", "backup-2/failed", "backup-1/completed"}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Errorf("unexpected runs: %s", diff)
+	}
+	if runs[1].Duration != 60 || runs[0].Duration < 3600 || len(runs[0].Pods) != 1 ||
+		runs[0].Pods[0].LogURL != "/api/v1/namespaces/default/pods/backup-4-x7k2p/log" {
+		t.Errorf("unexpected runs %+v", runs)
+	}
+
+	deleted, err := operator.Prune("default", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"backup-1"}, deleted); diff != "" {
+		t.Errorf("unexpected pruned jobs: %s", diff)
+	}
+
+	job, err := operator.Trigger("default", "backup", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(job.Name, "backup-manual-") || !metav1.IsControlledBy(job, cronJob) ||
+		job.Annotations[CronJobInstantiateAnnotation] != "manual" || job.Annotations[constants.CreatorAnnotationKey] != "admin" ||
+		job.Labels["app"] != "backup" {
+		t.Errorf("unexpected job %+v", job.ObjectMeta)
+	}
+
+	if _, err = operator.Trigger("default", "missing", "admin"); !k8serr.IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if _, err = client.BatchV1().Jobs("default").Get(context.Background(), "other", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected job of other cronjob to be kept, got %v", err)
+	}
+}
+
+func TestGetPruneRules(t *testing.T) {
+	tests := []struct {
+		annotations map[string]string
+		expected    *PruneRules
+		invalid     bool
+	}{
+		{nil, nil, false},
+		{map[string]string{CronJobHistoryTTLAnnotation: "72h"}, &PruneRules{TTL: 72 * time.Hour}, false},
+		{map[string]string{CronJobHistoryLimitAnnotation: "5"}, &PruneRules{Limit: 5}, false},
+		{map[string]string{CronJobHistoryTTLAnnotation: "3 days"}, nil, true},
+		{map[string]string{CronJobHistoryLimitAnnotation: "0"}, nil, true},
+	}
+	for _, tt := range tests {
+		rules, err := GetPruneRules(newCronJob(tt.annotations))
+		if (err != nil) != tt.invalid || !cmp.Equal(tt.expected, rules) {
+			t.Errorf("GetPruneRules(%v) = %v, %v", tt.annotations, rules, err)
+		}
+	}
+}
@@ -0,0 +1,568 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	k8sinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/query"
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+// The reasons of the problems of the abnormal workloads.
+const (
+	ReasonCrashLoopBackOff = "CrashLoopBackOff"
+	ReasonImagePullBackOff = "ImagePullBackOff"
+	ReasonOOMKilled        = "OOMKilled"
+	// ReasonContainerError is any other reason a container is waiting for, e.g. CreateContainerConfigError.
+	ReasonContainerError               = "ContainerError"
+	ReasonProbeFailed                  = "ProbeFailed"
+	ReasonInsufficientResources        = "InsufficientResources"
+	ReasonUnboundPersistentVolumeClaim = "UnboundPersistentVolumeClaim"
+	ReasonUnschedulable                = "Unschedulable"
+	ReasonProgressDeadlineExceeded     = "ProgressDeadlineExceeded"
+	ReasonReplicaFailure               = "ReplicaFailure"
+	ReasonJobFailed                    = "JobFailed"
+	// ReasonNotReady is reported if the replicas are not ready for none of the reasons above, e.g. a rolling update.
+	ReasonNotReady = "NotReady"
+
+	// DiagnosisFilterKind and DiagnosisFilterReason filter the abnormal workloads by kind and by the reason of any problem.
+	DiagnosisFilterKind   = "kind"
+	DiagnosisFilterReason = "reason"
+
+	// the max number of the events linked to an abnormal workload
+	maxDiagnosisEvents = 10
+)
+
+// Problem is a problem of a workload, of one of its pods or containers if Pod or Container is set.
+type Problem struct {
+	Reason                string `json:"reason"`
+	Message               string `json:"message,omitempty"`
+	Pod                   string `json:"pod,omitempty"`
+	Container             string `json:"container,omitempty"`
+	PersistentVolumeClaim string `json:"persistentVolumeClaim,omitempty"`
+	RestartCount          int32  `json:"restartCount,omitempty"`
+	// LastState is the state the container was last terminated with, e.g. the exit code and the reason
+	LastState *corev1.ContainerState `json:"lastState,omitempty"`
+}
+
+// DiagnosisEvent is a warning event of the workload, its pods or its replica sets.
+type DiagnosisEvent struct {
+	Kind          string      `json:"kind"`
+	Name          string      `json:"name"`
+	Reason        string      `json:"reason"`
+	Message       string      `json:"message"`
+	Count         int32       `json:"count"`
+	LastTimestamp metav1.Time `json:"lastTimestamp"`
+}
+
+type WorkloadDiagnosis struct {
+	Kind      string           `json:"kind"`
+	Namespace string           `json:"namespace"`
+	Name      string           `json:"name"`
+	Desired   int32            `json:"desired"`
+	Ready     int32            `json:"ready"`
+	Problems  []Problem        `json:"problems"`
+	Events    []DiagnosisEvent `json:"events,omitempty"`
+}
+
+type DiagnosisResult struct {
+	Items      []WorkloadDiagnosis `json:"items"`
+	TotalItems int                 `json:"totalItems"`
+	// Reasons is the number of the abnormal workloads by the reasons of their problems
+	Reasons map[string]int `json:"reasons"`
+}
+
+// DiagnosisOperator finds out the abnormal deployments, statefulsets, daemonsets and jobs, and why.
+type DiagnosisOperator interface {
+	// Diagnose the workloads in the namespace, of the workspace if namespace is empty,
+	// or of the whole cluster if both are empty.
+	Diagnose(workspace, namespace string, q *query.Query) (*DiagnosisResult, error)
+}
+
+type diagnosisOperator struct {
+	informers k8sinformers.SharedInformerFactory
+	client    kubernetes.Interface
+}
+
+// NewDiagnosisOperator creates a DiagnosisOperator, the workloads are read from the informers,
+// and the events, which are not cached, from the client.
+func NewDiagnosisOperator(informers k8sinformers.SharedInformerFactory, client kubernetes.Interface) DiagnosisOperator {
+	return &diagnosisOperator{informers: informers, client: client}
+}
+
+func (d *diagnosisOperator) Diagnose(workspace, namespace string, q *query.Query) (*DiagnosisResult, error) {
+	namespaces, err := d.namespaces(workspace, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []WorkloadDiagnosis
+	for _, ns := range namespaces {
+		diagnoses, err := d.diagnoseNamespace(ns)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, diagnoses...)
+	}
+
+	result := &DiagnosisResult{Items: []WorkloadDiagnosis{}, Reasons: map[string]int{}}
+	for _, item := range items {
+		if !matchesDiagnosis(item, q) {
+			continue
+		}
+		result.Items = append(result.Items, item)
+		reasons := make(map[string]bool)
+		for _, problem := range item.Problems {
+			if !reasons[problem.Reason] {
+				reasons[problem.Reason] = true
+				result.Reasons[problem.Reason]++
+			}
+		}
+	}
+	sort.Slice(result.Items, func(i, j int) bool {
+		a, b := result.Items[i], result.Items[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	result.TotalItems = len(result.Items)
+	start, end := q.Pagination.GetValidPagination(result.TotalItems)
+	result.Items = result.Items[start:end]
+	return result, nil
+}
+
+// namespaces returns the namespaces to diagnose, the empty namespace stands for all of them.
+func (d *diagnosisOperator) namespaces(workspace, namespace string) ([]string, error) {
+	if namespace != "" {
+		return []string{namespace}, nil
+	}
+	if workspace == "" {
+		return []string{metav1.NamespaceAll}, nil
+	}
+	selector := labels.SelectorFromSet(labels.Set{constants.WorkspaceLabelKey: workspace})
+	list, err := d.informers.Core().V1().Namespaces().Lister().List(selector)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := make([]string, 0, len(list))
+	for _, ns := range list {
+		namespaces = append(namespaces, ns.Name)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+func matchesDiagnosis(item WorkloadDiagnosis, q *query.Query) bool {
+	if kind, ok := q.Filters[DiagnosisFilterKind]; ok && !strings.EqualFold(string(kind), item.Kind) {
+		return false
+	}
+	if reason, ok := q.Filters[DiagnosisFilterReason]; ok {
+		for _, problem := range item.Problems {
+			if strings.EqualFold(string(reason), problem.Reason) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// workload is a workload being diagnosed, with the pods it controls.
+type workload struct {
+	diagnosis *WorkloadDiagnosis
+	pods      []*corev1.Pod
+	// the replica sets of a deployment, whose events are linked to the deployment
+	replicaSets []string
+}
+
+func workloadKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+func (d *diagnosisOperator) diagnoseNamespace(namespace string) ([]WorkloadDiagnosis, error) {
+	workloads, err := d.listWorkloads(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err = d.listPods(namespace, workloads); err != nil {
+		return nil, err
+	}
+
+	var abnormal []*workload
+	for _, w := range workloads {
+		for _, pod := range w.pods {
+			w.diagnosis.Problems = append(w.diagnosis.Problems, d.podProblems(pod)...)
+		}
+		if len(w.diagnosis.Problems) == 0 && w.diagnosis.Ready < w.diagnosis.Desired {
+			w.diagnosis.Problems = append(w.diagnosis.Problems, Problem{
+				Reason:  ReasonNotReady,
+				Message: fmt.Sprintf("%d of %d replicas are ready", w.diagnosis.Ready, w.diagnosis.Desired),
+			})
+		}
+		if len(w.diagnosis.Problems) > 0 {
+			abnormal = append(abnormal, w)
+		}
+	}
+	if len(abnormal) == 0 {
+		return nil, nil
+	}
+	if err = d.linkEvents(namespace, abnormal); err != nil {
+		return nil, err
+	}
+
+	diagnoses := make([]WorkloadDiagnosis, 0, len(abnormal))
+	for _, w := range abnormal {
+		diagnoses = append(diagnoses, *w.diagnosis)
+	}
+	return diagnoses, nil
+}
+
+// listWorkloads lists the workloads of the namespace by their keys, with the problems of their own status.
+func (d *diagnosisOperator) listWorkloads(namespace string) (map[string]*workload, error) {
+	workloads := make(map[string]*workload)
+	add := func(kind string, meta metav1.ObjectMeta, desired, ready int32, problems []Problem) {
+		workloads[workloadKey(kind, meta.Namespace, meta.Name)] = &workload{
+			diagnosis: &WorkloadDiagnosis{
+				Kind:      kind,
+				Namespace: meta.Namespace,
+				Name:      meta.Name,
+				Desired:   desired,
+				Ready:     ready,
+				Problems:  problems,
+			},
+		}
+	}
+
+	deployments, err := d.informers.Apps().V1().Deployments().Lister().Deployments(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments {
+		add("Deployment", deployment.ObjectMeta, replicas(deployment.Spec.Replicas), deployment.Status.ReadyReplicas, deploymentProblems(deployment))
+	}
+
+	statefulSets, err := d.informers.Apps().V1().StatefulSets().Lister().StatefulSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets {
+		add("StatefulSet", statefulSet.ObjectMeta, replicas(statefulSet.Spec.Replicas), statefulSet.Status.ReadyReplicas, nil)
+	}
+
+	daemonSets, err := d.informers.Apps().V1().DaemonSets().Lister().DaemonSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, daemonSet := range daemonSets {
+		add("DaemonSet", daemonSet.ObjectMeta, daemonSet.Status.DesiredNumberScheduled, daemonSet.Status.NumberReady, nil)
+	}
+
+	jobs, err := d.informers.Batch().V1().Jobs().Lister().Jobs(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		// the jobs are not expected to be ready, only failing to complete is a problem
+		add("Job", job.ObjectMeta, 0, 0, jobProblems(job))
+	}
+	return workloads, nil
+}
+
+func replicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+func deploymentProblems(deployment *appsv1.Deployment) []Problem {
+	var problems []Problem
+	for _, condition := range deployment.Status.Conditions {
+		switch {
+		case condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == "ProgressDeadlineExceeded":
+			problems = append(problems, Problem{Reason: ReasonProgressDeadlineExceeded, Message: condition.Message})
+		case condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == corev1.ConditionTrue:
+			problems = append(problems, Problem{Reason: ReasonReplicaFailure, Message: condition.Message})
+		}
+	}
+	return problems
+}
+
+func jobProblems(job *batchv1.Job) []Problem {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return []Problem{{Reason: ReasonJobFailed, Message: strings.TrimSpace(condition.Reason + " " + condition.Message)}}
+		}
+	}
+	return nil
+}
+
+// listPods assigns the pods of the namespace to the workloads controlling them,
+// the pods of a deployment are controlled by its replica sets. The pods and replica sets
+// are sorted by name, so the problems of a workload are in a stable order.
+func (d *diagnosisOperator) listPods(namespace string, workloads map[string]*workload) error {
+	replicaSets, err := d.informers.Apps().V1().ReplicaSets().Lister().ReplicaSets(namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	sort.Slice(replicaSets, func(i, j int) bool {
+		return replicaSets[i].Name < replicaSets[j].Name
+	})
+	deploymentOf := make(map[string]string, len(replicaSets))
+	for _, replicaSet := range replicaSets {
+		if owner := metav1.GetControllerOf(replicaSet); owner != nil && owner.Kind == "Deployment" {
+			key := workloadKey(owner.Kind, replicaSet.Namespace, owner.Name)
+			deploymentOf[replicaSet.Namespace+"/"+replicaSet.Name] = key
+			if w, ok := workloads[key]; ok {
+				w.replicaSets = append(w.replicaSets, replicaSet.Name)
+			}
+		}
+	}
+
+	pods, err := d.informers.Core().V1().Pods().Lister().Pods(namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+	for _, pod := range pods {
+		owner := metav1.GetControllerOf(pod)
+		if owner == nil {
+			continue
+		}
+		key := workloadKey(owner.Kind, pod.Namespace, owner.Name)
+		if owner.Kind == "ReplicaSet" {
+			key = deploymentOf[pod.Namespace+"/"+owner.Name]
+		}
+		if w, ok := workloads[key]; ok {
+			w.pods = append(w.pods, pod)
+		}
+	}
+	return nil
+}
+
+// podProblems classifies the problems of the pod, the succeeded pods have none.
+func (d *diagnosisOperator) podProblems(pod *corev1.Pod) []Problem {
+	if pod.Status.Phase == corev1.PodSucceeded {
+		return nil
+	}
+	if problems := d.schedulingProblems(pod); len(problems) > 0 {
+		return problems
+	}
+
+	var problems []Problem
+	for _, status := range pod.Status.InitContainerStatuses {
+		if problem := containerProblem(pod, status, true); problem != nil {
+			problems = append(problems, *problem)
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if problem := containerProblem(pod, status, false); problem != nil {
+			problems = append(problems, *problem)
+		}
+	}
+	return problems
+}
+
+// schedulingProblems returns why the pod can't be scheduled, the claims of the pod not bound
+// are reported even if the scheduler has not given up yet.
+func (d *diagnosisOperator) schedulingProblems(pod *corev1.Pod) []Problem {
+	if pod.Status.Phase != corev1.PodPending || pod.Spec.NodeName != "" {
+		return nil
+	}
+
+	var problems []Problem
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		claim := volume.PersistentVolumeClaim.ClaimName
+		pvc, err := d.informers.Core().V1().PersistentVolumeClaims().Lister().PersistentVolumeClaims(pod.Namespace).Get(claim)
+		switch {
+		case err != nil:
+			problems = append(problems, Problem{
+				Reason:                ReasonUnboundPersistentVolumeClaim,
+				Message:               fmt.Sprintf("persistentvolumeclaim %q not found", claim),
+				Pod:                   pod.Name,
+				PersistentVolumeClaim: claim,
+			})
+		case pvc.Status.Phase != corev1.ClaimBound:
+			problems = append(problems, Problem{
+				Reason:                ReasonUnboundPersistentVolumeClaim,
+				Message:               fmt.Sprintf("persistentvolumeclaim %q is %s", claim, pvc.Status.Phase),
+				Pod:                   pod.Name,
+				PersistentVolumeClaim: claim,
+			})
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type != corev1.PodScheduled || condition.Status != corev1.ConditionFalse ||
+			condition.Reason != corev1.PodReasonUnschedulable {
+			continue
+		}
+		reason := ReasonUnschedulable
+		if strings.Contains(condition.Message, "Insufficient") {
+			reason = ReasonInsufficientResources
+		}
+		return []Problem{{Reason: reason, Message: condition.Message, Pod: pod.Name}}
+	}
+	return nil
+}
+
+func containerProblem(pod *corev1.Pod, status corev1.ContainerStatus, init bool) *Problem {
+	problem := &Problem{Pod: pod.Name, Container: status.Name, RestartCount: status.RestartCount}
+	if status.LastTerminationState.Terminated != nil {
+		problem.LastState = status.LastTerminationState.DeepCopy()
+	}
+	lastOOMKilled := problem.LastState != nil && problem.LastState.Terminated.Reason == ReasonOOMKilled
+
+	switch state := status.State; {
+	case state.Waiting != nil:
+		problem.Message = state.Waiting.Message
+		switch state.Waiting.Reason {
+		case "", "ContainerCreating", "PodInitializing":
+			return nil
+		case "CrashLoopBackOff":
+			problem.Reason = ReasonCrashLoopBackOff
+			if lastOOMKilled {
+				problem.Reason = ReasonOOMKilled
+			}
+		case "ImagePullBackOff", "ErrImagePull", "InvalidImageName", "ErrImageNeverPull":
+			problem.Reason = ReasonImagePullBackOff
+		default:
+			problem.Reason = ReasonContainerError
+			problem.Message = strings.TrimSpace(state.Waiting.Reason + ": " + state.Waiting.Message)
+		}
+	case state.Terminated != nil:
+		if state.Terminated.Reason != ReasonOOMKilled {
+			return nil
+		}
+		problem.Reason = ReasonOOMKilled
+		problem.LastState = status.State.DeepCopy()
+	case state.Running != nil:
+		switch {
+		case !init && !status.Ready && pod.DeletionTimestamp == nil:
+			problem.Reason = ReasonProbeFailed
+			if status.Started != nil && !*status.Started {
+				problem.Message = "the startup probe has not succeeded"
+			} else {
+				problem.Message = "the readiness probe has not succeeded"
+			}
+		case lastOOMKilled:
+			problem.Reason = ReasonOOMKilled
+		default:
+			return nil
+		}
+	default:
+		return nil
+	}
+	return problem
+}
+
+// linkEvents links the warning events of the abnormal workloads, of their pods and of their replica sets,
+// the latest first. The messages of the failed probes are taken from the events of the pods.
+func (d *diagnosisOperator) linkEvents(namespace string, abnormal []*workload) error {
+	selector := fields.OneTermEqualSelector("type", corev1.EventTypeWarning).String()
+	events, err := d.client.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return err
+	}
+
+	byObject := make(map[string][]corev1.Event)
+	for _, event := range events.Items {
+		key := workloadKey(event.InvolvedObject.Kind, event.Namespace, event.InvolvedObject.Name)
+		byObject[key] = append(byObject[key], event)
+	}
+
+	for _, w := range abnormal {
+		diagnosis := w.diagnosis
+		var linked []corev1.Event
+		linked = append(linked, byObject[workloadKey(diagnosis.Kind, diagnosis.Namespace, diagnosis.Name)]...)
+		for _, replicaSet := range w.replicaSets {
+			linked = append(linked, byObject[workloadKey("ReplicaSet", diagnosis.Namespace, replicaSet)]...)
+		}
+		for _, pod := range w.pods {
+			linked = append(linked, byObject[workloadKey("Pod", pod.Namespace, pod.Name)]...)
+		}
+		sort.SliceStable(linked, func(i, j int) bool {
+			return eventTime(linked[j]).Before(eventTime(linked[i]))
+		})
+
+		for i := range diagnosis.Problems {
+			problem := &diagnosis.Problems[i]
+			if problem.Reason != ReasonProbeFailed {
+				continue
+			}
+			for _, event := range linked {
+				if event.InvolvedObject.Kind == "Pod" && event.InvolvedObject.Name == problem.Pod && event.Reason == "Unhealthy" {
+					problem.Message = event.Message
+					break
+				}
+			}
+		}
+
+		if len(linked) > maxDiagnosisEvents {
+			linked = linked[:maxDiagnosisEvents]
+		}
+		for _, event := range linked {
+			diagnosis.Events = append(diagnosis.Events, DiagnosisEvent{
+				Kind:          event.InvolvedObject.Kind,
+				Name:          event.InvolvedObject.Name,
+				Reason:        event.Reason,
+				Message:       event.Message,
+				Count:         event.Count,
+				LastTimestamp: metav1.NewTime(eventTime(event)),
+			})
+		}
+	}
+	return nil
+}
+
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
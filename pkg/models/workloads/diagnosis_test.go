@@ -0,0 +1,217 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+
+	"kubesphere.io/kubesphere/pkg/apiserver/query"
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+func newDeployment(name string, replicas, ready int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(replicas)},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: ready},
+	}
+}
+
+// newDeploymentPod creates a pod of the deployment, through a replica set of the same name.
+func newDeploymentPod(deployment *appsv1.Deployment, name string, status corev1.PodStatus, volumes ...corev1.Volume) (*appsv1.ReplicaSet, *corev1.Pod) {
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            deployment.Name,
+			Namespace:       deployment.Namespace,
+			UID:             types.UID(deployment.Name + "-rs"),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       deployment.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(replicaSet, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))},
+		},
+		Spec:   corev1.PodSpec{Volumes: volumes},
+		Status: status,
+	}
+	return replicaSet, pod
+}
+
+func TestDiagnose(t *testing.T) {
+	now := metav1.NewTime(time.Unix(1700000000, 0))
+	oomKilled := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: ReasonOOMKilled, ExitCode: 137}}
+
+	healthy := newDeployment("healthy", 1, 1)
+	healthyRS, healthyPod := newDeploymentPod(healthy, "healthy-1", corev1.PodStatus{
+		Phase:             corev1.PodRunning,
+		ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+	})
+	crashing := newDeployment("crashing", 1, 0)
+	crashingRS, crashingPod := newDeploymentPod(crashing, "crashing-1", corev1.PodStatus{
+		Phase: corev1.PodRunning,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:                 "app",
+			RestartCount:         3,
+			State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			LastTerminationState: oomKilled,
+		}},
+	})
+	pulling := newDeployment("pulling", 1, 0)
+	pullingRS, pullingPod := newDeploymentPod(pulling, "pulling-1", corev1.PodStatus{
+		Phase: corev1.PodPending,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "app",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "not found"}},
+		}},
+	})
+	unready := newDeployment("unready", 1, 0)
+	unreadyRS, unreadyPod := newDeploymentPod(unready, "unready-1", corev1.PodStatus{
+		Phase:             corev1.PodRunning,
+		ContainerStatuses: []corev1.ContainerStatus{{Name: "app", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+	})
+	pending := newDeployment("pending", 2, 0)
+	pendingRS, pendingPod := newDeploymentPod(pending, "pending-1", corev1.PodStatus{
+		Phase: corev1.PodPending,
+		Conditions: []corev1.PodCondition{{
+			Type:    corev1.PodScheduled,
+			Status:  corev1.ConditionFalse,
+			Reason:  corev1.PodReasonUnschedulable,
+			Message: "0/3 nodes are available: 3 Insufficient memory.",
+		}},
+	})
+	_, unboundPod := newDeploymentPod(pending, "pending-2", corev1.PodStatus{Phase: corev1.PodPending},
+		corev1.Volume{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}})
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+
+	events := []runtime.Object{
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "unhealthy", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "unready-1"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "Unhealthy",
+			Message:        "Readiness probe failed: connection refused",
+			Count:          5,
+			LastTimestamp:  now,
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "backoff", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "crashing-1"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			Count:          3,
+			LastTimestamp:  now,
+		},
+	}
+
+	client := fake.NewSimpleClientset(events...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	objects := []runtime.Object{
+		healthy, healthyRS, healthyPod, crashing, crashingRS, crashingPod, pulling, pullingRS, pullingPod,
+		unready, unreadyRS, unreadyPod, pending, pendingRS, pendingPod, unboundPod, pvc,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{constants.WorkspaceLabelKey: "system-workspace"}}},
+	}
+	for _, object := range objects {
+		var err error
+		switch o := object.(type) {
+		case *appsv1.Deployment:
+			err = informerFactory.Apps().V1().Deployments().Informer().GetIndexer().Add(o)
+		case *appsv1.ReplicaSet:
+			err = informerFactory.Apps().V1().ReplicaSets().Informer().GetIndexer().Add(o)
+		case *corev1.Pod:
+			err = informerFactory.Core().V1().Pods().Informer().GetIndexer().Add(o)
+		case *corev1.PersistentVolumeClaim:
+			err = informerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(o)
+		case *corev1.Namespace:
+			err = informerFactory.Core().V1().Namespaces().Informer().GetIndexer().Add(o)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the other informers are listed from, though empty
+	informerFactory.Apps().V1().StatefulSets().Informer()
+	informerFactory.Apps().V1().DaemonSets().Informer()
+	informerFactory.Batch().V1().Jobs().Informer()
+
+	operator := NewDiagnosisOperator(informerFactory, client)
+
+	expected := []WorkloadDiagnosis{
+		{
+			Kind: "Deployment", Namespace: "default", Name: "crashing", Desired: 1,
+			Problems: []Problem{{Reason: ReasonOOMKilled, Pod: "crashing-1", Container: "app", RestartCount: 3, LastState: &oomKilled}},
+			Events:   []DiagnosisEvent{{Kind: "Pod", Name: "crashing-1", Reason: "BackOff", Message: "Back-off restarting failed container", Count: 3, LastTimestamp: now}},
+		},
+		{
+			Kind: "Deployment", Namespace: "default", Name: "pending", Desired: 2,
+			Problems: []Problem{
+				{Reason: ReasonInsufficientResources, Message: "0/3 nodes are available: 3 Insufficient memory.", Pod: "pending-1"},
+				{Reason: ReasonUnboundPersistentVolumeClaim, Message: `persistentvolumeclaim "data" is Pending`, Pod: "pending-2", PersistentVolumeClaim: "data"},
+			},
+		},
+		{
+			Kind: "Deployment", Namespace: "default", Name: "pulling", Desired: 1,
+			Problems: []Problem{{Reason: ReasonImagePullBackOff, Message: "not found", Pod: "pulling-1", Container: "app"}},
+		},
+		{
+			Kind: "Deployment", Namespace: "default", Name: "unready", Desired: 1,
+			Problems: []Problem{{Reason: ReasonProbeFailed, Message: "Readiness probe failed: connection refused", Pod: "unready-1", Container: "app"}},
+			Events:   []DiagnosisEvent{{Kind: "Pod", Name: "unready-1", Reason: "Unhealthy", Message: "Readiness probe failed: connection refused", Count: 5, LastTimestamp: now}},
+		},
+	}
+
+	for _, scope := range []struct{ workspace, namespace string }{{"", ""}, {"system-workspace", ""}, {"", "default"}} {
+		result, err := operator.Diagnose(scope.workspace, scope.namespace, query.New())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(expected, result.Items); diff != "" {
+			t.Errorf("scope %+v: %s", scope, diff)
+		}
+		if result.TotalItems != 4 || result.Reasons[ReasonOOMKilled] != 1 || result.Reasons[ReasonInsufficientResources] != 1 {
+			t.Errorf("scope %+v: unexpected summary %d, %v", scope, result.TotalItems, result.Reasons)
+		}
+	}
+
+	result, err := operator.Diagnose("other-workspace", "", query.New())
+	if err != nil || result.TotalItems != 0 {
+		t.Errorf("expected no abnormal workloads of other workspaces, got %v, %v", result, err)
+	}
+
+	q := query.New()
+	q.Filters[DiagnosisFilterReason] = "imagepullbackoff"
+	result, err = operator.Diagnose("", "default", q)
+	if err != nil || result.TotalItems != 1 || result.Items[0].Name != "pulling" {
+		t.Errorf("expected the workloads filtered by reason, got %v, %v", result, err)
+	}
+}
@@ -20,13 +20,18 @@ package healthz
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/emicklei/go-restful/v3"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/server/httplog"
 	"k8s.io/klog/v2"
 )
@@ -58,6 +63,14 @@ func InstallLivezHandler(container *restful.Container, checks ...HealthChecker)
 	return AddToContainer(container, "/livez", checks...)
 }
 
+func InstallReadyzHandler(container *restful.Container, checks ...HealthChecker) error {
+	if len(checks) == 0 {
+		klog.V(4).Info("No default health checks specified. Installing the ping handler.")
+		checks = []HealthChecker{PingHealthz}
+	}
+	return AddToContainer(container, "/readyz", checks...)
+}
+
 // handleRootHealth returns an http.HandlerFunc that serves the provided checks.
 func handleRootHealth(name string, firstTimeHealthy func(), checks ...HealthChecker) http.HandlerFunc {
 	var notifyOnce sync.Once
@@ -145,6 +158,123 @@ func getExcludedChecks(r *http.Request) sets.Set[string] {
 	return sets.New[string]()
 }
 
+// CheckTimeout is how long a health checker waits for the dependency it checks.
+const CheckTimeout = 5 * time.Second
+
+// NamedCheck returns a healthz checker for the given name and function.
+func NamedCheck(name string, check func(r *http.Request) error) HealthChecker {
+	return &healthzCheck{name, check}
+}
+
+// healthzCheck implements HealthChecker on an arbitrary name and check function.
+type healthzCheck struct {
+	name  string
+	check func(r *http.Request) error
+}
+
+func (c *healthzCheck) Name() string {
+	return c.name
+}
+
+func (c *healthzCheck) Check(r *http.Request) error {
+	return c.check(r)
+}
+
+// HTTPCheck returns a healthz checker which succeeds if a GET of the url responds with a 2xx status code,
+// prepare is used to modify the request before it's sent, e.g. set the credentials.
+func HTTPCheck(name, url string, prepare func(req *http.Request)) HealthChecker {
+	client := &http.Client{Timeout: CheckTimeout}
+	return NamedCheck(name, func(r *http.Request) error {
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		if prepare != nil {
+			prepare(req)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url)
+		}
+		return nil
+	})
+}
+
+// ReachableCheck returns a healthz checker which succeeds if a tcp connection to the host of the url
+// can be established, it's used for the backends without a health endpoint.
+func ReachableCheck(name, rawURL string) (HealthChecker, error) {
+	// hosts without a scheme, e.g. jenkins.kubesphere-devops-system.svc:80
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	address := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+	return NamedCheck(name, func(r *http.Request) error {
+		dialer := &net.Dialer{Timeout: CheckTimeout}
+		conn, err := dialer.DialContext(r.Context(), "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}), nil
+}
+
+// CachedCheckInterval is how often a cached checker checks the dependency it wraps.
+const CachedCheckInterval = 30 * time.Second
+
+// CachedCheck returns a healthz checker which serves the last result of check, check is run
+// every interval until the stopCh is closed, so that the health endpoints, which are public,
+// never hit the dependencies directly.
+func CachedCheck(check HealthChecker, interval time.Duration, stopCh <-chan struct{}) HealthChecker {
+	c := &cachedCheck{check: check, err: fmt.Errorf("%s is not checked yet", check.Name())}
+	go wait.Until(c.run, interval, stopCh)
+	return c
+}
+
+// cachedCheck implements HealthChecker with the last result of the check it wraps.
+type cachedCheck struct {
+	check HealthChecker
+
+	mutex sync.RWMutex
+	err   error
+}
+
+func (c *cachedCheck) run() {
+	ctx, cancel := context.WithTimeout(context.Background(), CheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	if err == nil {
+		err = c.check.Check(req)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.err = err
+}
+
+func (c *cachedCheck) Name() string {
+	return c.check.Name()
+}
+
+func (c *cachedCheck) Check(_ *http.Request) error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.err
+}
+
 // PingHealthz returns true automatically when checked
 var PingHealthz HealthChecker = ping{}
 
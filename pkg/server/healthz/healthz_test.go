@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthz
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestHTTPCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, _, _ := r.BasicAuth(); username != "admin" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+	if err := HTTPCheck("es", server.URL, nil).Check(req); err == nil {
+		t.Error("expected unauthorized error")
+	}
+	check := HTTPCheck("es", server.URL, func(req *http.Request) {
+		req.SetBasicAuth("admin", "P@88w0rd")
+	})
+	if err := check.Check(req); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReachableCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+
+	// any response means the backend is reachable
+	for _, rawURL := range []string{server.URL, strings.TrimPrefix(server.URL, "http://")} {
+		check, err := ReachableCheck("jenkins", rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = check.Check(req); err != nil {
+			t.Error(err)
+		}
+	}
+	check, err := ReachableCheck("jenkins", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	if err = check.Check(req); err == nil {
+		t.Error("expected the closed server to be unreachable")
+	}
+	if _, err = ReachableCheck("jenkins", "http://jenkins:port"); err == nil {
+		t.Error("expected error of the invalid url")
+	}
+}
+
+func TestInstallReadyzHandler(t *testing.T) {
+	container := restful.NewContainer()
+	failing := NamedCheck("cache", func(_ *http.Request) error {
+		return fmt.Errorf("connection refused")
+	})
+	if err := InstallReadyzHandler(container, PingHealthz, failing); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		code     int
+		contains string
+	}{
+		{"/readyz", http.StatusInternalServerError, "[-]cache failed: reason withheld"},
+		{"/readyz?exclude=cache", http.StatusOK, "ok"},
+		{"/readyz?exclude=cache&verbose", http.StatusOK, "[+]cache excluded: ok"},
+		{"/readyz/ping", http.StatusOK, "ok"},
+		{"/readyz/cache", http.StatusInternalServerError, "connection refused"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		container.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.contains) {
+			t.Errorf("GET %s = %d %q, want %d containing %q", tt.path, w.Code, w.Body.String(), tt.code, tt.contains)
+		}
+	}
+}
+
+func TestCachedCheck(t *testing.T) {
+	calls := make(chan struct{}, 10)
+	check := NamedCheck("ldap", func(_ *http.Request) error {
+		calls <- struct{}{}
+		return fmt.Errorf("connection refused")
+	})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	cached := CachedCheck(check, time.Hour, stopCh)
+	if cached.Name() != "ldap" {
+		t.Errorf("expected the name of the check wrapped, got %s", cached.Name())
+	}
+
+	<-calls
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	if err := wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		return cached.Check(req) != nil && !strings.Contains(cached.Check(req).Error(), "not checked yet"), nil
+	}); err != nil {
+		t.Fatalf("expected the result of the check, got %v", cached.Check(req))
+	}
+	// the dependency isn't checked again by the requests within the interval
+	for i := 0; i < 3; i++ {
+		_ = cached.Check(req)
+	}
+	if len(calls) != 0 {
+		t.Errorf("expected the dependency to be checked once, got %d more", len(calls))
+	}
+}
@@ -40,6 +40,12 @@ type ServerRunOptions struct {
 
 	// tls private key file
 	TlsPrivateKey string
+
+	// client certificate presented to the backends of the APIServices of the RequestHeader auth mode
+	ProxyClientCertFile string
+
+	// private key of the proxy client certificate
+	ProxyClientKeyFile string
 }
 
 func NewServerRunOptions() *ServerRunOptions {
@@ -80,6 +86,10 @@ func (s *ServerRunOptions) Validate() []error {
 		}
 	}
 
+	if (s.ProxyClientCertFile == "") != (s.ProxyClientKeyFile == "") {
+		errs = append(errs, fmt.Errorf("proxy client cert file and key file must be specified together"))
+	}
+
 	return errs
 }
 
@@ -90,4 +100,9 @@ func (s *ServerRunOptions) AddFlags(fs *pflag.FlagSet, c *ServerRunOptions) {
 	fs.IntVar(&s.SecurePort, "secure-port", s.SecurePort, "secure port number")
 	fs.StringVar(&s.TlsCertFile, "tls-cert-file", c.TlsCertFile, "tls cert file")
 	fs.StringVar(&s.TlsPrivateKey, "tls-private-key", c.TlsPrivateKey, "tls private key")
+	fs.StringVar(&s.ProxyClientCertFile, "proxy-client-cert-file", c.ProxyClientCertFile, ""+
+		"Client certificate used to prove the identity of ks-apiserver when it proxies requests to the APIServices "+
+		"of the RequestHeader auth mode, which are disabled without it.")
+	fs.StringVar(&s.ProxyClientKeyFile, "proxy-client-key-file", c.ProxyClientKeyFile, ""+
+		"Private key of the client certificate used to prove the identity of ks-apiserver to the APIServices.")
 }
@@ -0,0 +1,43 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alerting
+
+import (
+	"net/http"
+	"strings"
+
+	"kubesphere.io/kubesphere/pkg/server/healthz"
+)
+
+// NewHealthChecker returns a healthz checker named "alerting" which checks the configured
+// prometheus and thanos ruler endpoints are ready.
+func NewHealthChecker(options *Options) healthz.HealthChecker {
+	var checks []healthz.HealthChecker
+	for _, endpoint := range []string{options.PrometheusEndpoint, options.ThanosRulerEndpoint} {
+		if endpoint != "" {
+			checks = append(checks, healthz.HTTPCheck(endpoint, strings.TrimSuffix(endpoint, "/")+"/-/ready", nil))
+		}
+	}
+	return healthz.NamedCheck("alerting", func(r *http.Request) error {
+		for _, check := range checks {
+			if err := check.Check(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -49,6 +49,13 @@ type Interface interface {
 	Expire(key string, duration time.Duration) error
 }
 
+// ScriptRunner is implemented by the caches which run lua scripts atomically, e.g. redis,
+// it's used to update the values shared by the replicas without races.
+type ScriptRunner interface {
+	// RunScript runs the lua script with the keys and the arguments, and returns the result of the script.
+	RunScript(script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
 func RegisterCacheFactory(factory CacheFactory) {
 	cacheFactories[factory.Type()] = factory
 }
@@ -0,0 +1,38 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"net/http"
+
+	"kubesphere.io/kubesphere/pkg/server/healthz"
+)
+
+// pinger is implemented by the caches backed by a remote server.
+type pinger interface {
+	Ping() error
+}
+
+// NewHealthChecker returns a healthz checker named "cache" which checks the server of the cache is reachable.
+func NewHealthChecker(cache Interface) healthz.HealthChecker {
+	return healthz.NamedCheck("cache", func(_ *http.Request) error {
+		if p, ok := cache.(pinger); ok {
+			return p.Ping()
+		}
+		return nil
+	})
+}
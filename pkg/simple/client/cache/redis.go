@@ -104,6 +104,11 @@ func (r *redisClient) Expire(key string, duration time.Duration) error {
 	return r.client.Expire(key, duration).Err()
 }
 
+// RunScript runs the script by its sha1 digest, the script is loaded to redis on the first run.
+func (r *redisClient) RunScript(script string, keys []string, args ...interface{}) (interface{}, error) {
+	return redis.NewScript(script).Run(r.client, keys, args...).Result()
+}
+
 type redisFactory struct{}
 
 func (rf *redisFactory) Type() string {
@@ -131,3 +136,7 @@ func (rf *redisFactory) Create(options options.DynamicOptions, stopCh <-chan str
 func init() {
 	RegisterCacheFactory(&redisFactory{})
 }
+
+func (r *redisClient) Ping() error {
+	return r.client.Ping().Err()
+}
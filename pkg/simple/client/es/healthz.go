@@ -0,0 +1,36 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package es
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"kubesphere.io/kubesphere/pkg/server/healthz"
+)
+
+// NewHealthChecker returns a healthz checker which checks the elasticsearch cluster at the host is
+// reachable and its status is at least yellow.
+func NewHealthChecker(name, host string, basicAuth bool, username, password string) healthz.HealthChecker {
+	url := fmt.Sprintf("%s/_cluster/health?wait_for_status=yellow&timeout=1s", strings.TrimSuffix(host, "/"))
+	return healthz.HTTPCheck(name, url, func(req *http.Request) {
+		if basicAuth {
+			req.SetBasicAuth(username, password)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ldap
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/go-ldap/ldap"
+
+	"kubesphere.io/kubesphere/pkg/server/healthz"
+)
+
+// NewHealthChecker returns a healthz checker named "ldap" which checks the manager can bind to the server.
+func NewHealthChecker(options *Options) healthz.HealthChecker {
+	return healthz.NamedCheck("ldap", func(_ *http.Request) error {
+		c, err := net.DialTimeout("tcp", options.Host, healthz.CheckTimeout)
+		if err != nil {
+			return err
+		}
+		conn := ldap.NewConn(c, false)
+		conn.SetTimeout(healthz.CheckTimeout)
+		conn.Start()
+		defer conn.Close()
+		return conn.Bind(options.ManagerDN, options.ManagerPassword)
+	})
+}
@@ -0,0 +1,28 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"strings"
+
+	"kubesphere.io/kubesphere/pkg/server/healthz"
+)
+
+// NewHealthChecker returns a healthz checker named "monitoring" which checks prometheus is ready to serve queries.
+func NewHealthChecker(options *Options) healthz.HealthChecker {
+	return healthz.HTTPCheck("monitoring", strings.TrimSuffix(options.Endpoint, "/")+"/-/ready", nil)
+}
@@ -51,6 +51,9 @@ func parseS3Url(parse *url.URL) (region, endpoint, bucket, path string) {
 }
 
 func loadData(ctx context.Context, u string, cred *v1alpha1.HelmRepoCredential) (*bytes.Buffer, error) {
+	if IsOCIRepo(u) {
+		return loadOCIChart(ctx, u, cred)
+	}
 	parsedURL, err := url.Parse(u)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,398 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmrepoindex
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/registry"
+	helmrepo "helm.sh/helm/v3/pkg/repo"
+	"k8s.io/klog/v2"
+	orasregistry "oras.land/oras-go/pkg/registry"
+	"oras.land/oras-go/pkg/registry/remote"
+	"oras.land/oras-go/pkg/registry/remote/auth"
+
+	"kubesphere.io/api/application/v1alpha1"
+)
+
+const (
+	OCIScheme = registry.OCIScheme + "://"
+
+	// maximum size of the manifest and chart config
+	ociMaxMetadataBytes = 4 * 1024 * 1024
+	// maximum size of the chart package
+	ociMaxChartBytes = 20 * 1024 * 1024
+)
+
+// IsOCIRepo returns whether the url refers to an OCI registry, e.g. oci://harbor.example.com/library
+func IsOCIRepo(u string) bool {
+	return strings.HasPrefix(u, OCIScheme)
+}
+
+// ociClient works with the Helm charts stored as OCI artifacts.
+type ociClient struct {
+	host   string
+	client *auth.Client
+	// plainHTTP is opted in by the repo explicitly, https is never downgraded
+	plainHTTP  bool
+	httpClient *http.Client
+}
+
+func newOCIClient(host string, cred *v1alpha1.HelmRepoCredential) (*ociClient, error) {
+	tlsConfig, err := newTLSConfig(cred)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{
+		Timeout:   5 * time.Minute,
+		Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig},
+	}
+	credential := auth.EmptyCredential
+	if cred.Username != "" || cred.Password != "" {
+		credential = auth.Credential{Username: cred.Username, Password: cred.Password}
+	}
+	return &ociClient{
+		host:       host,
+		plainHTTP:  cred.PlainHTTP,
+		httpClient: httpClient,
+		client: &auth.Client{
+			Client: httpClient,
+			Cache:  auth.NewCache(),
+			Credential: func(context.Context, string) (auth.Credential, error) {
+				return credential, nil
+			},
+		},
+	}, nil
+}
+
+func newTLSConfig(cred *v1alpha1.HelmRepoCredential) (*tls.Config, error) {
+	// keep consistent with the http repos, tls verification is skipped by default
+	config := &tls.Config{InsecureSkipVerify: cred.InsecureSkipTLSVerify == nil || *cred.InsecureSkipTLSVerify}
+	if cred.CertFile != "" && cred.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cred.CertFile, cred.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if cred.CAFile != "" {
+		data, err := os.ReadFile(cred.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("failed to append certificates from ca file %s", cred.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// parseOCIURL splits oci://host/path into host and path, the credential in the url is ignored.
+func parseOCIURL(u string) (host, path string, err error) {
+	parsedURL, err := url.Parse(u)
+	if err != nil {
+		return "", "", err
+	}
+	if parsedURL.Scheme != registry.OCIScheme || parsedURL.Host == "" {
+		return "", "", fmt.Errorf("invalid oci url %s", u)
+	}
+	return parsedURL.Host, strings.Trim(parsedURL.Path, "/"), nil
+}
+
+// do sends the request to the registry over https, or plain http if the repo opts in.
+func (c *ociClient) do(ctx context.Context, path string, accept ...string) (*http.Response, error) {
+	scheme := "https"
+	if c.plainHTTP {
+		scheme = "http"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s%s", scheme, c.host, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("GET %s: unexpected status code %d: %s", req.URL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+func (c *ociClient) get(ctx context.Context, path string, limit int64, accept ...string) ([]byte, *http.Response, error) {
+	resp, err := c.do(ctx, path, accept...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, nil, fmt.Errorf("GET %s: response exceeds the limit of %d bytes", path, limit)
+	}
+	return data, resp, nil
+}
+
+// repositories lists the repositories under the namespace by the catalog api.
+func (c *ociClient) repositories(ctx context.Context, namespace string) ([]string, error) {
+	ctx = auth.AppendScopes(ctx, auth.ScopeRegistryCatalog)
+	var result []string
+	path := "/v2/_catalog?n=1000"
+	for path != "" {
+		data, resp, err := c.get(ctx, path, ociMaxMetadataBytes)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Repositories []string `json:"repositories"`
+		}
+		if err = json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		for _, repository := range page.Repositories {
+			if namespace == "" || strings.HasPrefix(repository, namespace+"/") {
+				result = append(result, repository)
+			}
+		}
+		path = nextLink(resp)
+	}
+	return result, nil
+}
+
+// nextLink returns the path of the next page from the Link header.
+func nextLink(resp *http.Response) string {
+	link := resp.Header.Get("Link")
+	start, end := strings.IndexByte(link, '<'), strings.IndexByte(link, '>')
+	if start != 0 || end < start {
+		return ""
+	}
+	next, err := resp.Request.URL.Parse(link[1:end])
+	if err != nil {
+		return ""
+	}
+	return next.RequestURI()
+}
+
+// tags lists the semver compliant tags of the repository.
+func (c *ociClient) tags(ctx context.Context, repository string) ([]string, error) {
+	repo := &remote.Repository{
+		Client:    c.client,
+		Reference: orasregistry.Reference{Registry: c.host, Repository: repository},
+		PlainHTTP: c.plainHTTP,
+	}
+	var tags []string
+	err := repo.Tags(ctx, func(page []string) error {
+		for _, tag := range page {
+			// Helm replaces the plus sign (+) of the chart version with underscore (_)
+			if _, err := semver.StrictNewVersion(strings.ReplaceAll(tag, "_", "+")); err == nil {
+				tags = append(tags, tag)
+			}
+		}
+		return nil
+	})
+	return tags, err
+}
+
+func (c *ociClient) manifest(ctx context.Context, repository, reference string) (*ocispec.Manifest, error) {
+	ctx = auth.AppendScopes(ctx, auth.ScopeRepository(repository, auth.ActionPull))
+	data, _, err := c.get(ctx, fmt.Sprintf("/v2/%s/manifests/%s", repository, reference), ociMaxMetadataBytes, ocispec.MediaTypeImageManifest)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &ocispec.Manifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (c *ociClient) blob(ctx context.Context, repository string, descriptor ocispec.Descriptor, limit int64) ([]byte, error) {
+	ctx = auth.AppendScopes(ctx, auth.ScopeRepository(repository, auth.ActionPull))
+	data, _, err := c.get(ctx, fmt.Sprintf("/v2/%s/blobs/%s", repository, descriptor.Digest), limit)
+	if err != nil {
+		return nil, err
+	}
+	if err = descriptor.Digest.Validate(); err != nil {
+		return nil, err
+	}
+	if descriptor.Digest.Algorithm().FromBytes(data) != descriptor.Digest {
+		return nil, fmt.Errorf("digest mismatch of blob %s", descriptor.Digest)
+	}
+	return data, nil
+}
+
+// chartVersion loads the chart metadata from the manifest config.
+func (c *ociClient) chartVersion(ctx context.Context, repository, tag string) (*helmrepo.ChartVersion, error) {
+	manifest, err := c.manifest(ctx, repository, tag)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Config.MediaType != registry.ConfigMediaType {
+		return nil, fmt.Errorf("%s:%s is not a helm chart", repository, tag)
+	}
+	layer := chartLayer(manifest)
+	if layer == nil {
+		return nil, fmt.Errorf("chart layer of %s:%s not found", repository, tag)
+	}
+	data, err := c.blob(ctx, repository, manifest.Config, ociMaxMetadataBytes)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &chart.Metadata{}
+	if err = json.Unmarshal(data, metadata); err != nil {
+		return nil, err
+	}
+	created := time.Now()
+	if value, ok := manifest.Annotations[ocispec.AnnotationCreated]; ok {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			created = t
+		}
+	}
+	return &helmrepo.ChartVersion{
+		Metadata: metadata,
+		URLs:     []string{fmt.Sprintf("%s%s/%s:%s", OCIScheme, c.host, repository, tag)},
+		Created:  created,
+		Digest:   layer.Digest.Encoded(),
+	}, nil
+}
+
+func chartLayer(manifest *ocispec.Manifest) *ocispec.Descriptor {
+	for i := range manifest.Layers {
+		switch manifest.Layers[i].MediaType {
+		case registry.ChartLayerMediaType, registry.LegacyChartLayerMediaType:
+			return &manifest.Layers[i]
+		}
+	}
+	return nil
+}
+
+func provenanceLayer(manifest *ocispec.Manifest) *ocispec.Descriptor {
+	for i := range manifest.Layers {
+		if manifest.Layers[i].MediaType == registry.ProvLayerMediaType {
+			return &manifest.Layers[i]
+		}
+	}
+	return nil
+}
+
+// loadOCIRepoIndex builds the index of the charts under the url. The url refers to either a namespace
+// of the registry, e.g. oci://harbor.example.com/library, or a single chart, e.g. oci://harbor.example.com/library/nginx.
+func loadOCIRepoIndex(ctx context.Context, u string, cred *v1alpha1.HelmRepoCredential) (*helmrepo.IndexFile, error) {
+	host, namespace, err := parseOCIURL(u)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newOCIClient(host, cred)
+	if err != nil {
+		return nil, err
+	}
+	repositories, err := client.repositories(ctx, namespace)
+	if err != nil {
+		// the catalog api is optional, e.g. Harbor requires the system admin permission
+		klog.V(4).Infof("list repositories of %s failed, fall back to a single chart: %v", u, err)
+	}
+	if len(repositories) == 0 {
+		if namespace == "" {
+			return nil, fmt.Errorf("no repository found in %s", u)
+		}
+		repositories = []string{namespace}
+	}
+
+	index := helmrepo.NewIndexFile()
+	for _, repository := range repositories {
+		tags, err := client.tags(ctx, repository)
+		if err != nil {
+			klog.Warningf("list tags of %s/%s failed: %v", host, repository, err)
+			// the repository is specified explicitly
+			if len(repositories) == 1 {
+				return nil, err
+			}
+			continue
+		}
+		for _, tag := range tags {
+			chartVersion, err := client.chartVersion(ctx, repository, tag)
+			if err != nil {
+				// the repository may contain other artifacts
+				klog.V(4).Infof("load chart %s/%s:%s failed: %v", host, repository, tag, err)
+				continue
+			}
+			index.Entries[chartVersion.Name] = append(index.Entries[chartVersion.Name], chartVersion)
+		}
+	}
+	index.SortEntries()
+	return index, nil
+}
+
+// loadOCIChart pulls the chart package, e.g. oci://harbor.example.com/library/nginx:1.0.0
+func loadOCIChart(ctx context.Context, u string, cred *v1alpha1.HelmRepoCredential) (*bytes.Buffer, error) {
+	return loadOCILayer(ctx, u, cred, chartLayer)
+}
+
+// loadOCILayer pulls the layer of the chart selected by the layer func
+func loadOCILayer(ctx context.Context, u string, cred *v1alpha1.HelmRepoCredential, selectLayer func(*ocispec.Manifest) *ocispec.Descriptor) (*bytes.Buffer, error) {
+	host, path, err := parseOCIURL(u)
+	if err != nil {
+		return nil, err
+	}
+	reference, err := orasregistry.ParseReference(fmt.Sprintf("%s/%s", host, path))
+	if err != nil {
+		return nil, err
+	}
+	if reference.Reference == "" {
+		return nil, fmt.Errorf("chart version is required in %s", u)
+	}
+	client, err := newOCIClient(host, cred)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := client.manifest(ctx, reference.Repository, reference.Reference)
+	if err != nil {
+		return nil, err
+	}
+	layer := selectLayer(manifest)
+	if layer == nil {
+		return nil, fmt.Errorf("layer of %s not found", u)
+	}
+	data, err := client.blob(ctx, reference.Repository, *layer, ociMaxChartBytes)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(data), nil
+}
@@ -40,6 +40,10 @@ import (
 const IndexYaml = "index.yaml"
 
 func LoadRepoIndex(ctx context.Context, u string, cred *v1alpha1.HelmRepoCredential) (*helmrepo.IndexFile, error) {
+	// OCI registries have no index.yaml, the index is built from the chart artifacts
+	if IsOCIRepo(u) {
+		return loadOCIRepoIndex(ctx, u, cred)
+	}
 
 	if !strings.HasSuffix(u, "/") {
 		u = fmt.Sprintf("%s/%s", u, IndexYaml)
@@ -95,6 +99,9 @@ func MergeRepoIndex(repo *v1alpha1.HelmRepo, index *helmrepo.IndexFile, existsSa
 	saved.APIVersion = index.APIVersion
 	saved.Generated = index.Generated
 	saved.PublicKeys = index.PublicKeys
+	if existsSavedIndex != nil {
+		saved.VerificationDigest = existsSavedIndex.VerificationDigest
+	}
 
 	allAppNames := make(map[string]struct{}, len(index.Entries))
 	for name, versions := range index.Entries {
@@ -215,6 +222,9 @@ func (i *SavedIndex) GetApplicationVersion(appId, versionId string) *v1alpha1.He
 								Annotations: ver.Annotations,
 							},
 						},
+						Status: v1alpha1.HelmApplicationVersionStatus{
+							Verification: ver.Verification,
+						},
 					}
 					return version
 				}
@@ -249,6 +259,8 @@ type SavedIndex struct {
 	Generated    time.Time               `json:"generated"`
 	Applications map[string]*Application `json:"apps"`
 	PublicKeys   []string                `json:"publicKeys,omitempty"`
+	// digest of the keys which are used to verify the charts, the charts will be verified again if the keys change
+	VerificationDigest string `json:"verificationDigest,omitempty"`
 
 	// Annotations are additional mappings uninterpreted by Helm. They are made available for
 	// other applications to add information to the index file.
@@ -319,6 +331,8 @@ type ChartVersion struct {
 	ApplicationId         string `json:"-"`
 	ApplicationVersionId  string `json:"verId"`
 	helmrepo.ChartVersion `json:",inline"`
+	// nil if the chart has not been verified
+	Verification *v1alpha1.ChartVerificationStatus `json:"verification,omitempty"`
 }
 
 type Application struct {
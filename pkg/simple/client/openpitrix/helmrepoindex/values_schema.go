@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmrepoindex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/xeipuuv/gojsonschema"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// ValuesError is a field of the values which violates the values.schema.json of the chart
+type ValuesError struct {
+	// path of the field, e.g. service.port, the values of the subcharts are prefixed with the name of the subchart
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// LoadValuesSchema returns the values.schema.json of the chart package, and the schemas of the subcharts
+// indexed by the path of the subchart values, e.g. mysql. The schema is nil if the chart has no schema.
+// The subcharts disabled by the default values are skipped, and the aliased ones are indexed by their aliases.
+func LoadValuesSchema(pkg []byte) (json.RawMessage, map[string]json.RawMessage, error) {
+	chrt, err := loader.LoadArchive(bytes.NewReader(pkg))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = chartutil.ProcessDependencies(chrt, chartutil.Values{}); err != nil {
+		return nil, nil, err
+	}
+
+	subcharts := make(map[string]json.RawMessage)
+	var walk func(prefix string, c *chart.Chart)
+	walk = func(prefix string, c *chart.Chart) {
+		for _, sub := range c.Dependencies() {
+			path := joinField(prefix, sub.Name())
+			if len(sub.Schema) > 0 {
+				subcharts[path] = sub.Schema
+			}
+			walk(path, sub)
+		}
+	}
+	walk("", chrt)
+
+	var schema json.RawMessage
+	if len(chrt.Schema) > 0 {
+		schema = chrt.Schema
+	}
+	return schema, subcharts, nil
+}
+
+// ValidateValues merges the values with the default values of the chart package, and validates them against
+// the values.schema.json of the chart and the subcharts. The fields which violate the schemas are returned.
+// Like helm install, the conditions, tags and aliases of the dependencies are processed first, so that the
+// disabled subcharts are not validated and the aliased ones are validated against their aliased values.
+func ValidateValues(pkg []byte, values []byte) ([]ValuesError, error) {
+	chrt, err := loader.LoadArchive(bytes.NewReader(pkg))
+	if err != nil {
+		return nil, err
+	}
+	vals, err := chartutil.ReadValues(values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid values: %v", err)
+	}
+	if err = chartutil.ProcessDependencies(chrt, vals); err != nil {
+		return nil, fmt.Errorf("invalid dependencies of chart %s: %v", chrt.Name(), err)
+	}
+	coalesced, err := chartutil.CoalesceValues(chrt, vals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid values: %v", err)
+	}
+	return validateChartValues("", chrt, coalesced)
+}
+
+func validateChartValues(prefix string, chrt *chart.Chart, values map[string]interface{}) (errs []ValuesError, reterr error) {
+	defer func() {
+		// the schema may be malformed
+		if r := recover(); r != nil {
+			reterr = fmt.Errorf("unable to validate values of chart %s: %v", chrt.Name(), r)
+		}
+	}()
+
+	if len(chrt.Schema) > 0 {
+		data, err := json.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(data, []byte("null")) {
+			data = []byte("{}")
+		}
+		result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(chrt.Schema), gojsonschema.NewBytesLoader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid values schema of chart %s: %v", chrt.Name(), err)
+		}
+		for _, e := range result.Errors() {
+			field := e.Field()
+			if field == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+				field = ""
+			}
+			// point to the missing field instead of its parent
+			if property, ok := e.Details()["property"].(string); ok && e.Type() == "required" {
+				field = joinField(field, property)
+			}
+			errs = append(errs, ValuesError{Field: joinField(prefix, field), Message: e.Description()})
+		}
+	}
+
+	for _, sub := range chrt.Dependencies() {
+		subValues, ok := values[sub.Name()].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		subErrs, err := validateChartValues(joinField(prefix, sub.Name()), sub, subValues)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, subErrs...)
+	}
+	return errs, nil
+}
+
+func joinField(prefix, field string) string {
+	if prefix == "" {
+		return field
+	}
+	if field == "" {
+		return prefix
+	}
+	return prefix + "." + field
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmrepoindex
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const nginxSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["replicaCount", "service"],
+  "properties": {
+    "replicaCount": {"type": "integer", "minimum": 1},
+    "service": {
+      "type": "object",
+      "required": ["port"],
+      "properties": {"port": {"type": "integer"}}
+    }
+  }
+}`
+
+const redisSchema = `{
+  "type": "object",
+  "properties": {"password": {"type": "string", "minLength": 8}}
+}`
+
+func newChartPackage(t *testing.T) []byte {
+	redis := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "redis", Version: "0.1.0"},
+		Raw:      []*chart.File{{Name: chartutil.ValuesfileName, Data: []byte("password: P@88w0rd\n")}},
+		Schema:   []byte(redisSchema),
+	}
+	nginx := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "nginx", Version: "0.1.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "redis", Version: "0.1.0", Condition: "redis.enabled"},
+				{Name: "redis", Version: "0.1.0", Alias: "cache"},
+			},
+		},
+		Raw:    []*chart.File{{Name: chartutil.ValuesfileName, Data: []byte("replicaCount: 1\nservice:\n  port: 80\n")}},
+		Schema: []byte(nginxSchema),
+	}
+	nginx.AddDependency(redis)
+
+	path, err := chartutil.Save(nginx, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestLoadValuesSchema(t *testing.T) {
+	schema, subcharts, err := LoadValuesSchema(newChartPackage(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(schema) != nginxSchema {
+		t.Errorf("unexpected schema: %s", schema)
+	}
+	if len(subcharts) != 2 || string(subcharts["redis"]) != redisSchema || string(subcharts["cache"]) != redisSchema {
+		t.Errorf("unexpected subchart schemas: %v", subcharts)
+	}
+}
+
+func TestValidateValues(t *testing.T) {
+	pkg := newChartPackage(t)
+
+	tests := []struct {
+		name     string
+		values   string
+		expected []string
+	}{
+		{
+			name: "default values",
+		},
+		{
+			name:   "valid values",
+			values: "replicaCount: 3\nservice:\n  port: 8080\n",
+		},
+		{
+			name:     "invalid type",
+			values:   "replicaCount: three\n",
+			expected: []string{"replicaCount"},
+		},
+		{
+			name:     "required field removed",
+			values:   "service:\n  port: null\n",
+			expected: []string{"service.port"},
+		},
+		{
+			name:     "invalid subchart values",
+			values:   "replicaCount: 0\nredis:\n  password: short\n",
+			expected: []string{"replicaCount", "redis.password"},
+		},
+		{
+			name:   "disabled subchart",
+			values: "redis:\n  enabled: false\n  password: short\n",
+		},
+		{
+			name:     "invalid aliased subchart values",
+			values:   "cache:\n  password: short\n",
+			expected: []string{"cache.password"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := ValidateValues(pkg, []byte(tt.values))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields []string
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.expected) {
+				t.Errorf("got %v, want %v: %v", fields, tt.expected, errs)
+			}
+		})
+	}
+
+	if _, err := ValidateValues(pkg, []byte("replicaCount: [")); err == nil {
+		t.Error("expected error of malformed values")
+	}
+}
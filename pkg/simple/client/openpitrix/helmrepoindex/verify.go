@@ -0,0 +1,307 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmrepoindex
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"           //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/clearsign" //nolint:staticcheck
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"kubesphere.io/api/application/v1alpha1"
+)
+
+const (
+	// ProvenanceSuffix is appended to the chart url to get the provenance file
+	ProvenanceSuffix = ".prov"
+	// SignatureSuffix is appended to the chart url to get the cosign signature
+	SignatureSuffix = ".sig"
+)
+
+// ErrChartNotVerified is returned if the chart is not allowed to be deployed
+var ErrChartNotVerified = errors.New("chart is not verified")
+
+// CheckVerification returns an error if the chart with the verification status should not be deployed.
+// The charts whose verification failed are always rejected, and unsigned charts are rejected if required is true.
+func CheckVerification(status *v1alpha1.ChartVerificationStatus, required bool) error {
+	if status != nil && status.State == v1alpha1.VerificationStateFailed {
+		return fmt.Errorf("%w: %s", ErrChartNotVerified, status.Message)
+	}
+	if required && (status == nil || status.State != v1alpha1.VerificationStateVerified) {
+		return ErrChartNotVerified
+	}
+	return nil
+}
+
+// CheckChartDigest returns an error if the chart package to deploy is not the one verified, e.g. the chart was
+// replaced in the repo after it was verified. The charts verified without a digest are never trusted.
+func CheckChartDigest(status *v1alpha1.ChartVerificationStatus, chartData []byte) error {
+	if status == nil || status.State != v1alpha1.VerificationStateVerified {
+		return nil
+	}
+	if status.Digest == "" {
+		return fmt.Errorf("%w: the digest of the chart verified is unknown", ErrChartNotVerified)
+	}
+	if digest := chartDigest(chartData); digest != status.Digest {
+		return fmt.Errorf("%w: digest %s of the chart differs from digest %s verified", ErrChartNotVerified, digest, status.Digest)
+	}
+	return nil
+}
+
+func chartDigest(chartData []byte) string {
+	sum := sha256.Sum256(chartData)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// VerifyChart verifies the chart package with the provenance file or the cosign signature, the provenance file
+// is preferred if both are provided. The chart is unsigned if there is no signature which can be verified by the keys.
+func VerifyChart(chartData, provenance, signature []byte, keys *v1alpha1.ChartVerification) *v1alpha1.ChartVerificationStatus {
+	now := metav1.Now()
+	status := &v1alpha1.ChartVerificationStatus{Digest: chartDigest(chartData), Time: &now}
+	if keys == nil {
+		keys = &v1alpha1.ChartVerification{}
+	}
+
+	var err error
+	switch {
+	case len(provenance) > 0 && keys.Keyring != "":
+		status.Method = v1alpha1.VerificationMethodPGP
+		status.Signer, err = verifyProvenance(chartData, provenance, keys.Keyring)
+	case len(signature) > 0 && keys.CosignKey != "":
+		status.Method = v1alpha1.VerificationMethodCosign
+		err = verifySignature(chartData, signature, keys.CosignKey)
+	default:
+		status.State = v1alpha1.VerificationStateUnsigned
+		if keys.Keyring == "" && keys.CosignKey == "" {
+			status.Message = "no key is configured to verify the chart"
+		} else {
+			status.Message = "no signature found"
+		}
+		return status
+	}
+
+	if err != nil {
+		status.State = v1alpha1.VerificationStateFailed
+		status.Message = err.Error()
+	} else {
+		status.State = v1alpha1.VerificationStateVerified
+	}
+	return status
+}
+
+// verifyProvenance checks the PGP signature of the provenance file, and the sha256 sum of the chart in it.
+// The identity of the signer is returned.
+func verifyProvenance(chartData, provenance []byte, keyring string) (string, error) {
+	keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(keyring))
+	if err != nil {
+		return "", fmt.Errorf("invalid keyring: %v", err)
+	}
+
+	block, _ := clearsign.Decode(provenance)
+	if block == nil {
+		return "", errors.New("signature block not found in the provenance file")
+	}
+	signer, err := openpgp.CheckDetachedSignature(keys, bytes.NewBuffer(block.Bytes), block.ArmoredSignature.Body)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature: %v", err)
+	}
+
+	// the message block is the Chart.yaml and the sums of the files, separated by "..."
+	parts := bytes.SplitN(block.Plaintext, []byte("\n...\n"), 2)
+	if len(parts) != 2 {
+		return "", errors.New("message block must have at least two parts")
+	}
+	sums := struct {
+		Files map[string]string `json:"files"`
+	}{}
+	if err = yaml.Unmarshal(parts[1], &sums); err != nil {
+		return "", fmt.Errorf("invalid message block: %v", err)
+	}
+
+	digest := chartDigest(chartData)
+	for _, s := range sums.Files {
+		if s == digest {
+			return signerIdentity(signer), nil
+		}
+	}
+	return "", fmt.Errorf("sha256 sum %s of the chart is not found in the provenance file", digest)
+}
+
+// signerIdentity returns the primary identity of the signer, or the first identity in order if none is primary.
+func signerIdentity(entity *openpgp.Entity) string {
+	names := make([]string, 0, len(entity.Identities))
+	for name, identity := range entity.Identities {
+		if identity.SelfSignature != nil && identity.SelfSignature.IsPrimaryId != nil && *identity.SelfSignature.IsPrimaryId {
+			return name
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return entity.PrimaryKey.KeyIdString()
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// verifySignature checks the signature created by `cosign sign-blob --key`, which is base64 encoded.
+func verifySignature(chartData, signature []byte, key string) error {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return errors.New("invalid cosign key: PEM block not found")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid cosign key: %v", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+
+	digest := sha256.Sum256(chartData)
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest[:], sig) {
+			return errors.New("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig); err != nil {
+			return fmt.Errorf("invalid signature: %v", err)
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, chartData, sig) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", pub)
+	}
+	return nil
+}
+
+// LoadProvenance loads the provenance file of the chart, the file is stored next to the chart package
+// in http and s3 repos, or as a layer of the chart in oci registries.
+func LoadProvenance(ctx context.Context, u string, cred *v1alpha1.HelmRepoCredential) ([]byte, error) {
+	if IsOCIRepo(u) {
+		buf, err := loadOCILayer(ctx, u, cred, provenanceLayer)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	buf, err := loadData(ctx, u+ProvenanceSuffix, cred)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// LoadSignature loads the cosign signature stored next to the chart package, oci registries are not supported.
+func LoadSignature(ctx context.Context, u string, cred *v1alpha1.HelmRepoCredential) ([]byte, error) {
+	if IsOCIRepo(u) {
+		return nil, errors.New("cosign signatures in oci registries are not supported")
+	}
+	buf, err := loadData(ctx, u+SignatureSuffix, cred)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// VerifyCharts verifies at most limit chart versions in the index with the keys of the repo, until ctx is done.
+// The verification status is kept until the keys change, so only the new versions are downloaded. It returns whether
+// the index is changed, and whether there are versions left to verify because of the limit or the deadline.
+func (i *SavedIndex) VerifyCharts(ctx context.Context, repo *v1alpha1.HelmRepo, limit int) (changed bool, pending bool) {
+	digest := verificationDigest(repo.Spec.Verification)
+	if digest != i.VerificationDigest {
+		for _, app := range i.Applications {
+			for _, ver := range app.Charts {
+				ver.Verification = nil
+			}
+		}
+		i.VerificationDigest = digest
+		changed = true
+	}
+	if repo.Spec.Verification == nil {
+		return changed, false
+	}
+
+	verified := 0
+	for _, app := range i.Applications {
+		for _, ver := range app.Charts {
+			// the charts verified without a digest are verified again to pin their digests
+			if (ver.Verification != nil && ver.Verification.Digest != "") || len(ver.URLs) == 0 {
+				continue
+			}
+			if verified >= limit || ctx.Err() != nil {
+				return changed, true
+			}
+			verified++
+			u := ChartURL(repo, ver.URLs[0])
+			chartData, err := LoadChart(ctx, u, &repo.Spec.Credential)
+			if err != nil {
+				// try again in the next verification
+				klog.Errorf("load chart %s failed, repo: %s, error: %s", u, repo.Name, err)
+				continue
+			}
+			provenance, err := LoadProvenance(ctx, u, &repo.Spec.Credential)
+			if err != nil {
+				klog.V(4).Infof("load provenance of chart %s failed, error: %s", u, err)
+			}
+			signature, err := LoadSignature(ctx, u, &repo.Spec.Credential)
+			if err != nil {
+				klog.V(4).Infof("load signature of chart %s failed, error: %s", u, err)
+			}
+			ver.Verification = VerifyChart(chartData.Bytes(), provenance, signature, repo.Spec.Verification)
+			changed = true
+			klog.V(4).Infof("chart %s verified, repo: %s, state: %s", u, repo.Name, ver.Verification.State)
+		}
+	}
+	return changed, false
+}
+
+func verificationDigest(keys *v1alpha1.ChartVerification) string {
+	if keys == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(keys.Keyring + "\n" + keys.CosignKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// ChartURL returns the absolute url of the chart, the url in the index may be relative to the repo url
+func ChartURL(repo *v1alpha1.HelmRepo, u string) string {
+	if strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "s3://") || IsOCIRepo(u) {
+		return u
+	}
+	return repo.Spec.Url + "/" + u
+}
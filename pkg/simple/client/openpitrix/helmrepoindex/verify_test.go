@@ -0,0 +1,298 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmrepoindex
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"           //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/armor"     //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/clearsign" //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/packet"    //nolint:staticcheck
+	helmrepo "helm.sh/helm/v3/pkg/repo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubesphere.io/api/application/v1alpha1"
+)
+
+func newProvenance(t *testing.T, entity *openpgp.Entity, chartData []byte) []byte {
+	sum := sha256.Sum256(chartData)
+	message := fmt.Sprintf("apiVersion: v2\nname: nginx\nversion: 0.1.0\n\n...\nfiles:\n  nginx-0.1.0.tgz: sha256:%s\n", hex.EncodeToString(sum[:]))
+
+	buf := &bytes.Buffer{}
+	w, err := clearsign.Encode(buf, entity.PrivateKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte(message)); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func armoredKeyring(t *testing.T, entity *openpgp.Entity) string {
+	buf := &bytes.Buffer{}
+	w, err := armor.Encode(buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestVerifyChart(t *testing.T) {
+	chartData := []byte("chart package")
+
+	signer, err := openpgp.NewEntity("kubesphere", "", "kubesphere@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := openpgp.NewEntity("other", "", "other@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provenance := newProvenance(t, signer, chartData)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cosignKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	digest := sha256.Sum256(chartData)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := []byte(base64.StdEncoding.EncodeToString(sig))
+
+	tests := []struct {
+		name       string
+		chartData  []byte
+		provenance []byte
+		signature  []byte
+		keys       *v1alpha1.ChartVerification
+		state      string
+		method     string
+	}{
+		{
+			name:      "no keys",
+			chartData: chartData, provenance: provenance,
+			state: v1alpha1.VerificationStateUnsigned,
+		},
+		{
+			name:      "no signature",
+			chartData: chartData,
+			keys:      &v1alpha1.ChartVerification{Keyring: armoredKeyring(t, signer)},
+			state:     v1alpha1.VerificationStateUnsigned,
+		},
+		{
+			name:      "pgp verified",
+			chartData: chartData, provenance: provenance,
+			keys:   &v1alpha1.ChartVerification{Keyring: armoredKeyring(t, signer)},
+			state:  v1alpha1.VerificationStateVerified,
+			method: v1alpha1.VerificationMethodPGP,
+		},
+		{
+			name:      "pgp unknown signer",
+			chartData: chartData, provenance: provenance,
+			keys:   &v1alpha1.ChartVerification{Keyring: armoredKeyring(t, other)},
+			state:  v1alpha1.VerificationStateFailed,
+			method: v1alpha1.VerificationMethodPGP,
+		},
+		{
+			name:      "pgp chart tampered",
+			chartData: []byte("tampered"), provenance: provenance,
+			keys:   &v1alpha1.ChartVerification{Keyring: armoredKeyring(t, signer)},
+			state:  v1alpha1.VerificationStateFailed,
+			method: v1alpha1.VerificationMethodPGP,
+		},
+		{
+			name:      "cosign verified",
+			chartData: chartData, signature: signature,
+			keys:   &v1alpha1.ChartVerification{CosignKey: cosignKey},
+			state:  v1alpha1.VerificationStateVerified,
+			method: v1alpha1.VerificationMethodCosign,
+		},
+		{
+			name:      "cosign chart tampered",
+			chartData: []byte("tampered"), signature: signature,
+			keys:   &v1alpha1.ChartVerification{CosignKey: cosignKey},
+			state:  v1alpha1.VerificationStateFailed,
+			method: v1alpha1.VerificationMethodCosign,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := VerifyChart(tt.chartData, tt.provenance, tt.signature, tt.keys)
+			if status.State != tt.state || status.Method != tt.method {
+				t.Errorf("got %s %s, want %s %s: %s", status.State, status.Method, tt.state, tt.method, status.Message)
+			}
+			if status.State == v1alpha1.VerificationStateVerified && tt.method == v1alpha1.VerificationMethodPGP &&
+				status.Signer != "kubesphere <kubesphere@example.com>" {
+				t.Errorf("unexpected signer %s", status.Signer)
+			}
+		})
+	}
+}
+
+func TestCheckVerification(t *testing.T) {
+	verified := &v1alpha1.ChartVerificationStatus{State: v1alpha1.VerificationStateVerified}
+	unsigned := &v1alpha1.ChartVerificationStatus{State: v1alpha1.VerificationStateUnsigned}
+	failed := &v1alpha1.ChartVerificationStatus{State: v1alpha1.VerificationStateFailed, Message: "invalid signature"}
+
+	tests := []struct {
+		status   *v1alpha1.ChartVerificationStatus
+		required bool
+		allowed  bool
+	}{
+		{nil, false, true},
+		{nil, true, false},
+		{verified, true, true},
+		{unsigned, false, true},
+		{unsigned, true, false},
+		{failed, false, false},
+	}
+	for i, tt := range tests {
+		if err := CheckVerification(tt.status, tt.required); (err == nil) != tt.allowed {
+			t.Errorf("case %d: got error %v, want allowed %v", i, err, tt.allowed)
+		}
+	}
+}
+
+func TestCheckChartDigest(t *testing.T) {
+	chartData := []byte("chart package")
+	verified := VerifyChart(chartData, nil, nil, nil)
+	verified.State = v1alpha1.VerificationStateVerified
+
+	if err := CheckChartDigest(verified, chartData); err != nil {
+		t.Errorf("expected the chart verified to be allowed, got %v", err)
+	}
+	if err := CheckChartDigest(verified, []byte("replaced chart package")); !errors.Is(err, ErrChartNotVerified) {
+		t.Errorf("expected the chart replaced to be rejected, got %v", err)
+	}
+	if err := CheckChartDigest(&v1alpha1.ChartVerificationStatus{State: v1alpha1.VerificationStateVerified}, chartData); err == nil {
+		t.Errorf("expected the chart verified without a digest to be rejected")
+	}
+	if err := CheckChartDigest(&v1alpha1.ChartVerificationStatus{State: v1alpha1.VerificationStateUnsigned}, chartData); err != nil {
+		t.Errorf("expected the unsigned chart to be checked by CheckVerification only, got %v", err)
+	}
+}
+
+func TestSignerIdentity(t *testing.T) {
+	signer, err := openpgp.NewEntity("kubesphere", "", "kubesphere@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer.Identities["a"] = &openpgp.Identity{Name: "a", SelfSignature: &packet.Signature{}}
+	for i := 0; i < 10; i++ {
+		if identity := signerIdentity(signer); identity != "kubesphere <kubesphere@example.com>" {
+			t.Fatalf("unexpected identity %s", identity)
+		}
+	}
+
+	// the first identity in order if none is primary
+	for _, identity := range signer.Identities {
+		identity.SelfSignature = &packet.Signature{}
+	}
+	if identity := signerIdentity(signer); identity != "a" {
+		t.Errorf("unexpected identity %s", identity)
+	}
+}
+
+func TestVerifyCharts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".tgz") {
+			w.Write([]byte("chart package"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	repo := &v1alpha1.HelmRepo{
+		ObjectMeta: metav1.ObjectMeta{Name: "repo"},
+		Spec: v1alpha1.HelmRepoSpec{
+			Url:          server.URL,
+			Verification: &v1alpha1.ChartVerification{CosignKey: "key"},
+		},
+	}
+	app := &Application{Name: "nginx"}
+	for _, version := range []string{"0.1.0", "0.2.0", "0.3.0"} {
+		app.Charts = append(app.Charts, &ChartVersion{
+			ChartVersion: helmrepo.ChartVersion{URLs: []string{"nginx-" + version + ".tgz"}},
+		})
+	}
+	index := &SavedIndex{Applications: map[string]*Application{"nginx": app}}
+
+	tests := []struct {
+		changed  bool
+		pending  bool
+		verified int
+	}{
+		{changed: true, pending: true, verified: 2},
+		{changed: true, pending: false, verified: 3},
+		{changed: false, pending: false, verified: 3},
+	}
+	for i, tt := range tests {
+		changed, pending := index.VerifyCharts(context.Background(), repo, 2)
+		verified := 0
+		for _, ver := range app.Charts {
+			if ver.Verification != nil {
+				verified++
+			}
+		}
+		if changed != tt.changed || pending != tt.pending || verified != tt.verified {
+			t.Errorf("%d: got %v %v %d, want %v %v %d", i, changed, pending, verified, tt.changed, tt.pending, tt.verified)
+		}
+	}
+
+	// the verification stops at the deadline
+	repo.Spec.Verification = &v1alpha1.ChartVerification{CosignKey: "other"}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if changed, pending := index.VerifyCharts(ctx, repo, 2); !changed || !pending {
+		t.Errorf("got %v %v, want the status reset and pending", changed, pending)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmwrapper
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog/v2"
+
+	"kubesphere.io/api/application/v1alpha1"
+)
+
+// Drift compares the resources in the manifest of the deployed release with the live objects,
+// and returns the resources which are deleted or modified in the cluster.
+func (c *helmWrapper) Drift() ([]v1alpha1.DriftedResource, error) {
+	if c.mock {
+		return nil, nil
+	}
+
+	manifest, err := c.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := c.helmConf.KubeClient.Build(bytes.NewBufferString(manifest), false)
+	if err != nil {
+		klog.Errorf("namespace: %s, name: %s, build resources failed, error: %v", c.Namespace, c.ReleaseName, err)
+		return nil, err
+	}
+
+	var drifted []v1alpha1.DriftedResource
+	for _, info := range resources {
+		desired, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+		if err != nil {
+			return nil, err
+		}
+		gvk := info.Object.GetObjectKind().GroupVersionKind()
+		r := v1alpha1.DriftedResource{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Namespace:  info.Namespace,
+			Name:       info.Name,
+		}
+
+		live, err := resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				r.Reason = v1alpha1.DriftReasonDeleted
+				drifted = append(drifted, r)
+				continue
+			}
+			return nil, err
+		}
+		liveObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+		if err != nil {
+			return nil, err
+		}
+
+		if field := divergedField(normalizeObject(desired), liveObj); field != "" {
+			r.Reason = v1alpha1.DriftReasonModified
+			r.Field = field
+			drifted = append(drifted, r)
+		}
+	}
+
+	klog.V(4).Infof("namespace: %s, name: %s, %d resources drifted", c.Namespace, c.ReleaseName, len(drifted))
+	return drifted, nil
+}
+
+// normalizeObject converts the desired object to the form stored by the api server,
+// so that it can be compared with the live object.
+func normalizeObject(obj map[string]interface{}) map[string]interface{} {
+	// the status is not managed by the release
+	delete(obj, "status")
+
+	// stringData of secrets is write-only, it's merged into data
+	u := &unstructured.Unstructured{Object: obj}
+	if u.GetKind() == "Secret" {
+		if stringData, found, _ := unstructured.NestedStringMap(obj, "stringData"); found {
+			data, _, _ := unstructured.NestedMap(obj, "data")
+			if data == nil {
+				data = make(map[string]interface{})
+			}
+			for k, v := range stringData {
+				data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+			}
+			delete(obj, "stringData")
+			_ = unstructured.SetNestedMap(obj, data, "data")
+		}
+	}
+	return obj
+}
+
+// divergedField returns the path of the first field in desired whose value differs from live,
+// or an empty string if all the fields in desired are the same in live. The fields set by the
+// api server or other controllers, which are absent from desired, are ignored.
+func divergedField(desired, live map[string]interface{}) string {
+	return divergedValue("", desired, live)
+}
+
+func divergedValue(path string, desired, live interface{}) string {
+	switch d := desired.(type) {
+	case nil:
+		// e.g. creationTimestamp: null
+		return ""
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return path
+		}
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			if field := divergedValue(p, d[k], l[k]); field != "" {
+				return field
+			}
+		}
+		return ""
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(d) {
+			return path
+		}
+		for i := range d {
+			if field := divergedValue(fmt.Sprintf("%s[%d]", path, i), d[i], l[i]); field != "" {
+				return field
+			}
+		}
+		return ""
+	default:
+		if reflect.DeepEqual(desired, live) {
+			return ""
+		}
+		// the quantities and numbers are stored in the canonical form, e.g. cpu: 0.5 is stored as 500m
+		if dq, ok := toQuantity(desired); ok {
+			if lq, ok := toQuantity(live); ok && dq.Cmp(lq) == 0 {
+				return ""
+			}
+		}
+		return path
+	}
+}
+
+// toQuantity parses the number or the numeric string, e.g. 0.5, "500m" and "1Gi", as a quantity.
+func toQuantity(v interface{}) (apiresource.Quantity, bool) {
+	var s string
+	switch n := v.(type) {
+	case int64:
+		return *apiresource.NewQuantity(n, apiresource.DecimalSI), true
+	case int:
+		return *apiresource.NewQuantity(int64(n), apiresource.DecimalSI), true
+	case float64:
+		s = strconv.FormatFloat(n, 'f', -1, 64)
+	case string:
+		s = n
+	default:
+		return apiresource.Quantity{}, false
+	}
+	q, err := apiresource.ParseQuantity(s)
+	if err != nil {
+		return apiresource.Quantity{}, false
+	}
+	return q, true
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmwrapper
+
+import (
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+const desiredDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  creationTimestamp: null
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+`
+
+func TestDivergedField(t *testing.T) {
+	tests := []struct {
+		name     string
+		desired  string
+		live     string
+		expected string
+	}{
+		{
+			name:    "fields set by server are ignored",
+			desired: desiredDeployment,
+			live: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  uid: 1234
+  creationTimestamp: "2023-01-01T00:00:00Z"
+spec:
+  replicas: 1
+  strategy:
+    type: RollingUpdate
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+        imagePullPolicy: IfNotPresent
+status:
+  replicas: 1
+`,
+		},
+		{
+			name:    "modified",
+			desired: desiredDeployment,
+			live: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+`,
+			expected: "spec.replicas",
+		},
+		{
+			name:    "container added",
+			desired: desiredDeployment,
+			live: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+      - name: sidecar
+        image: busybox
+`,
+			expected: "spec.template.spec.containers",
+		},
+		{
+			name: "quantities in canonical form",
+			desired: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+spec:
+  containers:
+  - name: nginx
+    resources:
+      limits:
+        cpu: 0.5
+        memory: 1024Mi
+      requests:
+        cpu: "1"
+        memory: "1e9"
+`,
+			live: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+spec:
+  containers:
+  - name: nginx
+    resources:
+      limits:
+        cpu: 500m
+        memory: 1Gi
+      requests:
+        cpu: 1
+        memory: 1G
+`,
+		},
+		{
+			name: "quantity modified",
+			desired: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+spec:
+  containers:
+  - name: nginx
+    resources:
+      limits:
+        cpu: 0.5
+`,
+			live: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+spec:
+  containers:
+  - name: nginx
+    resources:
+      limits:
+        cpu: "1"
+`,
+			expected: "spec.containers[0].resources.limits.cpu",
+		},
+		{
+			name: "secret string data",
+			desired: `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: nginx
+stringData:
+  password: P@88w0rd
+`,
+			live: `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: nginx
+data:
+  password: UEA4OHcwcmQ=
+type: Opaque
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := make(map[string]interface{})
+			if err := yaml.Unmarshal([]byte(tt.desired), &desired); err != nil {
+				t.Fatal(err)
+			}
+			live := make(map[string]interface{})
+			if err := yaml.Unmarshal([]byte(tt.live), &live); err != nil {
+				t.Fatal(err)
+			}
+			if field := divergedField(normalizeObject(desired), live); field != tt.expected {
+				t.Errorf("got %q, want %q", field, tt.expected)
+			}
+		})
+	}
+}
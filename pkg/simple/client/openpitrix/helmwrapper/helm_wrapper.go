@@ -31,9 +31,12 @@ import (
 
 	"helm.sh/helm/v3/pkg/chartutil"
 	helmrelease "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
 	"k8s.io/klog/v2"
 	kpath "k8s.io/utils/path"
 
+	"kubesphere.io/api/application/v1alpha1"
+
 	"kubesphere.io/kubesphere/pkg/server/errors"
 	"kubesphere.io/kubesphere/pkg/utils/idutils"
 )
@@ -61,8 +64,19 @@ type HelmWrapper interface {
 	// upgrade a release
 	Upgrade(chartName, chartData, values string) error
 	Uninstall() error
+	// Rollback rolls back the release to the given revision
+	Rollback(revision int) error
 	// Get manifests
 	Manifest() (string, error)
+	// History returns all the revisions of the release, the latest comes first
+	History() ([]*helmrelease.Release, error)
+	// Revision returns the given revision of the release
+	Revision(revision int) (*helmrelease.Release, error)
+	// Render renders the manifest of the chart in dry-run mode, the release is upgraded
+	// if it's installed, otherwise it's installed
+	Render(chartName, chartData, values string) (string, error)
+	// Drift returns the resources of the release which are modified or deleted in the cluster
+	Drift() ([]v1alpha1.DriftedResource, error)
 
 	// IsReleaseReady check helm release is ready or not
 	IsReleaseReady(timeout time.Duration) (bool, error)
@@ -302,7 +316,8 @@ func (c *helmWrapper) Upgrade(chartName, chartData, values string) error {
 	}
 
 	if sts.Info.Status == "deployed" {
-		return c.writeAction(chartName, chartData, values, true)
+		_, err = c.writeAction(chartName, chartData, values, true)
+		return err
 	} else {
 		err = errors.New("cannot upgrade release %s/%s, current state is %s", c.Namespace, c.ReleaseName, sts.Info.Status)
 		return err
@@ -321,7 +336,8 @@ func (c *helmWrapper) Install(chartName, chartData, values string) error {
 	} else {
 		if err.Error() == StatusNotFoundFormat {
 			// continue to install
-			return c.writeAction(chartName, chartData, values, false)
+			_, err = c.writeAction(chartName, chartData, values, false)
+			return err
 		}
 		return err
 	}
@@ -362,7 +378,7 @@ func (c *helmWrapper) helmInstall(chart *chart.Chart, values map[string]interfac
 	return install.Run(chart, values)
 }
 
-func (c *helmWrapper) writeAction(chartName, chartData, values string, upgrade bool) error {
+func (c *helmWrapper) writeAction(chartName, chartData, values string, upgrade bool) (*helmrelease.Release, error) {
 	if klog.V(2).Enabled() {
 		start := time.Now()
 		defer func() {
@@ -371,23 +387,23 @@ func (c *helmWrapper) writeAction(chartName, chartData, values string, upgrade b
 	}
 
 	if err := c.ensureWorkspace(); err != nil {
-		return err
+		return nil, err
 	}
 	defer c.cleanup()
 
 	if err := c.createChart(chartName, chartData, values); err != nil {
-		return err
+		return nil, err
 	}
 	klog.V(8).Infof("namespace: %s, name: %s, chart values: %s", c.Namespace, c.ReleaseName, values)
 
 	chartRequested, err := loader.Load(c.chartPath())
 	if err != nil {
-		return err
+		return nil, err
 	}
 	valuePath := filepath.Join(c.Workspace(), "values.yaml")
 	helmValues, err := chartutil.ReadValuesFile(valuePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var rel *helmrelease.Release
@@ -403,12 +419,36 @@ func (c *helmWrapper) writeAction(chartName, chartData, values string, upgrade b
 
 	if err != nil {
 		klog.Errorf("namespace: %s, name: %s,  error: %v", c.Namespace, c.ReleaseName, err)
-		return err
+		return nil, err
 	}
 
 	klog.V(2).Infof("namespace: %s, name: %s, run command success", c.Namespace, c.ReleaseName)
 	klog.V(8).Infof("namespace: %s, name: %s, run command success, manifest: %s", c.Namespace, c.ReleaseName, rel.Manifest)
-	return nil
+	return rel, nil
+}
+
+// Render runs helm install or upgrade in dry-run mode and returns the rendered manifest,
+// the post renderer is applied and the resources are validated against the cluster.
+func (c *helmWrapper) Render(chartName, chartData, values string) (string, error) {
+	dryRun := c.dryRun
+	c.dryRun = true
+	defer func() {
+		c.dryRun = dryRun
+	}()
+
+	upgrade := true
+	if _, err := c.Status(); err != nil {
+		if err.Error() != StatusNotFoundFormat {
+			return "", err
+		}
+		upgrade = false
+	}
+
+	rel, err := c.writeAction(chartName, chartData, values, upgrade)
+	if err != nil {
+		return "", err
+	}
+	return rel.Manifest, nil
 }
 
 func (c *helmWrapper) Manifest() (string, error) {
@@ -424,3 +464,52 @@ func (c *helmWrapper) Manifest() (string, error) {
 	klog.V(8).Infof("namespace: %s, name: %s, run command success, manifest: %s", c.Namespace, c.ReleaseName, rel.Manifest)
 	return rel.Manifest, nil
 }
+
+// helm history
+func (c *helmWrapper) History() ([]*helmrelease.Release, error) {
+	history := action.NewHistory(c.helmConf)
+	releases, err := history.Run(c.ReleaseName)
+	if err != nil {
+		klog.Errorf("namespace: %s, name: %s, run command failed, error: %v", c.Namespace, c.ReleaseName, err)
+		return nil, err
+	}
+	releaseutil.Reverse(releases, releaseutil.SortByRevision)
+	return releases, nil
+}
+
+// helm get --revision
+func (c *helmWrapper) Revision(revision int) (*helmrelease.Release, error) {
+	get := action.NewGet(c.helmConf)
+	get.Version = revision
+	rel, err := get.Run(c.ReleaseName)
+	if err != nil {
+		klog.Errorf("namespace: %s, name: %s, revision: %d, run command failed, error: %v", c.Namespace, c.ReleaseName, revision, err)
+		return nil, err
+	}
+	return rel, nil
+}
+
+// helm rollback
+func (c *helmWrapper) Rollback(revision int) error {
+	start := time.Now()
+	defer func() {
+		klog.V(2).Infof("run command end, namespace: %s, name: %s, revision: %d, elapsed: %v", c.Namespace, c.ReleaseName, revision, time.Since(start))
+	}()
+
+	if c.mock {
+		return nil
+	}
+
+	rollback := action.NewRollback(c.helmConf)
+	rollback.Version = revision
+	if c.dryRun {
+		rollback.DryRun = true
+	}
+
+	if err := rollback.Run(c.ReleaseName); err != nil {
+		klog.Errorf("namespace: %s, name: %s, rollback to revision %d failed, error: %v", c.Namespace, c.ReleaseName, revision, err)
+		return err
+	}
+	klog.V(2).Infof("namespace: %s, name: %s, run command success", c.Namespace, c.ReleaseName)
+	return nil
+}
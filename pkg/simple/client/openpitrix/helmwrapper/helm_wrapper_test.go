@@ -17,11 +17,18 @@ limitations under the License.
 package helmwrapper
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"testing"
 
+	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
 
 	"kubesphere.io/kubesphere/pkg/constants"
 )
@@ -33,12 +40,58 @@ func TestHelmInstall(t *testing.T) {
 	charData := GenerateChartData(t, "dummy-chart")
 	chartValues := `helm-wrapper: "test-val"`
 
-	err := wr.writeAction("dummy-chart", charData, chartValues, false)
+	_, err := wr.writeAction("dummy-chart", charData, chartValues, false)
 	if err != nil {
 		t.Fail()
 	}
 }
 
+func TestHelmRollback(t *testing.T) {
+	wr := &helmWrapper{
+		Namespace:   "dummy",
+		ReleaseName: "dummy",
+		helmConf: &action.Configuration{
+			Releases:     storage.Init(driver.NewMemory()),
+			KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+			Capabilities: chartutil.DefaultCapabilities,
+			Log:          t.Logf,
+		},
+	}
+	for revision, status := range []helmrelease.Status{helmrelease.StatusSuperseded, helmrelease.StatusDeployed} {
+		rel := helmrelease.Mock(&helmrelease.MockReleaseOptions{
+			Name:      wr.ReleaseName,
+			Namespace: wr.Namespace,
+			Version:   revision + 1,
+			Status:    status,
+		})
+		rel.Chart.Metadata.Version = fmt.Sprintf("0.%d.0", revision+1)
+		if err := wr.helmConf.Releases.Create(rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := wr.Rollback(1); err != nil {
+		t.Fatal(err)
+	}
+	history, err := wr.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 || history[0].Version != 3 || history[0].Info.Status != helmrelease.StatusDeployed {
+		t.Fatalf("unexpected history after rollback")
+	}
+	if history[0].Chart.Metadata.Version != "0.1.0" {
+		t.Errorf("expected chart version 0.1.0, got %s", history[0].Chart.Metadata.Version)
+	}
+	rel, err := wr.Revision(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.Info.Status != helmrelease.StatusSuperseded {
+		t.Errorf("expected revision 2 superseded, got %s", rel.Info.Status)
+	}
+}
+
 func TempDir(t *testing.T) string {
 	t.Helper()
 	d, err := os.MkdirTemp("", "kubesphere")
@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmwrapper
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	DiffActionCreated   = "created"
+	DiffActionDeleted   = "deleted"
+	DiffActionModified  = "modified"
+	DiffActionUnchanged = "unchanged"
+)
+
+// ResourceDiff is the change of a resource between two manifests
+type ResourceDiff struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// one of created, deleted, modified and unchanged
+	Action string `json:"action"`
+	// unified diff of the resource, empty if unchanged
+	Diff string `json:"diff,omitempty"`
+}
+
+type manifestResource struct {
+	obj  *unstructured.Unstructured
+	data string
+}
+
+func (r *manifestResource) key() string {
+	return fmt.Sprintf("%s/%s/%s/%s", r.obj.GetAPIVersion(), r.obj.GetKind(), r.obj.GetNamespace(), r.obj.GetName())
+}
+
+// parseManifest splits the manifest into resources, indexed by apiVersion, kind, namespace and name.
+// The resources are marshaled again, so the formatting and field order don't make any difference.
+func parseManifest(manifest string) (map[string]*manifestResource, error) {
+	resources := make(map[string]*manifestResource)
+	for _, content := range releaseutil.SplitManifests(manifest) {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(content), &obj.Object); err != nil {
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		r := &manifestResource{obj: obj, data: string(data)}
+		resources[r.key()] = r
+	}
+	return resources, nil
+}
+
+// DiffManifest compares the resources in the live manifest and the target manifest,
+// the result is sorted by kind, namespace and name.
+func DiffManifest(live, target string) ([]ResourceDiff, error) {
+	liveResources, err := parseManifest(live)
+	if err != nil {
+		return nil, fmt.Errorf("parse live manifest failed: %v", err)
+	}
+	targetResources, err := parseManifest(target)
+	if err != nil {
+		return nil, fmt.Errorf("parse target manifest failed: %v", err)
+	}
+
+	diffs := make([]ResourceDiff, 0, len(targetResources))
+	for key, r := range targetResources {
+		diff := newResourceDiff(r.obj)
+		old, exists := liveResources[key]
+		var oldData string
+		switch {
+		case !exists:
+			diff.Action = DiffActionCreated
+		case old.data == r.data:
+			diff.Action = DiffActionUnchanged
+		default:
+			diff.Action = DiffActionModified
+			oldData = old.data
+		}
+		if diff.Action != DiffActionUnchanged {
+			if diff.Diff, err = unifiedDiff(oldData, r.data); err != nil {
+				return nil, err
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	for key, r := range liveResources {
+		if _, exists := targetResources[key]; exists {
+			continue
+		}
+		diff := newResourceDiff(r.obj)
+		diff.Action = DiffActionDeleted
+		if diff.Diff, err = unifiedDiff(r.data, ""); err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Kind != diffs[j].Kind {
+			return diffs[i].Kind < diffs[j].Kind
+		}
+		if diffs[i].Namespace != diffs[j].Namespace {
+			return diffs[i].Namespace < diffs[j].Namespace
+		}
+		if diffs[i].Name != diffs[j].Name {
+			return diffs[i].Name < diffs[j].Name
+		}
+		return diffs[i].APIVersion < diffs[j].APIVersion
+	})
+	return diffs, nil
+}
+
+func newResourceDiff(obj *unstructured.Unstructured) ResourceDiff {
+	return ResourceDiff{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+func unifiedDiff(live, target string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(live),
+		B:        difflib.SplitLines(target),
+		FromFile: "live",
+		ToFile:   "target",
+		Context:  3,
+	})
+}
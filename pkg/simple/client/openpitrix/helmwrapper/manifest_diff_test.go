@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmwrapper
+
+import (
+	"strings"
+	"testing"
+)
+
+const liveManifest = `---
+# Source: nginx/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nginx
+data:
+  key: value
+---
+# Source: nginx/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+  - port: 80
+---
+# Source: nginx/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 1
+`
+
+const targetManifest = `---
+# Source: nginx/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 2
+metadata:
+  name: nginx
+---
+# Source: nginx/templates/service.yaml
+kind: Service
+apiVersion: v1
+metadata:
+  name: nginx
+spec:
+  ports:
+    - port: 80
+---
+# Source: nginx/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: nginx
+`
+
+func TestDiffManifest(t *testing.T) {
+	diffs, err := DiffManifest(liveManifest, targetManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		kind   string
+		action string
+	}{
+		{"ConfigMap", DiffActionDeleted},
+		{"Deployment", DiffActionModified},
+		{"Secret", DiffActionCreated},
+		{"Service", DiffActionUnchanged},
+	}
+	if len(diffs) != len(expected) {
+		t.Fatalf("got %d diffs, want %d", len(diffs), len(expected))
+	}
+	for i, e := range expected {
+		if diffs[i].Kind != e.kind || diffs[i].Action != e.action {
+			t.Errorf("got %s %s, want %s %s", diffs[i].Kind, diffs[i].Action, e.kind, e.action)
+		}
+	}
+
+	if !strings.Contains(diffs[1].Diff, "-  replicas: 1") || !strings.Contains(diffs[1].Diff, "+  replicas: 2") {
+		t.Errorf("unexpected diff: %s", diffs[1].Diff)
+	}
+	if diffs[3].Diff != "" {
+		t.Errorf("unexpected diff of unchanged resource: %s", diffs[3].Diff)
+	}
+}
@@ -33,6 +33,9 @@ type Options struct {
 type ReleaseControllerOptions struct {
 	MaxConcurrent int           `json:"maxConcurrent,omitempty" yaml:"maxConcurrent,omitempty" mapstructure:"maxConcurrent"`
 	WaitTime      time.Duration `json:"waitTime,omitempty" yaml:"waitTime,omitempty" mapstructure:"waitTime"`
+	// DriftCheckInterval is the interval of checking whether the resources of releases are modified, 0 means disable,
+	// which is the default as each check gets all the resources of a release from the cluster
+	DriftCheckInterval time.Duration `json:"driftCheckInterval,omitempty" yaml:"driftCheckInterval,omitempty" mapstructure:"driftCheckInterval"`
 }
 
 func NewOptions() *Options {
@@ -91,4 +94,5 @@ func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
 
 	fs.DurationVar(&s.ReleaseControllerOptions.WaitTime, "openpitrix-release-controller-options-wait-time", c.ReleaseControllerOptions.WaitTime, "wait time when check release is ready or not")
 	fs.IntVar(&s.ReleaseControllerOptions.MaxConcurrent, "openpitrix-release-controller-options-max-concurrent", c.ReleaseControllerOptions.MaxConcurrent, "the maximum number of concurrent Reconciles which can be run for release controller")
+	fs.DurationVar(&s.ReleaseControllerOptions.DriftCheckInterval, "openpitrix-release-controller-options-drift-check-interval", c.ReleaseControllerOptions.DriftCheckInterval, "interval of checking whether the resources of releases are drifted, 0 means disable drift detection, which is the default")
 }
@@ -0,0 +1,45 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"kubesphere.io/kubesphere/pkg/server/healthz"
+)
+
+// NewHealthChecker returns a healthz checker named "s3" which checks the bucket is accessible.
+func NewHealthChecker(options *Options) (healthz.HealthChecker, error) {
+	client, err := NewS3Client(options)
+	if err != nil {
+		return nil, err
+	}
+	return healthz.NamedCheck("s3", func(r *http.Request) error {
+		c, ok := client.(*Client)
+		if !ok {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), healthz.CheckTimeout)
+		defer cancel()
+		_, err := c.Client().HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(options.Bucket)})
+		return err
+	}), nil
+}
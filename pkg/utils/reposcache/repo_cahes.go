@@ -284,7 +284,8 @@ func (c *cachedRepos) addRepo(repo *v1alpha1.HelmRepo, builtin bool) error {
 					Data:   chartData,
 				},
 				Status: v1alpha1.HelmApplicationVersionStatus{
-					State: v1alpha1.StateActive,
+					State:        v1alpha1.StateActive,
+					Verification: chartVersion.Verification,
 				},
 			}
 
@@ -412,10 +413,7 @@ func (c *cachedRepos) getAppVersion(versionId string, withData bool) (ret *v1alp
 			}
 
 			c.RUnlock()
-			url := version.Spec.URLs[0]
-			if !(strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "s3://")) {
-				url = repo.Spec.Url + "/" + url
-			}
+			url := helmrepoindex.ChartURL(repo, version.Spec.URLs[0])
 
 			buf, err := helmrepoindex.LoadChart(context.TODO(), url, &repo.Spec.Credential)
 			if err != nil {
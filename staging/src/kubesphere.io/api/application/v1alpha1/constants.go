@@ -43,6 +43,22 @@ const (
 	HelmStatusCreated     = "created"
 	HelmStatusUpgraded    = "upgraded"
 
+	// helm release condition
+	HelmReleaseConditionDrifted = "Drifted"
+
+	// reason of the drifted resource
+	DriftReasonModified = "Modified"
+	DriftReasonDeleted  = "Deleted"
+
+	// chart verification state
+	VerificationStateVerified = "verified"
+	VerificationStateUnsigned = "unsigned"
+	VerificationStateFailed   = "failed"
+
+	// chart signature kind
+	VerificationMethodPGP    = "pgp"
+	VerificationMethodCosign = "cosign"
+
 	AttachmentTypeScreenshot = "screenshot"
 	AttachmentTypeIcon       = "icon"
 
@@ -54,6 +54,24 @@ type HelmApplicationVersionSpec struct {
 type HelmApplicationVersionStatus struct {
 	State string  `json:"state,omitempty"`
 	Audit []Audit `json:"audit,omitempty"`
+	// result of verifying the signature of the chart
+	Verification *ChartVerificationStatus `json:"verification,omitempty"`
+}
+
+// ChartVerificationStatus is the result of verifying the signature of a chart package
+type ChartVerificationStatus struct {
+	// verified, unsigned or failed
+	State string `json:"state"`
+	// the kind of the signature, pgp or cosign
+	Method string `json:"method,omitempty"`
+	// the identity of the PGP key which signed the chart
+	Signer string `json:"signer,omitempty"`
+	// A human readable message indicating details about the verification
+	Message string `json:"message,omitempty"`
+	// the sha256 digest of the chart package verified, e.g. sha256:<hex>
+	Digest string `json:"digest,omitempty"`
+	// the time when the chart was verified
+	Time *metav1.Time `json:"time,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -231,3 +249,7 @@ func (in *HelmApplicationVersion) State() string {
 
 	return in.Status.State
 }
+
+func (in *HelmApplicationVersion) IsVerified() bool {
+	return in.Status.Verification != nil && in.Status.Verification.State == VerificationStateVerified
+}
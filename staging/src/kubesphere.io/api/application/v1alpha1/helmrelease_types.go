@@ -53,6 +53,11 @@ type HelmReleaseSpec struct {
 	// expected release version, when this version is not equal status.version, the release need upgrade
 	// this filed should be modified when any filed of the spec modified.
 	Version int `json:"version"`
+	// the revision of the helm release to roll back to, the release is rolled back instead of upgraded
+	// when version is changed, and it should be cleared when the release is upgraded.
+	RollbackRevision int `json:"rollbackRevision,omitempty"`
+	// re-apply the release automatically when the resources are found drifted from the release manifest
+	ReconcileDrift bool `json:"reconcileDrift,omitempty"`
 }
 
 type HelmReleaseDeployStatus struct {
@@ -78,6 +83,22 @@ type HelmReleaseStatus struct {
 	LastUpdate metav1.Time `json:"lastUpdate,omitempty"`
 	// last deploy time or upgrade time
 	LastDeployed *metav1.Time `json:"lastDeployed,omitempty"`
+	// conditions of the release, such as Drifted
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// resources which are modified or deleted after the release is deployed
+	DriftedResources []DriftedResource `json:"driftedResources,omitempty"`
+}
+
+// DriftedResource is a resource of the release whose live state diverges from the release manifest
+type DriftedResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Modified or Deleted
+	Reason string `json:"reason"`
+	// the first field diverged from the manifest
+	Field string `json:"field,omitempty"`
 }
 
 // +kubebuilder:object:root=true
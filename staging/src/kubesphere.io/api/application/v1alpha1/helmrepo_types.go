@@ -41,6 +41,8 @@ type HelmRepoCredential struct {
 	CAFile string `json:"caFile,omitempty"`
 	// skip tls certificate checks for the repository, default is ture
 	InsecureSkipTLSVerify *bool `json:"insecureSkipTLSVerify,omitempty"`
+	// access the OCI registry over plain http, only for the registries which don't serve https
+	PlainHTTP bool `json:"plainHTTP,omitempty"`
 
 	S3Config `json:",inline"`
 }
@@ -50,6 +52,16 @@ type S3Config struct {
 	SecretAccessKey string `json:"secretAccessKey,omitempty"`
 }
 
+// ChartVerification specifies the keys used to verify the signatures of the chart packages
+type ChartVerification struct {
+	// armored PGP public keyring, used to verify the provenance files (.prov) of the charts
+	Keyring string `json:"keyring,omitempty"`
+	// PEM encoded public key, used to verify the cosign signatures (.sig) of the charts
+	CosignKey string `json:"cosignKey,omitempty"`
+	// only the chart versions which are signed and verified are available if required
+	Required bool `json:"required,omitempty"`
+}
+
 // HelmRepoSpec defines the desired state of HelmRepo
 type HelmRepoSpec struct {
 	// name of the repo
@@ -62,6 +74,8 @@ type HelmRepoSpec struct {
 	Description string `json:"description,omitempty"`
 	// sync period in seconds, no sync when SyncPeriod=0, the minimum SyncPeriod is 180s
 	SyncPeriod int `json:"syncPeriod,omitempty"`
+	// verify the signatures of the charts in the repo
+	Verification *ChartVerification `json:"verification,omitempty"`
 	// expected repo version, when this version is not equal status.version, the repo need upgrade
 	// this filed should be modified when any filed of the spec modified.
 	Version int `json:"version,omitempty"`
@@ -139,3 +153,8 @@ func (in *HelmRepo) GetWorkspace() string {
 func (in *HelmRepo) GetCreator() string {
 	return getValue(in.Annotations, constants.CreatorAnnotationKey)
 }
+
+// VerificationRequired returns true if only the verified charts in the repo can be deployed
+func (in *HelmRepo) VerificationRequired() bool {
+	return in.Spec.Verification != nil && in.Spec.Verification.Required
+}
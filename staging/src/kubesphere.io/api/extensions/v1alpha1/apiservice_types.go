@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ResourceKindAPIService      = "APIService"
+	ResourcesSingularAPIService = "apiservice"
+	ResourcesPluralAPIService   = "apiservices"
+
+	// APIServiceAvailable is the condition type of whether the backend of an APIService is reachable.
+	APIServiceAvailable = "Available"
+)
+
+// AuthMode is how the credentials of a request are passed to the backend.
+type AuthMode string
+
+const (
+	// AuthModePassthrough forwards the Authorization header of the request as it is.
+	AuthModePassthrough AuthMode = "Passthrough"
+	// AuthModeRequestHeader drops the Authorization header, and sets the authenticated
+	// user in X-Remote-User and X-Remote-Group headers instead.
+	AuthModeRequestHeader AuthMode = "RequestHeader"
+	// AuthModeNone drops the Authorization header.
+	AuthModeNone AuthMode = "None"
+)
+
+// ServiceReference holds a reference to a Service.
+type ServiceReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Port of the service, defaults to 443.
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+	// Path prepended to the requests proxied to the service.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// APIServiceSpec defines the desired state of APIService
+type APIServiceSpec struct {
+	// Group of the APIs, served at /kapis/{group}/{version}.
+	Group string `json:"group"`
+	// Version of the APIs.
+	Version string `json:"version"`
+
+	// Service is the backend of the APIs in the cluster, served by https.
+	// Either Service or URL must be specified.
+	// +optional
+	Service *ServiceReference `json:"service,omitempty"`
+	// URL of the backend of the APIs, e.g. https://devops-apiserver.kubesphere-devops-system:9443/kapis/devops.kubesphere.io
+	// +optional
+	URL *string `json:"url,omitempty"`
+
+	// CABundle is a PEM encoded CA bundle used to validate the serving certificate of the backend,
+	// the system trust roots are used if it's empty.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+	// InsecureSkipTLSVerify disables TLS certificate verification of the backend.
+	// +optional
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// AuthMode is how the credentials of requests are passed to the backend, defaults to Passthrough.
+	// +kubebuilder:validation:Enum=Passthrough;RequestHeader;None
+	// +optional
+	AuthMode AuthMode `json:"authMode,omitempty"`
+	// InsecurePassthrough allows the credentials of requests to be passed through to the backend served by http,
+	// the backends of the Passthrough auth mode must be served by https otherwise.
+	// +optional
+	InsecurePassthrough bool `json:"insecurePassthrough,omitempty"`
+}
+
+// APIServiceStatus defines the observed state of APIService
+type APIServiceStatus struct {
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Group",type="string",JSONPath=".spec.group"
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".spec.version"
+// +kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// APIService declares a group/version of KubeSphere APIs served by a backend,
+// ks-apiserver proxies the requests of /kapis/{group}/{version} to it once it's created.
+// The group/versions served by ks-apiserver itself can't be overridden.
+type APIService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   APIServiceSpec   `json:"spec,omitempty"`
+	Status APIServiceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// APIServiceList contains a list of APIService
+type APIServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []APIService `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&APIService{}, &APIServiceList{})
+}
@@ -61,6 +61,7 @@ const (
 	GrantedClustersAnnotation             = "iam.kubesphere.io/granted-clusters"
 	UninitializedAnnotation               = "iam.kubesphere.io/uninitialized"
 	LastPasswordChangeTimeAnnotation      = "iam.kubesphere.io/last-password-change-time"
+	PasswordHistoryAnnotation             = "iam.kubesphere.io/password-history"
 	RoleAnnotation                        = "iam.kubesphere.io/role"
 	RoleTemplateLabel                     = "iam.kubesphere.io/role-template"
 	ScopeLabelFormat                      = "scope.kubesphere.io/%s"
@@ -75,6 +76,7 @@ const (
 	ExtraUsername                         = "username"
 	ExtraDisplayName                      = "displayName"
 	ExtraUninitialized                    = "uninitialized"
+	ExtraPasswordExpired                  = "passwordExpired"
 	InGroup                               = "ingroup"
 	NotInGroup                            = "notingroup"
 	AggregateTo                           = "aggregateTo"
@@ -157,6 +159,8 @@ const (
 	UserDisabled UserState = "Disabled"
 	// UserAuthLimitExceeded means restrict user login.
 	UserAuthLimitExceeded UserState = "AuthLimitExceeded"
+	// UserLocked means the user is locked after too many failed login attempts, only administrators can unlock it.
+	UserLocked UserState = "Locked"
 
 	AuthenticatedSuccessfully = "authenticated successfully"
 )